@@ -19,6 +19,7 @@ package v1alpha2
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -27,6 +28,7 @@ import (
 	"github.com/kubesphere/kubekey/pkg/core/logger"
 	"github.com/kubesphere/kubekey/pkg/core/util"
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -40,16 +42,19 @@ type ClusterSpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Foo is an example field of Cluster. Edit Cluster_types.go to remove/update
-	Hosts                []HostCfg            `yaml:"hosts" json:"hosts,omitempty"`
-	RoleGroups           map[string][]string  `yaml:"roleGroups" json:"roleGroups,omitempty"`
-	ControlPlaneEndpoint ControlPlaneEndpoint `yaml:"controlPlaneEndpoint" json:"controlPlaneEndpoint,omitempty"`
-	System               System               `yaml:"system" json:"system,omitempty"`
-	Etcd                 EtcdCluster          `yaml:"etcd" json:"etcd,omitempty"`
-	Kubernetes           Kubernetes           `yaml:"kubernetes" json:"kubernetes,omitempty"`
-	Network              NetworkConfig        `yaml:"network" json:"network,omitempty"`
-	Registry             RegistryConfig       `yaml:"registry" json:"registry,omitempty"`
-	Addons               []Addon              `yaml:"addons" json:"addons,omitempty"`
-	KubeSphere           KubeSphere           `json:"kubesphere,omitempty"`
+	Hosts                []HostCfg              `yaml:"hosts" json:"hosts,omitempty"`
+	RoleGroups           map[string][]string    `yaml:"roleGroups" json:"roleGroups,omitempty"`
+	RoleGroupConfigs     map[string]NodeConfig  `yaml:"roleGroupConfigs" json:"roleGroupConfigs,omitempty"`
+	ControlPlaneEndpoint ControlPlaneEndpoint   `yaml:"controlPlaneEndpoint" json:"controlPlaneEndpoint,omitempty"`
+	System               System                 `yaml:"system" json:"system,omitempty"`
+	Etcd                 EtcdCluster            `yaml:"etcd" json:"etcd,omitempty"`
+	Kubernetes           Kubernetes             `yaml:"kubernetes" json:"kubernetes,omitempty"`
+	Network              NetworkConfig          `yaml:"network" json:"network,omitempty"`
+	Registry             RegistryConfig         `yaml:"registry" json:"registry,omitempty"`
+	ImageBOM             ImageBOM               `yaml:"imageBOM" json:"imageBOM,omitempty"`
+	Addons               []Addon                `yaml:"addons" json:"addons,omitempty"`
+	KubeSphere           KubeSphere             `json:"kubesphere,omitempty"`
+	Hooks                map[string]ModuleHooks `yaml:"hooks" json:"hooks,omitempty"`
 }
 
 // ClusterStatus defines the observed state of Cluster
@@ -154,6 +159,50 @@ type HostCfg struct {
 
 	// Labels defines the kubernetes labels for the node.
 	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	// Taints defines the kubernetes taints for the node.
+	Taints []Taint `yaml:"taints,omitempty" json:"taints,omitempty"`
+	// KubeletConfiguration overrides the kubelet configuration of the cluster on the node, e.g. maxPods, kubeReserved and evictionHard.
+	KubeletConfiguration runtime.RawExtension `yaml:"kubeletConfiguration,omitempty" json:"kubeletConfiguration,omitempty"`
+}
+
+// NodeConfig defines the settings shared by the nodes of a role group in roleGroupConfigs, e.g. a node pool.
+// The settings of a host take precedence over the ones of its role groups.
+type NodeConfig struct {
+	Labels map[string]string `yaml:"labels" json:"labels,omitempty"`
+	Taints []Taint           `yaml:"taints" json:"taints,omitempty"`
+	// KubeletConfiguration overrides the kubelet configuration of the cluster, the top-level fields are replaced as a whole.
+	KubeletConfiguration runtime.RawExtension `yaml:"kubeletConfiguration" json:"kubeletConfiguration,omitempty"`
+	// KubeletArgs are the extra flags of kubelet, e.g. node-status-update-frequency=4s, which are set when the nodes join the cluster.
+	KubeletArgs []string `yaml:"kubeletArgs" json:"kubeletArgs,omitempty"`
+	// ContainerRuntime overrides the settings of the container runtime of the cluster.
+	ContainerRuntime ContainerRuntimeConfig `yaml:"containerRuntime" json:"containerRuntime,omitempty"`
+	// PrepareScripts are the shell scripts run on the nodes after the operating system is initialized,
+	// e.g. to install the GPU drivers. They are run again on each run of kk, so they must be idempotent.
+	PrepareScripts []string `yaml:"prepareScripts" json:"prepareScripts,omitempty"`
+}
+
+// ContainerRuntimeConfig defines the settings of the container runtime which override the ones in registry.
+type ContainerRuntimeConfig struct {
+	RegistryMirrors    []string `yaml:"registryMirrors" json:"registryMirrors,omitempty"`
+	InsecureRegistries []string `yaml:"insecureRegistries" json:"insecureRegistries,omitempty"`
+	// DataRoot is the root directory of docker.
+	DataRoot string `yaml:"dataRoot" json:"dataRoot,omitempty"`
+}
+
+// Taint defines a kubernetes taint of the node.
+type Taint struct {
+	Key   string `yaml:"key" json:"key,omitempty"`
+	Value string `yaml:"value" json:"value,omitempty"`
+	// Effect is one of NoSchedule, PreferNoSchedule and NoExecute.
+	Effect string `yaml:"effect" json:"effect,omitempty"`
+}
+
+// String returns the taint in the form accepted by kubectl taint, i.e. key=value:effect.
+func (t Taint) String() string {
+	if t.Value == "" {
+		return fmt.Sprintf("%s:%s", t.Key, t.Effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect)
 }
 
 // ControlPlaneEndpoint defines the control plane endpoint information for cluster.
@@ -162,6 +211,41 @@ type ControlPlaneEndpoint struct {
 	Domain               string `yaml:"domain" json:"domain,omitempty"`
 	Address              string `yaml:"address" json:"address,omitempty"`
 	Port                 int    `yaml:"port" json:"port,omitempty"`
+	// KubeVip configures kube-vip when the internal load balancer is kube-vip.
+	KubeVip KubeVipCfg `yaml:"kubevip" json:"kubevip,omitempty"`
+	// Keepalived configures keepalived when the internal load balancer is keepalived+haproxy.
+	Keepalived KeepalivedCfg `yaml:"keepalived" json:"keepalived,omitempty"`
+}
+
+// KubeVipCfg defines how kube-vip announces the address of the control plane endpoint.
+type KubeVipCfg struct {
+	// Mode is either arp or bgp, defaults to arp.
+	Mode string `yaml:"mode" json:"mode,omitempty"`
+	// Interface is the network interface the VIP is bound to. It defaults to the interface of the
+	// internal address of each master in the arp mode, and to lo in the bgp mode.
+	Interface string     `yaml:"interface" json:"interface,omitempty"`
+	BGP       KubeVipBGP `yaml:"bgp" json:"bgp,omitempty"`
+}
+
+// KubeVipBGP defines the BGP settings of kube-vip. The router id of each master is its internal address.
+type KubeVipBGP struct {
+	AS    uint32    `yaml:"as" json:"as,omitempty"`
+	Peers []BGPPeer `yaml:"peers" json:"peers,omitempty"`
+}
+
+// BGPPeer defines a BGP neighbor.
+type BGPPeer struct {
+	Address  string `yaml:"address" json:"address,omitempty"`
+	AS       uint32 `yaml:"as" json:"as,omitempty"`
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+	Multihop bool   `yaml:"multihop,omitempty" json:"multihop,omitempty"`
+}
+
+// KeepalivedCfg defines the VRRP instance which holds the address of the control plane endpoint.
+type KeepalivedCfg struct {
+	// Interface is the network interface the VIP is bound to, defaults to the interface of the internal address of each master.
+	Interface       string `yaml:"interface" json:"interface,omitempty"`
+	VirtualRouterID int    `yaml:"virtualRouterID" json:"virtualRouterID,omitempty"`
 }
 
 // System defines the system config for each node in cluster.
@@ -179,6 +263,52 @@ type RegistryConfig struct {
 	DataRoot           string               `yaml:"dataRoot" json:"dataRoot,omitempty"`
 	NamespaceOverride  string               `yaml:"namespaceOverride" json:"namespaceOverride,omitempty"`
 	Auths              runtime.RawExtension `yaml:"auths" json:"auths,omitempty"`
+	// PKI supplies the CA or the server cert of the registry instead of the self-signed ones.
+	PKI PKI `yaml:"pki" json:"pki,omitempty"`
+}
+
+// PKI describes the CA and the certs supplied by users, which are used instead of the ones generated by KubeKey.
+type PKI struct {
+	// CAFile is the CA cert, which signs the certs not supplied in Certs.
+	// It can be an intermediate CA followed by its chain up to the root CA.
+	CAFile string `yaml:"caFile" json:"caFile,omitempty"`
+	// CAKeyFile is the key of the CA. It can be omitted if all the certs are supplied in Certs.
+	CAKeyFile string `yaml:"caKeyFile" json:"caKeyFile,omitempty"`
+	// Certs are the pre-issued certs, which are used instead of being signed by the CA.
+	Certs []PKICert `yaml:"certs" json:"certs,omitempty"`
+}
+
+// PKICert is a pre-issued cert and its key.
+type PKICert struct {
+	// Name is the base name of the cert, e.g. member-node1 of etcd, apiserver of kubernetes,
+	// or dockerhub.kubekey.local of registry.
+	Name string `yaml:"name" json:"name,omitempty"`
+	// CertFile may contain the intermediate CAs after the cert.
+	CertFile string `yaml:"certFile" json:"certFile,omitempty"`
+	KeyFile  string `yaml:"keyFile" json:"keyFile,omitempty"`
+}
+
+// Enabled returns true if the CA is supplied.
+func (p *PKI) Enabled() bool {
+	return p.CAFile != ""
+}
+
+// Cert returns the pre-issued cert of the name, or nil if it is not supplied.
+func (p *PKI) Cert(name string) *PKICert {
+	for i := range p.Certs {
+		if p.Certs[i].Name == name {
+			return &p.Certs[i]
+		}
+	}
+	return nil
+}
+
+// ImageBOM overrides the image bill-of-materials embedded in KubeKey.
+type ImageBOM struct {
+	// Components overrides the versions of the components, e.g. calico: v3.23.2
+	Components map[string]string `yaml:"components" json:"components,omitempty"`
+	// Images overrides the tags of the images, e.g. pause: "3.7"
+	Images map[string]string `yaml:"images" json:"images,omitempty"`
 }
 
 // KubeSphere defines the configuration information of the KubeSphere.
@@ -245,13 +375,107 @@ func (cfg *ClusterSpec) GroupHosts() map[string][]*KubeHost {
 		roleGroups[Master] = append(roleGroups[Master], host)
 	}
 
+	// The hosts of the node pools, i.e. the role groups other than the built-in ones, are workers.
+	for _, pool := range cfg.NodePools() {
+		if pool == K8s {
+			logger.Log.Fatal(fmt.Errorf("the role group %s is reserved", K8s))
+		}
+		for _, host := range roleGroups[pool] {
+			if !host.IsRole(Worker) {
+				host.SetRole(Worker)
+				roleGroups[Worker] = append(roleGroups[Worker], host)
+			}
+		}
+	}
+
+	if err := cfg.mergeNodeConfigs(hostMap, roleGroups); err != nil {
+		logger.Log.Fatal(err)
+	}
+	if err := cfg.validateHooks(roleGroups); err != nil {
+		logger.Log.Fatal(err)
+	}
+
 	return roleGroups
 }
 
+// NodePools returns the names of the node pools in roleGroups in order.
+func (cfg *ClusterSpec) NodePools() []string {
+	var pools []string
+	for role := range cfg.RoleGroups {
+		if !IsBuiltinRoleGroup(role) {
+			pools = append(pools, role)
+		}
+	}
+	sort.Strings(pools)
+	return pools
+}
+
+// IsBuiltinRoleGroup returns true if the role group is etcd, master, control-plane, worker or registry,
+// the other role groups are node pools.
+func IsBuiltinRoleGroup(role string) bool {
+	_, ok := roleGroupOrder[role]
+	return ok
+}
+
+// roleGroupOrder is the order in which the configs of the built-in role groups are merged,
+// the other role groups are merged after them by name.
+var roleGroupOrder = map[string]int{Etcd: 1, Master: 2, ControlPlane: 3, Worker: 4, Registry: 5}
+
+// mergeNodeConfigs merges the configs of the role groups into their hosts, then the config of each host.
+func (cfg *ClusterSpec) mergeNodeConfigs(hostMap map[string]*KubeHost, roleGroups map[string][]*KubeHost) error {
+	roles := make([]string, 0, len(cfg.RoleGroupConfigs))
+	for role := range cfg.RoleGroupConfigs {
+		if _, ok := roleGroups[role]; !ok {
+			return fmt.Errorf("the role group %s under roleGroupConfigs is not found in roleGroups", role)
+		}
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		oi, oj := roleGroupOrder[roles[i]], roleGroupOrder[roles[j]]
+		if oi == 0 || oj == 0 {
+			if oi != oj {
+				return oj == 0
+			}
+			return roles[i] < roles[j]
+		}
+		return oi < oj
+	})
+
+	for _, role := range roles {
+		if containerManager := cfg.Kubernetes.ContainerManager; cfg.RoleGroupConfigs[role].ContainerRuntime.DataRoot != "" &&
+			containerManager != "" && containerManager != Docker {
+			return fmt.Errorf("the dataRoot of the role group %s is only supported by docker, not %s", role, containerManager)
+		}
+		for _, host := range roleGroups[role] {
+			if err := host.mergeNodeConfig(cfg.RoleGroupConfigs[role]); err != nil {
+				return errors.Wrapf(err, "invalid config of the role group %s", role)
+			}
+		}
+	}
+	for _, hostCfg := range cfg.Hosts {
+		nodeConfig := NodeConfig{
+			Labels:               hostCfg.Labels,
+			Taints:               hostCfg.Taints,
+			KubeletConfiguration: hostCfg.KubeletConfiguration,
+		}
+		if err := hostMap[hostCfg.Name].mergeNodeConfig(nodeConfig); err != nil {
+			return errors.Wrapf(err, "invalid config of the host %s", hostCfg.Name)
+		}
+	}
+	return nil
+}
+
 // +kubebuilder:object:generate=false
 type KubeHost struct {
 	*connector.BaseHost
 	Labels map[string]string
+	Taints []Taint
+	// KubeletConfiguration is the kubelet configuration merged from the role groups and the host,
+	// which overrides the one of the cluster.
+	KubeletConfiguration map[string]interface{}
+	KubeletArgs          []string
+	ContainerRuntime     ContainerRuntimeConfig
+	PrepareScripts       []string
 }
 
 func toHosts(cfg HostCfg) *KubeHost {
@@ -268,12 +492,80 @@ func toHosts(cfg HostCfg) *KubeHost {
 	host.Timeout = *cfg.Timeout
 
 	kubeHost := &KubeHost{
-		BaseHost: host,
-		Labels:   cfg.Labels,
+		BaseHost:             host,
+		Labels:               make(map[string]string),
+		KubeletConfiguration: make(map[string]interface{}),
 	}
 	return kubeHost
 }
 
+// mergeNodeConfig merges the labels, the taints and the kubelet configuration into the host,
+// a taint replaces the one with the same key and effect.
+func (h *KubeHost) mergeNodeConfig(cfg NodeConfig) error {
+	for k, v := range cfg.Labels {
+		h.Labels[k] = v
+	}
+
+	for _, taint := range cfg.Taints {
+		if taint.Key == "" {
+			return errors.New("the key of taint is required")
+		}
+		switch taint.Effect {
+		case TaintEffectNoSchedule, TaintEffectPreferNoSchedule, TaintEffectNoExecute:
+		default:
+			return fmt.Errorf("invalid effect %q of taint %s, support: %s, %s, %s", taint.Effect, taint.Key,
+				TaintEffectNoSchedule, TaintEffectPreferNoSchedule, TaintEffectNoExecute)
+		}
+		replaced := false
+		for i := range h.Taints {
+			if h.Taints[i].Key == taint.Key && h.Taints[i].Effect == taint.Effect {
+				h.Taints[i] = taint
+				replaced = true
+			}
+		}
+		if !replaced {
+			h.Taints = append(h.Taints, taint)
+		}
+	}
+
+	if len(cfg.KubeletConfiguration.Raw) != 0 {
+		kubeletConfiguration := make(map[string]interface{})
+		if err := yaml.Unmarshal(cfg.KubeletConfiguration.Raw, &kubeletConfiguration); err != nil {
+			return errors.Wrap(err, "failed to parse kubelet configuration")
+		}
+		for k, v := range kubeletConfiguration {
+			h.KubeletConfiguration[k] = v
+		}
+	}
+
+	for _, arg := range cfg.KubeletArgs {
+		h.KubeletArgs = mergeArg(h.KubeletArgs, arg)
+	}
+	if len(cfg.ContainerRuntime.RegistryMirrors) != 0 {
+		h.ContainerRuntime.RegistryMirrors = cfg.ContainerRuntime.RegistryMirrors
+	}
+	if len(cfg.ContainerRuntime.InsecureRegistries) != 0 {
+		h.ContainerRuntime.InsecureRegistries = cfg.ContainerRuntime.InsecureRegistries
+	}
+	if cfg.ContainerRuntime.DataRoot != "" {
+		h.ContainerRuntime.DataRoot = cfg.ContainerRuntime.DataRoot
+	}
+	h.PrepareScripts = append(h.PrepareScripts, cfg.PrepareScripts...)
+	return nil
+}
+
+// mergeArg appends the flag in the form of --name=value, or replaces the one with the same name.
+func mergeArg(args []string, arg string) []string {
+	name := strings.SplitN(arg, "=", 2)[0]
+	for i := range args {
+		if strings.SplitN(args[i], "=", 2)[0] == name {
+			args[i] = arg
+			return args
+		}
+	}
+	return append(args, arg)
+}
+
 // ClusterIP is used to get the kube-apiserver service address inside the cluster.
 func (cfg *ClusterSpec) ClusterIP() string {
 	return util.ParseIp(cfg.Network.KubeServiceCIDR)[0]
@@ -360,3 +652,19 @@ func (c ControlPlaneEndpoint) IsInternalLBEnabled() bool {
 	}
 	return false
 }
+
+// IsKubeVipEnabled returns true if the masters hold the VIP of the control plane endpoint by kube-vip.
+func (c ControlPlaneEndpoint) IsKubeVipEnabled() bool {
+	return c.InternalLoadbalancer == KubeVip
+}
+
+// IsKeepalivedEnabled returns true if the masters hold the VIP of the control plane endpoint by keepalived,
+// and balance it to the apiservers by haproxy.
+func (c ControlPlaneEndpoint) IsKeepalivedEnabled() bool {
+	return c.InternalLoadbalancer == KeepalivedHaproxy
+}
+
+// IsVIPEnabled returns true if the address of the control plane endpoint is a VIP floating among the masters.
+func (c ControlPlaneEndpoint) IsVIPEnabled() bool {
+	return c.IsKubeVipEnabled() || c.IsKeepalivedEnabled()
+}
@@ -0,0 +1,165 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestGroupHostsNodeConfigs(t *testing.T) {
+	timeout := int64(30)
+	cfg := &ClusterSpec{
+		Hosts: []HostCfg{
+			{Name: "node1", Timeout: &timeout},
+			{
+				Name:    "node2",
+				Timeout: &timeout,
+				Labels:  map[string]string{"accelerator": "a100"},
+				Taints: []Taint{
+					{Key: "nvidia.com/gpu", Value: "a100", Effect: TaintEffectNoSchedule},
+				},
+				KubeletConfiguration: runtime.RawExtension{Raw: []byte(`{"maxPods": 64}`)},
+			},
+		},
+		RoleGroups: map[string][]string{
+			"etcd":          {"node1"},
+			"control-plane": {"node1"},
+			"worker":        {"node1", "node2"},
+		},
+		RoleGroupConfigs: map[string]NodeConfig{
+			"worker": {
+				Labels: map[string]string{"pool": "default"},
+				Taints: []Taint{
+					{Key: "nvidia.com/gpu", Effect: TaintEffectNoSchedule},
+				},
+				KubeletConfiguration: runtime.RawExtension{Raw: []byte(`{"maxPods": 110, "kubeReserved": {"cpu": "500m"}}`)},
+			},
+			"master": {
+				Labels: map[string]string{"pool": "control-plane"},
+			},
+		},
+	}
+
+	hosts := make(map[string]*KubeHost)
+	for _, h := range cfg.GroupHosts()[Worker] {
+		hosts[h.GetName()] = h
+	}
+
+	if got := hosts["node1"].Labels; !reflect.DeepEqual(got, map[string]string{"pool": "default"}) {
+		t.Errorf("labels of node1 = %v, want the ones of worker which are merged after master", got)
+	}
+	if got := hosts["node2"].Labels; !reflect.DeepEqual(got, map[string]string{"pool": "default", "accelerator": "a100"}) {
+		t.Errorf("labels of node2 = %v", got)
+	}
+	wantTaints := []Taint{{Key: "nvidia.com/gpu", Value: "a100", Effect: TaintEffectNoSchedule}}
+	if got := hosts["node2"].Taints; !reflect.DeepEqual(got, wantTaints) {
+		t.Errorf("taints of node2 = %v, want %v", got, wantTaints)
+	}
+	if got := hosts["node1"].KubeletConfiguration["maxPods"]; got != 110 {
+		t.Errorf("maxPods of node1 = %v, want 110", got)
+	}
+	if got := hosts["node2"].KubeletConfiguration["maxPods"]; got != 64 {
+		t.Errorf("maxPods of node2 = %v, want 64", got)
+	}
+	if _, ok := hosts["node2"].KubeletConfiguration["kubeReserved"]; !ok {
+		t.Errorf("kubeReserved of worker is not merged into node2")
+	}
+	if cfg.Hosts[1].Labels["pool"] != "" {
+		t.Errorf("the labels of the host config are modified")
+	}
+}
+
+func TestGroupHostsNodePools(t *testing.T) {
+	timeout := int64(30)
+	cfg := &ClusterSpec{
+		Hosts: []HostCfg{
+			{Name: "node1", Timeout: &timeout},
+			{Name: "node2", Timeout: &timeout},
+			{Name: "node3", Timeout: &timeout, Labels: map[string]string{"accelerator": "a100"}},
+		},
+		RoleGroups: map[string][]string{
+			"etcd":          {"node1"},
+			"control-plane": {"node1"},
+			"worker":        {"node2"},
+			"gpu":           {"node2", "node3"},
+		},
+		RoleGroupConfigs: map[string]NodeConfig{
+			"worker": {
+				KubeletArgs: []string{"max-pods=110", "node-status-update-frequency=10s"},
+			},
+			"gpu": {
+				Labels:      map[string]string{"pool": "gpu"},
+				KubeletArgs: []string{"node-status-update-frequency=4s"},
+				ContainerRuntime: ContainerRuntimeConfig{
+					RegistryMirrors: []string{"https://mirror.example.com"},
+				},
+				PrepareScripts: []string{"modprobe nvidia"},
+			},
+		},
+	}
+
+	if got := cfg.NodePools(); !reflect.DeepEqual(got, []string{"gpu"}) {
+		t.Fatalf("node pools = %v, want [gpu]", got)
+	}
+
+	roleGroups := cfg.GroupHosts()
+	hosts := make(map[string]*KubeHost)
+	for _, h := range roleGroups[Worker] {
+		hosts[h.GetName()] = h
+	}
+	if len(hosts) != 2 || len(roleGroups[Worker]) != 2 {
+		t.Fatalf("workers = %v, want node2 and node3", roleGroups[Worker])
+	}
+	if !hosts["node3"].IsRole(Worker) || !hosts["node3"].IsRole("gpu") {
+		t.Errorf("roles of node3 = %v, want worker and gpu", hosts["node3"].GetRoles())
+	}
+	if got := hosts["node3"].Labels; !reflect.DeepEqual(got, map[string]string{"pool": "gpu", "accelerator": "a100"}) {
+		t.Errorf("labels of node3 = %v", got)
+	}
+	if got := hosts["node2"].KubeletArgs; !reflect.DeepEqual(got, []string{"max-pods=110", "node-status-update-frequency=4s"}) {
+		t.Errorf("kubelet args of node2 = %v", got)
+	}
+	if got := hosts["node3"].ContainerRuntime.RegistryMirrors; !reflect.DeepEqual(got, []string{"https://mirror.example.com"}) {
+		t.Errorf("registry mirrors of node3 = %v", got)
+	}
+	if got := hosts["node3"].PrepareScripts; !reflect.DeepEqual(got, []string{"modprobe nvidia"}) {
+		t.Errorf("prepare scripts of node3 = %v", got)
+	}
+}
+
+func TestMergeNodeConfigsDataRoot(t *testing.T) {
+	timeout := int64(30)
+	for _, containerManager := range []string{"", Docker, Conatinerd} {
+		cfg := &ClusterSpec{
+			Hosts:      []HostCfg{{Name: "node1", Timeout: &timeout}},
+			RoleGroups: map[string][]string{"worker": {"node1"}},
+			RoleGroupConfigs: map[string]NodeConfig{
+				"worker": {ContainerRuntime: ContainerRuntimeConfig{DataRoot: "/data/docker"}},
+			},
+			Kubernetes: Kubernetes{ContainerManager: containerManager},
+		}
+		hostMap := map[string]*KubeHost{"node1": toHosts(cfg.Hosts[0])}
+		roleGroups := map[string][]*KubeHost{"worker": {hostMap["node1"]}}
+		err := cfg.mergeNodeConfigs(hostMap, roleGroups)
+		if wantErr := containerManager == Conatinerd; (err != nil) != wantErr {
+			t.Errorf("mergeNodeConfigs() with %q error = %v, wantErr %v", containerManager, err, wantErr)
+		}
+	}
+}
@@ -61,6 +61,13 @@ const (
 	DefaultIPIPMode             = "Always"
 	DefaultVXLANMode            = "Never"
 	DefaultVethMTU              = 0
+	DefaultIPAutodetection      = "can-reach=$(NODEIP)"
+	DefaultRouteReflectorID     = "244.0.0.1"
+	DefaultCalicoNodeSelector   = "all()"
+	DefaultCalicoIPAM           = "calico-ipam"
+	CalicoHostLocalIPAM         = "host-local"
+	DefaultCustomCNIName        = "custom-cni"
+	DefaultCustomCNINamespace   = "kube-system"
 	DefaultBackendMode          = "vxlan"
 	DefaultProxyMode            = "ipvs"
 	DefaultCrioEndpoint         = "unix:///var/run/crio/crio.sock"
@@ -83,12 +90,34 @@ const (
 	DefaultDPDKVersion          = "19.11"
 	DefaultDNSAddress           = "114.114.114.114"
 
+	NoneCNI   = "none"
+	CustomCNI = "custom"
+
+	CiliumKubeProxyReplacementDisabled = "disabled"
+	CiliumKubeProxyReplacementStrict   = "strict"
+	DefaultCiliumTunnel                = "vxlan"
+	CiliumTunnelDisabled               = "disabled"
+	DefaultCiliumIPAM                  = "cluster-pool"
+	CiliumKubernetesIPAM               = "kubernetes"
+	DefaultCiliumEncryptionType        = "wireguard"
+
 	Docker     = "docker"
 	Conatinerd = "containerd"
 	Crio       = "crio"
 	Isula      = "isula"
 
-	Haproxy = "haproxy"
+	Haproxy           = "haproxy"
+	KubeVip           = "kube-vip"
+	KeepalivedHaproxy = "keepalived+haproxy"
+
+	TaintEffectNoSchedule       = "NoSchedule"
+	TaintEffectPreferNoSchedule = "PreferNoSchedule"
+	TaintEffectNoExecute        = "NoExecute"
+
+	DefaultKubeVipMode          = "arp"
+	KubeVipBGPMode              = "bgp"
+	DefaultKeepalivedLBPort     = 8443
+	DefaultKeepalivedVirtualRID = 51
 )
 
 func (cfg *ClusterSpec) SetDefaultClusterSpec(incluster bool) (*ClusterSpec, map[string][]*KubeHost) {
@@ -96,6 +125,8 @@ func (cfg *ClusterSpec) SetDefaultClusterSpec(incluster bool) (*ClusterSpec, map
 
 	clusterCfg.Hosts = SetDefaultHostsCfg(cfg)
 	clusterCfg.RoleGroups = cfg.RoleGroups
+	clusterCfg.RoleGroupConfigs = cfg.RoleGroupConfigs
+	clusterCfg.Hooks = cfg.Hooks
 	clusterCfg.Etcd = SetDefaultEtcdCfg(cfg)
 	roleGroups := clusterCfg.GroupHosts()
 	clusterCfg.ControlPlaneEndpoint = SetDefaultLBCfg(cfg, roleGroups[Master], incluster)
@@ -103,6 +134,7 @@ func (cfg *ClusterSpec) SetDefaultClusterSpec(incluster bool) (*ClusterSpec, map
 	clusterCfg.System = cfg.System
 	clusterCfg.Kubernetes = SetDefaultClusterCfg(cfg)
 	clusterCfg.Registry = cfg.Registry
+	clusterCfg.ImageBOM = cfg.ImageBOM
 	clusterCfg.Addons = cfg.Addons
 	clusterCfg.KubeSphere = cfg.KubeSphere
 
@@ -186,6 +218,35 @@ func SetDefaultLBCfg(cfg *ClusterSpec, masterGroup []*KubeHost, incluster bool)
 			fmt.Println("You cannot set up the internal load balancer and the LB address at the same time.")
 			os.Exit(0)
 		}
+
+		// The LB address is the VIP floating among the masters
+		if cfg.ControlPlaneEndpoint.IsVIPEnabled() && cfg.ControlPlaneEndpoint.Address == "" {
+			fmt.Printf("When the internal load balancer is %s, the LB address must be set as the VIP.\n", cfg.ControlPlaneEndpoint.InternalLoadbalancer)
+			os.Exit(0)
+		}
+
+		// kube-vip only holds the VIP, the clients connect to the apiserver directly
+		if cfg.ControlPlaneEndpoint.IsKubeVipEnabled() && cfg.ControlPlaneEndpoint.Port != 0 && cfg.ControlPlaneEndpoint.Port != DefaultApiserverPort {
+			fmt.Printf("When the internal load balancer is kube-vip, the LB port must be %d.\n", DefaultApiserverPort)
+			os.Exit(0)
+		}
+
+		// haproxy runs on the masters beside the apiserver
+		if cfg.ControlPlaneEndpoint.IsKeepalivedEnabled() && cfg.ControlPlaneEndpoint.Port == DefaultApiserverPort {
+			fmt.Printf("When the internal load balancer is keepalived+haproxy, the LB port cannot be %d which is used by the apiserver.\n", DefaultApiserverPort)
+			os.Exit(0)
+		}
+
+		if cfg.ControlPlaneEndpoint.IsVIPEnabled() && cfg.Kubernetes.Type == "k3s" {
+			fmt.Printf("The internal load balancer %s is not supported by k3s.\n", cfg.ControlPlaneEndpoint.InternalLoadbalancer)
+			os.Exit(0)
+		}
+
+		if cfg.ControlPlaneEndpoint.IsKubeVipEnabled() && cfg.ControlPlaneEndpoint.KubeVip.Mode == KubeVipBGPMode &&
+			(cfg.ControlPlaneEndpoint.KubeVip.BGP.AS == 0 || len(cfg.ControlPlaneEndpoint.KubeVip.BGP.Peers) == 0) {
+			fmt.Println("When kube-vip is in the bgp mode, the AS and the peers of BGP must be set.")
+			os.Exit(0)
+		}
 	}
 
 	if cfg.ControlPlaneEndpoint.Address == "" || cfg.ControlPlaneEndpoint.Address == "127.0.0.1" {
@@ -195,7 +256,17 @@ func SetDefaultLBCfg(cfg *ClusterSpec, masterGroup []*KubeHost, incluster bool)
 		cfg.ControlPlaneEndpoint.Domain = DefaultLBDomain
 	}
 	if cfg.ControlPlaneEndpoint.Port == 0 {
-		cfg.ControlPlaneEndpoint.Port = DefaultLBPort
+		if cfg.ControlPlaneEndpoint.IsKeepalivedEnabled() {
+			cfg.ControlPlaneEndpoint.Port = DefaultKeepalivedLBPort
+		} else {
+			cfg.ControlPlaneEndpoint.Port = DefaultLBPort
+		}
+	}
+	if cfg.ControlPlaneEndpoint.KubeVip.Mode == "" {
+		cfg.ControlPlaneEndpoint.KubeVip.Mode = DefaultKubeVipMode
+	}
+	if cfg.ControlPlaneEndpoint.Keepalived.VirtualRouterID == 0 {
+		cfg.ControlPlaneEndpoint.Keepalived.VirtualRouterID = DefaultKeepalivedVirtualRID
 	}
 	defaultLbCfg := cfg.ControlPlaneEndpoint
 	return defaultLbCfg
@@ -220,6 +291,33 @@ func SetDefaultNetworkCfg(cfg *ClusterSpec) NetworkConfig {
 	if cfg.Network.Calico.VethMTU == 0 {
 		cfg.Network.Calico.VethMTU = DefaultVethMTU
 	}
+	if cfg.Network.Calico.IPAutodetectionMethod == "" {
+		cfg.Network.Calico.IPAutodetectionMethod = DefaultIPAutodetection
+	}
+	if cfg.Network.Calico.IPAM == "" {
+		cfg.Network.Calico.IPAM = DefaultCalicoIPAM
+	}
+	if cfg.Network.Calico.BGP.RouteReflectors.ClusterID == "" {
+		cfg.Network.Calico.BGP.RouteReflectors.ClusterID = DefaultRouteReflectorID
+	}
+	for i := range cfg.Network.Calico.IPPools {
+		pool := &cfg.Network.Calico.IPPools[i]
+		if pool.BlockSize == 0 {
+			pool.BlockSize = cfg.Kubernetes.NodeCidrMaskSize
+			if pool.BlockSize == 0 {
+				pool.BlockSize = DefaultNodeCidrMaskSize
+			}
+		}
+		if pool.IPIPMode == "" {
+			pool.IPIPMode = cfg.Network.Calico.IPIPMode
+		}
+		if pool.VXLANMode == "" {
+			pool.VXLANMode = cfg.Network.Calico.VXLANMode
+		}
+		if pool.NodeSelector == "" {
+			pool.NodeSelector = DefaultCalicoNodeSelector
+		}
+	}
 	if cfg.Network.Flannel.BackendMode == "" {
 		cfg.Network.Flannel.BackendMode = DefaultBackendMode
 	}
@@ -242,6 +340,35 @@ func SetDefaultNetworkCfg(cfg *ClusterSpec) NetworkConfig {
 	if cfg.Network.Kubeovn.DpdkVersion == "" {
 		cfg.Network.Kubeovn.DpdkVersion = DefaultDPDKVersion
 	}
+	// custom cni default config
+	if cfg.Network.Plugin == CustomCNI {
+		if cfg.Network.Custom.Name == "" {
+			cfg.Network.Custom.Name = DefaultCustomCNIName
+		}
+		if cfg.Network.Custom.Namespace == "" {
+			cfg.Network.Custom.Namespace = DefaultCustomCNINamespace
+		}
+	}
+	// cilium default config
+	if cfg.Network.Cilium.KubeProxyReplacement == "" {
+		if cfg.Kubernetes.DisableKubeProxy {
+			cfg.Network.Cilium.KubeProxyReplacement = CiliumKubeProxyReplacementStrict
+		} else {
+			cfg.Network.Cilium.KubeProxyReplacement = CiliumKubeProxyReplacementDisabled
+		}
+	}
+	if cfg.Network.Cilium.Tunnel == "" {
+		cfg.Network.Cilium.Tunnel = DefaultCiliumTunnel
+	}
+	if cfg.Network.Cilium.IsNativeRouting() && cfg.Network.Cilium.NativeRoutingCIDR == "" {
+		cfg.Network.Cilium.NativeRoutingCIDR = cfg.Network.KubePodsCIDR
+	}
+	if cfg.Network.Cilium.IPAM == "" {
+		cfg.Network.Cilium.IPAM = DefaultCiliumIPAM
+	}
+	if cfg.Network.Cilium.Encryption.Type == "" {
+		cfg.Network.Cilium.Encryption.Type = DefaultCiliumEncryptionType
+	}
 	defaultNetworkCfg := cfg.Network
 
 	return defaultNetworkCfg
@@ -304,6 +431,9 @@ func SetDefaultEtcdCfg(cfg *ClusterSpec) EtcdCluster {
 	if cfg.Etcd.BackupScriptDir == "" {
 		cfg.Etcd.BackupScriptDir = DefaultEtcdBackupScriptDir
 	}
+	if cfg.Etcd.Version == "" {
+		cfg.Etcd.Version = DefaultEtcdVersion
+	}
 
 	return cfg.Etcd
 }
@@ -31,6 +31,10 @@ type EtcdCluster struct {
 	BackupPeriod     int          `yaml:"backupPeriod" json:"backupPeriod,omitempty"`
 	KeepBackupNumber int          `yaml:"keepBackupNumber" json:"keepBackupNumber,omitempty"`
 	BackupScriptDir  string       `yaml:"backupScript" json:"backupScript,omitempty"`
+	// PKI supplies the CA or the certs of etcd instead of the self-signed ones when type is set to kubekey.
+	PKI PKI `yaml:"pki" json:"pki,omitempty"`
+	// Version of etcd installed when type is set to kubekey
+	Version string `yaml:"version" json:"version,omitempty"`
 }
 
 // ExternalEtcd describes how to connect to an external etcd cluster
@@ -0,0 +1,92 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"fmt"
+)
+
+const (
+	PreHook  = "pre"
+	PostHook = "post"
+)
+
+// ModuleHooks defines the hooks run on the nodes before and after a module, e.g. InstallContainerModule.
+type ModuleHooks struct {
+	Pre  []Hook `yaml:"pre" json:"pre,omitempty"`
+	Post []Hook `yaml:"post" json:"post,omitempty"`
+}
+
+// Hook defines a command run on the nodes of the roles. Exactly one of command, script and binary must be set.
+type Hook struct {
+	Name string `yaml:"name" json:"name,omitempty"`
+	// Roles are the role groups of the nodes to run the hook on, all the nodes if empty.
+	Roles []string `yaml:"roles" json:"roles,omitempty"`
+	// Command is a shell snippet run by bash.
+	Command string `yaml:"command" json:"command,omitempty"`
+	// Script is the path of a local script which is copied to the nodes and run by bash.
+	Script string `yaml:"script" json:"script,omitempty"`
+	// Binary is the path of a local executable which is copied to the nodes and executed.
+	Binary string `yaml:"binary" json:"binary,omitempty"`
+	// Args are the arguments of the script or the binary.
+	Args []string `yaml:"args" json:"args,omitempty"`
+	// Fatal fails kk when the hook fails, otherwise the failure is only logged.
+	Fatal bool `yaml:"fatal" json:"fatal,omitempty"`
+}
+
+// validateHooks checks that each hook sets exactly one of command, script and binary, and that its roles exist.
+func (cfg *ClusterSpec) validateHooks(roleGroups map[string][]*KubeHost) error {
+	for module, hooks := range cfg.Hooks {
+		for stage, list := range map[string][]Hook{PreHook: hooks.Pre, PostHook: hooks.Post} {
+			for i, hook := range list {
+				if err := hook.validate(roleGroups); err != nil {
+					return fmt.Errorf("invalid %s hook %s of the module %s: %v", stage, hook.DisplayName(stage, i), module, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (h Hook) validate(roleGroups map[string][]*KubeHost) error {
+	set := 0
+	for _, v := range []string{h.Command, h.Script, h.Binary} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of command, script and binary must be set")
+	}
+	if h.Command != "" && len(h.Args) != 0 {
+		return fmt.Errorf("args can only be set with script or binary")
+	}
+	for _, role := range h.Roles {
+		if _, ok := roleGroups[role]; !ok && !IsBuiltinRoleGroup(role) && role != K8s {
+			return fmt.Errorf("the role group %s is not found in roleGroups", role)
+		}
+	}
+	return nil
+}
+
+// DisplayName returns the name of the hook, or its stage and index in the hooks of the module if the name is empty.
+func (h Hook) DisplayName(stage string, index int) string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return fmt.Sprintf("%s-%d", stage, index)
+}
@@ -45,6 +45,30 @@ type Kubernetes struct {
 	FeatureGates             map[string]bool      `yaml:"featureGates" json:"featureGates,omitempty"`
 	KubeletConfiguration     runtime.RawExtension `yaml:"kubeletConfiguration" json:"kubeletConfiguration,omitempty"`
 	KubeProxyConfiguration   runtime.RawExtension `yaml:"kubeProxyConfiguration" json:"kubeProxyConfiguration,omitempty"`
+	// PKI supplies the CA, whose key is required, and the apiserver cert of kubernetes.
+	PKI PKI `yaml:"pki" json:"pki,omitempty"`
+	// EncryptionAtRest encrypts the resources, e.g. secrets, stored in etcd by kube-apiserver.
+	EncryptionAtRest EncryptionAtRest `yaml:"encryptionAtRest" json:"encryptionAtRest,omitempty"`
+}
+
+// EncryptionAtRest contains the configuration for the encryption of resources at rest in cluster
+type EncryptionAtRest struct {
+	Enabled *bool `yaml:"enabled" json:"enabled,omitempty"`
+	// Provider is one of aescbc, aesgcm, secretbox and kms. The default is aescbc.
+	Provider string `yaml:"provider" json:"provider,omitempty"`
+	// Resources are the resources to encrypt. The default is secrets.
+	Resources []string `yaml:"resources" json:"resources,omitempty"`
+	// KMS is the KMS plugin which encrypts the resources when provider is set to kms.
+	KMS KMSPlugin `yaml:"kms" json:"kms,omitempty"`
+}
+
+// KMSPlugin contains the configuration for a KMS plugin of kube-apiserver
+type KMSPlugin struct {
+	Name string `yaml:"name" json:"name,omitempty"`
+	// Endpoint is the unix socket the plugin listens on, e.g. unix:///var/run/kms-plugin/socket.sock.
+	Endpoint  string `yaml:"endpoint" json:"endpoint,omitempty"`
+	CacheSize int32  `yaml:"cacheSize" json:"cacheSize,omitempty"`
+	Timeout   string `yaml:"timeout" json:"timeout,omitempty"`
 }
 
 // Kata contains the configuration for the kata in cluster
@@ -81,6 +105,14 @@ func (k *Kubernetes) EnableNodeFeatureDiscovery() bool {
 	return *k.NodeFeatureDiscovery.Enabled
 }
 
+// EnableEncryptionAtRest is used to determine whether to encrypt resources at rest.
+func (k *Kubernetes) EnableEncryptionAtRest() bool {
+	if k.EncryptionAtRest.Enabled == nil {
+		return false
+	}
+	return *k.EncryptionAtRest.Enabled
+}
+
 func (k *Kubernetes) EnableAutoRenewCerts() bool {
 	if k.AutoRenewCerts == nil {
 		return false
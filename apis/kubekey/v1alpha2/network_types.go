@@ -22,14 +22,66 @@ type NetworkConfig struct {
 	KubeServiceCIDR string     `yaml:"kubeServiceCIDR" json:"kubeServiceCIDR,omitempty"`
 	Calico          CalicoCfg  `yaml:"calico" json:"calico,omitempty"`
 	Flannel         FlannelCfg `yaml:"flannel" json:"flannel,omitempty"`
+	Cilium          CiliumCfg  `yaml:"cilium" json:"cilium,omitempty"`
 	Kubeovn         KubeovnCfg `yaml:"kubeovn" json:"kubeovn,omitempty"`
 	MultusCNI       MultusCNI  `yaml:"multusCNI" json:"multusCNI,omitempty"`
+	// Custom is the CNI installed like an addon when the plugin is custom.
+	Custom Addon `yaml:"custom" json:"custom,omitempty"`
 }
 
 type CalicoCfg struct {
 	IPIPMode  string `yaml:"ipipMode" json:"ipipMode,omitempty"`
 	VXLANMode string `yaml:"vxlanMode" json:"vxlanMode,omitempty"`
 	VethMTU   int    `yaml:"vethMTU" json:"vethMTU,omitempty"`
+	// IPAutodetectionMethod is the IP_AUTODETECTION_METHOD of calico-node, defaults to can-reach=$(NODEIP).
+	IPAutodetectionMethod string `yaml:"ipAutodetectionMethod" json:"ipAutodetectionMethod,omitempty"`
+	// IPAM is the IPAM plugin of the calico CNI, calico-ipam by default, or host-local which allocates the pod
+	// addresses from the podCIDR of the node like flannel.
+	IPAM    string         `yaml:"ipam" json:"ipam,omitempty"`
+	BGP     CalicoBGP      `yaml:"bgp" json:"bgp,omitempty"`
+	IPPools []CalicoIPPool `yaml:"ipPools" json:"ipPools,omitempty"`
+}
+
+// CalicoBGP defines how calico peers with each other and with the routers outside the cluster.
+type CalicoBGP struct {
+	// AS is the default AS number of the nodes, calico uses 64512 when it is not set.
+	AS uint32 `yaml:"as" json:"as,omitempty"`
+	// NodeToNodeMesh defaults to true, or false when there are route reflectors.
+	NodeToNodeMesh  *bool                 `yaml:"nodeToNodeMesh" json:"nodeToNodeMesh,omitempty"`
+	Peers           []CalicoBGPPeer       `yaml:"peers" json:"peers,omitempty"`
+	RouteReflectors CalicoRouteReflectors `yaml:"routeReflectors" json:"routeReflectors,omitempty"`
+}
+
+// CalicoBGPPeer is a router peering with the nodes. The peer is global when neither Node nor NodeSelector is set.
+type CalicoBGPPeer struct {
+	Address string `yaml:"address" json:"address,omitempty"`
+	AS      uint32 `yaml:"as" json:"as,omitempty"`
+	// Node is the name of the host peering with the router.
+	Node string `yaml:"node" json:"node,omitempty"`
+	// NodeSelector is a calico selector of the nodes peering with the router, e.g. rack == 'r1'.
+	NodeSelector string `yaml:"nodeSelector" json:"nodeSelector,omitempty"`
+}
+
+// CalicoRouteReflectors turns the hosts with all the labels into BGP route reflectors.
+type CalicoRouteReflectors struct {
+	NodeLabels map[string]string `yaml:"nodeLabels" json:"nodeLabels,omitempty"`
+	// ClusterID defaults to 244.0.0.1.
+	ClusterID string `yaml:"clusterID" json:"clusterID,omitempty"`
+}
+
+// CalicoIPPool is an IP pool created besides the default one of kubePodsCIDR.
+type CalicoIPPool struct {
+	Name string `yaml:"name" json:"name,omitempty"`
+	CIDR string `yaml:"cidr" json:"cidr,omitempty"`
+	// BlockSize defaults to the nodeCidrMaskSize of kubernetes.
+	BlockSize int `yaml:"blockSize" json:"blockSize,omitempty"`
+	// IPIPMode and VXLANMode default to the ones of calico.
+	IPIPMode  string `yaml:"ipipMode" json:"ipipMode,omitempty"`
+	VXLANMode string `yaml:"vxlanMode" json:"vxlanMode,omitempty"`
+	// NATOutgoing defaults to true.
+	NATOutgoing *bool `yaml:"natOutgoing" json:"natOutgoing,omitempty"`
+	// NodeSelector is a calico selector of the nodes allocating addresses from the pool, defaults to all().
+	NodeSelector string `yaml:"nodeSelector" json:"nodeSelector,omitempty"`
 }
 
 type FlannelCfg struct {
@@ -37,6 +89,28 @@ type FlannelCfg struct {
 	Directrouting bool   `yaml:"directRouting" json:"directRouting,omitempty"`
 }
 
+type CiliumCfg struct {
+	KubeProxyReplacement string           `yaml:"kubeProxyReplacement" json:"kubeProxyReplacement,omitempty"`
+	Tunnel               string           `yaml:"tunnel" json:"tunnel,omitempty"`
+	NativeRoutingCIDR    string           `yaml:"nativeRoutingCIDR" json:"nativeRoutingCIDR,omitempty"`
+	AutoDirectNodeRoutes bool             `yaml:"autoDirectNodeRoutes" json:"autoDirectNodeRoutes,omitempty"`
+	IPAM                 string           `yaml:"ipam" json:"ipam,omitempty"`
+	Encryption           CiliumEncryption `yaml:"encryption" json:"encryption,omitempty"`
+	Hubble               CiliumHubble     `yaml:"hubble" json:"hubble,omitempty"`
+	BandwidthManager     bool             `yaml:"bandwidthManager" json:"bandwidthManager,omitempty"`
+}
+
+type CiliumEncryption struct {
+	Enabled bool   `yaml:"enabled" json:"enabled,omitempty"`
+	Type    string `yaml:"type" json:"type,omitempty"`
+}
+
+type CiliumHubble struct {
+	Enabled *bool `yaml:"enabled" json:"enabled,omitempty"`
+	Relay   bool  `yaml:"relay" json:"relay,omitempty"`
+	UI      bool  `yaml:"ui" json:"ui,omitempty"`
+}
+
 type KubeovnCfg struct {
 	JoinCIDR              string `yaml:"joinCIDR" json:"joinCIDR,omitempty"`
 	NetworkType           string `yaml:"networkType" json:"networkType,omitempty"`
@@ -63,3 +137,30 @@ func (n *NetworkConfig) EnableMultusCNI() bool {
 	}
 	return *n.MultusCNI.Enabled
 }
+
+// EnableHubble is used to determine whether Hubble is enabled in cilium, it is enabled by default.
+func (c *CiliumCfg) EnableHubble() bool {
+	if c.Hubble.Enabled == nil {
+		return true
+	}
+	return *c.Hubble.Enabled
+}
+
+// IsNativeRouting is used to determine whether cilium routes the pod traffic without a tunnel.
+func (c *CiliumCfg) IsNativeRouting() bool {
+	return c.Tunnel == CiliumTunnelDisabled
+}
+
+// IsCustomized is used to determine whether the BGP configuration or the IP pools of calico are set.
+func (c *CalicoCfg) IsCustomized() bool {
+	return c.BGP.AS != 0 || c.BGP.NodeToNodeMesh != nil || len(c.BGP.Peers) != 0 ||
+		len(c.BGP.RouteReflectors.NodeLabels) != 0 || len(c.IPPools) != 0
+}
+
+// EnableNATOutgoing is used to determine whether the traffic from the pool to the outside of the cluster is masqueraded.
+func (p *CalicoIPPool) EnableNATOutgoing() bool {
+	if p.NATOutgoing == nil {
+		return true
+	}
+	return *p.NATOutgoing
+}
@@ -41,6 +41,21 @@ func (in *Addon) DeepCopy() *Addon {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BGPPeer) DeepCopyInto(out *BGPPeer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BGPPeer.
+func (in *BGPPeer) DeepCopy() *BGPPeer {
+	if in == nil {
+		return nil
+	}
+	out := new(BGPPeer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CNI) DeepCopyInto(out *CNI) {
 	*out = *in
@@ -56,9 +71,58 @@ func (in *CNI) DeepCopy() *CNI {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalicoBGP) DeepCopyInto(out *CalicoBGP) {
+	*out = *in
+	if in.NodeToNodeMesh != nil {
+		in, out := &in.NodeToNodeMesh, &out.NodeToNodeMesh
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]CalicoBGPPeer, len(*in))
+		copy(*out, *in)
+	}
+	in.RouteReflectors.DeepCopyInto(&out.RouteReflectors)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoBGP.
+func (in *CalicoBGP) DeepCopy() *CalicoBGP {
+	if in == nil {
+		return nil
+	}
+	out := new(CalicoBGP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalicoBGPPeer) DeepCopyInto(out *CalicoBGPPeer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoBGPPeer.
+func (in *CalicoBGPPeer) DeepCopy() *CalicoBGPPeer {
+	if in == nil {
+		return nil
+	}
+	out := new(CalicoBGPPeer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CalicoCfg) DeepCopyInto(out *CalicoCfg) {
 	*out = *in
+	in.BGP.DeepCopyInto(&out.BGP)
+	if in.IPPools != nil {
+		in, out := &in.IPPools, &out.IPPools
+		*out = make([]CalicoIPPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoCfg.
@@ -71,6 +135,48 @@ func (in *CalicoCfg) DeepCopy() *CalicoCfg {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalicoIPPool) DeepCopyInto(out *CalicoIPPool) {
+	*out = *in
+	if in.NATOutgoing != nil {
+		in, out := &in.NATOutgoing, &out.NATOutgoing
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoIPPool.
+func (in *CalicoIPPool) DeepCopy() *CalicoIPPool {
+	if in == nil {
+		return nil
+	}
+	out := new(CalicoIPPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalicoRouteReflectors) DeepCopyInto(out *CalicoRouteReflectors) {
+	*out = *in
+	if in.NodeLabels != nil {
+		in, out := &in.NodeLabels, &out.NodeLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoRouteReflectors.
+func (in *CalicoRouteReflectors) DeepCopy() *CalicoRouteReflectors {
+	if in == nil {
+		return nil
+	}
+	out := new(CalicoRouteReflectors)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Chart) DeepCopyInto(out *Chart) {
 	*out = *in
@@ -91,6 +197,58 @@ func (in *Chart) DeepCopy() *Chart {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CiliumCfg) DeepCopyInto(out *CiliumCfg) {
+	*out = *in
+	out.Encryption = in.Encryption
+	in.Hubble.DeepCopyInto(&out.Hubble)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CiliumCfg.
+func (in *CiliumCfg) DeepCopy() *CiliumCfg {
+	if in == nil {
+		return nil
+	}
+	out := new(CiliumCfg)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CiliumEncryption) DeepCopyInto(out *CiliumEncryption) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CiliumEncryption.
+func (in *CiliumEncryption) DeepCopy() *CiliumEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(CiliumEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CiliumHubble) DeepCopyInto(out *CiliumHubble) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CiliumHubble.
+func (in *CiliumHubble) DeepCopy() *CiliumHubble {
+	if in == nil {
+		return nil
+	}
+	out := new(CiliumHubble)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Cluster) DeepCopyInto(out *Cluster) {
 	*out = *in
@@ -175,12 +333,20 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*out)[key] = outVal
 		}
 	}
-	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	if in.RoleGroupConfigs != nil {
+		in, out := &in.RoleGroupConfigs, &out.RoleGroupConfigs
+		*out = make(map[string]NodeConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	in.ControlPlaneEndpoint.DeepCopyInto(&out.ControlPlaneEndpoint)
 	in.System.DeepCopyInto(&out.System)
 	in.Etcd.DeepCopyInto(&out.Etcd)
 	in.Kubernetes.DeepCopyInto(&out.Kubernetes)
 	in.Network.DeepCopyInto(&out.Network)
 	in.Registry.DeepCopyInto(&out.Registry)
+	in.ImageBOM.DeepCopyInto(&out.ImageBOM)
 	if in.Addons != nil {
 		in, out := &in.Addons, &out.Addons
 		*out = make([]Addon, len(*in))
@@ -189,6 +355,13 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 		}
 	}
 	out.KubeSphere = in.KubeSphere
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make(map[string]ModuleHooks, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
@@ -313,9 +486,36 @@ func (in *ContainerRuntime) DeepCopy() *ContainerRuntime {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerRuntimeConfig) DeepCopyInto(out *ContainerRuntimeConfig) {
+	*out = *in
+	if in.RegistryMirrors != nil {
+		in, out := &in.RegistryMirrors, &out.RegistryMirrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InsecureRegistries != nil {
+		in, out := &in.InsecureRegistries, &out.InsecureRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRuntimeConfig.
+func (in *ContainerRuntimeConfig) DeepCopy() *ContainerRuntimeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerRuntimeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControlPlaneEndpoint) DeepCopyInto(out *ControlPlaneEndpoint) {
 	*out = *in
+	in.KubeVip.DeepCopyInto(&out.KubeVip)
+	out.Keepalived = in.Keepalived
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneEndpoint.
@@ -403,10 +603,37 @@ func (in *ETCD) DeepCopy() *ETCD {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionAtRest) DeepCopyInto(out *EncryptionAtRest) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.KMS = in.KMS
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionAtRest.
+func (in *EncryptionAtRest) DeepCopy() *EncryptionAtRest {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionAtRest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdCluster) DeepCopyInto(out *EtcdCluster) {
 	*out = *in
 	in.External.DeepCopyInto(&out.External)
+	in.PKI.DeepCopyInto(&out.PKI)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdCluster.
@@ -499,9 +726,39 @@ func (in *Helm) DeepCopy() *Helm {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Hook) DeepCopyInto(out *Hook) {
+	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Hook.
+func (in *Hook) DeepCopy() *Hook {
+	if in == nil {
+		return nil
+	}
+	out := new(Hook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostCfg) DeepCopyInto(out *HostCfg) {
 	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(int64)
+		**out = **in
+	}
 	if in.Labels != nil {
 		in, out := &in.Labels, &out.Labels
 		*out = make(map[string]string, len(*in))
@@ -509,6 +766,12 @@ func (in *HostCfg) DeepCopyInto(out *HostCfg) {
 			(*out)[key] = val
 		}
 	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]Taint, len(*in))
+		copy(*out, *in)
+	}
+	in.KubeletConfiguration.DeepCopyInto(&out.KubeletConfiguration)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostCfg.
@@ -521,6 +784,35 @@ func (in *HostCfg) DeepCopy() *HostCfg {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageBOM) DeepCopyInto(out *ImageBOM) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageBOM.
+func (in *ImageBOM) DeepCopy() *ImageBOM {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageBOM)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Iso) DeepCopyInto(out *Iso) {
 	*out = *in
@@ -558,6 +850,21 @@ func (in *JobInfo) DeepCopy() *JobInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KMSPlugin) DeepCopyInto(out *KMSPlugin) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KMSPlugin.
+func (in *KMSPlugin) DeepCopy() *KMSPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(KMSPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Kata) DeepCopyInto(out *Kata) {
 	*out = *in
@@ -578,6 +885,21 @@ func (in *Kata) DeepCopy() *Kata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeepalivedCfg) DeepCopyInto(out *KeepalivedCfg) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeepalivedCfg.
+func (in *KeepalivedCfg) DeepCopy() *KeepalivedCfg {
+	if in == nil {
+		return nil
+	}
+	out := new(KeepalivedCfg)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeSphere) DeepCopyInto(out *KubeSphere) {
 	*out = *in
@@ -593,6 +915,42 @@ func (in *KubeSphere) DeepCopy() *KubeSphere {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeVipBGP) DeepCopyInto(out *KubeVipBGP) {
+	*out = *in
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]BGPPeer, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeVipBGP.
+func (in *KubeVipBGP) DeepCopy() *KubeVipBGP {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeVipBGP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeVipCfg) DeepCopyInto(out *KubeVipCfg) {
+	*out = *in
+	in.BGP.DeepCopyInto(&out.BGP)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeVipCfg.
+func (in *KubeVipCfg) DeepCopy() *KubeVipCfg {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeVipCfg)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeovnCfg) DeepCopyInto(out *KubeovnCfg) {
 	*out = *in
@@ -657,6 +1015,8 @@ func (in *Kubernetes) DeepCopyInto(out *Kubernetes) {
 	}
 	in.KubeletConfiguration.DeepCopyInto(&out.KubeletConfiguration)
 	in.KubeProxyConfiguration.DeepCopyInto(&out.KubeProxyConfiguration)
+	in.PKI.DeepCopyInto(&out.PKI)
+	in.EncryptionAtRest.DeepCopyInto(&out.EncryptionAtRest)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Kubernetes.
@@ -811,6 +1171,35 @@ func (in *ManifestStatus) DeepCopy() *ManifestStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleHooks) DeepCopyInto(out *ModuleHooks) {
+	*out = *in
+	if in.Pre != nil {
+		in, out := &in.Pre, &out.Pre
+		*out = make([]Hook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Post != nil {
+		in, out := &in.Post, &out.Post
+		*out = make([]Hook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleHooks.
+func (in *ModuleHooks) DeepCopy() *ModuleHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultusCNI) DeepCopyInto(out *MultusCNI) {
 	*out = *in
@@ -834,10 +1223,12 @@ func (in *MultusCNI) DeepCopy() *MultusCNI {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkConfig) DeepCopyInto(out *NetworkConfig) {
 	*out = *in
-	out.Calico = in.Calico
+	in.Calico.DeepCopyInto(&out.Calico)
 	out.Flannel = in.Flannel
+	in.Cilium.DeepCopyInto(&out.Cilium)
 	out.Kubeovn = in.Kubeovn
 	in.MultusCNI.DeepCopyInto(&out.MultusCNI)
+	in.Custom.DeepCopyInto(&out.Custom)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkConfig.
@@ -908,6 +1299,80 @@ func (in *OperatingSystem) DeepCopy() *OperatingSystem {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeConfig) DeepCopyInto(out *NodeConfig) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]Taint, len(*in))
+		copy(*out, *in)
+	}
+	in.KubeletConfiguration.DeepCopyInto(&out.KubeletConfiguration)
+	if in.KubeletArgs != nil {
+		in, out := &in.KubeletArgs, &out.KubeletArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.ContainerRuntime.DeepCopyInto(&out.ContainerRuntime)
+	if in.PrepareScripts != nil {
+		in, out := &in.PrepareScripts, &out.PrepareScripts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeConfig.
+func (in *NodeConfig) DeepCopy() *NodeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PKI) DeepCopyInto(out *PKI) {
+	*out = *in
+	if in.Certs != nil {
+		in, out := &in.Certs, &out.Certs
+		*out = make([]PKICert, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PKI.
+func (in *PKI) DeepCopy() *PKI {
+	if in == nil {
+		return nil
+	}
+	out := new(PKI)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PKICert) DeepCopyInto(out *PKICert) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PKICert.
+func (in *PKICert) DeepCopy() *PKICert {
+	if in == nil {
+		return nil
+	}
+	out := new(PKICert)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PiplineInfo) DeepCopyInto(out *PiplineInfo) {
 	*out = *in
@@ -957,6 +1422,7 @@ func (in *RegistryConfig) DeepCopyInto(out *RegistryConfig) {
 		copy(*out, *in)
 	}
 	in.Auths.DeepCopyInto(&out.Auths)
+	in.PKI.DeepCopyInto(&out.PKI)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryConfig.
@@ -1022,6 +1488,21 @@ func (in *System) DeepCopy() *System {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Taint) DeepCopyInto(out *Taint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Taint.
+func (in *Taint) DeepCopy() *Taint {
+	if in == nil {
+		return nil
+	}
+	out := new(Taint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Yaml) DeepCopyInto(out *Yaml) {
 	*out = *in
@@ -26,17 +26,19 @@ import (
 
 type AddNodesOptions struct {
 	CommonOptions    *options.CommonOptions
+	DownloadOptions  *options.DownloadOptions
 	ClusterCfgFile   string
 	SkipPullImages   bool
 	ContainerManager string
-	DownloadCmd      string
 	Artifact         string
 	InstallPackages  bool
+	NodePool         string
 }
 
 func NewAddNodesOptions() *AddNodesOptions {
 	return &AddNodesOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:   options.NewCommonOptions(),
+		DownloadOptions: options.NewDownloadOptions(),
 	}
 }
 
@@ -53,6 +55,7 @@ func NewCmdAddNodes() *cobra.Command {
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
 	o.AddFlags(cmd)
 	return cmd
 }
@@ -77,16 +80,16 @@ func (o *AddNodesOptions) Run() error {
 		Artifact:         o.Artifact,
 		InstallPackages:  o.InstallPackages,
 		Namespace:        o.CommonOptions.Namespace,
+		NodePool:         o.NodePool,
 	}
-	return pipelines.AddNodes(arg, o.DownloadCmd)
+	return pipelines.AddNodes(arg, o.DownloadOptions.ToDownloadOptions())
 }
 
 func (o *AddNodesOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
 	cmd.Flags().BoolVarP(&o.SkipPullImages, "skip-pull-images", "", false, "Skip pre pull images")
 	cmd.Flags().StringVarP(&o.ContainerManager, "container-manager", "", "docker", "Container manager: docker, crio, containerd and isula.")
-	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
-		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL`)
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 	cmd.Flags().BoolVarP(&o.InstallPackages, "with-packages", "", false, "install operation system packages by artifact")
+	cmd.Flags().StringVarP(&o.NodePool, "pool", "", "", "Only add the new nodes of the specified node pool")
 }
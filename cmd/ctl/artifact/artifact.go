@@ -43,6 +43,8 @@ func NewCmdArtifact() *cobra.Command {
 	o.CommonOptions.AddCommonFlag(cmd)
 
 	cmd.AddCommand(NewCmdArtifactExport())
+	cmd.AddCommand(NewCmdArtifactInspect())
+	cmd.AddCommand(NewCmdArtifactDiff())
 	cmd.AddCommand(images.NewCmdArtifactImages())
 	return cmd
 }
@@ -0,0 +1,80 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/artifact"
+)
+
+type ArtifactDiffOptions struct {
+	Old string
+	New string
+}
+
+func NewArtifactDiffOptions() *ArtifactDiffOptions {
+	return &ArtifactDiffOptions{}
+}
+
+// NewCmdArtifactDiff creates a new `kubekey artifact diff` command
+func NewCmdArtifactDiff() *cobra.Command {
+	o := NewArtifactDiffOptions()
+	cmd := &cobra.Command{
+		Use:   "diff <old-artifact> <new-artifact>",
+		Short: "Show the added, removed and changed components between two KubeKey artifacts",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(cmd, args))
+			util.CheckErr(o.Validate(args))
+			util.CheckErr(o.Run())
+		},
+	}
+	return cmd
+}
+
+func (o *ArtifactDiffOptions) Complete(_ *cobra.Command, args []string) error {
+	o.Old = args[0]
+	o.New = args[1]
+	return nil
+}
+
+func (o *ArtifactDiffOptions) Validate(_ []string) error {
+	for _, f := range []string{o.Old, o.New} {
+		if _, err := os.Stat(f); err != nil {
+			return errors.Wrapf(err, "artifact %s not found", f)
+		}
+	}
+	return nil
+}
+
+func (o *ArtifactDiffOptions) Run() error {
+	oldInfo, err := artifact.Inspect(o.Old)
+	if err != nil {
+		return err
+	}
+	newInfo, err := artifact.Inspect(o.New)
+	if err != nil {
+		return err
+	}
+	artifact.PrintChanges(os.Stdout, artifact.Diff(oldInfo, newInfo))
+	return nil
+}
@@ -23,20 +23,23 @@ import (
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/pipelines"
 	"github.com/spf13/cobra"
+	"os"
 )
 
 type ArtifactExportOptions struct {
-	CommonOptions *options.CommonOptions
+	CommonOptions   *options.CommonOptions
+	DownloadOptions *options.DownloadOptions
 
 	ManifestFile string
 	Output       string
+	Base         string
 	CriSocket    string
-	DownloadCmd  string
 }
 
 func NewArtifactExportOptions() *ArtifactExportOptions {
 	return &ArtifactExportOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:   options.NewCommonOptions(),
+		DownloadOptions: options.NewDownloadOptions(),
 	}
 }
 
@@ -54,6 +57,7 @@ func NewCmdArtifactExport() *cobra.Command {
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
 	o.AddFlags(cmd)
 	return cmd
 }
@@ -69,6 +73,11 @@ func (o *ArtifactExportOptions) Validate(_ []string) error {
 	if o.ManifestFile == "" {
 		return fmt.Errorf("--manifest can not be an empty string")
 	}
+	if o.Base != "" {
+		if _, err := os.Stat(o.Base); err != nil {
+			return fmt.Errorf("the base artifact %s is not found: %v", o.Base, err)
+		}
+	}
 	return nil
 }
 
@@ -76,17 +85,18 @@ func (o *ArtifactExportOptions) Run() error {
 	arg := common.ArtifactArgument{
 		ManifestFile: o.ManifestFile,
 		Output:       o.Output,
+		Base:         o.Base,
 		CriSocket:    o.CriSocket,
 		Debug:        o.CommonOptions.Verbose,
 		IgnoreErr:    o.CommonOptions.IgnoreErr,
 	}
 
-	return pipelines.ArtifactExport(arg, o.DownloadCmd)
+	return pipelines.ArtifactExport(arg, o.DownloadOptions.ToDownloadOptions())
 }
 
 func (o *ArtifactExportOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ManifestFile, "manifest", "m", "", "Path to a manifest file")
 	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "Path to a output path")
-	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
-		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL`)
+	cmd.Flags().StringVarP(&o.Base, "base", "", "",
+		"Path to a base artifact. Only the binaries and image layers absent from the base artifact will be exported")
 }
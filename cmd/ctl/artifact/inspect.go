@@ -0,0 +1,72 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/artifact"
+)
+
+type ArtifactInspectOptions struct {
+	Artifact string
+}
+
+func NewArtifactInspectOptions() *ArtifactInspectOptions {
+	return &ArtifactInspectOptions{}
+}
+
+// NewCmdArtifactInspect creates a new `kubekey artifact inspect` command
+func NewCmdArtifactInspect() *cobra.Command {
+	o := NewArtifactInspectOptions()
+	cmd := &cobra.Command{
+		Use:   "inspect <artifact>",
+		Short: "List the binaries, ISO files, images and manifest in a KubeKey artifact",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(cmd, args))
+			util.CheckErr(o.Validate(args))
+			util.CheckErr(o.Run())
+		},
+	}
+	return cmd
+}
+
+func (o *ArtifactInspectOptions) Complete(_ *cobra.Command, args []string) error {
+	o.Artifact = args[0]
+	return nil
+}
+
+func (o *ArtifactInspectOptions) Validate(_ []string) error {
+	if _, err := os.Stat(o.Artifact); err != nil {
+		return errors.Wrapf(err, "artifact %s not found", o.Artifact)
+	}
+	return nil
+}
+
+func (o *ArtifactInspectOptions) Run() error {
+	info, err := artifact.Inspect(o.Artifact)
+	if err != nil {
+		return err
+	}
+	artifact.PrintInfo(os.Stdout, info)
+	return nil
+}
@@ -43,5 +43,7 @@ func NewCmdCerts() *cobra.Command {
 
 	cmd.AddCommand(NewCmdCertList())
 	cmd.AddCommand(NewCmdCertRenew())
+	cmd.AddCommand(NewCmdCertRotateCA())
+	cmd.AddCommand(NewCmdCertExporter())
 	return cmd
 }
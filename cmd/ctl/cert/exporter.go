@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cert
+
+import (
+	"time"
+
+	"github.com/kubesphere/kubekey/cmd/ctl/options"
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/pipelines"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type CertExporterOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+	ListenAddress  string
+	Interval       time.Duration
+}
+
+func NewCertExporterOptions() *CertExporterOptions {
+	return &CertExporterOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdCertExporter creates a new cert exporter command
+func NewCmdCertExporter() *cobra.Command {
+	o := NewCertExporterOptions()
+	cmd := &cobra.Command{
+		Use:   "exporter",
+		Short: "Serve the certificates expiration of a Kubernetes cluster as Prometheus metrics",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *CertExporterOptions) Validate() error {
+	if o.Interval < time.Minute {
+		return errors.New("the collection interval must be at least 1m")
+	}
+	return nil
+}
+
+func (o *CertExporterOptions) Run() error {
+	arg := common.Argument{
+		FilePath: o.ClusterCfgFile,
+		Debug:    o.CommonOptions.Verbose,
+	}
+	return pipelines.CertsExporter(arg, o.ListenAddress, o.Interval)
+}
+
+func (o *CertExporterOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVar(&o.ListenAddress, "listen-address", ":9527", "Address to serve the metrics on")
+	cmd.Flags().DurationVar(&o.Interval, "interval", time.Hour, "Interval to collect the certificates of the cluster")
+}
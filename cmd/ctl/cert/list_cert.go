@@ -19,14 +19,17 @@ package cert
 import (
 	"github.com/kubesphere/kubekey/cmd/ctl/options"
 	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/certs"
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/pipelines"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 type CertListOptions struct {
 	CommonOptions  *options.CommonOptions
 	ClusterCfgFile string
+	Output         string
 }
 
 func NewCertListOptions() *CertListOptions {
@@ -42,6 +45,7 @@ func NewCmdCertList() *cobra.Command {
 		Use:   "check-expiration",
 		Short: "Check certificates expiration for a Kubernetes cluster",
 		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
 			util.CheckErr(o.Run())
 		},
 	}
@@ -51,14 +55,24 @@ func NewCmdCertList() *cobra.Command {
 	return cmd
 }
 
+func (o *CertListOptions) Validate() error {
+	switch o.Output {
+	case "", certs.OutputJSON, certs.OutputYAML:
+		return nil
+	}
+	return errors.Errorf("unsupported output format %q, json and yaml are supported", o.Output)
+}
+
 func (o *CertListOptions) Run() error {
 	arg := common.Argument{
 		FilePath: o.ClusterCfgFile,
 		Debug:    o.CommonOptions.Verbose,
+		Output:   o.Output,
 	}
 	return pipelines.CheckCerts(arg)
 }
 
 func (o *CertListOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "Output format of the certs, one of json|yaml. The certs are printed as a table by default")
 }
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cert
+
+import (
+	"github.com/kubesphere/kubekey/cmd/ctl/options"
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/pipelines"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type CertRotateCAOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+	RotateSAKey    bool
+}
+
+func NewCertRotateCAOptions() *CertRotateCAOptions {
+	return &CertRotateCAOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdCertRotateCA creates a new cert rotate-ca command
+func NewCmdCertRotateCA() *cobra.Command {
+	o := NewCertRotateCAOptions()
+	cmd := &cobra.Command{
+		Use:   "rotate-ca",
+		Short: "rotate the cluster CAs and resign the certs",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *CertRotateCAOptions) Validate() error {
+	if o.ClusterCfgFile == "" {
+		return errors.New("the configuration file is required to rotate the CA")
+	}
+	return nil
+}
+
+func (o *CertRotateCAOptions) Run() error {
+	arg := common.Argument{
+		FilePath:         o.ClusterCfgFile,
+		Debug:            o.CommonOptions.Verbose,
+		SkipConfirmCheck: o.CommonOptions.SkipConfirmCheck,
+		RotateSAKey:      o.RotateSAKey,
+	}
+	return pipelines.RotateCA(arg)
+}
+
+func (o *CertRotateCAOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().BoolVar(&o.RotateSAKey, "rotate-sa-key", false, "Rotate the service-account signing key as well")
+}
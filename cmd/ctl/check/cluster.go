@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package check
+
+import (
+	"github.com/kubesphere/kubekey/cmd/ctl/options"
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/pipelines"
+	"github.com/spf13/cobra"
+)
+
+type CheckClusterOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+}
+
+func NewCheckClusterOptions() *CheckClusterOptions {
+	return &CheckClusterOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdCheckCluster creates a new check cluster command
+func NewCmdCheckCluster() *cobra.Command {
+	o := NewCheckClusterOptions()
+	cmd := &cobra.Command{
+		Use:   "cluster",
+		Short: "Check the pod and service networking, the DNS and the private registry of every node",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *CheckClusterOptions) Run() error {
+	arg := common.Argument{
+		FilePath: o.ClusterCfgFile,
+		Debug:    o.CommonOptions.Verbose,
+	}
+	return pipelines.CheckCluster(arg)
+}
+
+func (o *CheckClusterOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+}
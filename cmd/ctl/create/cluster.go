@@ -29,7 +29,8 @@ import (
 )
 
 type CreateClusterOptions struct {
-	CommonOptions *options.CommonOptions
+	CommonOptions   *options.CommonOptions
+	DownloadOptions *options.DownloadOptions
 
 	ClusterCfgFile   string
 	Kubernetes       string
@@ -39,16 +40,17 @@ type CreateClusterOptions struct {
 	SkipPullImages   bool
 	SkipPushImages   bool
 	ContainerManager string
-	DownloadCmd      string
 	Artifact         string
 	InstallPackages  bool
+	CheckCluster     bool
 
 	localStorageChanged bool
 }
 
 func NewCreateClusterOptions() *CreateClusterOptions {
 	return &CreateClusterOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:   options.NewCommonOptions(),
+		DownloadOptions: options.NewDownloadOptions(),
 	}
 }
 
@@ -66,6 +68,7 @@ func NewCmdCreateCluster() *cobra.Command {
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
 	o.AddFlags(cmd)
 	if err := completionSetting(cmd); err != nil {
 		panic(fmt.Sprintf("Got error with the completion setting"))
@@ -118,6 +121,7 @@ func (o *CreateClusterOptions) Run() error {
 		Artifact:          o.Artifact,
 		InstallPackages:   o.InstallPackages,
 		Namespace:         o.CommonOptions.Namespace,
+		CheckCluster:      o.CheckCluster,
 	}
 
 	if o.localStorageChanged {
@@ -125,7 +129,7 @@ func (o *CreateClusterOptions) Run() error {
 		arg.DeployLocalStorage = &deploy
 	}
 
-	return pipelines.CreateCluster(arg, o.DownloadCmd)
+	return pipelines.CreateCluster(arg, o.DownloadOptions.ToDownloadOptions())
 }
 
 func (o *CreateClusterOptions) AddFlags(cmd *cobra.Command) {
@@ -136,10 +140,9 @@ func (o *CreateClusterOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&o.SkipPullImages, "skip-pull-images", "", false, "Skip pre pull images")
 	cmd.Flags().BoolVarP(&o.SkipPushImages, "skip-push-images", "", false, "Skip pre push images")
 	cmd.Flags().StringVarP(&o.ContainerManager, "container-manager", "", "docker", "Container runtime: docker, crio, containerd and isula.")
-	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
-		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL`)
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 	cmd.Flags().BoolVarP(&o.InstallPackages, "with-packages", "", false, "install operation system packages by artifact")
+	cmd.Flags().BoolVarP(&o.CheckCluster, "with-cluster-check", "", false, "Check the networking and the DNS of the cluster after it is created")
 }
 
 func completionSetting(cmd *cobra.Command) (err error) {
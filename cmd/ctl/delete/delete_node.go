@@ -31,6 +31,7 @@ type DeleteNodeOptions struct {
 	CommonOptions  *options.CommonOptions
 	ClusterCfgFile string
 	nodeName       string
+	NodePool       string
 }
 
 func NewDeleteNodeOptions() *DeleteNodeOptions {
@@ -63,8 +64,11 @@ func (o *DeleteNodeOptions) Complete(cmd *cobra.Command, args []string) error {
 }
 
 func (o *DeleteNodeOptions) Validate() error {
-	if o.nodeName == "" {
-		return errors.New("node name can not be empty")
+	if o.nodeName == "" && o.NodePool == "" {
+		return errors.New("node name or node pool can not be empty")
+	}
+	if o.nodeName != "" && o.NodePool != "" {
+		return errors.New("node name and node pool can not be specified at the same time")
 	}
 	return nil
 }
@@ -74,11 +78,12 @@ func (o *DeleteNodeOptions) Run() error {
 		FilePath: o.ClusterCfgFile,
 		Debug:    o.CommonOptions.Verbose,
 		NodeName: o.nodeName,
+		NodePool: o.NodePool,
 	}
 	return pipelines.DeleteNode(arg)
 }
 
 func (o *DeleteNodeOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
-
+	cmd.Flags().StringVarP(&o.NodePool, "pool", "", "", "Delete all the nodes of the specified node pool")
 }
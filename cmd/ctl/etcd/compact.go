@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"github.com/kubesphere/kubekey/cmd/ctl/options"
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/etcd"
+	"github.com/kubesphere/kubekey/pkg/pipelines"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type EtcdCompactOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+	Revision       int64
+	Physical       bool
+}
+
+func NewEtcdCompactOptions() *EtcdCompactOptions {
+	return &EtcdCompactOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdEtcdCompact creates a new etcd compact command
+func NewCmdEtcdCompact() *cobra.Command {
+	o := NewEtcdCompactOptions()
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Compact the etcd keyspace",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *EtcdCompactOptions) Validate() error {
+	if o.ClusterCfgFile == "" {
+		return errors.New("the configuration file is required to maintain etcd")
+	}
+	return nil
+}
+
+func (o *EtcdCompactOptions) Run() error {
+	arg := common.Argument{
+		FilePath:         o.ClusterCfgFile,
+		Debug:            o.CommonOptions.Verbose,
+		SkipConfirmCheck: o.CommonOptions.SkipConfirmCheck,
+	}
+	return pipelines.EtcdMaintenance(arg, etcd.MaintenanceOptions{Operation: etcd.MaintenanceCompact, Revision: o.Revision, Physical: o.Physical})
+}
+
+func (o *EtcdCompactOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().Int64Var(&o.Revision, "revision", 0, "Revision to compact to. The current revision is used by default")
+	cmd.Flags().BoolVar(&o.Physical, "physical", false, "Wait for the compaction to be physically applied to the backend database")
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"github.com/kubesphere/kubekey/cmd/ctl/options"
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/pipelines"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type EtcdMigrateOptions struct {
+	CommonOptions   *options.CommonOptions
+	DownloadOptions *options.DownloadOptions
+	ClusterCfgFile  string
+	Artifact        string
+}
+
+func NewEtcdMigrateOptions() *EtcdMigrateOptions {
+	return &EtcdMigrateOptions{
+		CommonOptions:   options.NewCommonOptions(),
+		DownloadOptions: options.NewDownloadOptions(),
+	}
+}
+
+// NewCmdEtcdMigrate creates a new etcd migrate command
+func NewCmdEtcdMigrate() *cobra.Command {
+	o := NewEtcdMigrateOptions()
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate etcd between the control-plane nodes of kubeadm and the etcd nodes of KubeKey",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *EtcdMigrateOptions) Validate() error {
+	if o.ClusterCfgFile == "" {
+		return errors.New("the configuration file is required to migrate etcd")
+	}
+	return nil
+}
+
+func (o *EtcdMigrateOptions) Run() error {
+	arg := common.Argument{
+		FilePath:         o.ClusterCfgFile,
+		Debug:            o.CommonOptions.Verbose,
+		SkipConfirmCheck: o.CommonOptions.SkipConfirmCheck,
+		Artifact:         o.Artifact,
+	}
+	return pipelines.EtcdMigration(arg, o.DownloadOptions.ToDownloadOptions())
+}
+
+func (o *EtcdMigrateOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
+}
@@ -0,0 +1,99 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package images
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/cmd/ctl/options"
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/images"
+	"github.com/kubesphere/kubekey/pkg/version/kubernetes"
+)
+
+type ImagesListOptions struct {
+	CommonOptions *options.CommonOptions
+
+	ClusterCfgFile   string
+	Kubernetes       string
+	ContainerManager string
+	Output           string
+}
+
+func NewImagesListOptions() *ImagesListOptions {
+	return &ImagesListOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdImagesList creates a new `kubekey images list` command
+func NewCmdImagesList() *cobra.Command {
+	o := NewImagesListOptions()
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the images required by a cluster configuration",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.AddFlags(cmd)
+	if err := cmd.RegisterFlagCompletionFunc("with-kubernetes", func(cmd *cobra.Command, args []string, toComplete string) (
+		strings []string, directive cobra.ShellCompDirective) {
+		return kubernetes.SupportedK8sVersionList(), cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		panic(fmt.Sprintf("Got error with the completion setting"))
+	}
+	return cmd
+}
+
+func (o *ImagesListOptions) Validate() error {
+	switch o.Output {
+	case "", "wide":
+	default:
+		return fmt.Errorf("unsupported output format [%s]", o.Output)
+	}
+	return nil
+}
+
+func (o *ImagesListOptions) Run() error {
+	arg := common.Argument{
+		FilePath:          o.ClusterCfgFile,
+		KubernetesVersion: o.Kubernetes,
+		ContainerManager:  o.ContainerManager,
+		SkipConfirmCheck:  true,
+	}
+
+	list, err := images.ListImages(arg)
+	if err != nil {
+		return err
+	}
+	images.PrintImages(os.Stdout, list, o.Output == "wide")
+	return nil
+}
+
+func (o *ImagesListOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.Kubernetes, "with-kubernetes", "", "", "Specify a supported version of kubernetes")
+	cmd.Flags().StringVarP(&o.ContainerManager, "container-manager", "", "docker", "Container runtime: docker, crio, containerd and isula.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "Output format. One of: wide")
+}
@@ -25,15 +25,16 @@ import (
 )
 
 type InitRegistryOptions struct {
-	CommonOptions  *options.CommonOptions
-	ClusterCfgFile string
-	DownloadCmd    string
-	Artifact       string
+	CommonOptions   *options.CommonOptions
+	DownloadOptions *options.DownloadOptions
+	ClusterCfgFile  string
+	Artifact        string
 }
 
 func NewInitRegistryOptions() *InitRegistryOptions {
 	return &InitRegistryOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:   options.NewCommonOptions(),
+		DownloadOptions: options.NewDownloadOptions(),
 	}
 }
 
@@ -50,6 +51,7 @@ func NewCmdInitRegistry() *cobra.Command {
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
 	o.AddFlags(cmd)
 	return cmd
 }
@@ -64,12 +66,10 @@ func (o *InitRegistryOptions) Run() error {
 		Debug:    o.CommonOptions.Verbose,
 		Artifact: o.Artifact,
 	}
-	return pipelines.InitRegistry(arg, o.DownloadCmd)
+	return pipelines.InitRegistry(arg, o.DownloadOptions.ToDownloadOptions())
 }
 
 func (o *InitRegistryOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
-	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
-		`The user defined command to download the necessary files. The first param '%s' is output path, the second param '%s', is the URL`)
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 }
@@ -0,0 +1,58 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/pkg/files"
+)
+
+type DownloadOptions struct {
+	DownloadCmd        string
+	Proxy              string
+	CAFile             string
+	InsecureSkipVerify bool
+	Retry              int
+	Mirrors            []string
+}
+
+func NewDownloadOptions() *DownloadOptions {
+	return &DownloadOptions{}
+}
+
+func (o *DownloadOptions) AddDownloadFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "",
+		`The user defined command to download the necessary binary files, e.g. "curl -L -o %s %s". The first param '%s' is output path, the second param '%s', is the URL. The built-in downloader is used if it is empty`)
+	cmd.Flags().StringVar(&o.Proxy, "download-proxy", "", "The proxy URL used by the built-in downloader. The HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used if it is empty")
+	cmd.Flags().StringVar(&o.CAFile, "download-ca-file", "", "Path to a PEM encoded CA bundle trusted by the built-in downloader besides the system CAs")
+	cmd.Flags().BoolVar(&o.InsecureSkipVerify, "download-insecure-skip-tls-verify", false, "Skip the TLS verification of the built-in downloader")
+	cmd.Flags().IntVar(&o.Retry, "download-retry", files.DefaultDownloadRetry, "The number of attempts of the built-in downloader for each file")
+	cmd.Flags().StringArrayVar(&o.Mirrors, "download-mirror", []string{},
+		`A URL rewrite rule in the format of "prefix=replacement", e.g. "https://github.com=https://mirror.example.com/github.com". The replacement can be a file:// URL or a local directory of an offline cache. It can be specified multiple times and takes precedence over the KKZONE mirrors`)
+}
+
+func (o *DownloadOptions) ToDownloadOptions() files.DownloadOptions {
+	return files.DownloadOptions{
+		Command:            o.DownloadCmd,
+		Proxy:              o.Proxy,
+		CAFile:             o.CAFile,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+		Retry:              o.Retry,
+		Mirrors:            o.Mirrors,
+	}
+}
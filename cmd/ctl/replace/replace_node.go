@@ -0,0 +1,112 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package replace
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/cmd/ctl/options"
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/pipelines"
+)
+
+type ReplaceNodeOptions struct {
+	CommonOptions    *options.CommonOptions
+	DownloadOptions  *options.DownloadOptions
+	ClusterCfgFile   string
+	OldNode          string
+	NewNode          string
+	SkipPullImages   bool
+	ContainerManager string
+	Artifact         string
+	InstallPackages  bool
+}
+
+func NewReplaceNodeOptions() *ReplaceNodeOptions {
+	return &ReplaceNodeOptions{
+		CommonOptions:   options.NewCommonOptions(),
+		DownloadOptions: options.NewDownloadOptions(),
+	}
+}
+
+// NewCmdReplaceNode creates a new replace node command
+func NewCmdReplaceNode() *cobra.Command {
+	o := NewReplaceNodeOptions()
+	cmd := &cobra.Command{
+		Use:   "node",
+		Short: "Replace a failed node with a new node, the old node is not required to be reachable",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(cmd, args))
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *ReplaceNodeOptions) Complete(_ *cobra.Command, _ []string) error {
+	if o.Artifact == "" {
+		o.InstallPackages = false
+	}
+	return nil
+}
+
+func (o *ReplaceNodeOptions) Validate() error {
+	if o.ClusterCfgFile == "" {
+		return errors.New("the configuration file is required to replace a node")
+	}
+	if o.OldNode == "" || o.NewNode == "" {
+		return errors.New("both --old and --new are required")
+	}
+	if o.OldNode == o.NewNode {
+		return errors.New("the new node must be different from the old node")
+	}
+	return nil
+}
+
+func (o *ReplaceNodeOptions) Run() error {
+	arg := common.Argument{
+		FilePath:         o.ClusterCfgFile,
+		NodeName:         o.OldNode,
+		Debug:            o.CommonOptions.Verbose,
+		IgnoreErr:        o.CommonOptions.IgnoreErr,
+		SkipConfirmCheck: o.CommonOptions.SkipConfirmCheck,
+		SkipPullImages:   o.SkipPullImages,
+		InCluster:        o.CommonOptions.InCluster,
+		ContainerManager: o.ContainerManager,
+		Artifact:         o.Artifact,
+		InstallPackages:  o.InstallPackages,
+		Namespace:        o.CommonOptions.Namespace,
+	}
+	return pipelines.ReplaceNode(arg, o.NewNode, o.DownloadOptions.ToDownloadOptions())
+}
+
+func (o *ReplaceNodeOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.OldNode, "old", "", "", "Name of the node to be replaced")
+	cmd.Flags().StringVarP(&o.NewNode, "new", "", "", "Name of the new node, which must be in the hosts of the configuration file")
+	cmd.Flags().BoolVarP(&o.SkipPullImages, "skip-pull-images", "", false, "Skip pre pull images")
+	cmd.Flags().StringVarP(&o.ContainerManager, "container-manager", "", "docker", "Container manager: docker, crio, containerd and isula.")
+	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
+	cmd.Flags().BoolVarP(&o.InstallPackages, "with-packages", "", false, "install operation system packages by artifact")
+}
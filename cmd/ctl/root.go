@@ -21,14 +21,21 @@ import (
 	"github.com/kubesphere/kubekey/cmd/ctl/add"
 	"github.com/kubesphere/kubekey/cmd/ctl/artifact"
 	"github.com/kubesphere/kubekey/cmd/ctl/cert"
+	"github.com/kubesphere/kubekey/cmd/ctl/check"
 	"github.com/kubesphere/kubekey/cmd/ctl/completion"
 	"github.com/kubesphere/kubekey/cmd/ctl/create"
 	"github.com/kubesphere/kubekey/cmd/ctl/delete"
+	"github.com/kubesphere/kubekey/cmd/ctl/etcd"
+	"github.com/kubesphere/kubekey/cmd/ctl/images"
 	initOs "github.com/kubesphere/kubekey/cmd/ctl/init"
 	"github.com/kubesphere/kubekey/cmd/ctl/options"
 	"github.com/kubesphere/kubekey/cmd/ctl/plugin"
+	"github.com/kubesphere/kubekey/cmd/ctl/replace"
+	"github.com/kubesphere/kubekey/cmd/ctl/secrets"
+	"github.com/kubesphere/kubekey/cmd/ctl/update"
 	"github.com/kubesphere/kubekey/cmd/ctl/upgrade"
 	"github.com/kubesphere/kubekey/cmd/ctl/version"
+	"github.com/kubesphere/kubekey/pkg/files"
 	"github.com/spf13/cobra"
 	"os"
 	"os/exec"
@@ -40,6 +47,7 @@ import (
 type KubeKeyOptions struct {
 	PluginHandler PluginHandler
 	Arguments     []string
+	Catalog       string
 
 	options.IOStreams
 }
@@ -100,16 +108,28 @@ func NewKubeKeyCommand(o KubeKeyOptions) *cobra.Command {
 1. Install Kubernetes only
 2. Install Kubernetes and KubeSphere together in one command
 3. Install Kubernetes first, then deploy KubeSphere on it using https://github.com/kubesphere/ks-installer`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return files.SetCatalog(o.Catalog)
+		},
 	}
 
+	cmds.PersistentFlags().StringVar(&o.Catalog, "catalog", os.Getenv(files.CatalogEnv),
+		"Path or URL of a binary catalog which is merged on top of the embedded one. [Env: KKCATALOG]")
+
 	cmds.AddCommand(initOs.NewCmdInit())
 
 	cmds.AddCommand(create.NewCmdCreate())
 	cmds.AddCommand(delete.NewCmdDelete())
 	cmds.AddCommand(add.NewCmdAdd())
+	cmds.AddCommand(replace.NewCmdReplace())
 	cmds.AddCommand(upgrade.NewCmdUpgrade())
+	cmds.AddCommand(update.NewCmdUpdate())
 	cmds.AddCommand(cert.NewCmdCerts())
+	cmds.AddCommand(check.NewCmdCheck())
+	cmds.AddCommand(etcd.NewCmdEtcd())
+	cmds.AddCommand(secrets.NewCmdSecrets())
 	cmds.AddCommand(artifact.NewCmdArtifact())
+	cmds.AddCommand(images.NewCmdImages())
 
 	cmds.AddCommand(plugin.NewCmdPlugin(o.IOStreams))
 
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"github.com/kubesphere/kubekey/cmd/ctl/options"
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/pipelines"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type RotateEncryptionKeyOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+}
+
+func NewRotateEncryptionKeyOptions() *RotateEncryptionKeyOptions {
+	return &RotateEncryptionKeyOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdRotateEncryptionKey creates a new secrets rotate-encryption-key command
+func NewCmdRotateEncryptionKey() *cobra.Command {
+	o := NewRotateEncryptionKeyOptions()
+	cmd := &cobra.Command{
+		Use:   "rotate-encryption-key",
+		Short: "Rotate the key which encrypts secrets at rest, and rewrite the secrets with the new key",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *RotateEncryptionKeyOptions) Validate() error {
+	if o.ClusterCfgFile == "" {
+		return errors.New("the configuration file is required to rotate the encryption key")
+	}
+	return nil
+}
+
+func (o *RotateEncryptionKeyOptions) Run() error {
+	arg := common.Argument{
+		FilePath:         o.ClusterCfgFile,
+		Debug:            o.CommonOptions.Verbose,
+		SkipConfirmCheck: o.CommonOptions.SkipConfirmCheck,
+	}
+	return pipelines.RotateEncryptionKey(arg)
+}
+
+func (o *RotateEncryptionKeyOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package update
+
+import (
+	"github.com/kubesphere/kubekey/cmd/ctl/options"
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/pipelines"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type UpdateLoadBalancerOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+	Mode           string
+	Address        string
+	Port           int
+}
+
+func NewUpdateLoadBalancerOptions() *UpdateLoadBalancerOptions {
+	return &UpdateLoadBalancerOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdUpdateLoadBalancer creates a new update loadbalancer command
+func NewCmdUpdateLoadBalancer() *cobra.Command {
+	o := NewUpdateLoadBalancerOptions()
+	cmd := &cobra.Command{
+		Use:   "loadbalancer",
+		Short: "Switch the load balancer of the control plane endpoint",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *UpdateLoadBalancerOptions) Validate() error {
+	if o.ClusterCfgFile == "" {
+		return errors.New("the configuration file is required to update the load balancer")
+	}
+	if o.Mode == "" {
+		return errors.New("the mode of the load balancer is required")
+	}
+	if o.Port < 0 || o.Port > 65535 {
+		return errors.Errorf("invalid port %d", o.Port)
+	}
+	return nil
+}
+
+func (o *UpdateLoadBalancerOptions) Run() error {
+	arg := common.Argument{
+		FilePath:         o.ClusterCfgFile,
+		Debug:            o.CommonOptions.Verbose,
+		SkipConfirmCheck: o.CommonOptions.SkipConfirmCheck,
+	}
+	return pipelines.UpdateLoadBalancer(arg, o.Mode, o.Address, o.Port)
+}
+
+func (o *UpdateLoadBalancerOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVar(&o.Mode, "mode", "", "The load balancer of the control plane endpoint, support: haproxy, kube-vip, keepalived+haproxy, external")
+	cmd.Flags().StringVar(&o.Address, "address", "", "The VIP of kube-vip and keepalived+haproxy, or the address of the external load balancer")
+	cmd.Flags().IntVar(&o.Port, "port", 0, "The port of the control plane endpoint, defaults to 8443 for keepalived+haproxy and 6443 for the others")
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package update
+
+import (
+	"github.com/kubesphere/kubekey/cmd/ctl/options"
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/pipelines"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type UpdateNetworkOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+	Plugin         string
+}
+
+func NewUpdateNetworkOptions() *UpdateNetworkOptions {
+	return &UpdateNetworkOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdUpdateNetwork creates a new update network command
+func NewCmdUpdateNetwork() *cobra.Command {
+	o := NewUpdateNetworkOptions()
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "Migrate the network plugin from flannel to calico or cilium",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *UpdateNetworkOptions) Validate() error {
+	if o.ClusterCfgFile == "" {
+		return errors.New("the configuration file is required to update the network plugin")
+	}
+	if o.Plugin == "" {
+		return errors.New("the network plugin is required")
+	}
+	return nil
+}
+
+func (o *UpdateNetworkOptions) Run() error {
+	arg := common.Argument{
+		FilePath:         o.ClusterCfgFile,
+		Debug:            o.CommonOptions.Verbose,
+		SkipConfirmCheck: o.CommonOptions.SkipConfirmCheck,
+		InCluster:        o.CommonOptions.InCluster,
+	}
+	return pipelines.UpdateNetwork(arg, o.Plugin)
+}
+
+func (o *UpdateNetworkOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVar(&o.Plugin, "plugin", "", "The network plugin to migrate to, support: calico, cilium")
+}
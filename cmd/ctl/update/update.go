@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package update
+
+import (
+	"github.com/kubesphere/kubekey/cmd/ctl/options"
+	"github.com/spf13/cobra"
+)
+
+type UpdateOptions struct {
+	CommonOptions *options.CommonOptions
+}
+
+func NewUpdateOptions() *UpdateOptions {
+	return &UpdateOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdUpdate creates a new update command
+func NewCmdUpdate() *cobra.Command {
+	o := NewUpdateOptions()
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the configuration of an existing cluster",
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+
+	cmd.AddCommand(NewCmdUpdateLoadBalancer())
+	cmd.AddCommand(NewCmdUpdateNetwork())
+	return cmd
+}
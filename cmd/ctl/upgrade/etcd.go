@@ -0,0 +1,85 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"github.com/kubesphere/kubekey/cmd/ctl/options"
+	"github.com/kubesphere/kubekey/cmd/ctl/util"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/pipelines"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type UpgradeEtcdOptions struct {
+	CommonOptions   *options.CommonOptions
+	DownloadOptions *options.DownloadOptions
+	ClusterCfgFile  string
+	Version         string
+	Artifact        string
+}
+
+func NewUpgradeEtcdOptions() *UpgradeEtcdOptions {
+	return &UpgradeEtcdOptions{
+		CommonOptions:   options.NewCommonOptions(),
+		DownloadOptions: options.NewDownloadOptions(),
+	}
+}
+
+// NewCmdUpgradeEtcd creates a new upgrade etcd command
+func NewCmdUpgradeEtcd() *cobra.Command {
+	o := NewUpgradeEtcdOptions()
+	cmd := &cobra.Command{
+		Use:   "etcd",
+		Short: "Upgrade the etcd cluster installed by KubeKey member by member",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *UpgradeEtcdOptions) Validate() error {
+	if o.ClusterCfgFile == "" {
+		return errors.New("the configuration file is required to upgrade etcd")
+	}
+	if o.Version == "" {
+		return errors.New("the etcd version to upgrade to is required")
+	}
+	return nil
+}
+
+func (o *UpgradeEtcdOptions) Run() error {
+	arg := common.Argument{
+		FilePath:         o.ClusterCfgFile,
+		Debug:            o.CommonOptions.Verbose,
+		SkipConfirmCheck: o.CommonOptions.SkipConfirmCheck,
+		Artifact:         o.Artifact,
+	}
+	return pipelines.EtcdUpgrade(arg, o.DownloadOptions.ToDownloadOptions(), o.Version)
+}
+
+func (o *UpgradeEtcdOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.Version, "version", "", "", "Version of etcd to upgrade to, e.g. v3.5.4")
+	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
+}
@@ -30,18 +30,19 @@ import (
 
 type UpgradeOptions struct {
 	CommonOptions    *options.CommonOptions
+	DownloadOptions  *options.DownloadOptions
 	ClusterCfgFile   string
 	Kubernetes       string
 	EnableKubeSphere bool
 	KubeSphere       string
 	SkipPullImages   bool
-	DownloadCmd      string
 	Artifact         string
 }
 
 func NewUpgradeOptions() *UpgradeOptions {
 	return &UpgradeOptions{
-		CommonOptions: options.NewCommonOptions(),
+		CommonOptions:   options.NewCommonOptions(),
+		DownloadOptions: options.NewDownloadOptions(),
 	}
 }
 
@@ -58,10 +59,13 @@ func NewCmdUpgrade() *cobra.Command {
 	}
 
 	o.CommonOptions.AddCommonFlag(cmd)
+	o.DownloadOptions.AddDownloadFlags(cmd)
 	o.AddFlags(cmd)
 	if err := completionSetting(cmd); err != nil {
 		panic(fmt.Sprintf("Got error with the completion setting"))
 	}
+
+	cmd.AddCommand(NewCmdUpgradeEtcd())
 	return cmd
 }
 
@@ -87,7 +91,7 @@ func (o *UpgradeOptions) Run() error {
 		SkipConfirmCheck:  o.CommonOptions.SkipConfirmCheck,
 		Artifact:          o.Artifact,
 	}
-	return pipelines.UpgradeCluster(arg, o.DownloadCmd)
+	return pipelines.UpgradeCluster(arg, o.DownloadOptions.ToDownloadOptions())
 }
 
 func (o *UpgradeOptions) AddFlags(cmd *cobra.Command) {
@@ -95,8 +99,6 @@ func (o *UpgradeOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.Kubernetes, "with-kubernetes", "", "", "Specify a supported version of kubernetes")
 	cmd.Flags().BoolVarP(&o.EnableKubeSphere, "with-kubesphere", "", false, fmt.Sprintf("Deploy a specific version of kubesphere (default %s)", kubesphere.Latest().Version))
 	cmd.Flags().BoolVarP(&o.SkipPullImages, "skip-pull-images", "", false, "Skip pre pull images")
-	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
-		`The user defined command to download the necessary binary files. The first param '%s' is output path, the second param '%s', is the URL`)
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 }
 
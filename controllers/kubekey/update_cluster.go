@@ -434,3 +434,55 @@ func ClearConditions(runtime *common.KubeRuntime) error {
 	}
 	return nil
 }
+
+// ReplaceClusterNode is used to replace the old node with the new node in the spec of the cluster.
+func ReplaceClusterNode(runtime *common.KubeRuntime, oldNode, newNode string) error {
+	cluster, err := getCluster(runtime.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	hosts := make([]kubekeyapiv1alpha2.HostCfg, 0, len(cluster.Spec.Hosts))
+	for _, host := range cluster.Spec.Hosts {
+		if host.Name != oldNode {
+			hosts = append(hosts, host)
+		}
+	}
+	cluster.Spec.Hosts = hosts
+
+	for role := range cluster.Spec.RoleGroups {
+		for i, name := range cluster.Spec.RoleGroups[role] {
+			if name == oldNode {
+				cluster.Spec.RoleGroups[role][i] = newNode
+			}
+		}
+	}
+
+	if _, err := runtime.ClientSet.KubekeyV1alpha2().Clusters().Update(context.TODO(), cluster, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateClusterNetworkPlugin is used to update the network plugin and its IPAM in the spec of the cluster after the
+// network is migrated.
+func UpdateClusterNetworkPlugin(runtime *common.KubeRuntime) error {
+	cluster, err := getCluster(runtime.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	network := runtime.Cluster.Network
+	cluster.Spec.Network.Plugin = network.Plugin
+	switch network.Plugin {
+	case common.Calico:
+		cluster.Spec.Network.Calico.IPAM = network.Calico.IPAM
+	case common.Cilium:
+		cluster.Spec.Network.Cilium.IPAM = network.Cilium.IPAM
+	}
+
+	if _, err := runtime.ClientSet.KubekeyV1alpha2().Clusters().Update(context.TODO(), cluster, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"encoding/json"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/pkg/common"
+	coreutil "github.com/kubesphere/kubekey/pkg/core/util"
+)
+
+// Delta describes an incremental artifact. It only contains the files absent from the base artifact,
+// and the checksums of all the files that the unpacked artifact must have after the delta is merged.
+type Delta struct {
+	// Base is the md5 value of the base artifact.
+	Base string `json:"base"`
+	// Checksums is the sha256 value of every file in the full artifact, keyed by the relative path.
+	Checksums map[string]string `json:"checksums"`
+}
+
+// NewDelta computes the checksums of all the files under the artifact dir.
+func NewDelta(dir, base string) (*Delta, error) {
+	d := &Delta{
+		Base:      coreutil.LocalMd5Sum(base),
+		Checksums: make(map[string]string),
+	}
+
+	err := filepath.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == common.ArtifactDeltaFile {
+			return nil
+		}
+		sum, err := fileSha256(path)
+		if err != nil {
+			return err
+		}
+		d.Checksums[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Prune removes the files which already exist in the base artifact with the same checksum.
+// The manifest and the OCI index are always kept because they describe the new artifact.
+func (d *Delta) Prune(dir string, base *Info) ([]string, error) {
+	existing := make(map[string]string)
+	for _, b := range base.Binaries {
+		existing[b.Path] = b.Sha256
+	}
+	for _, i := range base.Isos {
+		existing[i.Path] = i.Sha256
+	}
+	for _, blob := range base.Blobs {
+		// blobs are content addressable, so the name is the checksum
+		existing[blob] = blob[strings.LastIndex(blob, "/")+1:]
+	}
+
+	removed := make([]string, 0)
+	for path, sum := range d.Checksums {
+		if v, ok := existing[path]; !ok || v != sum {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(path))); err != nil {
+			return nil, errors.Wrapf(errors.WithStack(err), "remove %s failed", path)
+		}
+		removed = append(removed, path)
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
+
+// Write saves the delta description into the artifact dir.
+func (d *Delta) Write(dir string) error {
+	content, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "marshal delta failed")
+	}
+	return coreutil.WriteFile(filepath.Join(dir, common.ArtifactDeltaFile), content)
+}
+
+// Validate checks that every file of the full artifact exists under the dir with the expected checksum.
+func (d *Delta) Validate(dir string) error {
+	paths := make([]string, 0, len(d.Checksums))
+	for path := range d.Checksums {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		file := filepath.Join(dir, filepath.FromSlash(path))
+		if !coreutil.IsExist(file) {
+			return errors.Errorf("%s is missing. Please unarchive the base artifact of this delta first", file)
+		}
+		sum, err := fileSha256(file)
+		if err != nil {
+			return errors.Wrapf(errors.WithStack(err), "compute the sha256 of %s failed", file)
+		}
+		if sum != d.Checksums[path] {
+			return errors.Errorf("SHA256 no match. %s: %s not equal %s", file, d.Checksums[path], sum)
+		}
+	}
+	return nil
+}
+
+// LoadDelta reads the delta description from the dir.
+func LoadDelta(dir string) (*Delta, error) {
+	file := filepath.Join(dir, common.ArtifactDeltaFile)
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "read delta file %s failed", file)
+	}
+	d := &Delta{}
+	if err := json.Unmarshal(content, d); err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "unmarshal delta file %s failed", file)
+	}
+	return d, nil
+}
+
+func fileSha256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return sha256sum(f)
+}
@@ -0,0 +1,105 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	coreutil "github.com/kubesphere/kubekey/pkg/core/util"
+)
+
+func TestDelta(t *testing.T) {
+	base := buildArtifact(t, map[string]string{
+		"kube/v1.21.5/amd64/kubeadm":     "kubeadm-v1.21.5",
+		"etcd/v3.4.13/amd64/etcd.tar.gz": "etcd",
+		"images/index.json":              `{"manifests":[]}`,
+		"images/blobs/sha256/2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae": "foo",
+	})
+	baseInfo, err := Inspect(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join(t.TempDir(), "artifact")
+	for name, content := range map[string]string{
+		"kube/v1.21.5/amd64/kubeadm":     "kubeadm-v1.21.5",
+		"etcd/v3.4.13/amd64/etcd.tar.gz": "etcd-rebuilt",
+		"images/index.json":              `{"manifests":[]}`,
+		"images/blobs/sha256/2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae": "foo",
+	} {
+		if err := coreutil.WriteFile(filepath.Join(src, name), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	delta, err := NewDelta(src, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed, err := delta.Prune(src, baseInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"images/blobs/sha256/2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+		"kube/v1.21.5/amd64/kubeadm",
+	}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("Prune() = %v, want %v", removed, want)
+	}
+	if err := delta.Validate(src); err == nil {
+		t.Errorf("Validate() should fail before the delta is merged into the base")
+	}
+
+	// merge the delta on top of the unpacked base artifact
+	dst := t.TempDir()
+	if err := coreutil.Untar(base, dst); err != nil {
+		t.Fatal(err)
+	}
+	if err := coreutil.WriteFile(filepath.Join(dst, "etcd/v3.4.13/amd64/etcd.tar.gz"), []byte("etcd-rebuilt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := delta.Validate(dst); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestBaseMd5(t *testing.T) {
+	dir := t.TempDir()
+	if got, err := readBaseMd5(dir, "last"); err != nil || got != "last" {
+		t.Errorf("readBaseMd5() = %s, %v, want the md5 of the last artifact when the base is not recorded", got, err)
+	}
+
+	// a full artifact is the base of itself
+	if err := writeBaseMd5(dir, "full"); err != nil {
+		t.Fatal(err)
+	}
+	// the deltas of the chain keep the full artifact as the base
+	for _, md5 := range []string{"delta1", "delta2"} {
+		if err := (&Delta{Base: "full"}).Write(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeBaseMd5(dir, md5); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := readBaseMd5(dir, md5); err != nil || got != "full" {
+			t.Errorf("readBaseMd5() after %s = %s, %v, want full", md5, got, err)
+		}
+	}
+}
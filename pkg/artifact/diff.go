@@ -0,0 +1,165 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+
+	KindBinary = "binary"
+	KindIso    = "iso"
+	KindImage  = "image"
+)
+
+// Change is a difference of one component between two artifacts.
+type Change struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// component is the comparable form of a binary, an iso or an image.
+type component struct {
+	key     string
+	name    string
+	version string
+	sum     string
+}
+
+// Diff compares two artifacts and returns the added, removed and changed components from a to b.
+func Diff(a, b *Info) []Change {
+	changes := make([]Change, 0)
+	changes = append(changes, diffComponents(KindBinary, binaryComponents(a), binaryComponents(b))...)
+	changes = append(changes, diffComponents(KindIso, isoComponents(a), isoComponents(b))...)
+	changes = append(changes, diffComponents(KindImage, imageComponents(a), imageComponents(b))...)
+	return changes
+}
+
+func binaryComponents(info *Info) []component {
+	c := make([]component, 0, len(info.Binaries))
+	for _, b := range info.Binaries {
+		c = append(c, component{key: b.Key(), name: b.Key(), version: b.Version, sum: b.Sha256})
+	}
+	return c
+}
+
+func isoComponents(info *Info) []component {
+	c := make([]component, 0, len(info.Isos))
+	for _, i := range info.Isos {
+		c = append(c, component{key: i.Key(), name: i.Key(), version: i.Version, sum: i.Sha256})
+	}
+	return c
+}
+
+func imageComponents(info *Info) []component {
+	c := make([]component, 0, len(info.Images))
+	for _, i := range info.Images {
+		c = append(c, component{key: i.Key(), name: i.Key(), version: i.Ref, sum: i.Digest})
+	}
+	return c
+}
+
+// diffComponents matches components by key and version first. The components with the same key
+// but different versions are reported as one change when there is exactly one on each side,
+// e.g. kube/kubeadm/amd64 v1.21.5 -> v1.22.10.
+func diffComponents(kind string, a, b []component) []Change {
+	old := make(map[string]component, len(a))
+	for _, c := range a {
+		old[c.key+"@"+c.version] = c
+	}
+	cur := make(map[string]component, len(b))
+	for _, c := range b {
+		cur[c.key+"@"+c.version] = c
+	}
+
+	changes := make([]Change, 0)
+	removed := make(map[string][]component)
+	added := make(map[string][]component)
+	for id, c := range old {
+		n, ok := cur[id]
+		if !ok {
+			removed[c.key] = append(removed[c.key], c)
+			continue
+		}
+		if n.sum != c.sum {
+			changes = append(changes, Change{Kind: kind, Name: c.name, Action: Changed,
+				Old: fmt.Sprintf("%s (%s)", c.version, short(c.sum)), New: fmt.Sprintf("%s (%s)", n.version, short(n.sum))})
+		}
+	}
+	for id, c := range cur {
+		if _, ok := old[id]; !ok {
+			added[c.key] = append(added[c.key], c)
+		}
+	}
+
+	for key, rs := range removed {
+		as := added[key]
+		if len(rs) == 1 && len(as) == 1 {
+			changes = append(changes, Change{Kind: kind, Name: rs[0].name, Action: Changed, Old: rs[0].version, New: as[0].version})
+			delete(added, key)
+			continue
+		}
+		for _, r := range rs {
+			changes = append(changes, Change{Kind: kind, Name: r.name, Action: Removed, Old: r.version})
+		}
+	}
+	for _, as := range added {
+		for _, a := range as {
+			changes = append(changes, Change{Kind: kind, Name: a.name, Action: Added, New: a.version})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Name == changes[j].Name {
+			return changes[i].Old+changes[i].New < changes[j].Old+changes[j].New
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+func short(sum string) string {
+	if len(sum) > 12 {
+		return sum[:12]
+	}
+	return sum
+}
+
+// PrintChanges prints the differences between two artifacts as a table.
+func PrintChanges(out io.Writer, changes []Change) {
+	if len(changes) == 0 {
+		_, _ = fmt.Fprintln(out, "No differences found")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "KIND\tNAME\tACTION\tOLD\tNEW")
+	for _, c := range changes {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Kind, c.Name, c.Action, c.Old, c.New)
+	}
+	_ = w.Flush()
+}
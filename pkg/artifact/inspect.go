@@ -0,0 +1,289 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/files"
+	"github.com/kubesphere/kubekey/pkg/images"
+)
+
+const (
+	imagesDir     = "images"
+	repositoryDir = "repository"
+	indexFile     = "index.json"
+	blobsDir      = "blobs"
+)
+
+// Binary describes a binary file packaged in an artifact.
+type Binary struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Arch    string `json:"arch"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Sha256  string `json:"sha256"`
+}
+
+// Key returns the identity of a binary regardless of its version.
+func (b Binary) Key() string {
+	return fmt.Sprintf("%s/%s/%s", b.Type, b.Name, b.Arch)
+}
+
+// Iso describes an OS repository ISO packaged in an artifact.
+type Iso struct {
+	Id      string `json:"id"`
+	Version string `json:"version"`
+	Arch    string `json:"arch"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Sha256  string `json:"sha256"`
+}
+
+// Key returns the identity of an ISO regardless of its version.
+func (i Iso) Key() string {
+	return fmt.Sprintf("%s/%s", i.Id, i.Arch)
+}
+
+// OCIImage describes an image stored in the OCI layout of an artifact.
+type OCIImage struct {
+	// Ref is the reference name in the OCI index, e.g. calico:cni:v3.20.0-amd64
+	Ref    string `json:"ref"`
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+// Key returns the identity of an image regardless of its tag, e.g. calico:cni
+func (o OCIImage) Key() string {
+	if n := strings.LastIndex(o.Ref, ":"); n > 0 {
+		return o.Ref[:n]
+	}
+	return o.Ref
+}
+
+// Info is the content summary of a KubeKey artifact.
+type Info struct {
+	File     string                    `json:"file"`
+	Manifest *kubekeyv1alpha2.Manifest `json:"manifest,omitempty"`
+	Binaries []Binary                  `json:"binaries"`
+	Isos     []Iso                     `json:"isos"`
+	Images   []OCIImage                `json:"images"`
+	// Blobs are the paths of the image blobs in the OCI layout, e.g. images/blobs/sha256/{hex}
+	Blobs []string `json:"blobs"`
+	// Delta is not nil if the artifact is an incremental artifact.
+	Delta *Delta `json:"delta,omitempty"`
+}
+
+// Inspect reads the artifact tarball as a stream and collects its content without extracting it to disk.
+func Inspect(file string) (*Info, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open artifact %s failed", file)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read artifact %s failed", file)
+	}
+	defer gr.Close()
+
+	info := &Info{
+		File:     file,
+		Binaries: make([]Binary, 0),
+		Isos:     make([]Iso, 0),
+		Images:   make([]OCIImage, 0),
+		Blobs:    make([]string, 0),
+	}
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "read artifact %s failed", file)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if err := info.addEntry(name, hdr.Size, tr); err != nil {
+			return nil, errors.Wrapf(err, "parse artifact entry %s failed", name)
+		}
+	}
+
+	sort.Slice(info.Binaries, func(i, j int) bool {
+		if info.Binaries[i].Key() == info.Binaries[j].Key() {
+			return info.Binaries[i].Version < info.Binaries[j].Version
+		}
+		return info.Binaries[i].Key() < info.Binaries[j].Key()
+	})
+	sort.Slice(info.Isos, func(i, j int) bool { return info.Isos[i].Path < info.Isos[j].Path })
+	sort.Slice(info.Images, func(i, j int) bool { return info.Images[i].Ref < info.Images[j].Ref })
+	return info, nil
+}
+
+func (i *Info) addEntry(name string, size int64, r io.Reader) error {
+	parts := strings.Split(name, "/")
+	switch {
+	case name == common.ArtifactManifestFile:
+		content, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		manifest, err := parseManifest(content)
+		if err != nil {
+			return err
+		}
+		i.Manifest = manifest
+	case name == common.ArtifactDeltaFile:
+		content, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		delta := &Delta{}
+		if err := json.Unmarshal(content, delta); err != nil {
+			return err
+		}
+		i.Delta = delta
+	case parts[0] == imagesDir:
+		if len(parts) == 4 && parts[1] == blobsDir {
+			i.Blobs = append(i.Blobs, name)
+			return nil
+		}
+		if name != path.Join(imagesDir, indexFile) {
+			return nil
+		}
+		content, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		index := images.NewIndex()
+		if err := json.Unmarshal(content, index); err != nil {
+			return err
+		}
+		for _, m := range index.Manifests {
+			i.Images = append(i.Images, OCIImage{Ref: m.Annotations.RefName, Digest: m.Digest, Size: m.Size})
+		}
+	case parts[0] == repositoryDir && len(parts) == 5:
+		// repository/{arch}/{id}/{version}/{id}-{version}-{arch}.iso
+		sum, err := sha256sum(r)
+		if err != nil {
+			return err
+		}
+		i.Isos = append(i.Isos, Iso{Arch: parts[1], Id: parts[2], Version: parts[3], Path: name, Size: size, Sha256: sum})
+	case parts[0] == files.REGISTRY && len(parts) == 5:
+		// registry/{id}/{version}/{arch}/{file}
+		sum, err := sha256sum(r)
+		if err != nil {
+			return err
+		}
+		i.Binaries = append(i.Binaries, Binary{Type: parts[0], Name: parts[1], Version: parts[2], Arch: parts[3],
+			Path: name, Size: size, Sha256: sum})
+	case len(parts) == 4:
+		// {type}/{version}/{arch}/{file}
+		sum, err := sha256sum(r)
+		if err != nil {
+			return err
+		}
+		binaryName := parts[0]
+		if parts[0] == files.KUBE {
+			binaryName = parts[3]
+		}
+		i.Binaries = append(i.Binaries, Binary{Type: parts[0], Name: binaryName, Version: parts[1], Arch: parts[2],
+			Path: name, Size: size, Sha256: sum})
+	}
+	return nil
+}
+
+func parseManifest(content []byte) (*kubekeyv1alpha2.Manifest, error) {
+	contentToJson, err := k8syaml.ToJSON(content)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to convert manifest to json")
+	}
+
+	manifest := &kubekeyv1alpha2.Manifest{}
+	if err := json.Unmarshal(contentToJson, manifest); err != nil {
+		return nil, errors.Wrap(err, "Failed to json unmarshal")
+	}
+	return manifest, nil
+}
+
+func sha256sum(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// PrintInfo prints the artifact content as tables.
+func PrintInfo(out io.Writer, info *Info) {
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	_, _ = fmt.Fprintf(w, "ARTIFACT: %s\n", info.File)
+	if info.Manifest != nil {
+		_, _ = fmt.Fprintf(w, "MANIFEST: %s\n", info.Manifest.Name)
+		for _, k := range info.Manifest.Spec.KubernetesDistributions {
+			_, _ = fmt.Fprintf(w, "KUBERNETES: %s %s\n", k.Type, k.Version)
+		}
+	} else {
+		_, _ = fmt.Fprintln(w, "MANIFEST: <none>")
+	}
+	if info.Delta != nil {
+		_, _ = fmt.Fprintf(w, "DELTA OF: %s (md5)\n", info.Delta.Base)
+	}
+
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "BINARY\tTYPE\tVERSION\tARCH\tSIZE\tSHA256")
+	for _, b := range info.Binaries {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", b.Name, b.Type, b.Version, b.Arch, b.Size, b.Sha256)
+	}
+
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "ISO\tVERSION\tARCH\tSIZE\tSHA256")
+	for _, iso := range info.Isos {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", iso.Id, iso.Version, iso.Arch, iso.Size, iso.Sha256)
+	}
+
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "IMAGE\tDIGEST")
+	for _, image := range info.Images {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", image.Ref, image.Digest)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,117 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	coreutil "github.com/kubesphere/kubekey/pkg/core/util"
+)
+
+const testManifest = `apiVersion: kubekey.kubesphere.io/v1alpha2
+kind: Manifest
+metadata:
+  name: sample
+spec:
+  arches:
+  - amd64
+  kubernetesDistributions:
+  - type: kubernetes
+    version: v1.21.5
+`
+
+func buildArtifact(t *testing.T, entries map[string]string) string {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "artifact")
+	for name, content := range entries {
+		if err := coreutil.WriteFile(filepath.Join(src, name), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dst := filepath.Join(dir, "artifact.tar.gz")
+	if err := coreutil.Tar(src, dst, src); err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+func TestInspectAndDiff(t *testing.T) {
+	a := buildArtifact(t, map[string]string{
+		"manifest.yaml":                                        testManifest,
+		"kube/v1.21.5/amd64/kubeadm":                           "kubeadm-v1.21.5",
+		"etcd/v3.4.13/amd64/etcd.tar.gz":                       "etcd",
+		"helm/v3.6.3/amd64/helm":                               "helm",
+		"registry/registry/2/amd64/registry.tgz":               "registry",
+		"repository/amd64/ubuntu/20.04/ubuntu-20.04-amd64.iso": "iso",
+		"images/index.json": `{"manifests":[{"digest":"sha256:aaa","size":1,` +
+			`"annotations":{"org.opencontainers.image.ref.name":"calico:cni:v3.20.0-amd64"}}]}`,
+		"images/blobs/sha256/aaa": "blob",
+	})
+	b := buildArtifact(t, map[string]string{
+		"kube/v1.22.10/amd64/kubeadm":            "kubeadm-v1.22.10",
+		"etcd/v3.4.13/amd64/etcd.tar.gz":         "etcd-rebuilt",
+		"registry/registry/2/amd64/registry.tgz": "registry",
+		"runc/v1.1.1/amd64/runc.amd64":           "runc",
+		"images/index.json": `{"manifests":[{"digest":"sha256:bbb","size":1,` +
+			`"annotations":{"org.opencontainers.image.ref.name":"calico:cni:v3.20.0-amd64"}}]}`,
+	})
+
+	info, err := Inspect(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Manifest == nil || info.Manifest.Name != "sample" {
+		t.Errorf("Inspect() manifest = %v, want sample", info.Manifest)
+	}
+	wantBinaries := []string{"etcd/etcd/amd64", "helm/helm/amd64", "kube/kubeadm/amd64", "registry/registry/amd64"}
+	gotBinaries := make([]string, 0)
+	for _, bin := range info.Binaries {
+		gotBinaries = append(gotBinaries, bin.Key())
+	}
+	if !reflect.DeepEqual(gotBinaries, wantBinaries) {
+		t.Errorf("Inspect() binaries = %v, want %v", gotBinaries, wantBinaries)
+	}
+	if len(info.Isos) != 1 || info.Isos[0].Id != "ubuntu" || info.Isos[0].Version != "20.04" {
+		t.Errorf("Inspect() isos = %v", info.Isos)
+	}
+	if len(info.Images) != 1 || info.Images[0].Digest != "sha256:aaa" {
+		t.Errorf("Inspect() images = %v", info.Images)
+	}
+
+	infoB, err := Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]string)
+	for _, c := range Diff(info, infoB) {
+		got[c.Kind+" "+c.Name] = c.Action
+	}
+	want := map[string]string{
+		"binary etcd/etcd/amd64":    Changed,
+		"binary helm/helm/amd64":    Removed,
+		"binary kube/kubeadm/amd64": Changed,
+		"binary runc/runc/amd64":    Added,
+		"iso ubuntu/amd64":          Removed,
+		"image calico:cni":          Changed,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+}
@@ -34,10 +34,22 @@ import (
 	"github.com/kubesphere/kubekey/pkg/client/kubernetes"
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/util"
+	"github.com/kubesphere/kubekey/pkg/images"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// kubernetesImages are the images in the BOM which every Kubernetes cluster needs.
+var kubernetesImages = []string{
+	"pause",
+	"kube-apiserver",
+	"kube-controller-manager",
+	"kube-scheduler",
+	"kube-proxy",
+	"coredns",
+	"k8s-dns-node-cache",
+}
+
 func CreateManifest(arg common.Argument, name string) error {
 	checkFileExists(arg.FilePath)
 
@@ -137,11 +149,6 @@ func CreateManifest(arg common.Argument, name string) error {
 		arch := v.(string)
 		archArr = append(archArr, arch)
 	}
-	imageArr := make([]string, 0, imagesSet.Cardinality())
-	for _, v := range imagesSet.ToSlice() {
-		image := v.(string)
-		imageArr = append(imageArr, image)
-	}
 	osArr := make([]kubekeyv1alpha2.OperatingSystem, 0, osSet.Cardinality())
 	for _, v := range osSet.ToSlice() {
 		osObj := v.(kubekeyv1alpha2.OperatingSystem)
@@ -153,6 +160,21 @@ func CreateManifest(arg common.Argument, name string) error {
 		containerArr = append(containerArr, container)
 	}
 
+	// the images required by the Kubernetes version, in case they have been removed from the nodes.
+	bom := images.DefaultBOM()
+	if kubernetesDistribution.Type == common.Kubernetes {
+		for _, container := range containerArr {
+			for _, name := range kubernetesImages {
+				imagesSet.Add(bom.ImageName(name, kubernetesDistribution.Version, container.Type))
+			}
+		}
+	}
+	imageArr := make([]string, 0, imagesSet.Cardinality())
+	for _, v := range imagesSet.ToSlice() {
+		image := v.(string)
+		imageArr = append(imageArr, image)
+	}
+
 	// todo: Whether it need to detect components version
 	sort.Strings(imageArr)
 	options := &templates.Options{
@@ -163,7 +185,7 @@ func CreateManifest(arg common.Argument, name string) error {
 		Components: kubekeyv1alpha2.Components{
 			Helm:              kubekeyv1alpha2.Helm{Version: kubekeyv1alpha2.DefaultHelmVersion},
 			CNI:               kubekeyv1alpha2.CNI{Version: kubekeyv1alpha2.DefaultCniVersion},
-			ETCD:              kubekeyv1alpha2.ETCD{Version: kubekeyv1alpha2.DefaultEtcdVersion},
+			ETCD:              kubekeyv1alpha2.ETCD{Version: bom.Components[common.ETCD]},
 			Crictl:            kubekeyv1alpha2.Crictl{Version: kubekeyv1alpha2.DefaultCrictlVersion},
 			ContainerRuntimes: containerArr,
 		},
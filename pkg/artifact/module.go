@@ -18,6 +18,7 @@ package artifact
 
 import (
 	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/prepare"
 	"github.com/kubesphere/kubekey/pkg/core/task"
 )
 
@@ -48,6 +49,30 @@ func (r *RepositoryModule) Init() {
 	}
 }
 
+type DeltaModule struct {
+	common.ArtifactModule
+	Skip bool
+}
+
+func (d *DeltaModule) IsSkip() bool {
+	return d.Skip
+}
+
+func (d *DeltaModule) Init() {
+	d.Name = "ArtifactDeltaModule"
+	d.Desc = "Remove the dependencies which already exist in the base artifact"
+
+	createDelta := &task.LocalTask{
+		Name:   "CreateDelta",
+		Desc:   "Remove the dependencies which already exist in the base artifact",
+		Action: new(CreateDelta),
+	}
+
+	d.Tasks = []task.Interface{
+		createDelta,
+	}
+}
+
 type ArchiveModule struct {
 	common.ArtifactModule
 }
@@ -56,6 +81,12 @@ func (a *ArchiveModule) Init() {
 	a.Name = "ArtifactArchiveModule"
 	a.Desc = "Archive the dependencies"
 
+	saveManifest := &task.LocalTask{
+		Name:   "SaveManifest",
+		Desc:   "Save the manifest into the artifact dir",
+		Action: new(SaveManifest),
+	}
+
 	archive := &task.LocalTask{
 		Name:   "ArchiveDependencies",
 		Desc:   "Archive the dependencies",
@@ -63,6 +94,7 @@ func (a *ArchiveModule) Init() {
 	}
 
 	a.Tasks = []task.Interface{
+		saveManifest,
 		archive,
 	}
 }
@@ -93,6 +125,13 @@ func (u *UnArchiveModule) Init() {
 		Action:  new(UnArchive),
 	}
 
+	validateDelta := &task.LocalTask{
+		Name:    "ValidateDelta",
+		Desc:    "Validate the checksums of the artifact merged with a delta",
+		Prepare: &prepare.PrepareCollection{&Md5AreEqual{Not: true}, new(IsDelta)},
+		Action:  new(ValidateDelta),
+	}
+
 	createMd5File := &task.LocalTask{
 		Name:    "CreateArtifactMd5File",
 		Desc:    "Create the KubeKey artifact Md5 file",
@@ -103,6 +142,7 @@ func (u *UnArchiveModule) Init() {
 	u.Tasks = []task.Interface{
 		md5Check,
 		unArchive,
+		validateDelta,
 		createMd5File,
 	}
 }
@@ -18,8 +18,11 @@ package artifact
 
 import (
 	"fmt"
+	"path/filepath"
+
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/connector"
+	coreutil "github.com/kubesphere/kubekey/pkg/core/util"
 )
 
 type EnableDownload struct {
@@ -51,3 +54,11 @@ func (m *Md5AreEqual) PreCheck(_ connector.Runtime) (bool, error) {
 	}
 	return m.Not, nil
 }
+
+type IsDelta struct {
+	common.KubePrepare
+}
+
+func (i *IsDelta) PreCheck(runtime connector.Runtime) (bool, error) {
+	return coreutil.IsExist(filepath.Join(runtime.GetWorkDir(), common.ArtifactDeltaFile)), nil
+}
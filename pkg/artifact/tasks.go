@@ -45,31 +45,8 @@ func (d *DownloadISOFile) Execute(runtime connector.Runtime) error {
 
 		fileName := fmt.Sprintf("%s-%s-%s.iso", sys.Id, sys.Version, sys.Arch)
 		filePath := filepath.Join(runtime.GetWorkDir(), fileName)
-		getCmd := d.Manifest.Arg.DownloadCommand(filePath, sys.Repository.Iso.Url)
-
-		cmd := exec.Command("/bin/sh", "-c", getCmd)
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			return fmt.Errorf("Failed to download %s iso file: %s error: %w ", fileName, getCmd, err)
-		}
-		cmd.Stderr = cmd.Stdout
-
-		if err = cmd.Start(); err != nil {
-			return fmt.Errorf("Failed to download %s iso file: %s error: %w ", fileName, getCmd, err)
-		}
-		for {
-			tmp := make([]byte, 1024)
-			_, err := stdout.Read(tmp)
-			fmt.Print(string(tmp))
-			if errors.Is(err, io.EOF) {
-				break
-			} else if err != nil {
-				logger.Log.Errorln(err)
-				break
-			}
-		}
-		if err = cmd.Wait(); err != nil {
-			return fmt.Errorf("Failed to download %s iso file: %s error: %w ", fileName, getCmd, err)
+		if err := d.Manifest.Arg.Downloader.Download(sys.Repository.Iso.Url, filePath); err != nil {
+			return fmt.Errorf("Failed to download %s iso file: %s error: %w ", fileName, sys.Repository.Iso.Url, err)
 		}
 		d.Manifest.Spec.OperatingSystems[i].Repository.Iso.LocalPath = filePath
 	}
@@ -100,6 +77,54 @@ func (l *LocalCopy) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+type SaveManifest struct {
+	common.ArtifactAction
+}
+
+func (s *SaveManifest) Execute(runtime connector.Runtime) error {
+	content, err := ioutil.ReadFile(s.Manifest.Arg.ManifestFile)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "read manifest %s failed", s.Manifest.Arg.ManifestFile)
+	}
+
+	path := filepath.Join(runtime.GetWorkDir(), common.Artifact, common.ArtifactManifestFile)
+	if err := coreutil.WriteFile(path, content); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "write manifest %s failed", path)
+	}
+	return nil
+}
+
+type CreateDelta struct {
+	common.ArtifactAction
+}
+
+func (c *CreateDelta) Execute(runtime connector.Runtime) error {
+	base, err := Inspect(c.Manifest.Arg.Base)
+	if err != nil {
+		return err
+	}
+	if base.Delta != nil {
+		return errors.Errorf("the base artifact %s is a delta artifact, please use a full artifact", c.Manifest.Arg.Base)
+	}
+
+	src := filepath.Join(runtime.GetWorkDir(), common.Artifact)
+	delta, err := NewDelta(src, c.Manifest.Arg.Base)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "compute the checksums of %s failed", src)
+	}
+
+	removed, err := delta.Prune(src, base)
+	if err != nil {
+		return err
+	}
+	for _, path := range removed {
+		logger.Log.Debugf("%s already exists in the base artifact", path)
+	}
+	logger.Log.Infof("%d of %d files already exist in the base artifact", len(removed), len(delta.Checksums))
+
+	return delta.Write(src)
+}
+
 type ArchiveDependencies struct {
 	common.ArtifactAction
 }
@@ -122,12 +147,38 @@ type UnArchive struct {
 }
 
 func (u *UnArchive) Execute(runtime connector.Runtime) error {
+	// a delta file left by the last unarchived artifact must not be applied to this one
+	if err := os.RemoveAll(filepath.Join(runtime.GetWorkDir(), common.ArtifactDeltaFile)); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "remove the old delta file failed")
+	}
 	if err := coreutil.Untar(u.KubeConf.Arg.Artifact, runtime.GetWorkDir()); err != nil {
 		return errors.Wrapf(errors.WithStack(err), "unArchive %s failed", u.KubeConf.Arg.Artifact)
 	}
 	return nil
 }
 
+type ValidateDelta struct {
+	common.KubeAction
+}
+
+func (v *ValidateDelta) Execute(runtime connector.Runtime) error {
+	delta, err := LoadDelta(runtime.GetWorkDir())
+	if err != nil {
+		return err
+	}
+
+	// the deltas of a chain share the full artifact as their base, so it is compared with the base of the chain
+	// rather than the last unarchived delta
+	if baseMd5, ok := v.ModuleCache.GetMustString("baseMd5"); !ok || baseMd5 != delta.Base {
+		logger.Log.Warningf("the last unarchived full artifact is not the base artifact of %s", v.KubeConf.Arg.Artifact)
+	}
+
+	if err := delta.Validate(runtime.GetWorkDir()); err != nil {
+		return errors.Wrapf(err, "merge the delta artifact %s failed", v.KubeConf.Arg.Artifact)
+	}
+	return nil
+}
+
 type Md5Check struct {
 	common.KubeAction
 }
@@ -146,6 +197,12 @@ func (m *Md5Check) Execute(runtime connector.Runtime) error {
 		return errors.Wrapf(errors.WithStack(err), "read old md5 file %s failed", oldFile)
 	}
 
+	m.ModuleCache.Set("oldMd5", string(oldMd5))
+	baseMd5, err := readBaseMd5(runtime.GetWorkDir(), string(oldMd5))
+	if err != nil {
+		return err
+	}
+	m.ModuleCache.Set("baseMd5", baseMd5)
 	newMd5 := coreutil.LocalMd5Sum(m.KubeConf.Arg.Artifact)
 
 	if string(oldMd5) == newMd5 {
@@ -169,5 +226,36 @@ func (c *CreateMd5File) Execute(runtime connector.Runtime) error {
 	if _, err := io.Copy(f, strings.NewReader(newMd5)); err != nil {
 		return errors.Wrapf(errors.WithStack(err), "write md5 value to file %s failed", oldFile)
 	}
+	return writeBaseMd5(runtime.GetWorkDir(), newMd5)
+}
+
+// readBaseMd5 returns the md5 value of the full artifact which the unarchived artifacts are based on,
+// or the md5 value of the last unarchived artifact if it is not recorded.
+func readBaseMd5(workDir, lastMd5 string) (string, error) {
+	baseFile := filepath.Join(workDir, common.ArtifactBaseMd5File)
+	if !coreutil.IsExist(baseFile) {
+		return lastMd5, nil
+	}
+	baseMd5, err := ioutil.ReadFile(baseFile)
+	if err != nil {
+		return "", errors.Wrapf(errors.WithStack(err), "read base md5 file %s failed", baseFile)
+	}
+	return string(baseMd5), nil
+}
+
+// writeBaseMd5 records the base of the unarchived artifact, which is the artifact itself unless it is a delta.
+func writeBaseMd5(workDir, artifactMd5 string) error {
+	baseMd5 := artifactMd5
+	if coreutil.IsExist(filepath.Join(workDir, common.ArtifactDeltaFile)) {
+		delta, err := LoadDelta(workDir)
+		if err != nil {
+			return err
+		}
+		baseMd5 = delta.Base
+	}
+	baseFile := filepath.Join(workDir, common.ArtifactBaseMd5File)
+	if err := ioutil.WriteFile(baseFile, []byte(baseMd5), 0644); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "write base md5 value to file %s failed", baseFile)
+	}
 	return nil
 }
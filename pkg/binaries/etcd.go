@@ -0,0 +1,64 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package binaries
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/cache"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/core/util"
+	"github.com/kubesphere/kubekey/pkg/files"
+)
+
+// EtcdFilesDownloadHTTP downloads the etcd binary of the version and adds it to the binaries of the arch in the pipeline cache.
+func EtcdFilesDownloadHTTP(kubeConf *common.KubeConf, path, version, arch string, pipelineCache *cache.Cache) error {
+	etcd := files.NewKubeBinary("etcd", arch, version, path, kubeConf.Arg.Downloader)
+	if err := etcd.CreateBaseDir(); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "create file %s base dir failed", etcd.FileName)
+	}
+
+	logger.Log.Messagef(common.LocalHost, "downloading %s %s %s ...", arch, etcd.ID, etcd.Version)
+
+	exist := false
+	if util.IsExist(etcd.Path()) {
+		// download it again if it's incorrect
+		if err := etcd.SHA256Check(); err != nil {
+			_ = exec.Command("/bin/sh", "-c", fmt.Sprintf("rm -f %s", etcd.Path())).Run()
+		} else {
+			logger.Log.Messagef(common.LocalHost, "%s is existed", etcd.ID)
+			exist = true
+		}
+	}
+	if !exist {
+		if err := etcd.Download(); err != nil {
+			return fmt.Errorf("Failed to download %s binary: %s error: %w ", etcd.ID, etcd.Url, err)
+		}
+	}
+
+	binariesMap := make(map[string]*files.KubeBinary)
+	if v, ok := pipelineCache.Get(common.KubeBinaries + "-" + arch); ok {
+		binariesMap = v.(map[string]*files.KubeBinary)
+	}
+	binariesMap[etcd.ID] = etcd
+	pipelineCache.Set(common.KubeBinaries+"-"+arch, binariesMap)
+	return nil
+}
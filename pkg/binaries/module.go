@@ -61,6 +61,31 @@ func (k *K3sNodeBinariesModule) Init() {
 	}
 }
 
+type EtcdBinariesModule struct {
+	common.KubeModule
+	Skip    bool
+	Version string
+}
+
+func (e *EtcdBinariesModule) IsSkip() bool {
+	return e.Skip
+}
+
+func (e *EtcdBinariesModule) Init() {
+	e.Name = "EtcdBinariesModule"
+	e.Desc = "Download etcd binaries"
+
+	download := &task.LocalTask{
+		Name:   "DownloadEtcdBinaries",
+		Desc:   "Download etcd binaries",
+		Action: &EtcdDownload{Version: e.Version},
+	}
+
+	e.Tasks = []task.Interface{
+		download,
+	}
+}
+
 type ArtifactBinariesModule struct {
 	common.ArtifactModule
 }
@@ -35,12 +35,12 @@ func RegistryPackageDownloadHTTP(kubeConf *common.KubeConf, path, arch string, p
 	switch kubeConf.Cluster.Registry.Type {
 	case common.Harbor:
 		// TODO: Harbor only supports amd64, so there is no need to consider other architectures at present.
-		harbor := files.NewKubeBinary("harbor", arch, kubekeyapiv1alpha2.DefaultHarborVersion, path, kubeConf.Arg.DownloadCommand)
-		compose := files.NewKubeBinary("compose", arch, kubekeyapiv1alpha2.DefaultDockerComposeVersion, path, kubeConf.Arg.DownloadCommand)
-		docker := files.NewKubeBinary("docker", arch, kubekeyapiv1alpha2.DefaultDockerVersion, path, kubeConf.Arg.DownloadCommand)
+		harbor := files.NewKubeBinary("harbor", arch, kubekeyapiv1alpha2.DefaultHarborVersion, path, kubeConf.Arg.Downloader)
+		compose := files.NewKubeBinary("compose", arch, kubekeyapiv1alpha2.DefaultDockerComposeVersion, path, kubeConf.Arg.Downloader)
+		docker := files.NewKubeBinary("docker", arch, kubekeyapiv1alpha2.DefaultDockerVersion, path, kubeConf.Arg.Downloader)
 		binaries = []*files.KubeBinary{harbor, docker, compose}
 	default:
-		registry := files.NewKubeBinary("registry", arch, kubekeyapiv1alpha2.DefaultRegistryVersion, path, kubeConf.Arg.DownloadCommand)
+		registry := files.NewKubeBinary("registry", arch, kubekeyapiv1alpha2.DefaultRegistryVersion, path, kubeConf.Arg.Downloader)
 		binaries = []*files.KubeBinary{registry}
 	}
 
@@ -63,7 +63,7 @@ func RegistryPackageDownloadHTTP(kubeConf *common.KubeConf, path, arch string, p
 		}
 
 		if err := binary.Download(); err != nil {
-			return fmt.Errorf("Failed to download %s binary: %s error: %w ", binary.ID, binary.GetCmd(), err)
+			return fmt.Errorf("Failed to download %s binary: %s error: %w ", binary.ID, binary.Url, err)
 		}
 	}
 
@@ -77,12 +77,12 @@ func RegistryBinariesDownload(manifest *common.ArtifactManifest, path, arch stri
 	binaries := make([]*files.KubeBinary, 0, 0)
 
 	if m.Components.DockerRegistry.Version != "" {
-		registry := files.NewKubeBinary("registry", arch, kubekeyapiv1alpha2.DefaultRegistryVersion, path, manifest.Arg.DownloadCommand)
+		registry := files.NewKubeBinary("registry", arch, kubekeyapiv1alpha2.DefaultRegistryVersion, path, manifest.Arg.Downloader)
 		binaries = append(binaries, registry)
 	}
 
 	if m.Components.Harbor.Version != "" {
-		harbor := files.NewKubeBinary("harbor", arch, kubekeyapiv1alpha2.DefaultHarborVersion, path, manifest.Arg.DownloadCommand)
+		harbor := files.NewKubeBinary("harbor", arch, kubekeyapiv1alpha2.DefaultHarborVersion, path, manifest.Arg.Downloader)
 		// TODO: Harbor only supports amd64, so there is no need to consider other architectures at present.
 		if arch == "amd64" {
 			binaries = append(binaries, harbor)
@@ -90,9 +90,9 @@ func RegistryBinariesDownload(manifest *common.ArtifactManifest, path, arch stri
 	}
 
 	if m.Components.DockerCompose.Version != "" {
-		compose := files.NewKubeBinary("compose", arch, kubekeyapiv1alpha2.DefaultDockerComposeVersion, path, manifest.Arg.DownloadCommand)
+		compose := files.NewKubeBinary("compose", arch, kubekeyapiv1alpha2.DefaultDockerComposeVersion, path, manifest.Arg.Downloader)
 		// TODO: Harbor only supports amd64, so there is no need to consider other architectures at present. docker-compose is required only if harbor is installed.
-		containerManager := files.NewKubeBinary("docker", arch, kubekeyapiv1alpha2.DefaultDockerVersion, path, manifest.Arg.DownloadCommand)
+		containerManager := files.NewKubeBinary("docker", arch, kubekeyapiv1alpha2.DefaultDockerVersion, path, manifest.Arg.Downloader)
 		if arch == "amd64" {
 			binaries = append(binaries, compose)
 			binaries = append(binaries, containerManager)
@@ -117,7 +117,7 @@ func RegistryBinariesDownload(manifest *common.ArtifactManifest, path, arch stri
 		}
 
 		if err := binary.Download(); err != nil {
-			return fmt.Errorf("Failed to download %s binary: %s error: %w ", binary.ID, binary.GetCmd(), err)
+			return fmt.Errorf("Failed to download %s binary: %s error: %w ", binary.ID, binary.Url, err)
 		}
 	}
 	return nil
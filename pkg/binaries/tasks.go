@@ -94,6 +94,25 @@ func (k *K3sDownload) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+type EtcdDownload struct {
+	common.KubeAction
+	Version string
+}
+
+func (e *EtcdDownload) Execute(runtime connector.Runtime) error {
+	archMap := make(map[string]bool)
+	for _, host := range runtime.GetHostsByRole(common.ETCD) {
+		archMap[host.GetArch()] = true
+	}
+
+	for arch := range archMap {
+		if err := EtcdFilesDownloadHTTP(e.KubeConf, runtime.GetWorkDir(), e.Version, arch, e.PipelineCache); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type ArtifactDownload struct {
 	common.ArtifactAction
 }
@@ -67,16 +67,27 @@ func (d *DeleteClusterConfirmModule) Init() {
 
 type DeleteNodeConfirmModule struct {
 	common.KubeModule
+	Skip bool
+	// Content describes the nodes to delete, defaults to node.
+	Content string
+}
+
+func (d *DeleteNodeConfirmModule) IsSkip() bool {
+	return d.Skip
 }
 
 func (d *DeleteNodeConfirmModule) Init() {
 	d.Name = "DeleteNodeConfirmModule"
 	d.Desc = "Display delete node confirmation form"
 
+	content := d.Content
+	if content == "" {
+		content = "node"
+	}
 	display := &task.LocalTask{
 		Name:   "ConfirmForm",
 		Desc:   "Display confirmation form",
-		Action: &DeleteConfirm{Content: "node"},
+		Action: &DeleteConfirm{Content: content},
 	}
 
 	d.Tasks = []task.Interface{
@@ -108,6 +119,56 @@ func (u *UpgradeConfirmModule) Init() {
 	}
 }
 
+type RotateCAConfirmModule struct {
+	common.KubeModule
+	Skip   bool
+	Phases []string
+}
+
+func (r *RotateCAConfirmModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *RotateCAConfirmModule) Init() {
+	r.Name = "RotateCAConfirmModule"
+	r.Desc = "Display CA rotation confirmation form"
+
+	display := &task.LocalTask{
+		Name:   "ConfirmForm",
+		Desc:   "Display confirmation form",
+		Action: &RotateCAConfirm{Phases: r.Phases},
+	}
+
+	r.Tasks = []task.Interface{
+		display,
+	}
+}
+
+type MaintenanceConfirmModule struct {
+	common.KubeModule
+	Skip    bool
+	Message string
+}
+
+func (m *MaintenanceConfirmModule) IsSkip() bool {
+	return m.Skip
+}
+
+func (m *MaintenanceConfirmModule) Init() {
+	m.Name = "MaintenanceConfirmModule"
+	m.Desc = "Display maintenance confirmation form"
+
+	display := &task.LocalTask{
+		Name:   "ConfirmForm",
+		Desc:   "Display confirmation form",
+		Action: &MaintenanceConfirm{Message: m.Message},
+	}
+
+	m.Tasks = []task.Interface{
+		display,
+	}
+}
+
 type CheckFileExistModule struct {
 	module.BaseTaskModule
 	FileName string
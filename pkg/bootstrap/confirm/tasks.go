@@ -176,6 +176,57 @@ func (d *DeleteConfirm) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+type RotateCAConfirm struct {
+	common.KubeAction
+	Phases []string
+}
+
+func (r *RotateCAConfirm) Execute(runtime connector.Runtime) error {
+	fmt.Printf("The following phases of CA rotation will be run: %s\n", strings.Join(r.Phases, ", "))
+	fmt.Println("The control-plane components, etcd and kubelet of every node will be restarted in each phase.")
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Are you sure to rotate the cluster CA? [yes/no]: ")
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "yes", "y":
+			return nil
+		case "no", "n":
+			os.Exit(0)
+		}
+	}
+}
+
+type MaintenanceConfirm struct {
+	common.KubeAction
+	Message string
+}
+
+func (m *MaintenanceConfirm) Execute(runtime connector.Runtime) error {
+	fmt.Println(m.Message)
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Are you sure to continue? [yes/no]: ")
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "yes", "y":
+			return nil
+		case "no", "n":
+			os.Exit(0)
+		}
+	}
+}
+
 type UpgradeConfirm struct {
 	common.KubeAction
 }
@@ -65,6 +65,14 @@ func (c *ConfigureOSModule) Init() {
 		Parallel: true,
 	}
 
+	ExecPrepareScripts := &task.RemoteTask{
+		Name:     "ExecPrepareScripts",
+		Desc:     "Exec the prepare scripts of the node pools",
+		Hosts:    c.Runtime.GetAllHosts(),
+		Action:   new(NodeExecPrepareScripts),
+		Parallel: true,
+	}
+
 	ConfigureNtpServer := &task.RemoteTask{
 		Name:     "ConfigureNtpServer",
 		Desc:     "configure the ntp server for each node",
@@ -78,6 +86,7 @@ func (c *ConfigureOSModule) Init() {
 		initOS,
 		GenerateScript,
 		ExecScript,
+		ExecPrepareScripts,
 		ConfigureNtpServer,
 	}
 }
@@ -92,7 +101,7 @@ func (c *ClearNodeOSModule) Init() {
 	resetNetworkConfig := &task.RemoteTask{
 		Name:     "ResetNetworkConfig",
 		Desc:     "Reset os network config",
-		Hosts:    c.Runtime.GetHostsByRole(common.Worker),
+		Hosts:    c.Runtime.GetHostsByRole(common.K8s),
 		Prepare:  new(DeleteNode),
 		Action:   new(ResetNetworkConfig),
 		Parallel: true,
@@ -101,16 +110,28 @@ func (c *ClearNodeOSModule) Init() {
 	removeFiles := &task.RemoteTask{
 		Name:     "RemoveFiles",
 		Desc:     "Remove node files",
-		Hosts:    c.Runtime.GetHostsByRole(common.Worker),
+		Hosts:    c.Runtime.GetHostsByRole(common.K8s),
 		Prepare:  new(DeleteNode),
 		Action:   new(RemoveNodeFiles),
 		Parallel: true,
 	}
 
+	uninstallETCD := &task.RemoteTask{
+		Name:  "UninstallETCD",
+		Desc:  "Uninstall etcd",
+		Hosts: c.Runtime.GetHostsByRole(common.ETCD),
+		Prepare: &prepare.PrepareCollection{
+			new(DeleteNode),
+			new(EtcdTypeIsKubeKey),
+		},
+		Action:   new(UninstallETCD),
+		Parallel: true,
+	}
+
 	daemonReload := &task.RemoteTask{
 		Name:     "DaemonReload",
 		Desc:     "Systemd daemon reload",
-		Hosts:    c.Runtime.GetHostsByRole(common.Worker),
+		Hosts:    c.Runtime.GetHostsByRole(common.K8s),
 		Prepare:  new(DeleteNode),
 		Action:   new(DaemonReload),
 		Parallel: true,
@@ -119,6 +140,7 @@ func (c *ClearNodeOSModule) Init() {
 	c.Tasks = []task.Interface{
 		resetNetworkConfig,
 		removeFiles,
+		uninstallETCD,
 		daemonReload,
 	}
 }
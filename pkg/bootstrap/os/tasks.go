@@ -17,6 +17,7 @@
 package os
 
 import (
+	"encoding/base64"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -24,6 +25,7 @@ import (
 	osrelease "github.com/dominodatalab/os-release"
 	"github.com/pkg/errors"
 
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/pkg/bootstrap/os/repository"
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/connector"
@@ -132,6 +134,34 @@ func (n *NodeExecScript) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+type NodeExecPrepareScripts struct {
+	common.KubeAction
+}
+
+func (n *NodeExecPrepareScripts) Execute(runtime connector.Runtime) error {
+	host, ok := runtime.RemoteHost().(*kubekeyv1alpha2.KubeHost)
+	if !ok || len(host.PrepareScripts) == 0 {
+		return nil
+	}
+
+	script := prepareScript(host.PrepareScripts)
+	scriptPath := filepath.Join(common.KubeScriptDir, "prepare.sh")
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("echo %s | base64 -d > %s && chmod +x %s",
+		base64.StdEncoding.EncodeToString([]byte(script)), scriptPath, scriptPath), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "Failed to write the prepare script")
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(scriptPath, true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "Failed to exec the prepare script")
+	}
+	return nil
+}
+
+// prepareScript joins the prepare scripts of the node into one, which stops at the first failed command.
+func prepareScript(scripts []string) string {
+	return "#!/usr/bin/env bash\nset -e\n\n" + strings.Join(scripts, "\n") + "\n"
+}
+
 var (
 	etcdFiles = []string{
 		"/usr/local/bin/etcd",
@@ -117,16 +117,30 @@ func (g *GenerateCerts) Execute(runtime connector.Runtime) error {
 	// Certs
 	certsList = append(certsList, KubekeyCertRegistryServer(&altName))
 
+	if pki := &g.KubeConf.Cluster.Registry.PKI; pki.Enabled() {
+		unsigned, err := certs.WriteExternalPKI(pki, certsList[0], certsList[1:], pkiPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to use the registry PKI supplied")
+		}
+		if pki.CAKeyFile == "" {
+			files = append(files[:1], files[2:]...)
+		}
+		certsList = append(certsList[:1], unsigned...)
+	}
+
 	var lastCACert *certs.KubekeyCert
 	for _, c := range certsList {
 		if c.CAName == "" {
-			err := certs.GenerateCA(c, pkiPath, g.KubeConf)
-			if err != nil {
-				return err
+			// The CA supplied has been written above.
+			if !g.KubeConf.Cluster.Registry.PKI.Enabled() {
+				err := certs.GenerateCA(c, pkiPath, g.KubeConf)
+				if err != nil {
+					return err
+				}
 			}
 			lastCACert = c
 		} else {
-			err := certs.GenerateCerts(c, lastCACert, pkiPath, g.KubeConf)
+			err := certs.GenerateCerts(c, lastCACert, pkiPath, g.KubeConf, false)
 			if err != nil {
 				return err
 			}
@@ -195,8 +195,8 @@ func InstallHarbor(i *InstallRegistryModule) []task.Interface {
 			Template: docker_template.DockerConfig,
 			Dst:      filepath.Join("/etc/docker/", docker_template.DockerConfig.Name()),
 			Data: util.Data{
-				"Mirrors":            docker_template.Mirrors(i.KubeConf),
-				"InsecureRegistries": docker_template.InsecureRegistries(i.KubeConf),
+				"Mirrors":            docker_template.Mirrors(i.KubeConf.Cluster.Registry.RegistryMirrors),
+				"InsecureRegistries": docker_template.InsecureRegistries(i.KubeConf.Cluster.Registry.InsecureRegistries),
 			},
 		},
 		Parallel: true,
@@ -0,0 +1,125 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	certLabels = []string{"certificate", "authority", "node"}
+
+	certExpirationDaysDesc = prometheus.NewDesc(
+		"kubekey_certificate_expiration_days",
+		"Days remaining until the certificate expires.",
+		certLabels, nil,
+	)
+	certExpirationTimestampDesc = prometheus.NewDesc(
+		"kubekey_certificate_expiration_timestamp_seconds",
+		"The time when the certificate expires, in unix seconds.",
+		certLabels, nil,
+	)
+	caExpirationDaysDesc = prometheus.NewDesc(
+		"kubekey_ca_certificate_expiration_days",
+		"Days remaining until the CA certificate expires.",
+		[]string{"certificate", "node"}, nil,
+	)
+	caExpirationTimestampDesc = prometheus.NewDesc(
+		"kubekey_ca_certificate_expiration_timestamp_seconds",
+		"The time when the CA certificate expires, in unix seconds.",
+		[]string{"certificate", "node"}, nil,
+	)
+	collectSuccessDesc = prometheus.NewDesc(
+		"kubekey_certificate_collection_success",
+		"Whether the last collection of the cluster certs succeeded.",
+		nil, nil,
+	)
+	collectTimestampDesc = prometheus.NewDesc(
+		"kubekey_certificate_collection_timestamp_seconds",
+		"The time of the last successful collection of the cluster certs, in unix seconds.",
+		nil, nil,
+	)
+)
+
+// Exporter exposes the expiration of the cluster certs as Prometheus metrics.
+// The certs of the last successful collection are kept if a collection fails.
+type Exporter struct {
+	mu            sync.RWMutex
+	clusterCerts  *ClusterCerts
+	success       bool
+	lastCollected time.Time
+}
+
+func NewExporter() *Exporter {
+	return &Exporter{clusterCerts: &ClusterCerts{}}
+}
+
+// Update records the result of a collection.
+func (e *Exporter) Update(clusterCerts *ClusterCerts, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.success = err == nil
+	if err == nil {
+		e.clusterCerts = clusterCerts
+		e.lastCollected = time.Now()
+	}
+}
+
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- certExpirationDaysDesc
+	ch <- certExpirationTimestampDesc
+	ch <- caExpirationDaysDesc
+	ch <- caExpirationTimestampDesc
+	ch <- collectSuccessDesc
+	ch <- collectTimestampDesc
+}
+
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, cert := range e.clusterCerts.Certificates {
+		ch <- prometheus.MustNewConstMetric(certExpirationDaysDesc, prometheus.GaugeValue,
+			daysUntil(cert.NotAfter), cert.Name, cert.AuthorityName, cert.NodeName)
+		ch <- prometheus.MustNewConstMetric(certExpirationTimestampDesc, prometheus.GaugeValue,
+			float64(cert.NotAfter.Unix()), cert.Name, cert.AuthorityName, cert.NodeName)
+	}
+	for _, ca := range e.clusterCerts.CertificateAuthorities {
+		ch <- prometheus.MustNewConstMetric(caExpirationDaysDesc, prometheus.GaugeValue,
+			daysUntil(ca.NotAfter), ca.AuthorityName, ca.NodeName)
+		ch <- prometheus.MustNewConstMetric(caExpirationTimestampDesc, prometheus.GaugeValue,
+			float64(ca.NotAfter.Unix()), ca.AuthorityName, ca.NodeName)
+	}
+
+	var success float64
+	if e.success {
+		success = 1
+	}
+	ch <- prometheus.MustNewConstMetric(collectSuccessDesc, prometheus.GaugeValue, success)
+	if !e.lastCollected.IsZero() {
+		ch <- prometheus.MustNewConstMetric(collectTimestampDesc, prometheus.GaugeValue, float64(e.lastCollected.Unix()))
+	}
+}
+
+// daysUntil returns the days remaining until t, which is negative if t has passed.
+func daysUntil(t time.Time) float64 {
+	return time.Until(t).Hours() / 24
+}
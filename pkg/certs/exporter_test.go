@@ -0,0 +1,64 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestExporter(t *testing.T) {
+	notAfter := time.Unix(1900000000, 0)
+	e := NewExporter()
+	e.Update(&ClusterCerts{
+		Certificates: []*Certificate{
+			{Name: "apiserver.crt", AuthorityName: "ca", NodeName: "node1", NotAfter: notAfter},
+		},
+		CertificateAuthorities: []*CaCertificate{
+			{AuthorityName: "ca.crt", NodeName: "node1", NotAfter: notAfter},
+		},
+	}, nil)
+	// The certs of the last successful collection are kept.
+	e.Update(nil, errors.New("connection refused"))
+
+	expected := `
+# HELP kubekey_ca_certificate_expiration_timestamp_seconds The time when the CA certificate expires, in unix seconds.
+# TYPE kubekey_ca_certificate_expiration_timestamp_seconds gauge
+kubekey_ca_certificate_expiration_timestamp_seconds{certificate="ca.crt",node="node1"} 1.9e+09
+# HELP kubekey_certificate_collection_success Whether the last collection of the cluster certs succeeded.
+# TYPE kubekey_certificate_collection_success gauge
+kubekey_certificate_collection_success 0
+# HELP kubekey_certificate_expiration_timestamp_seconds The time when the certificate expires, in unix seconds.
+# TYPE kubekey_certificate_expiration_timestamp_seconds gauge
+kubekey_certificate_expiration_timestamp_seconds{authority="ca",certificate="apiserver.crt",node="node1"} 1.9e+09
+`
+	if err := testutil.CollectAndCompare(e, strings.NewReader(expected),
+		"kubekey_certificate_expiration_timestamp_seconds",
+		"kubekey_ca_certificate_expiration_timestamp_seconds",
+		"kubekey_certificate_collection_success",
+	); err != nil {
+		t.Error(err)
+	}
+
+	if n := testutil.CollectAndCount(e, "kubekey_certificate_expiration_days"); n != 1 {
+		t.Errorf("expected 1 kubekey_certificate_expiration_days metric, got %d", n)
+	}
+}
@@ -17,16 +17,20 @@
 package certs
 
 import (
+	"fmt"
 	"path/filepath"
+	"time"
 
 	versionutil "k8s.io/apimachinery/pkg/util/version"
 
+	"github.com/kubesphere/kubekey/pkg/bootstrap/os"
 	"github.com/kubesphere/kubekey/pkg/certs/templates"
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/action"
 	"github.com/kubesphere/kubekey/pkg/core/prepare"
 	"github.com/kubesphere/kubekey/pkg/core/task"
 	"github.com/kubesphere/kubekey/pkg/core/util"
+	"github.com/kubesphere/kubekey/pkg/etcd"
 	"github.com/kubesphere/kubekey/pkg/kubernetes"
 )
 
@@ -46,8 +50,18 @@ func (c *CheckCertsModule) Init() {
 		Parallel: true,
 	}
 
+	checkETCD := &task.RemoteTask{
+		Name:     "CheckETCDCerts",
+		Desc:     "Check etcd certs",
+		Hosts:    c.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(os.EtcdTypeIsKubeKey),
+		Action:   new(ListETCDCerts),
+		Parallel: true,
+	}
+
 	c.Tasks = []task.Interface{
 		check,
+		checkETCD,
 	}
 }
 
@@ -70,6 +84,27 @@ func (p *PrintClusterCertsModule) Init() {
 	}
 }
 
+// CollectClusterCertsModule stores the certs listed by CheckCertsModule into ClusterCerts.
+type CollectClusterCertsModule struct {
+	common.KubeModule
+	ClusterCerts *ClusterCerts
+}
+
+func (c *CollectClusterCertsModule) Init() {
+	c.Name = "CollectClusterCertsModule"
+	c.Desc = "Collect cluster certs"
+
+	collect := &task.LocalTask{
+		Name:   "CollectClusterCerts",
+		Desc:   "Collect cluster certs",
+		Action: &CollectCerts{ClusterCerts: c.ClusterCerts},
+	}
+
+	c.Tasks = []task.Interface{
+		collect,
+	}
+}
+
 type RenewCertsModule struct {
 	common.KubeModule
 }
@@ -117,14 +152,54 @@ func (r *RenewCertsModule) Init() {
 		Retry:    3,
 	}
 
+	kubeletClientCert := &task.RemoteTask{
+		Name:     "CheckKubeletClientCert",
+		Desc:     "Check kubelet client cert rotation",
+		Hosts:    r.Runtime.GetHostsByRole(common.K8s),
+		Action:   new(KubeletClientCert),
+		Parallel: true,
+	}
+
 	r.Tasks = []task.Interface{
 		renew,
+		kubeletClientCert,
 		copyKubeConfig,
 		fetchKubeConfig,
 		syncKubeConfig,
 	}
 }
 
+type VerifyRenewedCertsModule struct {
+	common.KubeModule
+}
+
+func (v *VerifyRenewedCertsModule) Init() {
+	v.Name = "VerifyRenewedCertsModule"
+	v.Desc = "Verify renewed certs are served"
+
+	hosts := v.Runtime.GetHostsByRole(common.Master)
+	for _, host := range v.Runtime.GetHostsByRole(common.ETCD) {
+		if !host.IsRole(common.Master) {
+			hosts = append(hosts, host)
+		}
+	}
+
+	verify := &task.RemoteTask{
+		Name:     "VerifyRenewedCerts",
+		Desc:     "Verify the endpoints serve the renewed certs",
+		Hosts:    hosts,
+		Action:   new(VerifyRenewedCerts),
+		Parallel: true,
+		// The control-plane components take a while to be recreated after the renewal.
+		Retry: 12,
+		Delay: 10 * time.Second,
+	}
+
+	v.Tasks = []task.Interface{
+		verify,
+	}
+}
+
 type AutoRenewCertsModule struct {
 	common.KubeModule
 	Skip bool
@@ -212,3 +287,273 @@ func (u *UninstallAutoRenewCertsModule) Init() {
 		uninstall,
 	}
 }
+
+type CARotationStateModule struct {
+	common.KubeModule
+}
+
+func (c *CARotationStateModule) Init() {
+	c.Name = "CARotationStateModule"
+	c.Desc = "Get the state of CA rotation"
+
+	getState := &task.RemoteTask{
+		Name:     "GetCARotationState",
+		Desc:     "Fetch the files of unfinished CA rotation",
+		Hosts:    c.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(GetCARotationState),
+		Parallel: true,
+	}
+
+	c.Tasks = []task.Interface{
+		getState,
+	}
+}
+
+type PrepareCARotationModule struct {
+	common.KubeModule
+}
+
+func (p *PrepareCARotationModule) Init() {
+	p.Name = "PrepareCARotationModule"
+	p.Desc = "Generate new CAs"
+
+	fetchOldCAs := &task.RemoteTask{
+		Name:     "FetchOldCAs",
+		Desc:     "Fetch the CAs in use",
+		Hosts:    p.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(FetchOldCAs),
+		Parallel: true,
+	}
+
+	generateNewCAs := &task.LocalTask{
+		Name:   "GenerateNewCAs",
+		Desc:   "Generate new CAs",
+		Action: new(GenerateNewCAs),
+	}
+
+	upload := &task.RemoteTask{
+		Name:     "UploadCARotationFiles",
+		Desc:     "Upload the old and new CAs to the first master",
+		Hosts:    p.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(UploadCARotationFiles),
+		Parallel: true,
+	}
+
+	p.Tasks = []task.Interface{
+		fetchOldCAs,
+		generateNewCAs,
+		upload,
+	}
+}
+
+type DistributeCABundleModule struct {
+	common.KubeModule
+	Phase string
+}
+
+func (d *DistributeCABundleModule) Init() {
+	d.Name = "DistributeCABundleModule"
+	d.Desc = fmt.Sprintf("Distribute the CA bundles of the %s phase", d.Phase)
+
+	hosts := d.Runtime.GetHostsByRole(common.K8s)
+	for _, host := range d.Runtime.GetHostsByRole(common.ETCD) {
+		if !host.IsRole(common.K8s) {
+			hosts = append(hosts, host)
+		}
+	}
+
+	generate := &task.LocalTask{
+		Name:   "GenerateCABundles",
+		Desc:   "Generate CA bundles",
+		Action: &GenerateCABundles{Phase: d.Phase},
+	}
+
+	distribute := &task.RemoteTask{
+		Name:     "DistributeCABundle",
+		Desc:     "Distribute CA bundles",
+		Hosts:    hosts,
+		Action:   &DistributeCABundle{Phase: d.Phase},
+		Parallel: true,
+		Retry:    2,
+	}
+
+	updateKubeletClientCA := &task.RemoteTask{
+		Name:     "UpdateKubeletClientCA",
+		Desc:     "Update the client CA of kubelet",
+		Hosts:    d.Runtime.GetHostsByRole(common.K8s),
+		Action:   &UpdateKubeletClientCA{Phase: d.Phase},
+		Parallel: true,
+		Retry:    2,
+	}
+
+	updateKubeConfig := &task.RemoteTask{
+		Name:     "UpdateKubeConfigCA",
+		Desc:     "Update the CA of kube config files",
+		Hosts:    d.Runtime.GetHostsByRole(common.K8s),
+		Action:   &UpdateKubeConfigCA{Phase: d.Phase},
+		Parallel: true,
+		Retry:    2,
+	}
+
+	updateClusterInfo := &task.RemoteTask{
+		Name:     "UpdateClusterInfoCA",
+		Desc:     "Update the CA of the cluster-info ConfigMap",
+		Hosts:    d.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   &UpdateClusterInfoCA{Phase: d.Phase},
+		Parallel: true,
+		Retry:    2,
+	}
+
+	d.Tasks = []task.Interface{
+		generate,
+		distribute,
+		updateKubeletClientCA,
+		updateKubeConfig,
+		updateClusterInfo,
+	}
+}
+
+type RestartForCARotationModule struct {
+	common.KubeModule
+	Phase string
+}
+
+func (r *RestartForCARotationModule) Init() {
+	r.Name = "RestartForCARotationModule"
+	r.Desc = "Restart cluster components to reload CAs"
+
+	restartETCD := &task.RemoteTask{
+		Name:     "RollingRestartETCD",
+		Desc:     "Restart etcd members one by one",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(os.EtcdTypeIsKubeKey),
+		Action:   new(etcd.RestartMember),
+		Parallel: false,
+		Retry:    1,
+	}
+
+	restartControlPlane := &task.RemoteTask{
+		Name:     "RestartControlPlane",
+		Desc:     "Restart control-plane components one by one",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   &RestartControlPlane{Phase: r.Phase},
+		Parallel: false,
+		Retry:    2,
+	}
+
+	restartKubelet := &task.RemoteTask{
+		Name:     "RestartKubelet",
+		Desc:     "Restart kubelet on worker",
+		Hosts:    r.Runtime.GetHostsByRole(common.Worker),
+		Prepare:  new(common.OnlyWorker),
+		Action:   new(RestartKubelet),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	copyKubeConfig := &task.RemoteTask{
+		Name:     "CopyKubeConfig",
+		Desc:     "Copy admin.conf to ~/.kube/config",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(kubernetes.CopyKubeConfigForControlPlane),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	fetchKubeConfig := &task.RemoteTask{
+		Name:     "FetchKubeConfig",
+		Desc:     "Fetch kube config file from control-plane",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(FetchKubeConfig),
+		Parallel: true,
+	}
+
+	syncKubeConfig := &task.RemoteTask{
+		Name:     "SyncKubeConfig",
+		Desc:     "Synchronize kube config to worker",
+		Hosts:    r.Runtime.GetHostsByRole(common.Worker),
+		Prepare:  new(common.OnlyWorker),
+		Action:   new(SyneKubeConfigToWorker),
+		Parallel: true,
+		Retry:    3,
+	}
+
+	r.Tasks = []task.Interface{
+		restartETCD,
+		restartControlPlane,
+		restartKubelet,
+		copyKubeConfig,
+		fetchKubeConfig,
+		syncKubeConfig,
+	}
+
+	if r.Phase == CARotationFinalize {
+		removeCABundle := &task.RemoteTask{
+			Name:     "RemoveCABundle",
+			Desc:     "Remove the bundle of the Kubernetes CAs",
+			Hosts:    r.Runtime.GetHostsByRole(common.K8s),
+			Action:   new(RemoveCABundle),
+			Parallel: true,
+		}
+		r.Tasks = append(r.Tasks, removeCABundle)
+	}
+}
+
+type ResignKubeletCertsModule struct {
+	common.KubeModule
+}
+
+func (r *ResignKubeletCertsModule) Init() {
+	r.Name = "ResignKubeletCertsModule"
+	r.Desc = "Resign kubelet client certs by the new CA"
+
+	kubeletClientCert := &task.RemoteTask{
+		Name:     "CheckKubeletClientCert",
+		Desc:     "Check kubelet client cert rotation",
+		Hosts:    r.Runtime.GetHostsByRole(common.K8s),
+		Action:   new(KubeletClientCert),
+		Parallel: true,
+	}
+
+	resign := &task.RemoteTask{
+		Name:     "ResignKubeletClientCert",
+		Desc:     "Resign kubelet client cert",
+		Hosts:    r.Runtime.GetHostsByRole(common.K8s),
+		Action:   new(ResignKubeletClientCert),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	r.Tasks = []task.Interface{
+		kubeletClientCert,
+		resign,
+	}
+}
+
+type MarkCARotationPhaseModule struct {
+	common.KubeModule
+	Phase string
+}
+
+func (m *MarkCARotationPhaseModule) Init() {
+	m.Name = "MarkCARotationPhaseModule"
+	m.Desc = fmt.Sprintf("Record the %s phase of CA rotation", m.Phase)
+
+	mark := &task.RemoteTask{
+		Name:     "MarkCARotationPhase",
+		Desc:     "Record the finished phase on the first master",
+		Hosts:    m.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   &MarkCARotationPhase{Phase: m.Phase},
+		Parallel: true,
+	}
+
+	m.Tasks = []task.Interface{
+		mark,
+	}
+}
@@ -0,0 +1,559 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/core/util"
+	"github.com/kubesphere/kubekey/pkg/kubernetes"
+	"github.com/kubesphere/kubekey/pkg/utils/certs"
+)
+
+const (
+	CARotationPrepare  = "prepare"
+	CARotationTrust    = "trust"
+	CARotationResign   = "resign"
+	CARotationFinalize = "finalize"
+
+	// CARotationDir keeps the old and the new CAs and the last finished phase on the first master,
+	// so that an interrupted rotation can be resumed from any machine.
+	CARotationDir       = "/etc/kubernetes/pki/ca-rotation"
+	CARotationPhaseFile = "phase"
+
+	// KubeCABundle is the bundle of the old and the new Kubernetes CAs during the rotation. It is trusted by
+	// kube-apiserver, kube-controller-manager and the kubelets instead of ca.crt, which must only have the CA
+	// that kube-controller-manager signs with.
+	KubeCABundle = "/etc/kubernetes/pki/ca-bundle.crt"
+
+	kubeletClientCurrent = "/var/lib/kubelet/pki/kubelet-client-current.pem"
+	kubeletConfigFile    = "/var/lib/kubelet/config.yaml"
+)
+
+// CARotationPhases are the phases of a CA rotation in order.
+var CARotationPhases = []string{CARotationPrepare, CARotationTrust, CARotationResign, CARotationFinalize}
+
+// rotatedCA is a CA, or the service-account key pair, replaced by the CA rotation.
+type rotatedCA struct {
+	// Name is the base name of the local files, e.g. ca.pem and ca-key.pem.
+	Name     string
+	CertPath string
+	KeyPath  string
+	// BundlePath is where the CA bundle is written. If it is empty, the bundle is written to CertPath.
+	BundlePath string
+}
+
+func rotatedCAs(kubeConf *common.KubeConf, withSA bool) []rotatedCA {
+	cas := []rotatedCA{
+		{Name: "ca", CertPath: filepath.Join(common.KubeCertDir, "ca.crt"), KeyPath: filepath.Join(common.KubeCertDir, "ca.key"), BundlePath: KubeCABundle},
+		{Name: "front-proxy-ca", CertPath: filepath.Join(common.KubeCertDir, "front-proxy-ca.crt"), KeyPath: filepath.Join(common.KubeCertDir, "front-proxy-ca.key")},
+	}
+	switch kubeConf.Cluster.Etcd.Type {
+	case kubekeyapiv1alpha2.KubeKey:
+		cas = append(cas, rotatedCA{Name: "etcd-ca", CertPath: filepath.Join(common.ETCDCertDir, "ca.pem"), KeyPath: filepath.Join(common.ETCDCertDir, "ca-key.pem")})
+	case kubekeyapiv1alpha2.Kubeadm:
+		cas = append(cas, rotatedCA{Name: "etcd-ca", CertPath: filepath.Join(common.KubeCertDir, "etcd", "ca.crt"), KeyPath: filepath.Join(common.KubeCertDir, "etcd", "ca.key")})
+	}
+	if withSA {
+		cas = append(cas, rotatedCA{Name: "sa", CertPath: filepath.Join(common.KubeCertDir, "sa.pub"), KeyPath: filepath.Join(common.KubeCertDir, "sa.key")})
+	}
+	return cas
+}
+
+// CARotationLocalDir returns the local dir of the CA rotation files.
+func CARotationLocalDir(runtime connector.Runtime) string {
+	return filepath.Join(runtime.GetWorkDir(), "pki", "ca-rotation")
+}
+
+// rotateSAKey returns true if the service-account key pair is rotated, which is decided when the rotation is prepared.
+func rotateSAKey(kubeConf *common.KubeConf, runtime connector.Runtime) bool {
+	return kubeConf.Arg.RotateSAKey || util.IsExist(filepath.Join(CARotationLocalDir(runtime), "new", "sa.pem"))
+}
+
+// caFilesForPhase returns the signing cert, the cert bundle and the signing key of a CA in the phase.
+// In the trust phase, the new CA is trusted along with the old one, which still signs.
+// In the resign phase, the new CA signs and the old one is still trusted until all the certs are resigned.
+// In the finalize phase, the old CA is no longer trusted.
+func caFilesForPhase(phase string, oldCert, newCert, oldKey, newKey []byte) ([]byte, []byte, []byte, error) {
+	bundle := func(certs ...[]byte) []byte {
+		var b bytes.Buffer
+		for _, c := range certs {
+			b.Write(bytes.TrimSpace(c))
+			b.WriteString("\n")
+		}
+		return b.Bytes()
+	}
+
+	switch phase {
+	case CARotationTrust:
+		return bundle(oldCert), bundle(oldCert, newCert), oldKey, nil
+	case CARotationResign:
+		return bundle(newCert), bundle(newCert, oldCert), newKey, nil
+	case CARotationFinalize:
+		return bundle(newCert), bundle(newCert), newKey, nil
+	default:
+		return nil, nil, nil, errors.Errorf("unknown CA rotation phase %s", phase)
+	}
+}
+
+// caBundlePath returns the path of the local CA bundle of the CA in the dir.
+func caBundlePath(dir, name string) string {
+	return filepath.Join(dir, name+"-bundle.pem")
+}
+
+// trustedKubeCA returns the file of the Kubernetes CAs trusted by the components in the phase.
+func trustedKubeCA(phase string) string {
+	if phase == CARotationFinalize {
+		return filepath.Join(common.KubeCertDir, "ca.crt")
+	}
+	return KubeCABundle
+}
+
+type GetCARotationState struct {
+	common.KubeAction
+}
+
+// Execute fetches the files of an unfinished CA rotation from the first master.
+func (g *GetCARotationState) Execute(runtime connector.Runtime) error {
+	localDir := CARotationLocalDir(runtime)
+	// The local files may be left by a finished rotation, only the files on the master are trusted.
+	if err := os.RemoveAll(localDir); err != nil {
+		return errors.Wrapf(err, "failed to clean %s", localDir)
+	}
+
+	out, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("find %s -type f 2>/dev/null || true", CARotationDir), false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "find the CA rotation files failed")
+	}
+	for _, file := range strings.Fields(out) {
+		rel := strings.TrimPrefix(file, CARotationDir+"/")
+		if err := runtime.GetRunner().Fetch(filepath.Join(localDir, rel), file); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "fetch %s failed", file)
+		}
+	}
+	return nil
+}
+
+type FetchOldCAs struct {
+	common.KubeAction
+}
+
+func (f *FetchOldCAs) Execute(runtime connector.Runtime) error {
+	oldDir := filepath.Join(CARotationLocalDir(runtime), "old")
+	for _, ca := range rotatedCAs(f.KubeConf, f.KubeConf.Arg.RotateSAKey) {
+		certPath, keyPath := certs.PathsForCertAndKey(oldDir, ca.Name)
+		if err := runtime.GetRunner().Fetch(certPath, ca.CertPath); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "fetch %s failed", ca.CertPath)
+		}
+		if err := runtime.GetRunner().Fetch(keyPath, ca.KeyPath); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "fetch %s failed, the CA key is required to rotate the CA", ca.KeyPath)
+		}
+		if ca.Name == "sa" {
+			continue
+		}
+
+		caCerts, err := certutil.CertsFromFile(certPath)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load %s", ca.CertPath)
+		}
+		if len(caCerts) > 1 {
+			return errors.Errorf("%s is a CA bundle, the last CA rotation may be unfinished", ca.CertPath)
+		}
+	}
+	return nil
+}
+
+type GenerateNewCAs struct {
+	common.KubeAction
+}
+
+func (g *GenerateNewCAs) Execute(runtime connector.Runtime) error {
+	localDir := CARotationLocalDir(runtime)
+	for _, ca := range rotatedCAs(g.KubeConf, g.KubeConf.Arg.RotateSAKey) {
+		newDir := filepath.Join(localDir, "new")
+		if ca.Name == "sa" {
+			key, err := certs.NewPrivateKey(x509.RSA)
+			if err != nil {
+				return errors.Wrap(err, "failed to generate the service-account key")
+			}
+			der, err := x509.MarshalPKIXPublicKey(key.Public())
+			if err != nil {
+				return errors.Wrap(err, "failed to encode the service-account public key")
+			}
+			pub := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+			if err := certs.WriteKey(newDir, ca.Name, key); err != nil {
+				return err
+			}
+			certPath, _ := certs.PathsForCertAndKey(newDir, ca.Name)
+			if err := ioutil.WriteFile(certPath, pub, 0600); err != nil {
+				return errors.Wrapf(err, "failed to write %s", certPath)
+			}
+			continue
+		}
+
+		// The new CA keeps the subject of the old one.
+		oldCert, err := certs.TryLoadCertFromDisk(filepath.Join(localDir, "old"), ca.Name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load the old %s", ca.Name)
+		}
+		cert, key, err := certs.NewCertificateAuthority(&certs.CertConfig{
+			Config: certutil.Config{
+				CommonName:   oldCert.Subject.CommonName,
+				Organization: oldCert.Subject.Organization,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if err := certs.WriteCertAndKey(newDir, ca.Name, cert, key); err != nil {
+			return errors.Wrapf(err, "failed to write the new %s", ca.Name)
+		}
+		fmt.Printf("[certs] Generated the new %s certificate authority\n", ca.Name)
+	}
+	return nil
+}
+
+type UploadCARotationFiles struct {
+	common.KubeAction
+}
+
+func (u *UploadCARotationFiles) Execute(runtime connector.Runtime) error {
+	localDir := CARotationLocalDir(runtime)
+	for _, dir := range []string{"old", "new"} {
+		files, err := ioutil.ReadDir(filepath.Join(localDir, dir))
+		if err != nil {
+			return errors.Wrapf(err, "failed to read dir %s", filepath.Join(localDir, dir))
+		}
+		for _, f := range files {
+			if err := runtime.GetRunner().SudoScp(filepath.Join(localDir, dir, f.Name()), filepath.Join(CARotationDir, dir, f.Name())); err != nil {
+				return errors.Wrap(errors.WithStack(err), "upload the CA rotation files failed")
+			}
+		}
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod -R go-rwx %s", CARotationDir), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "chmod the CA rotation files failed")
+	}
+	return nil
+}
+
+type MarkCARotationPhase struct {
+	common.KubeAction
+	Phase string
+}
+
+// Execute records the finished phase on the first master. The rotation files are removed once the rotation is finished.
+func (m *MarkCARotationPhase) Execute(runtime connector.Runtime) error {
+	cmd := fmt.Sprintf("echo %s > %s", m.Phase, filepath.Join(CARotationDir, CARotationPhaseFile))
+	if m.Phase == CARotationFinalize {
+		cmd = fmt.Sprintf("rm -rf %s", CARotationDir)
+	}
+	if _, err := runtime.GetRunner().SudoCmd(cmd, false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "record the CA rotation phase %s failed", m.Phase)
+	}
+	if m.Phase == CARotationFinalize {
+		if err := os.RemoveAll(CARotationLocalDir(runtime)); err != nil {
+			return errors.Wrap(err, "failed to remove the local CA rotation files")
+		}
+	}
+	logger.Log.Infof("CA rotation phase %s is finished", m.Phase)
+	return nil
+}
+
+type GenerateCABundles struct {
+	common.KubeAction
+	Phase string
+}
+
+func (g *GenerateCABundles) Execute(runtime connector.Runtime) error {
+	localDir := CARotationLocalDir(runtime)
+	for _, ca := range rotatedCAs(g.KubeConf, rotateSAKey(g.KubeConf, runtime)) {
+		oldCertPath, oldKeyPath := certs.PathsForCertAndKey(filepath.Join(localDir, "old"), ca.Name)
+		newCertPath, newKeyPath := certs.PathsForCertAndKey(filepath.Join(localDir, "new"), ca.Name)
+
+		var files [4][]byte
+		for i, path := range []string{oldCertPath, newCertPath, oldKeyPath, newKeyPath} {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read %s", path)
+			}
+			files[i] = data
+		}
+
+		cert, bundle, key, err := caFilesForPhase(g.Phase, files[0], files[1], files[2], files[3])
+		if err != nil {
+			return err
+		}
+		phaseDir := filepath.Join(localDir, g.Phase)
+		if err := util.CreateDir(phaseDir); err != nil {
+			return errors.Wrapf(err, "failed to create dir %s", phaseDir)
+		}
+		certPath, keyPath := certs.PathsForCertAndKey(phaseDir, ca.Name)
+		for path, data := range map[string][]byte{certPath: cert, caBundlePath(phaseDir, ca.Name): bundle, keyPath: key} {
+			if err := ioutil.WriteFile(path, data, 0600); err != nil {
+				return errors.Wrapf(err, "failed to write %s", path)
+			}
+		}
+	}
+	return nil
+}
+
+type DistributeCABundle struct {
+	common.KubeAction
+	Phase string
+}
+
+// Execute replaces the CA files which exist on the host with the ones of the phase.
+// A CA with its own bundle file keeps only the signing CA in its cert file, and the bundle is written next to it.
+func (d *DistributeCABundle) Execute(runtime connector.Runtime) error {
+	phaseDir := filepath.Join(CARotationLocalDir(runtime), d.Phase)
+	for _, ca := range rotatedCAs(d.KubeConf, rotateSAKey(d.KubeConf, runtime)) {
+		certPath, keyPath := certs.PathsForCertAndKey(phaseDir, ca.Name)
+		bundlePath := caBundlePath(phaseDir, ca.Name)
+
+		certExist, err := runtime.GetRunner().FileExist(ca.CertPath)
+		if err != nil {
+			return err
+		}
+		if certExist {
+			files := map[string]string{bundlePath: ca.CertPath}
+			if ca.BundlePath != "" {
+				files = map[string]string{certPath: ca.CertPath, bundlePath: ca.BundlePath}
+			}
+			for local, remote := range files {
+				if err := runtime.GetRunner().SudoScp(local, remote); err != nil {
+					return errors.Wrapf(errors.WithStack(err), "scp %s failed", remote)
+				}
+			}
+		}
+
+		keyExist, err := runtime.GetRunner().FileExist(ca.KeyPath)
+		if err != nil {
+			return err
+		}
+		if !keyExist {
+			continue
+		}
+		if err := runtime.GetRunner().SudoScp(keyPath, ca.KeyPath); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "scp %s failed", ca.KeyPath)
+		}
+		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod 600 %s", ca.KeyPath), false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "chmod %s failed", ca.KeyPath)
+		}
+	}
+	return nil
+}
+
+type UpdateKubeletClientCA struct {
+	common.KubeAction
+	Phase string
+}
+
+// Execute points the clientCAFile of the kubelet to the Kubernetes CAs trusted in the phase, which is loaded when
+// the kubelet is restarted.
+func (u *UpdateKubeletClientCA) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("sed -i 's#^clientCAFile:.*#clientCAFile: %s#' %s",
+		trustedKubeCA(u.Phase), kubeletConfigFile), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "update the clientCAFile of %s failed", kubeletConfigFile)
+	}
+	return nil
+}
+
+type UpdateKubeConfigCA struct {
+	common.KubeAction
+	Phase string
+}
+
+// Execute rewrites the certificate-authority-data of the kubeconfig files on the host with the CA bundle of the phase.
+func (u *UpdateKubeConfigCA) Execute(runtime connector.Runtime) error {
+	bundlePath := caBundlePath(filepath.Join(CARotationLocalDir(runtime), u.Phase), "ca")
+	bundle, err := ioutil.ReadFile(bundlePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", bundlePath)
+	}
+	caData := base64.StdEncoding.EncodeToString(bundle)
+
+	kubeConfigs := []string{"/root/.kube/config"}
+	for _, name := range []string{"admin.conf", "super-admin.conf", "controller-manager.conf", "scheduler.conf", "kubelet.conf"} {
+		kubeConfigs = append(kubeConfigs, filepath.Join(common.KubeConfigDir, name))
+	}
+	for _, kubeConfig := range kubeConfigs {
+		exist, err := runtime.GetRunner().FileExist(kubeConfig)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			continue
+		}
+		// base64 never contains '#', so it is safe to be the delimiter of sed.
+		if _, err := runtime.GetRunner().SudoCmd(
+			fmt.Sprintf("sed -i 's#certificate-authority-data:.*#certificate-authority-data: %s#' %s", caData, kubeConfig), false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "update the CA of %s failed", kubeConfig)
+		}
+	}
+	return nil
+}
+
+type UpdateClusterInfoCA struct {
+	common.KubeAction
+	Phase string
+}
+
+// Execute rewrites the certificate-authority-data of the kube-public/cluster-info ConfigMap with the CA bundle of the phase,
+// so that the nodes joining during or after the rotation trust the serving certificate of kube-apiserver.
+func (u *UpdateClusterInfoCA) Execute(runtime connector.Runtime) error {
+	bundlePath := caBundlePath(filepath.Join(CARotationLocalDir(runtime), u.Phase), "ca")
+	bundle, err := ioutil.ReadFile(bundlePath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", bundlePath)
+	}
+	caData := base64.StdEncoding.EncodeToString(bundle)
+
+	return kubernetes.PatchClusterInfo(runtime, func(cluster map[string]interface{}) bool {
+		if cluster["certificate-authority-data"] == caData {
+			return false
+		}
+		cluster["certificate-authority-data"] = caData
+		return true
+	})
+}
+
+type RestartControlPlane struct {
+	common.KubeAction
+	Phase string
+}
+
+// Execute points the client CA of kube-apiserver and kube-controller-manager, and the root CA published by
+// kube-controller-manager in the kube-root-ca.crt ConfigMaps, to the Kubernetes CAs trusted in the phase.
+// Then it restarts the control-plane components and waits for them to be healthy.
+func (r *RestartControlPlane) Execute(runtime connector.Runtime) error {
+	manifests := []string{
+		filepath.Join(common.KubeManifestDir, "kube-apiserver.yaml"),
+		filepath.Join(common.KubeManifestDir, "kube-controller-manager.yaml"),
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("sed -i -E 's#(--(client|root)-ca-file=).*#\\1%s#' %s",
+		trustedKubeCA(r.Phase), strings.Join(manifests, " ")), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "update the CA files of the control-plane manifests failed")
+	}
+
+	restartList := restartControlPlaneCmds(r.KubeConf.Cluster.Kubernetes.ContainerManager,
+		r.KubeConf.Cluster.Etcd.Type == kubekeyapiv1alpha2.Kubeadm)
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(restartList, " && "), false); err != nil {
+		return errors.Wrap(err, "kube-apiserver, kube-schedule, kube-controller-manager or kubelet restart failed")
+	}
+	return kubernetes.WaitForControlPlane(runtime)
+}
+
+type RemoveCABundle struct {
+	common.KubeAction
+}
+
+// Execute removes the bundle of the Kubernetes CAs, which is no longer used after the rotation.
+func (r *RemoveCABundle) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -f %s", KubeCABundle), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "remove %s failed", KubeCABundle)
+	}
+	return nil
+}
+
+type RestartKubelet struct {
+	common.KubeAction
+}
+
+func (r *RestartKubelet) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("systemctl restart kubelet", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restart kubelet failed")
+	}
+	return nil
+}
+
+type ResignKubeletClientCert struct {
+	common.KubeAction
+}
+
+// Execute signs a new kubelet client cert by the new CA, with the subject of the current one.
+// The kubelet rotates it later by itself, because the controller-manager signs with the new CA as well.
+func (r *ResignKubeletClientCert) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	localDir := CARotationLocalDir(runtime)
+
+	current, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("cat %s", kubeletClientCurrent), false)
+	if err != nil {
+		logger.Log.Messagef(host.GetName(), "%s is not found, skip resigning the kubelet client cert", kubeletClientCurrent)
+		return nil
+	}
+	currentCerts, err := certutil.ParseCertsPEM([]byte(current))
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse %s", kubeletClientCurrent)
+	}
+
+	caCert, caKey, err := certs.LoadCertificateAuthority(filepath.Join(localDir, "new"), "ca")
+	if err != nil {
+		return err
+	}
+	notAfter := time.Now().Add(365 * 24 * time.Hour).UTC()
+	cert, key, err := certs.NewCertAndKey(caCert, caKey, &certs.CertConfig{
+		Config: certutil.Config{
+			CommonName:   currentCerts[0].Subject.CommonName,
+			Organization: currentCerts[0].Subject.Organization,
+			Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+		NotAfter: &notAfter,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to sign the kubelet client cert of %s", host.GetName())
+	}
+	keyPEM, err := keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal private key to PEM")
+	}
+
+	name := fmt.Sprintf("kubelet-client-%s.pem", time.Now().Format("2006-01-02-15-04-05"))
+	localFile := filepath.Join(localDir, "kubelet", host.GetName(), name)
+	if err := util.CreateDir(filepath.Dir(localFile)); err != nil {
+		return errors.Wrapf(err, "failed to create dir %s", filepath.Dir(localFile))
+	}
+	if err := ioutil.WriteFile(localFile, append(certs.EncodeCertPEM(cert), keyPEM...), 0600); err != nil {
+		return errors.Wrapf(err, "failed to write %s", localFile)
+	}
+
+	remoteFile := filepath.Join(filepath.Dir(kubeletClientCurrent), name)
+	if err := runtime.GetRunner().SudoScp(localFile, remoteFile); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "scp %s failed", remoteFile)
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod 600 %s && ln -sf %s %s && systemctl restart kubelet",
+		remoteFile, remoteFile, kubeletClientCurrent), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "update the kubelet client cert failed")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"testing"
+)
+
+func TestCAFilesForPhase(t *testing.T) {
+	oldCert, newCert := []byte("old-cert\n"), []byte("new-cert")
+	oldKey, newKey := []byte("old-key"), []byte("new-key")
+
+	tests := []struct {
+		phase      string
+		wantCert   string
+		wantBundle string
+		wantKey    string
+		wantErr    bool
+	}{
+		{phase: CARotationTrust, wantCert: "old-cert\n", wantBundle: "old-cert\nnew-cert\n", wantKey: "old-key"},
+		{phase: CARotationResign, wantCert: "new-cert\n", wantBundle: "new-cert\nold-cert\n", wantKey: "new-key"},
+		{phase: CARotationFinalize, wantCert: "new-cert\n", wantBundle: "new-cert\n", wantKey: "new-key"},
+		{phase: CARotationPrepare, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phase, func(t *testing.T) {
+			cert, bundle, key, err := caFilesForPhase(tt.phase, oldCert, newCert, oldKey, newKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("caFilesForPhase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(cert) != tt.wantCert || string(bundle) != tt.wantBundle || string(key) != tt.wantKey {
+				t.Errorf("caFilesForPhase() = %q, %q, %q, want %q, %q, %q",
+					cert, bundle, key, tt.wantCert, tt.wantBundle, tt.wantKey)
+			}
+		})
+	}
+}
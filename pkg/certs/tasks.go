@@ -18,10 +18,14 @@ package certs
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/pkg/certs/templates"
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/kubernetes"
 	"github.com/kubesphere/kubekey/pkg/utils"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -31,24 +35,39 @@ import (
 	certutil "k8s.io/client-go/util/cert"
 	"os"
 	"path/filepath"
+	"sigs.k8s.io/yaml"
 	"strings"
 	"text/tabwriter"
 	"time"
 )
 
+// The formats of the certs output, which is printed as a table by default.
+const (
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
 type Certificate struct {
-	Name          string
-	Expires       string
-	Residual      string
-	AuthorityName string
-	NodeName      string
+	Name          string    `json:"name"`
+	Expires       string    `json:"expires"`
+	Residual      string    `json:"residualTime"`
+	AuthorityName string    `json:"certificateAuthority,omitempty"`
+	NodeName      string    `json:"node"`
+	NotAfter      time.Time `json:"notAfter"`
 }
 
 type CaCertificate struct {
-	AuthorityName string
-	Expires       string
-	Residual      string
-	NodeName      string
+	AuthorityName string    `json:"name"`
+	Expires       string    `json:"expires"`
+	Residual      string    `json:"residualTime"`
+	NodeName      string    `json:"node"`
+	NotAfter      time.Time `json:"notAfter"`
+}
+
+// ClusterCerts is the certs of all the control-plane and etcd nodes.
+type ClusterCerts struct {
+	Certificates           []*Certificate   `json:"certificates"`
+	CertificateAuthorities []*CaCertificate `json:"certificateAuthorities"`
 }
 
 var (
@@ -66,6 +85,16 @@ var (
 		"controller-manager.conf",
 		"scheduler.conf",
 	}
+	// stackedEtcdCertificateList is the etcd certs managed by kubeadm when etcd is deployed as static pods.
+	stackedEtcdCertificateList = []string{
+		"apiserver-etcd-client.crt",
+		"etcd/server.crt",
+		"etcd/peer.crt",
+		"etcd/healthcheck-client.crt",
+	}
+	stackedEtcdCaCertificateList = []string{
+		"etcd/ca.crt",
+	}
 )
 
 type ListClusterCerts struct {
@@ -78,7 +107,13 @@ func (l *ListClusterCerts) Execute(runtime connector.Runtime) error {
 	certificates := make([]*Certificate, 0)
 	caCertificates := make([]*CaCertificate, 0)
 
-	for _, certFileName := range certificateList {
+	certList, caCertList := certificateList, caCertificateList
+	if l.KubeConf.Cluster.Etcd.Type == kubekeyapiv1alpha2.Kubeadm {
+		certList = append(certList, stackedEtcdCertificateList...)
+		caCertList = append(caCertList, stackedEtcdCaCertificateList...)
+	}
+
+	for _, certFileName := range certList {
 		certPath := filepath.Join(common.KubeCertDir, certFileName)
 		certContext, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("cat %s", certPath), false)
 		if err != nil {
@@ -114,7 +149,7 @@ func (l *ListClusterCerts) Execute(runtime connector.Runtime) error {
 		}
 	}
 
-	for _, caCertFileName := range caCertificateList {
+	for _, caCertFileName := range caCertList {
 		certPath := filepath.Join(common.KubeCertDir, caCertFileName)
 		caCertContext, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("cat %s", certPath), false)
 		if err != nil {
@@ -132,19 +167,66 @@ func (l *ListClusterCerts) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+type ListETCDCerts struct {
+	common.KubeAction
+}
+
+// Execute lists the certs of the etcd deployed by KubeKey, which are named as etcd/<file> in the list.
+func (l *ListETCDCerts) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+
+	certificates := make([]*Certificate, 0)
+	caCertificates := make([]*CaCertificate, 0)
+
+	output, err := runtime.GetRunner().SudoCmd("ls /etc/ssl/etcd/ssl/ | grep .pem", false)
+	if err != nil {
+		return errors.Wrap(err, "failed to find etcd certificate files")
+	}
+	for _, certFileName := range strings.Split(output, "\r\n") {
+		if certFileName == "" || strings.HasSuffix(certFileName, "-key.pem") {
+			continue
+		}
+		certContext, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("cat %s", filepath.Join("/etc/ssl/etcd/ssl", certFileName)), false)
+		if err != nil {
+			return errors.Wrap(err, "get etcd certs failed")
+		}
+
+		name := filepath.Join("etcd", certFileName)
+		if certFileName == "ca.pem" {
+			cert, err := getCaCertInfo(certContext, name, host.GetName())
+			if err != nil {
+				return err
+			}
+			caCertificates = append(caCertificates, cert)
+			continue
+		}
+		cert, err := getCertInfo(certContext, name, host.GetName())
+		if err != nil {
+			return err
+		}
+		certificates = append(certificates, cert)
+	}
+
+	host.GetCache().Set(common.ETCDCertificate, certificates)
+	host.GetCache().Set(common.ETCDCaCertificate, caCertificates)
+	return nil
+}
+
 func getCertInfo(certContext, certFileName, nodeName string) (*Certificate, error) {
 	certs, err1 := certutil.ParseCertsPEM([]byte(certContext))
 	if err1 != nil {
 		return nil, errors.Wrap(err1, "Failed to get cluster certs")
 	}
 	var authorityName string
-	switch certFileName {
-	case "apiserver.crt":
+	switch {
+	case certFileName == "apiserver.crt":
 		authorityName = "ca"
-	case "apiserver-kubelet-client.crt":
+	case certFileName == "apiserver-kubelet-client.crt":
 		authorityName = "ca"
-	case "front-proxy-client.crt":
+	case certFileName == "front-proxy-client.crt":
 		authorityName = "front-proxy-ca"
+	case certFileName == "apiserver-etcd-client.crt", strings.HasPrefix(certFileName, "etcd/"):
+		authorityName = "etcd-ca"
 	default:
 		authorityName = ""
 	}
@@ -154,6 +236,7 @@ func getCertInfo(certContext, certFileName, nodeName string) (*Certificate, erro
 		Residual:      ResidualTime(certs[0].NotAfter),
 		AuthorityName: authorityName,
 		NodeName:      nodeName,
+		NotAfter:      certs[0].NotAfter,
 	}
 	return &cert, nil
 }
@@ -168,6 +251,7 @@ func getCaCertInfo(certContext, certFileName, nodeName string) (*CaCertificate,
 		Expires:       certs[0].NotAfter.Format("Jan 02, 2006 15:04 MST"),
 		Residual:      ResidualTime(certs[0].NotAfter),
 		NodeName:      nodeName,
+		NotAfter:      certs[0].NotAfter,
 	}
 	return &cert1, nil
 }
@@ -190,32 +274,67 @@ func ResidualTime(t time.Time) string {
 	return fmt.Sprintf("%dy", int(d.Hours()/24/365))
 }
 
-type DisplayForm struct {
-	common.KubeAction
-}
-
-func (d *DisplayForm) Execute(runtime connector.Runtime) error {
-	certificates := make([]*Certificate, 0)
-	caCertificates := make([]*CaCertificate, 0)
+// CollectClusterCerts collects the certs listed on all the control-plane and etcd nodes.
+func CollectClusterCerts(runtime connector.Runtime) (*ClusterCerts, error) {
+	clusterCerts := &ClusterCerts{
+		Certificates:           make([]*Certificate, 0),
+		CertificateAuthorities: make([]*CaCertificate, 0),
+	}
 
 	for _, host := range runtime.GetHostsByRole(common.Master) {
 		certs, ok := host.GetCache().Get(common.Certificate)
 		if !ok {
-			return errors.New("get certificate failed by pipeline cache")
+			return nil, errors.New("get certificate failed by pipeline cache")
 		}
 		ca, ok := host.GetCache().Get(common.CaCertificate)
 		if !ok {
-			return errors.New("get ca certificate failed by pipeline cache")
+			return nil, errors.New("get ca certificate failed by pipeline cache")
+		}
+		clusterCerts.Certificates = append(clusterCerts.Certificates, certs.([]*Certificate)...)
+		clusterCerts.CertificateAuthorities = append(clusterCerts.CertificateAuthorities, ca.([]*CaCertificate)...)
+	}
+	for _, host := range runtime.GetHostsByRole(common.ETCD) {
+		// The certs of etcd are listed only if etcd is deployed by KubeKey.
+		if certs, ok := host.GetCache().Get(common.ETCDCertificate); ok {
+			clusterCerts.Certificates = append(clusterCerts.Certificates, certs.([]*Certificate)...)
+		}
+		if ca, ok := host.GetCache().Get(common.ETCDCaCertificate); ok {
+			clusterCerts.CertificateAuthorities = append(clusterCerts.CertificateAuthorities, ca.([]*CaCertificate)...)
+		}
+	}
+	return clusterCerts, nil
+}
+
+type DisplayForm struct {
+	common.KubeAction
+}
+
+func (d *DisplayForm) Execute(runtime connector.Runtime) error {
+	clusterCerts, err := CollectClusterCerts(runtime)
+	if err != nil {
+		return err
+	}
+
+	switch d.KubeConf.Arg.Output {
+	case OutputJSON:
+		data, err := json.MarshalIndent(clusterCerts, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal cluster certs")
+		}
+		fmt.Println(string(data))
+		return nil
+	case OutputYAML:
+		data, err := yaml.Marshal(clusterCerts)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal cluster certs")
 		}
-		hostCertificates := certs.([]*Certificate)
-		hostCaCertificates := ca.([]*CaCertificate)
-		certificates = append(certificates, hostCertificates...)
-		caCertificates = append(caCertificates, hostCaCertificates...)
+		fmt.Print(string(data))
+		return nil
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
 	_, _ = fmt.Fprintln(w, "CERTIFICATE\tEXPIRES\tRESIDUAL TIME\tCERTIFICATE AUTHORITY\tNODE")
-	for _, cert := range certificates {
+	for _, cert := range clusterCerts.Certificates {
 		s := fmt.Sprintf("%s\t%s\t%s\t%s\t%-8v",
 			cert.Name,
 			cert.Expires,
@@ -229,7 +348,7 @@ func (d *DisplayForm) Execute(runtime connector.Runtime) error {
 	}
 	_, _ = fmt.Fprintln(w)
 	_, _ = fmt.Fprintln(w, "CERTIFICATE AUTHORITY\tEXPIRES\tRESIDUAL TIME\tNODE")
-	for _, caCert := range caCertificates {
+	for _, caCert := range clusterCerts.CertificateAuthorities {
 		c := fmt.Sprintf("%s\t%s\t%s\t%-8v",
 			caCert.AuthorityName,
 			caCert.Expires,
@@ -245,34 +364,47 @@ func (d *DisplayForm) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
-type RenewCerts struct {
+type CollectCerts struct {
 	common.KubeAction
+	ClusterCerts *ClusterCerts
 }
 
-func (r *RenewCerts) Execute(runtime connector.Runtime) error {
-	var kubeadmAlphaList = []string{
-		"/usr/local/bin/kubeadm alpha certs renew apiserver",
-		"/usr/local/bin/kubeadm alpha certs renew apiserver-kubelet-client",
-		"/usr/local/bin/kubeadm alpha certs renew front-proxy-client",
-		"/usr/local/bin/kubeadm alpha certs renew admin.conf",
-		"/usr/local/bin/kubeadm alpha certs renew controller-manager.conf",
-		"/usr/local/bin/kubeadm alpha certs renew scheduler.conf",
+// Execute stores the certs listed into ClusterCerts, which is read after the pipeline finishes.
+func (c *CollectCerts) Execute(runtime connector.Runtime) error {
+	clusterCerts, err := CollectClusterCerts(runtime)
+	if err != nil {
+		return err
 	}
+	*c.ClusterCerts = *clusterCerts
+	return nil
+}
 
-	var kubeadmList = []string{
-		"/usr/local/bin/kubeadm certs renew apiserver",
-		"/usr/local/bin/kubeadm certs renew apiserver-kubelet-client",
-		"/usr/local/bin/kubeadm certs renew front-proxy-client",
-		"/usr/local/bin/kubeadm certs renew admin.conf",
-		"/usr/local/bin/kubeadm certs renew controller-manager.conf",
-		"/usr/local/bin/kubeadm certs renew scheduler.conf",
-	}
+type RenewCerts struct {
+	common.KubeAction
+}
 
-	var restartList = []string{
-		"docker ps -af name=k8s_kube-apiserver* -q | xargs --no-run-if-empty docker rm -f",
-		"docker ps -af name=k8s_kube-scheduler* -q | xargs --no-run-if-empty docker rm -f",
-		"docker ps -af name=k8s_kube-controller-manager* -q | xargs --no-run-if-empty docker rm -f",
-		"systemctl restart kubelet",
+func (r *RenewCerts) Execute(runtime connector.Runtime) error {
+	var renewList []string
+	// The apiserver cert supplied by users is reissued by its issuer.
+	if r.KubeConf.Cluster.Kubernetes.PKI.Cert("apiserver") == nil {
+		renewList = append(renewList, "apiserver")
+	}
+	renewList = append(renewList,
+		"apiserver-kubelet-client",
+		"front-proxy-client",
+		"admin.conf",
+		"controller-manager.conf",
+		"scheduler.conf",
+	)
+	// The stacked etcd certs are managed by kubeadm only if etcd is deployed as static pods.
+	stackedEtcd := r.KubeConf.Cluster.Etcd.Type == kubekeyapiv1alpha2.Kubeadm
+	if stackedEtcd {
+		renewList = append(renewList,
+			"etcd-server",
+			"etcd-peer",
+			"etcd-healthcheck-client",
+			"apiserver-etcd-client",
+		)
 	}
 
 	version, err := runtime.GetRunner().SudoCmd("/usr/local/bin/kubeadm version -o short", true)
@@ -283,25 +415,145 @@ func (r *RenewCerts) Execute(runtime connector.Runtime) error {
 	if err != nil {
 		return errors.Wrap(errors.WithStack(err), "parse kubeadm version failed")
 	}
+	kubeadmCerts := "/usr/local/bin/kubeadm certs"
 	if cmp == -1 {
-		_, err := runtime.GetRunner().SudoCmd(strings.Join(kubeadmAlphaList, " && "), false)
+		kubeadmCerts = "/usr/local/bin/kubeadm alpha certs"
+	}
+
+	renewCmds := make([]string, 0, len(renewList))
+	for _, name := range renewList {
+		renewCmds = append(renewCmds, fmt.Sprintf("%s renew %s", kubeadmCerts, name))
+	}
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(renewCmds, " && "), false); err != nil {
+		return errors.Wrap(err, "kubeadm certs renew failed")
+	}
+
+	restartList := restartControlPlaneCmds(r.KubeConf.Cluster.Kubernetes.ContainerManager, stackedEtcd)
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(restartList, " && "), false); err != nil {
+		return errors.Wrap(err, "kube-apiserver, kube-schedule, kube-controller-manager or kubelet restart failed")
+	}
+	return kubernetes.WaitForControlPlane(runtime)
+}
+
+// restartControlPlaneCmds returns the commands to recreate the control-plane static pods, so that they load the renewed certs.
+// The kubelet is restarted at last to reload its kubeconfig.
+func restartControlPlaneCmds(containerManager string, stackedEtcd bool) []string {
+	components := []string{"kube-apiserver", "kube-scheduler", "kube-controller-manager"}
+	if stackedEtcd {
+		components = append(components, "etcd")
+	}
+	return append(kubernetes.RestartControlPlaneCmds(containerManager, components...), "systemctl restart kubelet")
+}
+
+type KubeletClientCert struct {
+	common.KubeAction
+}
+
+// Execute makes sure the kubelet authenticates with its rotated client cert. The kubelet.conf generated by older kubeadm
+// embeds the client cert, which is never rotated and can not be renewed by "kubeadm certs renew".
+func (k *KubeletClientCert) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	kubeletConf := filepath.Join(common.KubeConfigDir, "kubelet.conf")
+	currentPem := "/var/lib/kubelet/pki/kubelet-client-current.pem"
+
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("test -f %s", currentPem), false); err != nil {
+		logger.Log.Messagef(host.GetName(), "%s is not found, the kubelet client cert rotation may be disabled", currentPem)
+		return nil
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("grep -q client-certificate-data %s", kubeletConf), false); err == nil {
+		updateCmd := fmt.Sprintf("cp -f %s %s.bak && "+
+			"sed -i 's#client-certificate-data:.*#client-certificate: %s#; s#client-key-data:.*#client-key: %s#' %s && "+
+			"systemctl restart kubelet",
+			kubeletConf, kubeletConf, currentPem, currentPem, kubeletConf)
+		if _, err := runtime.GetRunner().SudoCmd(updateCmd, false); err != nil {
+			return errors.Wrap(errors.WithStack(err), "update kubelet.conf to use the rotated client cert failed")
+		}
+		logger.Log.Messagef(host.GetName(), "kubelet.conf is updated to use %s", currentPem)
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("openssl x509 -noout -checkend 0 -in %s", currentPem), false); err != nil {
+		return errors.Errorf("the kubelet client cert %s on %s has expired, it can not be rotated by the kubelet", currentPem, host.GetName())
+	}
+	return nil
+}
+
+// certEndpoint is a TLS endpoint whose serving cert is checked after the renewal.
+type certEndpoint struct {
+	Name string
+	Port int
+	// CertFile is the cert that must be served on the endpoint. If empty, the served cert is only checked to be valid.
+	CertFile string
+}
+
+type VerifyRenewedCerts struct {
+	common.KubeAction
+}
+
+// Execute checks that the endpoints on the host serve the renewed certs, which means the components have been restarted.
+// The self-signed serving cert of the kubelet is not renewed by kubeadm, so it is not checked.
+func (v *VerifyRenewedCerts) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+
+	var endpoints []certEndpoint
+	if host.IsRole(common.Master) {
+		endpoints = append(endpoints,
+			certEndpoint{Name: "kube-apiserver", Port: 6443, CertFile: filepath.Join(common.KubeCertDir, "apiserver.crt")},
+			certEndpoint{Name: "kube-controller-manager", Port: 10257},
+			certEndpoint{Name: "kube-scheduler", Port: 10259},
+		)
+		if v.KubeConf.Cluster.Etcd.Type == kubekeyapiv1alpha2.Kubeadm {
+			endpoints = append(endpoints,
+				certEndpoint{Name: "etcd", Port: 2379, CertFile: filepath.Join(common.KubeCertDir, "etcd", "server.crt")})
+		}
+	}
+	if host.IsRole(common.ETCD) && v.KubeConf.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey {
+		endpoints = append(endpoints,
+			certEndpoint{Name: "etcd", Port: 2379, CertFile: filepath.Join(common.ETCDCertDir, fmt.Sprintf("member-%s.pem", host.GetName()))})
+	}
+
+	for _, e := range endpoints {
+		served, err := runtime.GetRunner().SudoCmd(
+			fmt.Sprintf("echo | openssl s_client -connect 127.0.0.1:%d 2>/dev/null | openssl x509 -noout -enddate", e.Port), false)
 		if err != nil {
-			return errors.Wrap(err, "kubeadm alpha certs renew failed")
+			return errors.Wrapf(errors.WithStack(err), "get the serving cert of %s failed", e.Name)
 		}
-	} else {
-		_, err := runtime.GetRunner().SudoCmd(strings.Join(kubeadmList, " && "), false)
+		servedNotAfter, err := parseNotAfter(served)
 		if err != nil {
-			return errors.Wrap(err, "kubeadm alpha certs renew failed")
+			return errors.Wrapf(err, "parse the serving cert of %s failed", e.Name)
+		}
+		if time.Now().After(servedNotAfter) {
+			return errors.Errorf("%s on %s serves an expired cert, notAfter: %s", e.Name, host.GetName(), servedNotAfter)
+		}
+		if e.CertFile == "" {
+			continue
 		}
-	}
 
-	_, err = runtime.GetRunner().SudoCmd(strings.Join(restartList, " && "), false)
-	if err != nil {
-		return errors.Wrap(err, "kube-apiserver, kube-schedule, kube-controller-manager or kubelet restart failed")
+		file, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("openssl x509 -noout -enddate -in %s", e.CertFile), false)
+		if err != nil {
+			return errors.Wrapf(errors.WithStack(err), "get the expiration of %s failed", e.CertFile)
+		}
+		fileNotAfter, err := parseNotAfter(file)
+		if err != nil {
+			return errors.Wrapf(err, "parse the expiration of %s failed", e.CertFile)
+		}
+		if !servedNotAfter.Equal(fileNotAfter) {
+			return errors.Errorf("%s on %s still serves the old cert, notAfter: %s, expected: %s",
+				e.Name, host.GetName(), servedNotAfter, fileNotAfter)
+		}
 	}
 	return nil
 }
 
+// parseNotAfter parses the output of "openssl x509 -noout -enddate", e.g. notAfter=Oct 19 08:00:00 2027 GMT
+func parseNotAfter(out string) (time.Time, error) {
+	out = strings.TrimSpace(out)
+	if !strings.HasPrefix(out, "notAfter=") {
+		return time.Time{}, errors.Errorf("unexpected output: %q", out)
+	}
+	return time.Parse("Jan _2 15:04:05 2006 MST", strings.TrimPrefix(out, "notAfter="))
+}
+
 type FetchKubeConfig struct {
 	common.KubeAction
 }
@@ -0,0 +1,67 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseNotAfter(t *testing.T) {
+	got, err := parseNotAfter("notAfter=Oct  9 08:05:01 2027 GMT\r\n")
+	if err != nil {
+		t.Fatalf("parseNotAfter() error = %v", err)
+	}
+	if want := time.Date(2027, time.October, 9, 8, 5, 1, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("parseNotAfter() = %v, want %v", got, want)
+	}
+
+	if _, err := parseNotAfter("unable to load certificate"); err == nil {
+		t.Error("parseNotAfter() expects an error for an unexpected output")
+	}
+}
+
+func TestRestartControlPlaneCmds(t *testing.T) {
+	tests := []struct {
+		name             string
+		containerManager string
+		stackedEtcd      bool
+		want             string
+		wantLen          int
+	}{
+		{name: "docker", containerManager: "docker", want: "docker rm -f", wantLen: 4},
+		{name: "containerd", containerManager: "containerd", want: "crictl rmp -f", wantLen: 4},
+		{name: "containerd with stacked etcd", containerManager: "containerd", stackedEtcd: true, want: "crictl rmp -f", wantLen: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmds := restartControlPlaneCmds(tt.containerManager, tt.stackedEtcd)
+			if len(cmds) != tt.wantLen {
+				t.Fatalf("restartControlPlaneCmds() returns %d commands, want %d", len(cmds), tt.wantLen)
+			}
+			for _, cmd := range cmds[:len(cmds)-1] {
+				if !strings.Contains(cmd, tt.want) {
+					t.Errorf("restartControlPlaneCmds() = %q, want it to contain %q", cmd, tt.want)
+				}
+			}
+			if cmds[len(cmds)-1] != "systemctl restart kubelet" {
+				t.Errorf("restartControlPlaneCmds() must restart the kubelet at last")
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package check
+
+import (
+	"path/filepath"
+
+	"github.com/kubesphere/kubekey/pkg/check/templates"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/action"
+	"github.com/kubesphere/kubekey/pkg/core/task"
+	"github.com/kubesphere/kubekey/pkg/core/util"
+	"github.com/kubesphere/kubekey/pkg/images"
+)
+
+// ClusterModule deploys a probe on every node and checks the pod to pod, ClusterIP and NodePort networking, the
+// cluster DNS and the private registry of each node. The probes are removed when the checks are done.
+type ClusterModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (c *ClusterModule) IsSkip() bool {
+	return c.Skip
+}
+
+func (c *ClusterModule) Init() {
+	c.Name = "CheckClusterModule"
+	c.Desc = "Check the networking and the DNS of the cluster"
+
+	generate := &task.RemoteTask{
+		Name:    "GenerateProbe",
+		Desc:    "Generate the probe manifest",
+		Hosts:   c.Runtime.GetHostsByRole(common.Master),
+		Prepare: new(common.OnlyFirstMaster),
+		Action: &action.Template{
+			Template: templates.Probe,
+			Dst:      filepath.Join(common.KubeConfigDir, templates.Probe.Name()),
+			Data: util.Data{
+				"Namespace":  probeNamespace,
+				"Name":       probeName,
+				"Port":       probePort,
+				"ProbeImage": images.GetImage(c.Runtime, c.KubeConf, "busybox").ImageName(),
+			},
+		},
+		Parallel: true,
+	}
+
+	registry := &task.RemoteTask{
+		Name:     "CheckRegistry",
+		Desc:     "Check the connectivity to the private registry",
+		Hosts:    c.Runtime.GetHostsByRole(common.K8s),
+		Action:   new(CheckRegistry),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	pull := &task.RemoteTask{
+		Name:     "PullProbeImage",
+		Desc:     "Pull the probe image",
+		Hosts:    c.Runtime.GetHostsByRole(common.K8s),
+		Action:   new(PullProbeImage),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	probe := &task.RemoteTask{
+		Name:     "ProbeCluster",
+		Desc:     "Probe the networking and the DNS from every node",
+		Hosts:    c.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(ProbeCluster),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	c.Tasks = []task.Interface{
+		generate,
+	}
+	if c.KubeConf.Cluster.Registry.PrivateRegistry != "" {
+		c.Tasks = append(c.Tasks, registry)
+	}
+	c.Tasks = append(c.Tasks, pull, probe)
+}
@@ -0,0 +1,325 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package check
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/pkg/check/templates"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/images"
+)
+
+const (
+	probeNamespace = "kubekey-check"
+	probeName      = "kubekey-probe"
+	probePort      = 8080
+
+	// probeTimeout is the timeout in seconds of each request of the probes.
+	probeTimeout = 3
+)
+
+type CheckRegistry struct {
+	common.KubeAction
+}
+
+// Execute checks that the node can connect to the private registry, an http error such as 401 means it is reachable.
+func (c *CheckRegistry) Execute(runtime connector.Runtime) error {
+	registry := strings.Split(c.KubeConf.Cluster.Registry.PrivateRegistry, "/")[0]
+	out, _ := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"curl -sk -o /dev/null -w %%{http_code} --connect-timeout 5 https://%[1]s/v2/ || "+
+			"curl -s -o /dev/null -w %%{http_code} --connect-timeout 5 http://%[1]s/v2/", registry), false)
+	c.ModuleCache.Set(registryResultKey(runtime.RemoteHost().GetName()), registryReachable(out))
+	return nil
+}
+
+func registryResultKey(node string) string {
+	return fmt.Sprintf("registry-%s", node)
+}
+
+// registryReachable parses the http codes written by curl, the last one is 000 if the registry can not be connected
+// by both https and http.
+func registryReachable(out string) bool {
+	out = strings.TrimSpace(out)
+	return len(out) >= 3 && out[len(out)-3:] != "000"
+}
+
+type PullProbeImage struct {
+	common.KubeAction
+}
+
+// Execute pulls the probe image, which is not pulled with the images of the cluster.
+func (p *PullProbeImage) Execute(runtime connector.Runtime) error {
+	if p.KubeConf.Arg.SkipPullImages {
+		return nil
+	}
+	i := images.Images{Images: []images.Image{images.GetImage(runtime, p.KubeConf, "busybox")}}
+	return i.PullImages(runtime, p.KubeConf)
+}
+
+type ProbeCluster struct {
+	common.KubeAction
+}
+
+// Execute deploys the probes, runs the checks in the probe of every node and prints the results.
+func (p *ProbeCluster) Execute(runtime connector.Runtime) error {
+	manifest := filepath.Join(common.KubeConfigDir, templates.Probe.Name())
+	defer func() {
+		// Wait for the namespace to be deleted, so that the checks can be run again right away.
+		if _, err := runtime.GetRunner().SudoCmd(
+			fmt.Sprintf("/usr/local/bin/kubectl delete -f %s --ignore-not-found --timeout=300s", manifest), false); err != nil {
+			logger.Log.Warningf("delete the probes failed, remove the namespace %s manually: %v", probeNamespace, err)
+		}
+	}()
+
+	if err := waitForProbeNamespaceDeleted(runtime); err != nil {
+		return err
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("/usr/local/bin/kubectl apply -f %s", manifest), true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "deploy the probes failed")
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl -n %s rollout status ds/%s --timeout=300s", probeNamespace, probeName), true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "wait for the probes to be ready failed")
+	}
+
+	podsOut, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl -n %s get pods -l app=%s --no-headers "+
+			"-o custom-columns=NAME:.metadata.name,NODE:.spec.nodeName,IP:.status.podIP", probeNamespace, probeName), false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "get the probes failed")
+	}
+	pods := parseProbePods(podsOut)
+
+	svcOut, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl -n %s get svc %s -o jsonpath='{.spec.clusterIP} {.spec.ports[0].nodePort}'", probeNamespace, probeName), false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "get the service of the probes failed")
+	}
+	svc := strings.Fields(svcOut)
+	if len(svc) != 2 {
+		return errors.Errorf("unexpected service of the probes: %s", svcOut)
+	}
+	clusterIP, nodePort := svc[0], svc[1]
+
+	cluster := p.KubeConf.Cluster
+	service := fmt.Sprintf("kubernetes.default.svc.%s", cluster.Kubernetes.DNSDomain)
+	var results []*probeResult
+	for _, host := range runtime.GetHostsByRole(common.K8s) {
+		r := &probeResult{Node: host.GetName()}
+		results = append(results, r)
+		if v, ok := p.ModuleCache.Get(registryResultKey(host.GetName())); ok {
+			reachable := v.(bool)
+			r.Registry = &reachable
+		}
+
+		pod, ok := pods[host.GetName()]
+		if !ok {
+			continue
+		}
+		r.Probe = true
+
+		// All the checks of the probe are run in a single exec, which prints the result of every check.
+		checks := make(map[string]string)
+		var podChecks []string
+		for _, other := range pods {
+			if other.Node == pod.Node {
+				continue
+			}
+			name := "pod-" + other.Node
+			checks[name] = probeWget(fmt.Sprintf("%s:%d", other.IP, probePort))
+			podChecks = append(podChecks, name)
+		}
+		checks["clusterip"] = probeWget(clusterIP)
+		checks["nodeport"] = probeWget(fmt.Sprintf("%s:%s", host.GetInternalAddress(), nodePort))
+		checks["coredns"] = fmt.Sprintf("nslookup %s %s", service, cluster.CorednsClusterIP())
+		if cluster.Kubernetes.EnableNodelocaldns() {
+			checks["nodelocaldns"] = fmt.Sprintf("nslookup %s %s", service, cluster.ClusterDNS())
+		}
+
+		// A failed exec leaves the output empty, so that all the checks of the probe fail.
+		out, _ := runtime.GetRunner().SudoCmd(fmt.Sprintf("/usr/local/bin/kubectl -n %s exec %s -- sh -c '%s'",
+			probeNamespace, pod.Name, probeScript(checks)), false)
+		passed := parseProbeOutput(out)
+
+		r.PodToPodTotal = len(podChecks)
+		for _, name := range podChecks {
+			if passed[name] {
+				r.PodToPod++
+			}
+		}
+		r.ClusterIP = passed["clusterip"]
+		r.NodePort = passed["nodeport"]
+		r.CoreDNS = passed["coredns"]
+		if _, ok := checks["nodelocaldns"]; ok {
+			resolved := passed["nodelocaldns"]
+			r.NodeLocalDNS = &resolved
+		}
+	}
+
+	printProbeResults(os.Stdout, results)
+	var failed []string
+	for _, r := range results {
+		if r.failed() {
+			failed = append(failed, r.Node)
+		}
+	}
+	if len(failed) != 0 {
+		return errors.Errorf("the checks failed on the nodes %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// waitForProbeNamespaceDeleted waits for the namespace of the probes to be deleted if it is terminating, e.g. when
+// the probes of an interrupted check are still being deleted, since no resources can be created in it.
+func waitForProbeNamespaceDeleted(runtime connector.Runtime) error {
+	for i := 0; i < 60; i++ {
+		out, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+			"/usr/local/bin/kubectl get ns %s --ignore-not-found -o jsonpath='{.status.phase}'", probeNamespace), false)
+		if err != nil {
+			return errors.Wrap(errors.WithStack(err), "get the namespace of the probes failed")
+		}
+		if strings.TrimSpace(out) != "Terminating" {
+			return nil
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return errors.Errorf("the namespace %s is still terminating, check its remaining resources", probeNamespace)
+}
+
+func probeWget(addr string) string {
+	return fmt.Sprintf("wget -q -T %d -O /dev/null http://%s/", probeTimeout, addr)
+}
+
+// probeScript returns the shell script which runs the checks in a probe and prints "<name> ok" or "<name> failed"
+// for each of them. The checks are sorted by name so that the script is stable.
+func probeScript(checks map[string]string) string {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("if %s >/dev/null 2>&1; then echo %[2]s ok; else echo %[2]s failed; fi", checks[name], name))
+	}
+	return strings.Join(lines, "; ")
+}
+
+// parseProbeOutput parses the output of the probe script into whether each check passed.
+func parseProbeOutput(out string) map[string]bool {
+	passed := make(map[string]bool)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			passed[fields[0]] = fields[1] == "ok"
+		}
+	}
+	return passed
+}
+
+type probePod struct {
+	Name string
+	Node string
+	IP   string
+}
+
+// parseProbePods parses the names, the nodes and the IPs of the probes, the probes without an IP are ignored.
+func parseProbePods(out string) map[string]probePod {
+	pods := make(map[string]probePod)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[2] == "<none>" {
+			continue
+		}
+		pods[fields[1]] = probePod{Name: fields[0], Node: fields[1], IP: fields[2]}
+	}
+	return pods
+}
+
+// probeResult is the result of the checks of a node, the optional checks are nil if they are not run.
+type probeResult struct {
+	Node string
+	// Probe is false if there is no probe running on the node.
+	Probe         bool
+	PodToPod      int
+	PodToPodTotal int
+	ClusterIP     bool
+	NodePort      bool
+	CoreDNS       bool
+	NodeLocalDNS  *bool
+	Registry      *bool
+}
+
+func (r *probeResult) failed() bool {
+	if r.Registry != nil && !*r.Registry {
+		return true
+	}
+	if !r.Probe {
+		return true
+	}
+	return r.PodToPod != r.PodToPodTotal || !r.ClusterIP || !r.NodePort || !r.CoreDNS ||
+		(r.NodeLocalDNS != nil && !*r.NodeLocalDNS)
+}
+
+func (r *probeResult) columns() []string {
+	registry := "-"
+	if r.Registry != nil {
+		registry = checkResult(*r.Registry)
+	}
+	if !r.Probe {
+		return []string{r.Node, "no probe", "-", "-", "-", "-", registry}
+	}
+
+	podToPod := "-"
+	if r.PodToPodTotal != 0 {
+		podToPod = fmt.Sprintf("%s (%d/%d)", checkResult(r.PodToPod == r.PodToPodTotal), r.PodToPod, r.PodToPodTotal)
+	}
+	nodeLocalDNS := "-"
+	if r.NodeLocalDNS != nil {
+		nodeLocalDNS = checkResult(*r.NodeLocalDNS)
+	}
+	return []string{r.Node, podToPod, checkResult(r.ClusterIP), checkResult(r.NodePort), checkResult(r.CoreDNS), nodeLocalDNS, registry}
+}
+
+func checkResult(ok bool) string {
+	if ok {
+		return "ok"
+	}
+	return "failed"
+}
+
+func printProbeResults(out io.Writer, results []*probeResult) {
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NODE\tPOD TO POD\tCLUSTERIP\tNODEPORT\tCOREDNS\tNODELOCALDNS\tREGISTRY")
+	for _, r := range results {
+		_, _ = fmt.Fprintln(w, strings.Join(r.columns(), "\t"))
+	}
+	_ = w.Flush()
+}
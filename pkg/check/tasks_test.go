@@ -0,0 +1,115 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package check
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestParseProbePods(t *testing.T) {
+	out := "kubekey-probe-7kq2d   node1   10.233.64.5\r\n" +
+		"kubekey-probe-h8x4m   node2   10.233.65.7\r\n" +
+		"kubekey-probe-p2w9c   node3   <none>\r\n"
+	pods := parseProbePods(out)
+	if len(pods) != 2 {
+		t.Fatalf("parseProbePods() = %v, want the probes of node1 and node2", pods)
+	}
+	if pod := pods["node2"]; pod.Name != "kubekey-probe-h8x4m" || pod.IP != "10.233.65.7" {
+		t.Errorf("parseProbePods() node2 = %v", pod)
+	}
+}
+
+func TestRegistryReachable(t *testing.T) {
+	tests := []struct {
+		out  string
+		want bool
+	}{
+		{out: "401", want: true},
+		{out: "000200", want: true},
+		{out: "000000", want: false},
+		{out: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := registryReachable(tt.out); got != tt.want {
+			t.Errorf("registryReachable(%q) = %v, want %v", tt.out, got, tt.want)
+		}
+	}
+}
+
+func TestProbeScript(t *testing.T) {
+	script := probeScript(map[string]string{"pod-node2": "true", "clusterip": "false"})
+	want := "if false >/dev/null 2>&1; then echo clusterip ok; else echo clusterip failed; fi; " +
+		"if true >/dev/null 2>&1; then echo pod-node2 ok; else echo pod-node2 failed; fi"
+	if script != want {
+		t.Fatalf("probeScript() = %q, want %q", script, want)
+	}
+
+	out, err := exec.Command("sh", "-c", script).Output()
+	if err != nil {
+		t.Skipf("sh is not available: %v", err)
+	}
+	passed := parseProbeOutput(string(out))
+	if len(passed) != 2 || !passed["pod-node2"] || passed["clusterip"] {
+		t.Errorf("parseProbeOutput() = %v, want pod-node2 passed and clusterip failed", passed)
+	}
+}
+
+func TestParseProbeOutput(t *testing.T) {
+	passed := parseProbeOutput("clusterip ok\r\ncoredns failed\r\nwget: bad address\r\n")
+	if len(passed) != 2 || !passed["clusterip"] || passed["coredns"] {
+		t.Errorf("parseProbeOutput() = %v, want clusterip passed and coredns failed", passed)
+	}
+	if passed := parseProbeOutput(""); len(passed) != 0 {
+		t.Errorf("parseProbeOutput() = %v, want no results of a failed exec", passed)
+	}
+}
+
+func TestPrintProbeResults(t *testing.T) {
+	ok, failed := true, false
+	results := []*probeResult{
+		{Node: "node1", Probe: true, PodToPod: 2, PodToPodTotal: 2, ClusterIP: true, NodePort: true, CoreDNS: true, NodeLocalDNS: &ok, Registry: &ok},
+		{Node: "node2", Probe: true, PodToPod: 1, PodToPodTotal: 2, ClusterIP: true, NodePort: true, CoreDNS: true, NodeLocalDNS: &ok, Registry: &failed},
+		{Node: "node3"},
+	}
+	var buf bytes.Buffer
+	printProbeResults(&buf, results)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("printProbeResults() = %s", buf.String())
+	}
+	for i, want := range [][]string{
+		{"NODE", "POD", "TO", "POD", "CLUSTERIP", "NODEPORT", "COREDNS", "NODELOCALDNS", "REGISTRY"},
+		{"node1", "ok", "(2/2)", "ok", "ok", "ok", "ok", "ok"},
+		{"node2", "failed", "(1/2)", "ok", "ok", "ok", "ok", "failed"},
+		{"node3", "no", "probe", "-", "-", "-", "-", "-"},
+	} {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("printProbeResults() line %d = %q, want %q", i, lines[i], strings.Join(want, " "))
+		}
+	}
+
+	if results[0].failed() {
+		t.Errorf("failed() of node1 = true, want false")
+	}
+	if !results[1].failed() || !results[2].failed() {
+		t.Errorf("failed() of node2 and node3 = false, want true")
+	}
+}
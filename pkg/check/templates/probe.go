@@ -0,0 +1,73 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"github.com/lithammer/dedent"
+	"text/template"
+)
+
+// Probe runs a web server on every node, which is requested by the probes on the other nodes, directly and through
+// the ClusterIP and the NodePort of the service.
+var Probe = template.Must(template.New("kubekey-probe.yaml").Parse(
+	dedent.Dedent(`---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{ .Namespace }}
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+spec:
+  selector:
+    matchLabels:
+      app: {{ .Name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .Name }}
+    spec:
+      tolerations:
+      - operator: Exists
+      terminationGracePeriodSeconds: 0
+      containers:
+      - name: probe
+        image: {{ .ProbeImage }}
+        command: ["sh", "-c", "echo ok > /tmp/index.html && exec httpd -f -p {{ .Port }} -h /tmp"]
+        ports:
+        - containerPort: {{ .Port }}
+        readinessProbe:
+          tcpSocket:
+            port: {{ .Port }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+spec:
+  type: NodePort
+  selector:
+    app: {{ .Name }}
+  ports:
+  - port: 80
+    targetPort: {{ .Port }}
+
+    `)))
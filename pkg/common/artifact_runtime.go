@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/files"
 	"github.com/pkg/errors"
 	"io/ioutil"
 	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
@@ -27,12 +28,13 @@ import (
 )
 
 type ArtifactArgument struct {
-	ManifestFile    string
-	Output          string
-	CriSocket       string
-	Debug           bool
-	IgnoreErr       bool
-	DownloadCommand func(path, url string) string
+	ManifestFile string
+	Output       string
+	Base         string
+	CriSocket    string
+	Debug        bool
+	IgnoreErr    bool
+	Downloader   files.Downloader
 }
 
 type ArtifactRuntime struct {
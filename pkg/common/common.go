@@ -49,7 +49,8 @@ const (
 	ETCDCertDir     = "/etc/ssl/etcd/ssl"
 	RegistryCertDir = "/etc/ssl/registry/ssl"
 
-	HaproxyDir = "/etc/kubekey/haproxy"
+	HaproxyDir    = "/etc/kubekey/haproxy"
+	KeepalivedDir = "/etc/kubekey/keepalived"
 
 	IPv4Regexp = "[\\d]+\\.[\\d]+\\.[\\d]+\\.[\\d]+"
 	IPv6Regexp = "[a-f0-9]{1,4}(:[a-f0-9]{1,4}){7}|[a-f0-9]{1,4}(:[a-f0-9]{1,4}){0,7}::[a-f0-9]{0,4}(:[a-f0-9]{1,4}){0,7}"
@@ -87,9 +88,15 @@ const (
 	ClusterExist  = "clusterExist"
 
 	// CertsModule
-	Certificate   = "certificate"
-	CaCertificate = "caCertificate"
+	Certificate       = "certificate"
+	CaCertificate     = "caCertificate"
+	ETCDCertificate   = "etcdCertificate"
+	ETCDCaCertificate = "etcdCaCertificate"
 
 	// Artifact pipeline
-	Artifact = "artifact"
+	Artifact             = "artifact"
+	ArtifactManifestFile = "manifest.yaml"
+	ArtifactDeltaFile    = "delta.json"
+	// ArtifactBaseMd5File records the md5 value of the full artifact which the unarchived deltas are based on.
+	ArtifactBaseMd5File = "artifact.base.md5"
 )
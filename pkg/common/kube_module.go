@@ -19,6 +19,7 @@ package common
 import (
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	kubekeyclientset "github.com/kubesphere/kubekey/clients/clientset/versioned"
+	"github.com/kubesphere/kubekey/pkg/core/cache"
 	"github.com/kubesphere/kubekey/pkg/core/module"
 )
 
@@ -29,6 +30,7 @@ type KubeConf struct {
 	Kubeconfig   string
 	ClientSet    *kubekeyclientset.Clientset
 	Arg          Argument
+	Cache        *cache.Cache
 }
 
 type KubeModule struct {
@@ -48,6 +50,7 @@ func (k *KubeModule) AutoAssert() {
 		Kubeconfig:  kubeRuntime.Kubeconfig,
 		ClientSet:   kubeRuntime.ClientSet,
 		Arg:         kubeRuntime.Arg,
+		Cache:       kubeRuntime.Cache,
 	}
 
 	k.KubeConf = conf
@@ -66,6 +69,7 @@ func (k *KubeCustomModule) AutoAssert() {
 		Kubeconfig:  kubeRuntime.Kubeconfig,
 		ClientSet:   kubeRuntime.ClientSet,
 		Arg:         kubeRuntime.Arg,
+		Cache:       kubeRuntime.Cache,
 	}
 
 	k.KubeConf = conf
@@ -33,6 +33,7 @@ func (k *KubePrepare) AutoAssert(runtime connector.Runtime) {
 		Kubeconfig: kubeRuntime.Kubeconfig,
 		ClientSet:  kubeRuntime.ClientSet,
 		Arg:        kubeRuntime.Arg,
+		Cache:      kubeRuntime.Cache,
 	}
 
 	k.KubeConf = conf
@@ -129,3 +130,18 @@ func (e *EnableKubeProxy) PreCheck(_ connector.Runtime) (bool, error) {
 	}
 	return false, nil
 }
+
+// OnlyNodePool skips the hosts out of the node pool selected by kk add nodes --pool.
+type OnlyNodePool struct {
+	KubePrepare
+}
+
+func (o *OnlyNodePool) PreCheck(runtime connector.Runtime) (bool, error) {
+	return IsInNodePool(runtime.RemoteHost(), o.KubeConf.Arg.NodePool), nil
+}
+
+// IsInNodePool returns whether the host is in the node pool. The control-plane nodes are always in it, and all the hosts
+// are in it if no node pool is selected.
+func IsInNodePool(host connector.Host, pool string) bool {
+	return pool == "" || host.IsRole(pool) || host.IsRole(Master)
+}
@@ -34,6 +34,7 @@ func (k *KubeRollback) AutoAssert(runtime connector.Runtime) {
 		Kubeconfig:  kubeRuntime.Kubeconfig,
 		ClientSet:   kubeRuntime.ClientSet,
 		Arg:         kubeRuntime.Arg,
+		Cache:       kubeRuntime.Cache,
 	}
 
 	k.KubeConf = conf
@@ -19,7 +19,9 @@ package common
 import (
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	kubekeyclientset "github.com/kubesphere/kubekey/clients/clientset/versioned"
+	"github.com/kubesphere/kubekey/pkg/core/cache"
 	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/files"
 )
 
 type KubeRuntime struct {
@@ -29,6 +31,8 @@ type KubeRuntime struct {
 	Kubeconfig  string
 	ClientSet   *kubekeyclientset.Clientset
 	Arg         Argument
+	// Cache holds the values which are computed once per runtime from the cluster config, e.g. the image BOM.
+	Cache *cache.Cache
 }
 
 type Argument struct {
@@ -42,7 +46,7 @@ type Argument struct {
 	SkipPullImages     bool
 	SKipPushImages     bool
 	DeployLocalStorage *bool
-	DownloadCommand    func(path, url string) string
+	Downloader         files.Downloader
 	SkipConfirmCheck   bool
 	InCluster          bool
 	ContainerManager   string
@@ -52,6 +56,10 @@ type Argument struct {
 	InstallPackages    bool
 	ImagesDir          string
 	Namespace          string
+	RotateSAKey        bool
+	Output             string
+	CheckCluster       bool
+	NodePool           string
 }
 
 func NewKubeRuntime(flag string, arg Argument) (*KubeRuntime, error) {
@@ -86,6 +94,7 @@ func NewKubeRuntime(flag string, arg Argument) (*KubeRuntime, error) {
 		Cluster:     defaultCluster,
 		ClusterName: cluster.Name,
 		Arg:         arg,
+		Cache:       cache.NewCache(),
 	}
 	r.BaseRuntime = base
 
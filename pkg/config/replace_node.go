@@ -0,0 +1,142 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ReplaceNode updates the Cluster in the configuration file: the old node is removed from spec.hosts,
+// and it is replaced by the new node in spec.roleGroups. The other documents and the comments are kept.
+func ReplaceNode(path, oldNode, newNode string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "read the configuration file %s failed", path)
+	}
+
+	out, err := replaceNodeInConfig(content, oldNode, newNode)
+	if err != nil {
+		return errors.Wrapf(err, "update the configuration file %s failed", path)
+	}
+
+	if err := ioutil.WriteFile(path, out, 0644); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "write the configuration file %s failed", path)
+	}
+	return nil
+}
+
+func replaceNodeInConfig(content []byte, oldNode, newNode string) ([]byte, error) {
+	docs, err := decodeDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var replaced bool
+	for _, spec := range clusterSpecs(docs) {
+		if hosts := mappingValue(spec, "hosts"); hosts != nil && hosts.Kind == yaml.SequenceNode {
+			kept := hosts.Content[:0]
+			for _, host := range hosts.Content {
+				if name := mappingValue(host, "name"); name != nil && name.Value == oldNode {
+					continue
+				}
+				kept = append(kept, host)
+			}
+			hosts.Content = kept
+		}
+
+		if roleGroups := mappingValue(spec, "roleGroups"); roleGroups != nil && roleGroups.Kind == yaml.MappingNode {
+			for i := 1; i < len(roleGroups.Content); i += 2 {
+				for _, name := range roleGroups.Content[i].Content {
+					if name.Kind == yaml.ScalarNode && name.Value == oldNode {
+						name.Value = newNode
+						replaced = true
+					}
+				}
+			}
+		}
+	}
+	if !replaced {
+		return nil, errors.Errorf("the node %s is not listed by name in the roleGroups", oldNode)
+	}
+
+	return encodeDocuments(docs)
+}
+
+// decodeDocuments decodes all the documents of the configuration, keeping the comments.
+func decodeDocuments(content []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		doc := &yaml.Node{}
+		if err := decoder.Decode(doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Wrap(err, "unmarshal the configuration failed")
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func encodeDocuments(docs []*yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return nil, errors.Wrap(err, "marshal the configuration failed")
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, errors.Wrap(err, "marshal the configuration failed")
+	}
+	return buf.Bytes(), nil
+}
+
+// mappingValue returns the value node of the key in the mapping node, or nil if it is not found.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// clusterSpecs returns the spec nodes of the Cluster documents.
+func clusterSpecs(docs []*yaml.Node) []*yaml.Node {
+	var specs []*yaml.Node
+	for _, doc := range docs {
+		if len(doc.Content) == 0 || mappingValue(doc.Content[0], "kind") == nil ||
+			mappingValue(doc.Content[0], "kind").Value != "Cluster" {
+			continue
+		}
+		if spec := mappingValue(doc.Content[0], "spec"); spec != nil {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
@@ -0,0 +1,72 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const replaceNodeConfig = `apiVersion: kubekey.kubesphere.io/v1alpha2
+kind: Cluster
+metadata:
+  name: sample
+spec:
+  hosts:
+  - {name: master1, address: 172.16.0.2, internalAddress: 172.16.0.2}
+  - {name: master2, address: 172.16.0.3, internalAddress: 172.16.0.3}
+  - {name: master4, address: 172.16.0.5, internalAddress: 172.16.0.5}
+  roleGroups:
+    # the etcd members
+    etcd: [master1, master2]
+    control-plane:
+    - master1
+    - master2
+    worker:
+    - node[1:3]
+---
+apiVersion: installer.kubesphere.io/v1alpha1
+kind: ClusterConfiguration
+metadata:
+  name: ks-installer
+`
+
+func TestReplaceNodeInConfig(t *testing.T) {
+	out, err := replaceNodeInConfig([]byte(replaceNodeConfig), "master2", "master4")
+	if err != nil {
+		t.Fatalf("replaceNodeInConfig() error = %v", err)
+	}
+	got := string(out)
+	for _, want := range []string{
+		"etcd: [master1, master4]",
+		"- master4",
+		"# the etcd members",
+		"- node[1:3]",
+		"kind: ClusterConfiguration",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("replaceNodeInConfig() = %s, want it contains %q", got, want)
+		}
+	}
+	if strings.Contains(got, "master2") {
+		t.Errorf("replaceNodeInConfig() = %s, want master2 removed", got)
+	}
+
+	if _, err := replaceNodeInConfig([]byte(replaceNodeConfig), "node2", "master4"); err == nil {
+		t.Errorf("replaceNodeInConfig() expects an error for the node in a range")
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateControlPlaneEndpoint updates the internal load balancer, the address and the port of the
+// controlPlaneEndpoint of the Cluster in the configuration file. The other fields and the comments are kept.
+func UpdateControlPlaneEndpoint(path, internalLoadbalancer, address string, port int) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "read the configuration file %s failed", path)
+	}
+
+	out, err := updateControlPlaneEndpointInConfig(content, internalLoadbalancer, address, port)
+	if err != nil {
+		return errors.Wrapf(err, "update the configuration file %s failed", path)
+	}
+
+	if err := ioutil.WriteFile(path, out, 0644); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "write the configuration file %s failed", path)
+	}
+	return nil
+}
+
+func updateControlPlaneEndpointInConfig(content []byte, internalLoadbalancer, address string, port int) ([]byte, error) {
+	docs, err := decodeDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := clusterSpecs(docs)
+	if len(specs) == 0 {
+		return nil, errors.New("the Cluster is not found")
+	}
+	for _, spec := range specs {
+		endpoint := mappingValue(spec, "controlPlaneEndpoint")
+		if endpoint == nil || endpoint.Kind != yaml.MappingNode {
+			endpoint = &yaml.Node{Kind: yaml.MappingNode}
+			setMappingValue(spec, "controlPlaneEndpoint", endpoint)
+		}
+		setMappingValue(endpoint, "internalLoadbalancer", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: internalLoadbalancer})
+		setMappingValue(endpoint, "address", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: address})
+		setMappingValue(endpoint, "port", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(port)})
+	}
+
+	return encodeDocuments(docs)
+}
+
+// setMappingValue replaces the value node of the key in the mapping node, or appends the key if it is not found.
+// The comments of the old value are kept.
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			old := node.Content[i+1]
+			value.LineComment, value.HeadComment, value.FootComment = old.LineComment, old.HeadComment, old.FootComment
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
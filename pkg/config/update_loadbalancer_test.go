@@ -0,0 +1,70 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const updateLoadBalancerConfig = `apiVersion: kubekey.kubesphere.io/v1alpha2
+kind: Cluster
+metadata:
+  name: sample
+spec:
+  controlPlaneEndpoint:
+    internalLoadbalancer: haproxy # the internal load balancer
+    domain: lb.kubesphere.local
+    address: ""
+    port: 6443
+---
+apiVersion: installer.kubesphere.io/v1alpha1
+kind: ClusterConfiguration
+metadata:
+  name: ks-installer
+`
+
+func TestUpdateControlPlaneEndpointInConfig(t *testing.T) {
+	out, err := updateControlPlaneEndpointInConfig([]byte(updateLoadBalancerConfig), "keepalived+haproxy", "192.168.0.100", 8443)
+	if err != nil {
+		t.Fatalf("updateControlPlaneEndpointInConfig() error = %v", err)
+	}
+	got := string(out)
+	for _, want := range []string{
+		"internalLoadbalancer: keepalived+haproxy # the internal load balancer",
+		"domain: lb.kubesphere.local",
+		"address: 192.168.0.100",
+		"port: 8443",
+		"kind: ClusterConfiguration",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("updateControlPlaneEndpointInConfig() = %s, want it contains %q", got, want)
+		}
+	}
+
+	out, err = updateControlPlaneEndpointInConfig([]byte("kind: Cluster\nspec:\n  hosts: []\n"), "", "192.168.0.100", 6443)
+	if err != nil {
+		t.Fatalf("updateControlPlaneEndpointInConfig() error = %v", err)
+	}
+	if got := string(out); !strings.Contains(got, "controlPlaneEndpoint:\n    internalLoadbalancer: \"\"\n    address: 192.168.0.100\n    port: 6443") {
+		t.Errorf("updateControlPlaneEndpointInConfig() = %s, want the controlPlaneEndpoint added", got)
+	}
+
+	if _, err := updateControlPlaneEndpointInConfig([]byte("kind: Manifest\n"), "", "192.168.0.100", 6443); err == nil {
+		t.Errorf("updateControlPlaneEndpointInConfig() expects an error without the Cluster")
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateNetworkPlugin updates the network plugin of the Cluster in the configuration file, along with the ipam of
+// the plugin which is kept after the migration from flannel. The other fields and the comments are kept.
+func UpdateNetworkPlugin(path, plugin, ipam string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "read the configuration file %s failed", path)
+	}
+
+	out, err := updateNetworkPluginInConfig(content, plugin, ipam)
+	if err != nil {
+		return errors.Wrapf(err, "update the configuration file %s failed", path)
+	}
+
+	if err := ioutil.WriteFile(path, out, 0644); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "write the configuration file %s failed", path)
+	}
+	return nil
+}
+
+func updateNetworkPluginInConfig(content []byte, plugin, ipam string) ([]byte, error) {
+	docs, err := decodeDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := clusterSpecs(docs)
+	if len(specs) == 0 {
+		return nil, errors.New("the Cluster is not found")
+	}
+	for _, spec := range specs {
+		network := mappingChild(spec, "network")
+		setMappingValue(network, "plugin", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: plugin})
+		setMappingValue(mappingChild(network, plugin), "ipam", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ipam})
+	}
+
+	return encodeDocuments(docs)
+}
+
+// mappingChild returns the mapping node of the key in the mapping node, an empty one is set if it is not found.
+func mappingChild(node *yaml.Node, key string) *yaml.Node {
+	child := mappingValue(node, key)
+	if child == nil || child.Kind != yaml.MappingNode {
+		child = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(node, key, child)
+	}
+	return child
+}
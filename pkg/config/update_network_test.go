@@ -0,0 +1,63 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const updateNetworkConfig = `apiVersion: kubekey.kubesphere.io/v1alpha2
+kind: Cluster
+metadata:
+  name: sample
+spec:
+  network:
+    plugin: flannel # the network plugin
+    kubePodsCIDR: 10.233.64.0/18
+    calico:
+      vethMTU: 1440
+`
+
+func TestUpdateNetworkPluginInConfig(t *testing.T) {
+	out, err := updateNetworkPluginInConfig([]byte(updateNetworkConfig), "calico", "host-local")
+	if err != nil {
+		t.Fatalf("updateNetworkPluginInConfig() error = %v", err)
+	}
+	got := string(out)
+	for _, want := range []string{
+		"plugin: calico # the network plugin",
+		"kubePodsCIDR: 10.233.64.0/18",
+		"calico:\n      vethMTU: 1440\n      ipam: host-local",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("updateNetworkPluginInConfig() = %s, want it contains %q", got, want)
+		}
+	}
+
+	out, err = updateNetworkPluginInConfig([]byte("kind: Cluster\nspec:\n  hosts: []\n"), "cilium", "kubernetes")
+	if err != nil {
+		t.Fatalf("updateNetworkPluginInConfig() error = %v", err)
+	}
+	if got := string(out); !strings.Contains(got, "network:\n    plugin: cilium\n    cilium:\n      ipam: kubernetes") {
+		t.Errorf("updateNetworkPluginInConfig() = %s, want the network added", got)
+	}
+
+	if _, err := updateNetworkPluginInConfig([]byte("kind: Manifest\n"), "calico", "host-local"); err == nil {
+		t.Errorf("updateNetworkPluginInConfig() expects an error without the Cluster")
+	}
+}
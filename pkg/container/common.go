@@ -16,6 +16,36 @@
 
 package container
 
+import (
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+)
+
 const (
 	DefaultContainerdCRISocket = "/run/containerd/containerd.sock"
 )
+
+// containerRuntimeConfig returns the settings of the container runtime of the node,
+// the ones of its node pools override the ones of the cluster.
+func containerRuntimeConfig(kubeConf *common.KubeConf, host connector.Host) kubekeyv1alpha2.ContainerRuntimeConfig {
+	config := kubekeyv1alpha2.ContainerRuntimeConfig{
+		RegistryMirrors:    kubeConf.Cluster.Registry.RegistryMirrors,
+		InsecureRegistries: kubeConf.Cluster.Registry.InsecureRegistries,
+		DataRoot:           kubeConf.Cluster.Registry.DataRoot,
+	}
+	kubeHost, ok := host.(*kubekeyv1alpha2.KubeHost)
+	if !ok {
+		return config
+	}
+	if len(kubeHost.ContainerRuntime.RegistryMirrors) != 0 {
+		config.RegistryMirrors = kubeHost.ContainerRuntime.RegistryMirrors
+	}
+	if len(kubeHost.ContainerRuntime.InsecureRegistries) != 0 {
+		config.InsecureRegistries = kubeHost.ContainerRuntime.InsecureRegistries
+	}
+	if kubeHost.ContainerRuntime.DataRoot != "" {
+		config.DataRoot = kubeHost.ContainerRuntime.DataRoot
+	}
+	return config
+}
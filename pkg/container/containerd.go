@@ -19,13 +19,42 @@ package container
 import (
 	"fmt"
 	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/container/templates"
+	"github.com/kubesphere/kubekey/pkg/core/action"
 	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/util"
 	"github.com/kubesphere/kubekey/pkg/files"
+	"github.com/kubesphere/kubekey/pkg/images"
+	"github.com/kubesphere/kubekey/pkg/registry"
 	"github.com/kubesphere/kubekey/pkg/utils"
 	"github.com/pkg/errors"
 	"path/filepath"
 )
 
+type GenerateContainerdConfig struct {
+	common.KubeAction
+}
+
+func (g *GenerateContainerdConfig) Execute(runtime connector.Runtime) error {
+	config := containerRuntimeConfig(g.KubeConf, runtime.RemoteHost())
+	templateAction := action.Template{
+		Template: templates.ContainerdConfig,
+		Dst:      filepath.Join("/etc/containerd/", templates.ContainerdConfig.Name()),
+		Data: util.Data{
+			"Mirrors":            templates.Mirrors(config.RegistryMirrors),
+			"InsecureRegistries": config.InsecureRegistries,
+			"SandBoxImage":       images.GetImage(runtime, g.KubeConf, "pause").ImageName(),
+			"Auths":              registry.DockerRegistryAuthEntries(g.KubeConf.Cluster.Registry.Auths),
+		},
+	}
+
+	templateAction.Init(nil, nil)
+	if err := templateAction.Execute(runtime); err != nil {
+		return err
+	}
+	return nil
+}
+
 type SyncContainerd struct {
 	common.KubeAction
 }
@@ -23,12 +23,38 @@ import (
 	"strings"
 
 	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/container/templates"
+	"github.com/kubesphere/kubekey/pkg/core/action"
 	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/util"
 	"github.com/kubesphere/kubekey/pkg/files"
 	"github.com/kubesphere/kubekey/pkg/utils"
 	"github.com/pkg/errors"
 )
 
+type GenerateDockerConfig struct {
+	common.KubeAction
+}
+
+func (g *GenerateDockerConfig) Execute(runtime connector.Runtime) error {
+	config := containerRuntimeConfig(g.KubeConf, runtime.RemoteHost())
+	templateAction := action.Template{
+		Template: templates.DockerConfig,
+		Dst:      filepath.Join("/etc/docker/", templates.DockerConfig.Name()),
+		Data: util.Data{
+			"Mirrors":            templates.Mirrors(config.RegistryMirrors),
+			"InsecureRegistries": templates.InsecureRegistries(config.InsecureRegistries),
+			"DataRoot":           templates.DataRoot(config.DataRoot),
+		},
+	}
+
+	templateAction.Init(nil, nil)
+	if err := templateAction.Execute(runtime); err != nil {
+		return err
+	}
+	return nil
+}
+
 type SyncDockerBinaries struct {
 	common.KubeAction
 }
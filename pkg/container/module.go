@@ -17,7 +17,6 @@
 package container
 
 import (
-	"github.com/kubesphere/kubekey/pkg/registry"
 	"path/filepath"
 	"strings"
 
@@ -28,7 +27,6 @@ import (
 	"github.com/kubesphere/kubekey/pkg/core/prepare"
 	"github.com/kubesphere/kubekey/pkg/core/task"
 	"github.com/kubesphere/kubekey/pkg/core/util"
-	"github.com/kubesphere/kubekey/pkg/images"
 	"github.com/kubesphere/kubekey/pkg/kubernetes"
 )
 
@@ -96,15 +94,7 @@ func InstallDocker(m *InstallContainerModule) []task.Interface {
 			&kubernetes.NodeInCluster{Not: true},
 			&DockerExist{Not: true},
 		},
-		Action: &action.Template{
-			Template: templates.DockerConfig,
-			Dst:      filepath.Join("/etc/docker/", templates.DockerConfig.Name()),
-			Data: util.Data{
-				"Mirrors":            templates.Mirrors(m.KubeConf),
-				"InsecureRegistries": templates.InsecureRegistries(m.KubeConf),
-				"DataRoot":           templates.DataRoot(m.KubeConf),
-			},
-		},
+		Action:   new(GenerateDockerConfig),
 		Parallel: true,
 	}
 
@@ -192,16 +182,7 @@ func InstallContainerd(m *InstallContainerModule) []task.Interface {
 			&kubernetes.NodeInCluster{Not: true},
 			&ContainerdExist{Not: true},
 		},
-		Action: &action.Template{
-			Template: templates.ContainerdConfig,
-			Dst:      filepath.Join("/etc/containerd/", templates.ContainerdConfig.Name()),
-			Data: util.Data{
-				"Mirrors":            templates.Mirrors(m.KubeConf),
-				"InsecureRegistries": m.KubeConf.Cluster.Registry.InsecureRegistries,
-				"SandBoxImage":       images.GetImage(m.Runtime, m.KubeConf, "pause").ImageName(),
-				"Auths":              registry.DockerRegistryAuthEntries(m.KubeConf.Cluster.Registry.Auths),
-			},
-		},
+		Action:   new(GenerateContainerdConfig),
 		Parallel: true,
 	}
 
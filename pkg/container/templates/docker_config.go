@@ -21,7 +21,6 @@ import (
 	"strings"
 	"text/template"
 
-	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/lithammer/dedent"
 )
 
@@ -44,11 +43,11 @@ var DockerConfig = template.Must(template.New("daemon.json").Parse(
 }
     `)))
 
-func Mirrors(kubeConf *common.KubeConf) string {
+func Mirrors(registryMirrors []string) string {
 	var mirrors string
-	if kubeConf.Cluster.Registry.RegistryMirrors != nil {
+	if registryMirrors != nil {
 		var mirrorsArr []string
-		for _, mirror := range kubeConf.Cluster.Registry.RegistryMirrors {
+		for _, mirror := range registryMirrors {
 			mirrorsArr = append(mirrorsArr, fmt.Sprintf("\"%s\"", mirror))
 		}
 		mirrors = strings.Join(mirrorsArr, ", ")
@@ -56,11 +55,11 @@ func Mirrors(kubeConf *common.KubeConf) string {
 	return mirrors
 }
 
-func InsecureRegistries(kubeConf *common.KubeConf) string {
+func InsecureRegistries(registries []string) string {
 	var insecureRegistries string
-	if kubeConf.Cluster.Registry.InsecureRegistries != nil {
+	if registries != nil {
 		var registriesArr []string
-		for _, repo := range kubeConf.Cluster.Registry.InsecureRegistries {
+		for _, repo := range registries {
 			registriesArr = append(registriesArr, fmt.Sprintf("\"%s\"", repo))
 		}
 		insecureRegistries = strings.Join(registriesArr, ", ")
@@ -68,10 +67,10 @@ func InsecureRegistries(kubeConf *common.KubeConf) string {
 	return insecureRegistries
 }
 
-func DataRoot(kubeConf *common.KubeConf) string {
+func DataRoot(root string) string {
 	var dataRoot string
-	if kubeConf.Cluster.Registry.DataRoot != "" {
-		dataRoot = fmt.Sprintf("\"%s\"", kubeConf.Cluster.Registry.DataRoot)
+	if root != "" {
+		dataRoot = fmt.Sprintf("\"%s\"", root)
 	}
 	return dataRoot
 }
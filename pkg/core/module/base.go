@@ -31,6 +31,7 @@ type BaseModule struct {
 	ModuleCache   *cache.Cache
 	PipelineCache *cache.Cache
 	Runtime       connector.ModuleRuntime
+	PreHook       []PreHookInterface
 	PostHook      []PostHookInterface
 }
 
@@ -68,6 +69,21 @@ func (b *BaseModule) Slogan() {
 func (b *BaseModule) AutoAssert() {
 }
 
+func (b *BaseModule) AppendPreHook(h PreHookInterface) {
+	b.PreHook = append(b.PreHook, h)
+}
+
+func (b *BaseModule) CallPreHook() error {
+	for i := range b.PreHook {
+		h := b.PreHook[i]
+		h.Init(b)
+		if err := hook.Call(h); err != nil {
+			return errors.Wrapf(err, "Module[%s] call pre hook failed", b.Name)
+		}
+	}
+	return nil
+}
+
 func (b *BaseModule) AppendPostHook(h PostHookInterface) {
 	b.PostHook = append(b.PostHook, h)
 }
@@ -21,6 +21,30 @@ import (
 	"github.com/kubesphere/kubekey/pkg/core/hook"
 )
 
+type PreHookInterface interface {
+	hook.Interface
+	Init(module Module)
+}
+
+type PreHook struct {
+	Module Module
+}
+
+func (p *PreHook) Try() error {
+	panic("implement me")
+}
+
+func (p *PreHook) Catch(err error) error {
+	return err
+}
+
+func (p *PreHook) Finally() {
+}
+
+func (p *PreHook) Init(module Module) {
+	p.Module = module
+}
+
 type PostHookInterface interface {
 	hook.Interface
 	Init(module Module, result *ending.ModuleResult)
@@ -31,6 +31,8 @@ type Module interface {
 	Until() (*bool, error)
 	Slogan()
 	AutoAssert()
+	AppendPreHook(h PreHookInterface)
+	CallPreHook() error
 	AppendPostHook(h PostHookInterface)
 	CallPostHook(result *ending.ModuleResult) error
 }
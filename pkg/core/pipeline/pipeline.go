@@ -50,11 +50,13 @@ type Pipeline struct {
 	SpecHosts       int
 	PipelineCache   *cache.Cache
 	ModuleCachePool sync.Pool
+	ModulePreHooks  []module.PreHookInterface
 	ModulePostHooks []module.PostHookInterface
 }
 
 func (p *Pipeline) Init() error {
-	fmt.Print(logo)
+	// The logo is printed to stderr like the logs, so that the output of commands can be parsed.
+	fmt.Fprint(os.Stderr, logo)
 	p.PipelineCache = cache.NewCache()
 	p.SpecHosts = len(p.Runtime.GetAllHosts())
 	//if err := p.Runtime.GenerateWorkDir(); err != nil {
@@ -80,10 +82,16 @@ func (p *Pipeline) Start() error {
 		m.Default(p.Runtime, p.PipelineCache, moduleCache)
 		m.AutoAssert()
 		m.Init()
+		for j := range p.ModulePreHooks {
+			m.AppendPreHook(p.ModulePreHooks[j])
+		}
 		for j := range p.ModulePostHooks {
 			m.AppendPostHook(p.ModulePostHooks[j])
 		}
 
+		if err := m.CallPreHook(); err != nil {
+			return errors.Wrapf(err, "Pipeline[%s] execute failed", p.Name)
+		}
 		res := p.RunModule(m)
 		err := m.CallPostHook(res)
 		if res.IsFailed() {
@@ -139,6 +139,10 @@ func (f *FetchCerts) Execute(runtime connector.Runtime) error {
 
 type GenerateCerts struct {
 	common.KubeAction
+	// Renew reissues all the certs signed by the existing etcd CA instead of reusing the valid ones.
+	Renew bool
+	// ReissueChangedSANs reissues the existing certs whose SANs differ from the hosts, e.g. after a node is replaced.
+	ReissueChangedSANs bool
 }
 
 func (g *GenerateCerts) Execute(runtime connector.Runtime) error {
@@ -166,16 +170,40 @@ func (g *GenerateCerts) Execute(runtime connector.Runtime) error {
 		}
 	}
 
+	if pki := &g.KubeConf.Cluster.Etcd.PKI; pki.Enabled() {
+		// The supplied certs are used as they are, and only the others are signed by the supplied CA.
+		unsigned, err := certs.WriteExternalPKI(pki, certsList[0], certsList[1:], pkiPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to use the etcd PKI supplied")
+		}
+		if pki.CAKeyFile == "" {
+			files = append(files[:1], files[2:]...)
+		}
+		certsList = append(certsList[:1], unsigned...)
+	}
+
 	var lastCACert *certs.KubekeyCert
 	for _, c := range certsList {
 		if c.CAName == "" {
-			err := certs.GenerateCA(c, pkiPath, g.KubeConf)
-			if err != nil {
-				return err
+			// The CA supplied has been written above.
+			if !g.KubeConf.Cluster.Etcd.PKI.Enabled() {
+				if g.Renew {
+					// A new CA would not be trusted by the running cluster, so it must be fetched from the etcd node.
+					if _, _, err := certs.LoadCertificateAuthority(pkiPath, c.BaseName); err != nil {
+						return errors.Wrap(err, "failed to load the existing etcd CA")
+					}
+				} else if err := certs.GenerateCA(c, pkiPath, g.KubeConf); err != nil {
+					return err
+				}
 			}
 			lastCACert = c
+		} else if g.Renew {
+			fmt.Printf("[certs] Renewing %s certificate\n", c.BaseName)
+			if err := certs.ReissueCertAndKeyFilesWithCA(lastCACert, c, pkiPath, g.KubeConf); err != nil {
+				return err
+			}
 		} else {
-			err := certs.GenerateCerts(c, lastCACert, pkiPath, g.KubeConf)
+			err := certs.GenerateCerts(c, lastCACert, pkiPath, g.KubeConf, g.ReissueChangedSANs)
 			if err != nil {
 				return err
 			}
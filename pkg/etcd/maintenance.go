@@ -0,0 +1,362 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+)
+
+// The etcd maintenance operations.
+const (
+	MaintenanceStatus  = "status"
+	MaintenanceMembers = "members"
+	MaintenanceDefrag  = "defrag"
+	MaintenanceCompact = "compact"
+	MaintenanceAlarm   = "alarm"
+)
+
+// MaintenanceOptions describes an etcd maintenance operation.
+type MaintenanceOptions struct {
+	Operation string
+	// Revision is the revision to compact to. The current revision is used if it is 0.
+	Revision int64
+	// Physical waits for the compaction to be physically applied to the backend database.
+	Physical bool
+	// Disarm disarms all the alarms.
+	Disarm bool
+}
+
+// defragTimeout bounds the defragmentation of a member, which blocks the reads and writes of the member.
+const defragTimeout = "5m"
+
+// endpointStatus is the output of "etcdctl endpoint status -w json" for an endpoint.
+type endpointStatus struct {
+	Endpoint string `json:"Endpoint"`
+	Status   struct {
+		Header struct {
+			MemberID uint64 `json:"member_id"`
+			Revision int64  `json:"revision"`
+			RaftTerm uint64 `json:"raft_term"`
+		} `json:"header"`
+		Version     string   `json:"version"`
+		DBSize      int64    `json:"dbSize"`
+		DBSizeInUse int64    `json:"dbSizeInUse"`
+		Leader      uint64   `json:"leader"`
+		Errors      []string `json:"errors"`
+	} `json:"Status"`
+	// Err is the error of getting the status, when the endpoint is unreachable.
+	Err error `json:"-"`
+}
+
+func (e *endpointStatus) memberID() string {
+	return fmt.Sprintf("%x", e.Status.Header.MemberID)
+}
+
+func (e *endpointStatus) isLeader() bool {
+	return e.Err == nil && e.Status.Leader == e.Status.Header.MemberID
+}
+
+// maintenanceHosts returns the host to run etcdctl on: the first etcd node for the etcd deployed by KubeKey,
+// or the first control-plane node, which has the certs of the external etcd. It returns nil if there is no such node.
+func maintenanceHosts(kubeConf *common.KubeConf, runtime connector.ModuleRuntime) []connector.Host {
+	hosts := runtime.GetHostsByRole(common.ETCD)
+	if kubeConf.Cluster.Etcd.Type == kubekeyapiv1alpha2.External {
+		hosts = runtime.GetHostsByRole(common.Master)
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+	return hosts[:1]
+}
+
+// maintenanceEndpoints returns the client endpoints of all the etcd members.
+func maintenanceEndpoints(kubeConf *common.KubeConf, runtime connector.Runtime) []string {
+	if kubeConf.Cluster.Etcd.Type == kubekeyapiv1alpha2.External {
+		return kubeConf.Cluster.Etcd.External.Endpoints
+	}
+	var endpoints []string
+	for _, host := range runtime.GetHostsByRole(common.ETCD) {
+		endpoints = append(endpoints, fmt.Sprintf("https://%s:2379", host.GetInternalAddress()))
+	}
+	return endpoints
+}
+
+// maintenanceCmd builds an etcdctl v3 command with the admin certs of the host, or with the certs of the external etcd,
+// which are synchronized to the control-plane nodes.
+func maintenanceCmd(kubeConf *common.KubeConf, host connector.Host, endpoints []string, args string) string {
+	if kubeConf.Cluster.Etcd.Type != kubekeyapiv1alpha2.External {
+		return etcdctlCmd(host, strings.Join(endpoints, ","), args)
+	}
+
+	external := kubeConf.Cluster.Etcd.External
+	env := "export ETCDCTL_API=3;"
+	for _, v := range [][2]string{
+		{"ETCDCTL_CACERT", external.CAFile},
+		{"ETCDCTL_CERT", external.CertFile},
+		{"ETCDCTL_KEY", external.KeyFile},
+	} {
+		if v[1] != "" {
+			env += fmt.Sprintf("export %s='%s';", v[0], filepath.Join(common.ETCDCertDir, filepath.Base(v[1])))
+		}
+	}
+	return fmt.Sprintf("%s%s/etcdctl --endpoints=%s %s", env, common.BinDir, strings.Join(endpoints, ","), args)
+}
+
+// getEndpointStatus gets the status of the endpoints one by one, so that an unreachable member does not hide the others.
+func getEndpointStatus(kubeConf *common.KubeConf, runtime connector.Runtime, endpoints []string) []*endpointStatus {
+	statuses := make([]*endpointStatus, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		out, err := runtime.GetRunner().SudoCmd(
+			maintenanceCmd(kubeConf, runtime.RemoteHost(), []string{endpoint}, "endpoint status -w json --command-timeout=10s"), false)
+		statuses = append(statuses, parseEndpointStatus(endpoint, out, err))
+	}
+	return statuses
+}
+
+func parseEndpointStatus(endpoint, out string, err error) *endpointStatus {
+	if err != nil {
+		return &endpointStatus{Endpoint: endpoint, Err: err}
+	}
+	// The output may contain the warnings of etcdctl before the json.
+	if i := strings.LastIndex(out, "\n["); i >= 0 {
+		out = out[i+1:]
+	}
+	var statuses []*endpointStatus
+	if err := json.Unmarshal([]byte(out), &statuses); err != nil || len(statuses) == 0 {
+		return &endpointStatus{Endpoint: endpoint, Err: errors.Errorf("unexpected endpoint status: %s", out)}
+	}
+	return statuses[0]
+}
+
+// defragOrder sorts the members to defragment, the followers first and the leader last,
+// so that the leader is blocked only once and after all the followers succeed.
+func defragOrder(statuses []*endpointStatus) []*endpointStatus {
+	ordered := make([]*endpointStatus, len(statuses))
+	copy(ordered, statuses)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return !ordered[i].isLeader() && ordered[j].isLeader()
+	})
+	return ordered
+}
+
+// formatSize formats the size in bytes like etcdctl, e.g. 25 MB.
+func formatSize(size int64) string {
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "kMGTPE"[exp])
+}
+
+func printEndpointStatus(statuses []*endpointStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ENDPOINT\tID\tVERSION\tDB SIZE\tDB SIZE IN USE\tIS LEADER\tRAFT TERM\tREVISION\tERRORS")
+	for _, s := range statuses {
+		if s.Err != nil {
+			_, _ = fmt.Fprintf(w, "%s\t\t\t\t\t\t\t\t%s\n", s.Endpoint, "unreachable")
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t%d\t%d\t%s\n",
+			s.Endpoint,
+			s.memberID(),
+			s.Status.Version,
+			formatSize(s.Status.DBSize),
+			formatSize(s.Status.DBSizeInUse),
+			s.isLeader(),
+			s.Status.Header.RaftTerm,
+			s.Status.Header.Revision,
+			strings.Join(s.Status.Errors, ", "),
+		)
+	}
+	_ = w.Flush()
+}
+
+// listAlarms prints the alarms of the etcd cluster, and returns true if there is any.
+func listAlarms(kubeConf *common.KubeConf, runtime connector.Runtime, endpoints []string) (bool, error) {
+	out, err := runtime.GetRunner().SudoCmd(maintenanceCmd(kubeConf, runtime.RemoteHost(), endpoints, "alarm list"), false)
+	if err != nil {
+		return false, errors.Wrap(errors.WithStack(err), "list etcd alarms failed")
+	}
+	out = strings.TrimSpace(out)
+	if out == "" {
+		fmt.Println("No etcd alarms.")
+		return false, nil
+	}
+	fmt.Printf("etcd alarms:\n%s\n", out)
+	return true, nil
+}
+
+type EtcdStatus struct {
+	common.KubeAction
+}
+
+// Execute prints the status and the alarms of all the etcd members.
+func (e *EtcdStatus) Execute(runtime connector.Runtime) error {
+	endpoints := maintenanceEndpoints(e.KubeConf, runtime)
+	statuses := getEndpointStatus(e.KubeConf, runtime, endpoints)
+	printEndpointStatus(statuses)
+	fmt.Println()
+
+	if _, err := listAlarms(e.KubeConf, runtime, endpoints); err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		if s.Err != nil {
+			return errors.Wrapf(s.Err, "etcd member %s is unreachable", s.Endpoint)
+		}
+	}
+	return nil
+}
+
+type ListMembers struct {
+	common.KubeAction
+}
+
+func (l *ListMembers) Execute(runtime connector.Runtime) error {
+	endpoints := maintenanceEndpoints(l.KubeConf, runtime)
+	out, err := runtime.GetRunner().SudoCmd(maintenanceCmd(l.KubeConf, runtime.RemoteHost(), endpoints, "member list"), false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "list etcd members failed")
+	}
+
+	leaders := make(map[string]bool)
+	for _, s := range getEndpointStatus(l.KubeConf, runtime, endpoints) {
+		if s.isLeader() {
+			leaders[s.memberID()] = true
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tNAME\tPEER ADDRS\tCLIENT ADDRS\tIS LEADER")
+	for _, m := range parseMemberList(out) {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", m.ID, m.Name, m.PeerURL, m.ClientURL, leaders[m.ID])
+	}
+	_ = w.Flush()
+	return nil
+}
+
+type Defrag struct {
+	common.KubeAction
+}
+
+// Execute defragments the etcd members one by one, the followers first and the leader last.
+// A member is defragmented only after the previous one is healthy again.
+func (d *Defrag) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	statuses := getEndpointStatus(d.KubeConf, runtime, maintenanceEndpoints(d.KubeConf, runtime))
+	for _, s := range statuses {
+		if s.Err != nil {
+			return errors.Wrapf(s.Err, "etcd member %s is unreachable, all the members must be healthy to defragment", s.Endpoint)
+		}
+	}
+
+	var before, after int64
+	for _, s := range defragOrder(statuses) {
+		endpoint := []string{s.Endpoint}
+		fmt.Printf("Defragmenting etcd member %s (leader: %t) ...\n", s.Endpoint, s.isLeader())
+		if _, err := runtime.GetRunner().SudoCmd(
+			maintenanceCmd(d.KubeConf, host, endpoint, fmt.Sprintf("defrag --command-timeout=%s", defragTimeout)), false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "defragment etcd member %s failed", s.Endpoint)
+		}
+		if !waitForHealthy(runtime, maintenanceCmd(d.KubeConf, host, endpoint, "endpoint health --command-timeout=5s")) {
+			return errors.Errorf("etcd member %s is not healthy after defragmentation", s.Endpoint)
+		}
+
+		defragged := getEndpointStatus(d.KubeConf, runtime, endpoint)[0]
+		if defragged.Err != nil {
+			return errors.Wrapf(defragged.Err, "get the status of etcd member %s failed", s.Endpoint)
+		}
+		fmt.Printf("DB size of %s: %s -> %s\n", s.Endpoint, formatSize(s.Status.DBSize), formatSize(defragged.Status.DBSize))
+		before += s.Status.DBSize
+		after += defragged.Status.DBSize
+	}
+	fmt.Printf("Total DB size of the etcd members: %s -> %s\n\n", formatSize(before), formatSize(after))
+
+	hasAlarms, err := listAlarms(d.KubeConf, runtime, maintenanceEndpoints(d.KubeConf, runtime))
+	if err != nil {
+		return err
+	}
+	if hasAlarms {
+		fmt.Println("The alarms are not disarmed by defragmentation. Run 'kk etcd alarm --disarm' after the cause is resolved.")
+	}
+	return nil
+}
+
+type Compact struct {
+	common.KubeAction
+	Revision int64
+	Physical bool
+}
+
+// Execute compacts the keyspace of etcd to the revision, or to the current revision if it is not set.
+func (c *Compact) Execute(runtime connector.Runtime) error {
+	endpoints := maintenanceEndpoints(c.KubeConf, runtime)
+	revision := c.Revision
+	if revision == 0 {
+		for _, s := range getEndpointStatus(c.KubeConf, runtime, endpoints) {
+			if s.Err == nil && s.Status.Header.Revision > revision {
+				revision = s.Status.Header.Revision
+			}
+		}
+		if revision == 0 {
+			return errors.New("get the current revision of etcd failed, all the members are unreachable")
+		}
+	}
+
+	args := fmt.Sprintf("compact %d", revision)
+	if c.Physical {
+		args += " --physical"
+	}
+	if _, err := runtime.GetRunner().SudoCmd(maintenanceCmd(c.KubeConf, runtime.RemoteHost(), endpoints, args), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "compact etcd to revision %d failed", revision)
+	}
+	fmt.Printf("Compacted the etcd keyspace to revision %d. Run 'kk etcd defrag' to release the free space.\n", revision)
+	return nil
+}
+
+type Alarm struct {
+	common.KubeAction
+	Disarm bool
+}
+
+func (a *Alarm) Execute(runtime connector.Runtime) error {
+	endpoints := maintenanceEndpoints(a.KubeConf, runtime)
+	if a.Disarm {
+		if _, err := runtime.GetRunner().SudoCmd(maintenanceCmd(a.KubeConf, runtime.RemoteHost(), endpoints, "alarm disarm"), false); err != nil {
+			return errors.Wrap(errors.WithStack(err), "disarm etcd alarms failed")
+		}
+	}
+	_, err := listAlarms(a.KubeConf, runtime, endpoints)
+	return err
+}
@@ -0,0 +1,106 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"errors"
+	"testing"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+)
+
+func TestParseEndpointStatus(t *testing.T) {
+	out := `{"level":"warn","msg":"retrying of unary invoker failed"}` + "\r\n" +
+		`[{"Endpoint":"https://192.168.0.2:2379","Status":{"header":{"cluster_id":1,"member_id":10276657743932975437,"revision":1024,"raft_term":5},` +
+		`"version":"3.4.13","dbSize":25165824,"leader":10276657743932975437,"raftIndex":2048,"raftTerm":5,"dbSizeInUse":8388608}}]`
+	s := parseEndpointStatus("https://192.168.0.2:2379", out, nil)
+	if s.Err != nil {
+		t.Fatalf("parseEndpointStatus() error = %v", s.Err)
+	}
+	if s.memberID() != "8e9e05c52164694d" || !s.isLeader() || s.Status.Header.Revision != 1024 || s.Status.DBSize != 25165824 {
+		t.Errorf("parseEndpointStatus() = %+v", s)
+	}
+
+	if s := parseEndpointStatus("https://192.168.0.3:2379", "", errors.New("context deadline exceeded")); s.Err == nil || s.isLeader() {
+		t.Errorf("parseEndpointStatus() = %+v, want an unreachable endpoint", s)
+	}
+	if s := parseEndpointStatus("https://192.168.0.3:2379", "Error: unexpected", nil); s.Err == nil {
+		t.Error("parseEndpointStatus() expected an error for the unexpected output")
+	}
+}
+
+func TestDefragOrder(t *testing.T) {
+	newStatus := func(endpoint string, id, leader uint64) *endpointStatus {
+		s := &endpointStatus{Endpoint: endpoint}
+		s.Status.Header.MemberID = id
+		s.Status.Leader = leader
+		return s
+	}
+	statuses := []*endpointStatus{
+		newStatus("node1", 1, 2),
+		newStatus("node2", 2, 2),
+		newStatus("node3", 3, 2),
+	}
+
+	var got []string
+	for _, s := range defragOrder(statuses) {
+		got = append(got, s.Endpoint)
+	}
+	want := []string{"node1", "node3", "node2"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("defragOrder() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		512:       "512 B",
+		25165824:  "25.2 MB",
+		2 * 1e9:   "2.0 GB",
+		8 * 1e3:   "8.0 kB",
+		999999999: "1000.0 MB",
+	}
+	for size, want := range tests {
+		if got := formatSize(size); got != want {
+			t.Errorf("formatSize(%d) = %s, want %s", size, got, want)
+		}
+	}
+}
+
+func TestMaintenanceCmdForExternal(t *testing.T) {
+	kubeConf := &common.KubeConf{Cluster: &kubekeyapiv1alpha2.ClusterSpec{}}
+	kubeConf.Cluster.Etcd.Type = kubekeyapiv1alpha2.External
+	kubeConf.Cluster.Etcd.External = kubekeyapiv1alpha2.ExternalEtcd{
+		CAFile:   "/pki/etcd/ca.crt",
+		CertFile: "/pki/etcd/etcd.crt",
+		KeyFile:  "/pki/etcd/etcd.key",
+	}
+
+	got := maintenanceCmd(kubeConf, connector.NewHost(), []string{"https://10.0.0.1:2379", "https://10.0.0.2:2379"}, "alarm list")
+	want := "export ETCDCTL_API=3;" +
+		"export ETCDCTL_CACERT='/etc/ssl/etcd/ssl/ca.crt';" +
+		"export ETCDCTL_CERT='/etc/ssl/etcd/ssl/etcd.crt';" +
+		"export ETCDCTL_KEY='/etc/ssl/etcd/ssl/etcd.key';" +
+		"/usr/local/bin/etcdctl --endpoints=https://10.0.0.1:2379,https://10.0.0.2:2379 alarm list"
+	if got != want {
+		t.Errorf("maintenanceCmd() = %s, want %s", got, want)
+	}
+}
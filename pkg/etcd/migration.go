@@ -0,0 +1,282 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+)
+
+// MigrationInitialCluster is the pipeline cache key of the initial cluster of the etcd member being added by a migration.
+// It is empty if the member has already been started.
+const MigrationInitialCluster = "etcdMigrationInitialCluster"
+
+// StackedEndpoints returns the client endpoints of the etcd members stacked on the control-plane nodes by kubeadm.
+func StackedEndpoints(runtime connector.ModuleRuntime) []string {
+	var endpoints []string
+	for _, host := range runtime.GetHostsByRole(common.Master) {
+		endpoints = append(endpoints, fmt.Sprintf("https://%s:2379", host.GetInternalAddress()))
+	}
+	return endpoints
+}
+
+// KubeKeyEndpoints returns the client endpoints of the etcd members installed by KubeKey on the etcd nodes.
+func KubeKeyEndpoints(runtime connector.ModuleRuntime) []string {
+	var endpoints []string
+	for _, host := range runtime.GetHostsByRole(common.ETCD) {
+		endpoints = append(endpoints, fmt.Sprintf("https://%s:2379", host.GetInternalAddress()))
+	}
+	return endpoints
+}
+
+// peerURLs returns the peer URLs of the etcd members of the client endpoints.
+func peerURLs(endpoints []string) map[string]bool {
+	urls := make(map[string]bool)
+	for _, endpoint := range endpoints {
+		urls[strings.TrimSuffix(endpoint, ":2379")+":2380"] = true
+	}
+	return urls
+}
+
+// initialCluster returns the initial cluster to start a member joining the etcd cluster with,
+// which consists of the started members and the member itself, as "etcdctl member add" prints.
+func initialCluster(members []etcdMember, name, peerURL string) string {
+	var peers []string
+	for _, m := range members {
+		if m.Name != "" && m.PeerURL != peerURL {
+			peers = append(peers, fmt.Sprintf("%s=%s", m.Name, m.PeerURL))
+		}
+	}
+	return strings.Join(append(peers, fmt.Sprintf("%s=%s", name, peerURL)), ",")
+}
+
+// addMember adds the member to the etcd cluster unless it is already a member, and returns the initial cluster to start it with.
+// An empty initial cluster is returned if the member has already been started.
+func addMember(runtime connector.Runtime, endpoints []string, name, peerURL string) (string, error) {
+	host := runtime.RemoteHost()
+	out, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, strings.Join(endpoints, ","), "member list"), true)
+	if err != nil {
+		return "", errors.Wrap(errors.WithStack(err), "list etcd member failed")
+	}
+	members := parseMemberList(out)
+
+	exist := false
+	for _, m := range members {
+		if m.PeerURL == peerURL {
+			if m.Name != "" {
+				return "", nil
+			}
+			exist = true
+		}
+	}
+	if !exist {
+		if _, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, strings.Join(endpoints, ","),
+			fmt.Sprintf("member add %s --peer-urls=%s", name, peerURL)), true); err != nil {
+			return "", errors.Wrapf(errors.WithStack(err), "add etcd member %s failed", name)
+		}
+	}
+	return initialCluster(members, name, peerURL), nil
+}
+
+type CheckMigration struct {
+	common.KubeAction
+	ToKubeKey bool
+}
+
+// Execute checks that the etcd of the cluster is of the type migrated from.
+func (c *CheckMigration) Execute(runtime connector.Runtime) error {
+	file, from := "/etc/etcd.env", "kubekey"
+	if c.ToKubeKey {
+		file, from = "/etc/kubernetes/manifests/etcd.yaml", "kubeadm"
+	}
+	exist, err := runtime.GetRunner().FileExist(file)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.Errorf("%s is not found on %s, the etcd of the cluster is not of the %s type", file, runtime.RemoteHost().GetName(), from)
+	}
+	return nil
+}
+
+type JoinMigrationMember struct {
+	common.KubeAction
+	// Endpoints are the client endpoints of the running etcd members.
+	Endpoints []string
+}
+
+// Execute adds the etcd node to the running etcd cluster and starts etcd on it with the existing cluster state.
+func (j *JoinMigrationMember) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	name := fmt.Sprintf("etcd-%s", host.GetName())
+	endpoint := fmt.Sprintf("https://%s:2379", host.GetInternalAddress())
+
+	cluster, err := addMember(runtime, j.Endpoints, name, fmt.Sprintf("https://%s:2380", host.GetInternalAddress()))
+	if err != nil {
+		return err
+	}
+	if cluster == "" {
+		logger.Log.Messagef(host.GetName(), "etcd member %s has joined the cluster, skip adding it", name)
+		return nil
+	}
+
+	if err := refreshConfig(runtime, j.KubeConf.Cluster.Etcd.Version, strings.Split(cluster, ","), ExistCluster, name); err != nil {
+		return err
+	}
+	if _, err := runtime.GetRunner().SudoCmd("systemctl daemon-reload && systemctl restart etcd && systemctl enable etcd", true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "start etcd failed")
+	}
+	if !waitForHealthy(runtime, etcdctlCmd(host, endpoint, "endpoint health --command-timeout=5s")) {
+		return errors.Errorf("etcd member %s is not healthy after joining the cluster", name)
+	}
+	return nil
+}
+
+type AddMigrationMember struct {
+	common.KubeAction
+	// Endpoints are the client endpoints of the running etcd members.
+	Endpoints []string
+	Name      string
+	PeerURL   string
+}
+
+// Execute adds the member to the etcd cluster, and caches the initial cluster to start it with on its node.
+func (a *AddMigrationMember) Execute(runtime connector.Runtime) error {
+	cluster, err := addMember(runtime, a.Endpoints, a.Name, a.PeerURL)
+	if err != nil {
+		return err
+	}
+	if cluster == "" {
+		logger.Log.Messagef(runtime.RemoteHost().GetName(), "etcd member %s has joined the cluster, skip adding it", a.Name)
+	}
+	a.PipelineCache.Set(MigrationInitialCluster, cluster)
+	return nil
+}
+
+type WaitMemberHealthy struct {
+	common.KubeAction
+	Endpoint string
+}
+
+func (w *WaitMemberHealthy) Execute(runtime connector.Runtime) error {
+	// The image of etcd may be pulled when the static pod is created, so it waits longer than the other health checks.
+	cmd := etcdctlCmd(runtime.RemoteHost(), w.Endpoint, "endpoint health --command-timeout=5s")
+	for i := 0; i < 5; i++ {
+		if waitForHealthy(runtime, cmd) {
+			return nil
+		}
+	}
+	return errors.Errorf("etcd member %s is not healthy after joining the cluster", w.Endpoint)
+}
+
+type MoveLeader struct {
+	common.KubeAction
+	// From and To are the client endpoints of the etcd members migrated from and to.
+	From []string
+	To   []string
+}
+
+// Execute moves the leadership of the etcd cluster to a member migrated to, so that removing the members migrated from
+// does not cause leader elections.
+func (m *MoveLeader) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	endpoints := append(append([]string{}, m.From...), m.To...)
+	statuses := getEndpointStatus(m.KubeConf, runtime, endpoints)
+	leader, ok := agreedLeader(statuses)
+	if !ok {
+		return errors.New("etcd cluster has no stable leader")
+	}
+
+	to := make(map[string]bool)
+	for _, endpoint := range m.To {
+		to[endpoint] = true
+	}
+	var leaderEndpoint, target string
+	for _, s := range statuses {
+		if s.Status.Header.MemberID == leader {
+			leaderEndpoint = s.Endpoint
+		}
+		if to[s.Endpoint] && target == "" {
+			target = s.memberID()
+		}
+	}
+	if to[leaderEndpoint] {
+		return nil
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, leaderEndpoint, "move-leader "+target), true); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "move etcd leader to %s failed", target)
+	}
+	if !waitForStableLeader(m.KubeConf, runtime, endpoints) {
+		return errors.New("etcd cluster has no stable leader after moving the leader")
+	}
+	return nil
+}
+
+type RemoveMigratedMembers struct {
+	common.KubeAction
+	// From and To are the client endpoints of the etcd members migrated from and to.
+	From []string
+	To   []string
+}
+
+// Execute removes the etcd members migrated from one by one, and waits for the remaining members to be healthy after each.
+func (r *RemoveMigratedMembers) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	endpoints := strings.Join(r.To, ",")
+	out, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, endpoints, "member list"), true)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "list etcd member failed")
+	}
+
+	from := peerURLs(r.From)
+	for _, m := range parseMemberList(out) {
+		if !from[m.PeerURL] {
+			continue
+		}
+		if _, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, endpoints, "member remove "+m.ID), true); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "remove etcd member %s failed", m.Name)
+		}
+		logger.Log.Messagef(host.GetName(), "etcd member %s is removed", m.Name)
+		if !waitForHealthy(runtime, etcdctlCmd(host, endpoints, "endpoint health --command-timeout=5s")) {
+			return errors.Errorf("etcd cluster is not healthy after removing member %s", m.Name)
+		}
+	}
+	return nil
+}
+
+type StopMigratedMember struct {
+	common.KubeAction
+}
+
+// Execute stops the etcd installed by KubeKey which has been removed from the cluster, and keeps its data and config for recovery.
+func (s *StopMigratedMember) Execute(runtime connector.Runtime) error {
+	suffix := time.Now().Format("20060102150405")
+	cmd := fmt.Sprintf("systemctl disable --now etcd && (systemctl disable --now backup-etcd.timer || true) && "+
+		"mv -f /etc/etcd.env /etc/etcd.env.migrated-%[1]s && mv -f /var/lib/etcd /var/lib/etcd.migrated-%[1]s", suffix)
+	if _, err := runtime.GetRunner().SudoCmd(cmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "stop the migrated etcd failed")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInitialCluster(t *testing.T) {
+	members := []etcdMember{
+		{ID: "8e9e05c52164694d", Name: "master1", PeerURL: "https://192.168.0.2:2380"},
+		{ID: "91bc3c398fb3c146", Name: "master2", PeerURL: "https://192.168.0.3:2380"},
+		{ID: "fd422379fda50e48", Name: "", PeerURL: "https://192.168.0.5:2380"},
+	}
+	tests := []struct {
+		name    string
+		members []etcdMember
+		member  string
+		peerURL string
+		want    string
+	}{
+		{
+			name:    "new member",
+			members: members[:2],
+			member:  "etcd-node1",
+			peerURL: "https://192.168.0.4:2380",
+			want:    "master1=https://192.168.0.2:2380,master2=https://192.168.0.3:2380,etcd-node1=https://192.168.0.4:2380",
+		},
+		{
+			name:    "unstarted members are skipped",
+			members: members,
+			member:  "etcd-node1",
+			peerURL: "https://192.168.0.4:2380",
+			want:    "master1=https://192.168.0.2:2380,master2=https://192.168.0.3:2380,etcd-node1=https://192.168.0.4:2380",
+		},
+		{
+			name:    "added but not started",
+			members: members,
+			member:  "etcd-node2",
+			peerURL: "https://192.168.0.5:2380",
+			want:    "master1=https://192.168.0.2:2380,master2=https://192.168.0.3:2380,etcd-node2=https://192.168.0.5:2380",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := initialCluster(tt.members, tt.member, tt.peerURL); got != tt.want {
+				t.Errorf("initialCluster() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeerURLs(t *testing.T) {
+	got := peerURLs([]string{"https://192.168.0.2:2379", "https://192.168.0.3:2379"})
+	want := map[string]bool{"https://192.168.0.2:2380": true, "https://192.168.0.3:2380": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("peerURLs() = %v, want %v", got, want)
+	}
+}
@@ -17,11 +17,14 @@
 package etcd
 
 import (
+	"fmt"
 	"path/filepath"
 
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/binaries"
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/action"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
 	"github.com/kubesphere/kubekey/pkg/core/task"
 	"github.com/kubesphere/kubekey/pkg/core/util"
 	"github.com/kubesphere/kubekey/pkg/etcd/templates"
@@ -56,6 +59,8 @@ func (p *PreCheckModule) Init() {
 type CertsModule struct {
 	common.KubeModule
 	Skip bool
+	// ReissueChangedSANs reissues the existing certs whose SANs differ from the hosts of the cluster.
+	ReissueChangedSANs bool
 }
 
 func (p *CertsModule) IsSkip() bool {
@@ -88,7 +93,7 @@ func CertsModuleForKubeKey(c *CertsModule) []task.Interface {
 	generateCerts := &task.LocalTask{
 		Name:   "GenerateETCDCerts",
 		Desc:   "Generate etcd Certs",
-		Action: new(GenerateCerts),
+		Action: &GenerateCerts{ReissueChangedSANs: c.ReissueChangedSANs},
 	}
 
 	syncCertsFile := &task.RemoteTask{
@@ -433,3 +438,248 @@ func (b *BackupModule) Init() {
 		enable,
 	}
 }
+
+// remainingNode returns the first etcd node which is not going to be deleted.
+func remainingNode(runtime connector.ModuleRuntime, nodeName string) []connector.Host {
+	for _, host := range runtime.GetHostsByRole(common.ETCD) {
+		if host.GetName() != nodeName {
+			return []connector.Host{host}
+		}
+	}
+	return nil
+}
+
+type QuorumCheckModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (q *QuorumCheckModule) IsSkip() bool {
+	return q.Skip
+}
+
+func (q *QuorumCheckModule) Init() {
+	q.Name = "ETCDQuorumCheckModule"
+	q.Desc = "Check etcd quorum before deleting the member"
+
+	checkQuorum := &task.RemoteTask{
+		Name:   "CheckETCDQuorum",
+		Desc:   "Check whether the etcd cluster keeps its quorum without the member",
+		Hosts:  remainingNode(q.Runtime, q.KubeConf.Arg.NodeName),
+		Action: new(CheckQuorum),
+	}
+
+	q.Tasks = []task.Interface{
+		checkQuorum,
+	}
+}
+
+type RemoveMemberModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (r *RemoveMemberModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *RemoveMemberModule) Init() {
+	r.Name = "ETCDRemoveMemberModule"
+	r.Desc = "Remove the etcd member of the deleted node"
+
+	removeMember := &task.RemoteTask{
+		Name:   "RemoveETCDMember",
+		Desc:   "Remove etcd member",
+		Hosts:  remainingNode(r.Runtime, r.KubeConf.Arg.NodeName),
+		Action: new(RemoveMember),
+		Retry:  3,
+	}
+
+	r.Tasks = []task.Interface{
+		removeMember,
+	}
+}
+
+type RefreshConfigModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (r *RefreshConfigModule) IsSkip() bool {
+	return r.Skip
+}
+
+func (r *RefreshConfigModule) Init() {
+	r.Name = "ETCDRefreshConfigModule"
+	r.Desc = "Refresh etcd.env on the existing members"
+
+	refreshConfig := &task.RemoteTask{
+		Name:     "RefreshETCDConfig",
+		Desc:     "Refresh etcd.env config on all etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   &RefreshConfig{ToExisting: true},
+		Parallel: false,
+	}
+
+	r.Tasks = []task.Interface{
+		refreshConfig,
+	}
+}
+
+type RenewCertsModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (r *RenewCertsModule) IsSkip() bool {
+	return r.Skip || r.KubeConf.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey
+}
+
+func (r *RenewCertsModule) Init() {
+	r.Name = "RenewETCDCertsModule"
+	r.Desc = "Renew ETCD cluster certs"
+
+	fetchCerts := &task.RemoteTask{
+		Name:     "FetchETCDCerts",
+		Desc:     "Fetch etcd certs",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstETCDNode),
+		Action:   new(FetchCerts),
+		Parallel: false,
+	}
+
+	renewCerts := &task.LocalTask{
+		Name:   "RenewETCDCerts",
+		Desc:   "Renew etcd certs",
+		Action: &GenerateCerts{Renew: true},
+	}
+
+	syncCertsFile := &task.RemoteTask{
+		Name:     "SyncCertsFile",
+		Desc:     "Synchronize certs file",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(SyncCertsFile),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	syncCertsToMaster := &task.RemoteTask{
+		Name:     "SyncCertsFileToMaster",
+		Desc:     "Synchronize certs file to master",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Prepare:  &common.OnlyETCD{Not: true},
+		Action:   new(SyncCertsFile),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	restart := &task.RemoteTask{
+		Name:     "RollingRestartETCD",
+		Desc:     "Restart etcd members one by one",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(RestartMember),
+		Parallel: false,
+		Retry:    1,
+	}
+
+	r.Tasks = []task.Interface{
+		fetchCerts,
+		renewCerts,
+		syncCertsFile,
+		syncCertsToMaster,
+		restart,
+	}
+}
+
+type MaintenanceModule struct {
+	common.KubeModule
+	Options MaintenanceOptions
+}
+
+func (m *MaintenanceModule) Init() {
+	m.Name = "ETCDMaintenanceModule"
+	m.Desc = fmt.Sprintf("Run etcd %s", m.Options.Operation)
+
+	var act action.Action
+	switch m.Options.Operation {
+	case MaintenanceStatus:
+		act = new(EtcdStatus)
+	case MaintenanceMembers:
+		act = new(ListMembers)
+	case MaintenanceDefrag:
+		act = new(Defrag)
+	case MaintenanceCompact:
+		act = &Compact{Revision: m.Options.Revision, Physical: m.Options.Physical}
+	case MaintenanceAlarm:
+		act = &Alarm{Disarm: m.Options.Disarm}
+	}
+
+	maintenance := &task.RemoteTask{
+		Name:   "ETCDMaintenance",
+		Desc:   fmt.Sprintf("Run etcd %s", m.Options.Operation),
+		Hosts:  maintenanceHosts(m.KubeConf, m.Runtime),
+		Action: act,
+	}
+
+	m.Tasks = []task.Interface{
+		maintenance,
+	}
+}
+
+type UpgradeModule struct {
+	common.KubeModule
+	Skip bool
+	// Version is the etcd version to upgrade to.
+	Version string
+	// IgnoreNewer skips the members newer than the version instead of failing.
+	IgnoreNewer bool
+}
+
+func (u *UpgradeModule) IsSkip() bool {
+	return u.Skip
+}
+
+func (u *UpgradeModule) Init() {
+	u.Name = "ETCDUpgradeModule"
+	u.Desc = fmt.Sprintf("Upgrade etcd to %s", u.Version)
+
+	firstNode := u.Runtime.GetHostsByRole(common.ETCD)[:1]
+
+	checkUpgrade := &task.RemoteTask{
+		Name:   "CheckETCDUpgrade",
+		Desc:   "Check whether the etcd members can be upgraded",
+		Hosts:  firstNode,
+		Action: &CheckUpgrade{Version: u.Version, IgnoreNewer: u.IgnoreNewer},
+	}
+
+	download := &task.LocalTask{
+		Name:    "DownloadEtcdBinaries",
+		Desc:    "Download etcd binaries",
+		Prepare: new(UpgradeNeededCheck),
+		Action:  &binaries.EtcdDownload{Version: u.Version},
+	}
+
+	snapshot := &task.RemoteTask{
+		Name:    "SnapshotETCD",
+		Desc:    "Save an etcd snapshot before upgrading",
+		Hosts:   firstNode,
+		Prepare: new(UpgradeNeededCheck),
+		Action:  new(SnapshotETCD),
+	}
+
+	upgradeMember := &task.RemoteTask{
+		Name:     "UpgradeETCDMember",
+		Desc:     "Upgrade etcd members one by one",
+		Hosts:    u.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(UpgradeNeededCheck),
+		Action:   &UpgradeMember{Version: u.Version},
+		Parallel: false,
+	}
+
+	u.Tasks = []task.Interface{
+		checkUpgrade,
+		download,
+		snapshot,
+		upgradeMember,
+	}
+}
@@ -58,3 +58,14 @@ func (n *NodeETCDExist) PreCheck(runtime connector.Runtime) (bool, error) {
 	}
 	return false, errors.New("get etcd node status by host label failed")
 }
+
+type UpgradeNeededCheck struct {
+	common.KubePrepare
+}
+
+func (u *UpgradeNeededCheck) PreCheck(_ connector.Runtime) (bool, error) {
+	if v, ok := u.PipelineCache.GetMustBool(UpgradeNeeded); ok {
+		return v, nil
+	}
+	return false, errors.New("get etcd upgrade status by pipeline cache failed")
+}
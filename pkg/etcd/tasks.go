@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/kubesphere/kubekey/pkg/files"
 
@@ -28,7 +29,9 @@ import (
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/action"
+	"github.com/kubesphere/kubekey/pkg/core/cache"
 	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
 	"github.com/kubesphere/kubekey/pkg/core/util"
 	"github.com/kubesphere/kubekey/pkg/etcd/templates"
 	"github.com/kubesphere/kubekey/pkg/utils"
@@ -136,11 +139,16 @@ type InstallETCDBinary struct {
 }
 
 func (g *InstallETCDBinary) Execute(runtime connector.Runtime) error {
+	return installBinary(runtime, g.PipelineCache)
+}
+
+// installBinary installs the etcd binaries downloaded for the arch of the host to /usr/local/bin.
+func installBinary(runtime connector.Runtime, pipelineCache *cache.Cache) error {
 	if err := utils.ResetTmpDir(runtime); err != nil {
 		return err
 	}
 
-	binariesMapObj, ok := g.PipelineCache.Get(common.KubeBinaries + "-" + runtime.RemoteHost().GetArch())
+	binariesMapObj, ok := pipelineCache.Get(common.KubeBinaries + "-" + runtime.RemoteHost().GetArch())
 	if !ok {
 		return errors.New("get KubeBinary by pipeline cache failed")
 	}
@@ -232,11 +240,11 @@ func (g *GenerateConfig) Execute(runtime connector.Runtime) error {
 		g.PipelineCache.Set(common.ETCDCluster, cluster)
 
 		if !cluster.clusterExist {
-			if err := refreshConfig(runtime, cluster.peerAddresses, NewCluster, etcdName); err != nil {
+			if err := refreshConfig(runtime, g.KubeConf.Cluster.Etcd.Version, cluster.peerAddresses, NewCluster, etcdName); err != nil {
 				return err
 			}
 		} else {
-			if err := refreshConfig(runtime, cluster.peerAddresses, ExistCluster, etcdName); err != nil {
+			if err := refreshConfig(runtime, g.KubeConf.Cluster.Etcd.Version, cluster.peerAddresses, ExistCluster, etcdName); err != nil {
 				return err
 			}
 		}
@@ -262,18 +270,18 @@ func (r *RefreshConfig) Execute(runtime connector.Runtime) error {
 		cluster := v.(*EtcdCluster)
 
 		if r.ToExisting {
-			if err := refreshConfig(runtime, cluster.peerAddresses, ExistCluster, etcdName); err != nil {
+			if err := refreshConfig(runtime, r.KubeConf.Cluster.Etcd.Version, cluster.peerAddresses, ExistCluster, etcdName); err != nil {
 				return err
 			}
 			return nil
 		}
 
 		if !cluster.clusterExist {
-			if err := refreshConfig(runtime, cluster.peerAddresses, NewCluster, etcdName); err != nil {
+			if err := refreshConfig(runtime, r.KubeConf.Cluster.Etcd.Version, cluster.peerAddresses, NewCluster, etcdName); err != nil {
 				return err
 			}
 		} else {
-			if err := refreshConfig(runtime, cluster.peerAddresses, ExistCluster, etcdName); err != nil {
+			if err := refreshConfig(runtime, r.KubeConf.Cluster.Etcd.Version, cluster.peerAddresses, ExistCluster, etcdName); err != nil {
 				return err
 			}
 		}
@@ -282,7 +290,7 @@ func (r *RefreshConfig) Execute(runtime connector.Runtime) error {
 	return errors.New("get etcd cluster status by pipeline cache failed")
 }
 
-func refreshConfig(runtime connector.Runtime, endpoints []string, state, etcdName string) error {
+func refreshConfig(runtime connector.Runtime, version string, endpoints []string, state, etcdName string) error {
 	host := runtime.RemoteHost()
 
 	UnsupportedArch := false
@@ -294,7 +302,7 @@ func refreshConfig(runtime connector.Runtime, endpoints []string, state, etcdNam
 		Template: templates.EtcdEnv,
 		Dst:      filepath.Join("/etc/", templates.EtcdEnv.Name()),
 		Data: util.Data{
-			"Tag":             kubekeyapiv1alpha2.DefaultEtcdVersion,
+			"Tag":             version,
 			"Name":            etcdName,
 			"Ip":              host.GetInternalAddress(),
 			"Hostname":        host.GetName(),
@@ -418,3 +426,182 @@ func (e *EnableBackupETCDService) Execute(runtime connector.Runtime) error {
 	}
 	return nil
 }
+
+// DstMemberID is the pipeline cache key of the ID of the etcd member which is going to be deleted.
+const DstMemberID = "dstETCDMemberID"
+
+type etcdMember struct {
+	ID        string
+	Name      string
+	PeerURL   string
+	ClientURL string
+}
+
+// etcdctlCmd builds an etcdctl v3 command authenticated by the admin certs of the host.
+func etcdctlCmd(host connector.Host, endpoints, args string) string {
+	return fmt.Sprintf("export ETCDCTL_API=3;"+
+		"export ETCDCTL_CERT='/etc/ssl/etcd/ssl/admin-%s.pem';"+
+		"export ETCDCTL_KEY='/etc/ssl/etcd/ssl/admin-%s-key.pem';"+
+		"export ETCDCTL_CACERT='/etc/ssl/etcd/ssl/ca.pem';"+
+		"%s/etcdctl --endpoints=%s %s",
+		host.GetName(), host.GetName(), common.BinDir, endpoints, args)
+}
+
+// parseMemberList parses the output of "etcdctl member list", e.g.
+// 8e9e05c52164694d, started, etcd-node1, https://192.168.0.2:2380, https://192.168.0.2:2379, false
+func parseMemberList(out string) []etcdMember {
+	var members []etcdMember
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ",")
+		if len(fields) < 5 {
+			continue
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		members = append(members, etcdMember{
+			ID:        fields[0],
+			Name:      fields[2],
+			PeerURL:   fields[3],
+			ClientURL: fields[4],
+		})
+	}
+	return members
+}
+
+// parseEndpointHealth parses the output of "etcdctl endpoint health" and returns the healthy endpoints.
+func parseEndpointHealth(out string) map[string]bool {
+	healthy := make(map[string]bool)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] != "is" {
+			continue
+		}
+		healthy[fields[0]] = strings.HasPrefix(fields[2], "healthy")
+	}
+	return healthy
+}
+
+// checkQuorum returns an error if removing a member from the etcd cluster would break its quorum.
+func checkQuorum(members, healthy int, memberHealthy bool) error {
+	if members <= 1 {
+		return errors.New("the etcd cluster only has one member, it can not be removed")
+	}
+	if healthy < members/2+1 {
+		return errors.Errorf("the etcd cluster has lost its quorum: %d of %d members are healthy", healthy, members)
+	}
+	remainingHealthy := healthy
+	if memberHealthy {
+		remainingHealthy--
+	}
+	if remainingHealthy < (members-1)/2+1 {
+		return errors.Errorf("removing the member would break the etcd quorum: %d of %d remaining members are healthy",
+			remainingHealthy, members-1)
+	}
+	return nil
+}
+
+type CheckQuorum struct {
+	common.KubeAction
+}
+
+func (c *CheckQuorum) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	// The node may have been removed from the runtime if it can not be connected, so it is looked up in the config.
+	var dst *kubekeyapiv1alpha2.HostCfg
+	for i := range c.KubeConf.Cluster.Hosts {
+		if c.KubeConf.Cluster.Hosts[i].Name == c.KubeConf.Arg.NodeName {
+			dst = &c.KubeConf.Cluster.Hosts[i]
+		}
+	}
+	if dst == nil {
+		return errors.Errorf("the node %s is not found in the config", c.KubeConf.Arg.NodeName)
+	}
+
+	endpoint := fmt.Sprintf("https://%s:2379", host.GetInternalAddress())
+	memberList, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, endpoint, "member list"), true)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "list etcd member failed")
+	}
+	members := parseMemberList(memberList)
+
+	var dstMember *etcdMember
+	clientURLs := make([]string, 0, len(members))
+	for i := range members {
+		clientURLs = append(clientURLs, members[i].ClientURL)
+		if members[i].Name == fmt.Sprintf("etcd-%s", dst.Name) ||
+			members[i].PeerURL == fmt.Sprintf("https://%s:2380", dst.InternalAddress) {
+			dstMember = &members[i]
+		}
+	}
+	if dstMember == nil {
+		logger.Log.Messagef(host.GetName(), "the etcd member of %s is not found, skip removing it", dst.Name)
+		return nil
+	}
+
+	// endpoint health exits with non-zero if any endpoint is unhealthy, so the output is always inspected.
+	healthOut, _ := runtime.GetRunner().SudoCmd(
+		etcdctlCmd(host, strings.Join(clientURLs, ","), "endpoint health --command-timeout=5s 2>&1 || true"), true)
+	health := parseEndpointHealth(healthOut)
+
+	healthy := 0
+	for _, m := range members {
+		if health[m.ClientURL] {
+			healthy++
+		}
+	}
+	if err := checkQuorum(len(members), healthy, health[dstMember.ClientURL]); err != nil {
+		return err
+	}
+
+	c.PipelineCache.Set(DstMemberID, dstMember.ID)
+	return nil
+}
+
+type RemoveMember struct {
+	common.KubeAction
+}
+
+func (r *RemoveMember) Execute(runtime connector.Runtime) error {
+	id, ok := r.PipelineCache.GetMustString(DstMemberID)
+	if !ok {
+		return nil
+	}
+
+	host := runtime.RemoteHost()
+	endpoint := fmt.Sprintf("https://%s:2379", host.GetInternalAddress())
+	if _, err := runtime.GetRunner().SudoCmd(etcdctlCmd(host, endpoint, fmt.Sprintf("member remove %s", id)), true); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "remove etcd member %s failed", id)
+	}
+	return nil
+}
+
+type RestartMember struct {
+	common.KubeAction
+}
+
+// Execute restarts the etcd member on the host and waits for it to be healthy again,
+// so that members are never restarted while another one is still rejoining the cluster.
+func (r *RestartMember) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("systemctl restart etcd", true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restart etcd failed")
+	}
+
+	host := runtime.RemoteHost()
+	endpoint := fmt.Sprintf("https://%s:2379", host.GetInternalAddress())
+	if !waitForHealthy(runtime, etcdctlCmd(host, endpoint, "endpoint health --command-timeout=5s")) {
+		return errors.Errorf("etcd member on %s is not healthy after restart", host.GetName())
+	}
+	return nil
+}
+
+// waitForHealthy runs the etcdctl health check command until it succeeds, for one minute at most.
+func waitForHealthy(runtime connector.Runtime, healthCmd string) bool {
+	for i := 0; i < 12; i++ {
+		if _, err := runtime.GetRunner().SudoCmd(healthCmd, false); err == nil {
+			return true
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"testing"
+)
+
+func TestCheckQuorum(t *testing.T) {
+	tests := []struct {
+		name          string
+		members       int
+		healthy       int
+		memberHealthy bool
+		wantErr       bool
+	}{
+		{name: "single member", members: 1, healthy: 1, memberHealthy: true, wantErr: true},
+		{name: "healthy member of three", members: 3, healthy: 3, memberHealthy: true},
+		{name: "failed member of three", members: 3, healthy: 2, memberHealthy: false},
+		{name: "healthy member of three with a failed one", members: 3, healthy: 2, memberHealthy: true, wantErr: true},
+		{name: "lost quorum", members: 3, healthy: 1, memberHealthy: false, wantErr: true},
+		{name: "healthy member of two", members: 2, healthy: 2, memberHealthy: true},
+		{name: "failed member of five", members: 5, healthy: 3, memberHealthy: false},
+		{name: "healthy member of five with two failed", members: 5, healthy: 3, memberHealthy: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkQuorum(tt.members, tt.healthy, tt.memberHealthy); (err != nil) != tt.wantErr {
+				t.Errorf("checkQuorum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseMemberList(t *testing.T) {
+	out := "8e9e05c52164694d, started, etcd-node1, https://192.168.0.2:2380, https://192.168.0.2:2379, false\r\n" +
+		"91bc3c398fb3c146, started, etcd-node2, https://192.168.0.3:2380, https://192.168.0.3:2379, false"
+	members := parseMemberList(out)
+	if len(members) != 2 {
+		t.Fatalf("parseMemberList() got %d members, want 2", len(members))
+	}
+	want := etcdMember{ID: "91bc3c398fb3c146", Name: "etcd-node2", PeerURL: "https://192.168.0.3:2380", ClientURL: "https://192.168.0.3:2379"}
+	if members[1] != want {
+		t.Errorf("parseMemberList() = %v, want %v", members[1], want)
+	}
+
+	health := parseEndpointHealth("https://192.168.0.2:2379 is healthy: successfully committed proposal: took = 2.1ms\r\n" +
+		"https://192.168.0.3:2379 is unhealthy: failed to commit proposal: context deadline exceeded\r\n" +
+		"Error: unhealthy cluster")
+	if !health["https://192.168.0.2:2379"] || health["https://192.168.0.3:2379"] {
+		t.Errorf("parseEndpointHealth() = %v", health)
+	}
+}
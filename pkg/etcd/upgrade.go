@@ -0,0 +1,278 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	versionutil "k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/files"
+)
+
+const (
+	// UpgradeNeeded is the pipeline cache key of whether any etcd member is older than the target version.
+	UpgradeNeeded = "etcdUpgradeNeeded"
+	// SnapshotDir is where the snapshot taken before an upgrade or a migration is saved. It is not in the backup dir of the
+	// cluster, which is pruned by the backup script.
+	SnapshotDir = "/var/backups/kube_etcd_snapshot"
+)
+
+// kubeadmEtcdVersions lists the minor version of etcd which kubeadm deploys since a Kubernetes version, newest first.
+var kubeadmEtcdVersions = []struct {
+	Kubernetes string
+	Etcd       string
+}{
+	{Kubernetes: "v1.22.0", Etcd: "v3.5.0"},
+}
+
+// RequiredVersion returns the oldest etcd version which the Kubernetes version requires,
+// or an empty string if the etcd installed by default is new enough.
+func RequiredVersion(kubeVersion string) string {
+	v, err := versionutil.ParseGeneric(kubeVersion)
+	if err != nil {
+		return ""
+	}
+	for _, r := range kubeadmEtcdVersions {
+		if v.AtLeast(versionutil.MustParseGeneric(r.Kubernetes)) {
+			return r.Etcd
+		}
+	}
+	return ""
+}
+
+// UpgradeVersion returns the version etcd is upgraded to with the Kubernetes version, or an empty string if the
+// Kubernetes version does not require a newer etcd than the configured version. The version is the latest version
+// of the required minor which is in the binary catalog for all the archs.
+func UpgradeVersion(kubeVersion, etcdVersion string, archs []string) (string, error) {
+	required := RequiredVersion(kubeVersion)
+	if required == "" {
+		return "", nil
+	}
+	min := versionutil.MustParseGeneric(required)
+	if v, err := versionutil.ParseGeneric(etcdVersion); err == nil && v.AtLeast(min) {
+		return "", nil
+	}
+
+	var latest string
+	for _, version := range files.GetCatalog().Versions("etcd", archs[0]) {
+		v, err := versionutil.ParseGeneric(version)
+		if err != nil || !v.AtLeast(min) || v.Minor() != min.Minor() || !inCatalog(version, archs) {
+			continue
+		}
+		latest = version
+	}
+	if latest == "" {
+		return "", errors.Errorf("Kubernetes %s requires etcd v%d.%d, which is not in the binary catalog, "+
+			"add it with --catalog and set etcd.version", kubeVersion, min.Major(), min.Minor())
+	}
+	return latest, nil
+}
+
+func inCatalog(version string, archs []string) bool {
+	return CheckCatalog(version, archs) == nil
+}
+
+// CheckCatalog returns an error if the etcd version is not in the binary catalog for any of the archs,
+// so that the upgrade fails before it starts instead of when the binaries are downloaded.
+func CheckCatalog(version string, archs []string) error {
+	for _, arch := range archs {
+		if files.GetCatalog().Sha256("etcd", arch, version) == "" {
+			return errors.Errorf("etcd %s for %s is not in the binary catalog, add it with --catalog", version, arch)
+		}
+	}
+	return nil
+}
+
+// checkUpgradeVersion checks whether the etcd members of the current versions can be upgraded to the target version,
+// and returns whether any of them needs to be upgraded. etcd only supports upgrading one minor version at a time and
+// does not support downgrading, newer members are ignored if ignoreNewer is true.
+func checkUpgradeVersion(current []string, target string, ignoreNewer bool) (bool, error) {
+	t, err := versionutil.ParseSemantic(target)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid etcd version %s", target)
+	}
+
+	upgrade := false
+	for _, c := range current {
+		v, err := versionutil.ParseSemantic(c)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid version %s of the etcd member", c)
+		}
+		switch {
+		case t.LessThan(v):
+			if !ignoreNewer {
+				return false, errors.Errorf("downgrading etcd from v%s to %s is not supported", v, target)
+			}
+		case v.Major() != t.Major():
+			return false, errors.Errorf("upgrading etcd from v%s to %s is not supported", v, target)
+		case t.Minor() > v.Minor()+1:
+			return false, errors.Errorf("etcd can only be upgraded one minor version at a time, upgrade it from v%s to v%d.%d first",
+				v, v.Major(), v.Minor()+1)
+		case v.LessThan(t):
+			upgrade = true
+		}
+	}
+	return upgrade, nil
+}
+
+// agreedLeader returns the leader if all the etcd members are reachable and agree on it.
+func agreedLeader(statuses []*endpointStatus) (uint64, bool) {
+	var leader uint64
+	for _, s := range statuses {
+		if s.Err != nil || s.Status.Leader == 0 || (leader != 0 && s.Status.Leader != leader) {
+			return 0, false
+		}
+		leader = s.Status.Leader
+	}
+	return leader, leader != 0
+}
+
+// waitForStableLeader waits until the etcd members agree on the same leader twice in a row, for one minute at most.
+func waitForStableLeader(kubeConf *common.KubeConf, runtime connector.Runtime, endpoints []string) bool {
+	var last uint64
+	for i := 0; i < 12; i++ {
+		leader, ok := agreedLeader(getEndpointStatus(kubeConf, runtime, endpoints))
+		if ok && leader == last {
+			return true
+		}
+		last = leader
+		time.Sleep(5 * time.Second)
+	}
+	return false
+}
+
+type CheckUpgrade struct {
+	common.KubeAction
+	Version     string
+	IgnoreNewer bool
+}
+
+func (c *CheckUpgrade) Execute(runtime connector.Runtime) error {
+	var versions []string
+	for _, s := range getEndpointStatus(c.KubeConf, runtime, maintenanceEndpoints(c.KubeConf, runtime)) {
+		if s.Err != nil {
+			return errors.Wrapf(s.Err, "etcd member %s is unreachable, all the members must be healthy before upgrading", s.Endpoint)
+		}
+		if len(s.Status.Errors) != 0 {
+			return errors.Errorf("etcd member %s has errors: %s", s.Endpoint, strings.Join(s.Status.Errors, ", "))
+		}
+		versions = append(versions, s.Status.Version)
+	}
+
+	upgrade, err := checkUpgradeVersion(versions, c.Version, c.IgnoreNewer)
+	if err != nil {
+		return err
+	}
+	if !upgrade {
+		logger.Log.Messagef(runtime.RemoteHost().GetName(), "all the etcd members are already running %s or newer, skip upgrading", c.Version)
+	}
+	c.PipelineCache.Set(UpgradeNeeded, upgrade)
+	return nil
+}
+
+type SnapshotETCD struct {
+	common.KubeAction
+	// Endpoint is the etcd member to save the snapshot from, the member on the host by default.
+	Endpoint string
+}
+
+func (s *SnapshotETCD) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s:2379", host.GetInternalAddress())
+	}
+	snapshot := filepath.Join(SnapshotDir, fmt.Sprintf("snapshot-%s.db", time.Now().Format("20060102150405")))
+
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("mkdir -p %s && %s", SnapshotDir,
+		etcdctlCmd(host, endpoint, "snapshot save "+snapshot)), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "save etcd snapshot failed")
+	}
+	logger.Log.Messagef(host.GetName(), "etcd snapshot is saved to %s", snapshot)
+	return nil
+}
+
+type UpgradeMember struct {
+	common.KubeAction
+	Version string
+}
+
+// Execute replaces the etcd binaries of the host and restarts the member, then waits for it to be healthy and for the
+// cluster to have a stable leader before the next member is upgraded. The old binaries are restored if it fails.
+func (u *UpgradeMember) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	endpoint := fmt.Sprintf("https://%s:2379", host.GetInternalAddress())
+	if s := getEndpointStatus(u.KubeConf, runtime, []string{endpoint})[0]; s.Err == nil && "v"+s.Status.Version == u.Version {
+		logger.Log.Messagef(host.GetName(), "etcd is already %s, skip upgrading it", u.Version)
+		return nil
+	}
+
+	backupCmd := fmt.Sprintf("cp -f %[1]s/etcd %[1]s/etcd.bak && cp -f %[1]s/etcdctl %[1]s/etcdctl.bak", common.BinDir)
+	if _, err := runtime.GetRunner().SudoCmd(backupCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "backup etcd binaries failed")
+	}
+
+	if err := u.upgrade(runtime, endpoint); err != nil {
+		return u.rollback(runtime, endpoint, err)
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -f %[1]s/etcd.bak %[1]s/etcdctl.bak", common.BinDir), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "remove the backup of etcd binaries failed")
+	}
+	logger.Log.Messagef(host.GetName(), "etcd is upgraded to %s", u.Version)
+	return nil
+}
+
+func (u *UpgradeMember) upgrade(runtime connector.Runtime, endpoint string) error {
+	host := runtime.RemoteHost()
+	if err := installBinary(runtime, u.PipelineCache); err != nil {
+		return err
+	}
+	if _, err := runtime.GetRunner().SudoCmd("systemctl restart etcd", true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restart etcd failed")
+	}
+	if !waitForHealthy(runtime, etcdctlCmd(host, endpoint, "endpoint health --command-timeout=5s")) {
+		return errors.New("etcd member is not healthy after restart")
+	}
+	if s := getEndpointStatus(u.KubeConf, runtime, []string{endpoint})[0]; s.Err != nil || "v"+s.Status.Version != u.Version {
+		return errors.Errorf("etcd member is not running %s after restart", u.Version)
+	}
+	if !waitForStableLeader(u.KubeConf, runtime, maintenanceEndpoints(u.KubeConf, runtime)) {
+		return errors.New("etcd cluster has no stable leader after restart")
+	}
+	return nil
+}
+
+func (u *UpgradeMember) rollback(runtime connector.Runtime, endpoint string, cause error) error {
+	host := runtime.RemoteHost()
+	restoreCmd := fmt.Sprintf("mv -f %[1]s/etcd.bak %[1]s/etcd && mv -f %[1]s/etcdctl.bak %[1]s/etcdctl && systemctl restart etcd", common.BinDir)
+	if _, err := runtime.GetRunner().SudoCmd(restoreCmd, false); err != nil {
+		return errors.Wrapf(cause, "upgrade etcd on %s failed, and rolling back the binaries failed: %v", host.GetName(), err)
+	}
+	if !waitForHealthy(runtime, etcdctlCmd(host, endpoint, "endpoint health --command-timeout=5s")) {
+		return errors.Wrapf(cause, "upgrade etcd on %s failed, the binaries are rolled back but the member is not healthy", host.GetName())
+	}
+	return errors.Wrapf(cause, "upgrade etcd on %s failed, the binaries are rolled back", host.GetName())
+}
@@ -0,0 +1,136 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubesphere/kubekey/pkg/files"
+)
+
+func TestCheckUpgradeVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     []string
+		target      string
+		ignoreNewer bool
+		upgrade     bool
+		wantErr     bool
+	}{
+		{name: "minor upgrade", current: []string{"3.4.13", "3.4.13", "3.4.13"}, target: "v3.5.4", upgrade: true},
+		{name: "patch upgrade", current: []string{"3.5.0"}, target: "v3.5.4", upgrade: true},
+		{name: "partially upgraded", current: []string{"3.5.4", "3.4.13", "3.4.13"}, target: "v3.5.4", upgrade: true},
+		{name: "already upgraded", current: []string{"3.5.4", "3.5.4"}, target: "v3.5.4", upgrade: false},
+		{name: "skip a minor", current: []string{"3.3.25"}, target: "v3.5.4", wantErr: true},
+		{name: "downgrade", current: []string{"3.5.4"}, target: "v3.4.13", wantErr: true},
+		{name: "ignore newer", current: []string{"3.5.4"}, target: "v3.4.13", ignoreNewer: true, upgrade: false},
+		{name: "major upgrade", current: []string{"3.5.4"}, target: "v4.0.0", wantErr: true},
+		{name: "invalid target", current: []string{"3.4.13"}, target: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upgrade, err := checkUpgradeVersion(tt.current, tt.target, tt.ignoreNewer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkUpgradeVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if upgrade != tt.upgrade {
+				t.Errorf("checkUpgradeVersion() = %v, want %v", upgrade, tt.upgrade)
+			}
+		})
+	}
+}
+
+func TestAgreedLeader(t *testing.T) {
+	newStatus := func(leader uint64, err error) *endpointStatus {
+		s := &endpointStatus{Err: err}
+		s.Status.Leader = leader
+		return s
+	}
+
+	if leader, ok := agreedLeader([]*endpointStatus{newStatus(2, nil), newStatus(2, nil), newStatus(2, nil)}); !ok || leader != 2 {
+		t.Errorf("agreedLeader() = %d, %v, want 2, true", leader, ok)
+	}
+	if _, ok := agreedLeader([]*endpointStatus{newStatus(2, nil), newStatus(3, nil)}); ok {
+		t.Error("agreedLeader() expected no leader when the members disagree")
+	}
+	if _, ok := agreedLeader([]*endpointStatus{newStatus(2, nil), newStatus(0, os.ErrDeadlineExceeded)}); ok {
+		t.Error("agreedLeader() expected no leader when a member is unreachable")
+	}
+	if _, ok := agreedLeader([]*endpointStatus{newStatus(0, nil)}); ok {
+		t.Error("agreedLeader() expected no leader during an election")
+	}
+}
+
+func TestUpgradeVersion(t *testing.T) {
+	if v, err := UpgradeVersion("v1.21.5", "v3.4.13", []string{"amd64"}); err != nil || v != "" {
+		t.Errorf("UpgradeVersion() = %s, %v, want no upgrade without an etcd requirement", v, err)
+	}
+	if v, err := UpgradeVersion("v1.21.5", "v3.5.1", []string{"amd64"}); err != nil || v != "" {
+		t.Errorf("UpgradeVersion() = %s, %v, want no upgrade to a newer configured version", v, err)
+	}
+	if v, err := UpgradeVersion("v1.22.10", "v3.5.1", []string{"amd64"}); err != nil || v != "" {
+		t.Errorf("UpgradeVersion() = %s, %v, want no upgrade when the configured version is new enough", v, err)
+	}
+	if v, err := UpgradeVersion("v1.22.10", "v3.4.13", []string{"amd64", "arm64"}); err != nil || v != "v3.5.6" {
+		t.Errorf("UpgradeVersion() = %s, %v, want v3.5.6 of the default catalog", v, err)
+	}
+	if _, err := UpgradeVersion("v1.22.10", "v3.4.13", []string{"ppc64le"}); err == nil || !strings.Contains(err.Error(), "v3.5") {
+		t.Errorf("UpgradeVersion() error = %v, want etcd v3.5 missing in the catalog", err)
+	}
+
+	override := filepath.Join(t.TempDir(), "catalog.yaml")
+	content := `apiVersion: kubekey.kubesphere.io/v1alpha1
+kind: BinaryCatalog
+components:
+  etcd:
+    checksums:
+      amd64:
+        "v3.5.7": "7777777777777777777777777777777777777777777777777777777777777777"
+        "v3.5.9": "9999999999999999999999999999999999999999999999999999999999999999"
+        "v3.6.0": "6666666666666666666666666666666666666666666666666666666666666666"
+      arm64:
+        "v3.5.7": "7777777777777777777777777777777777777777777777777777777777777777"
+`
+	if err := os.WriteFile(override, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := files.SetCatalog(override); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = files.SetCatalog("")
+	}()
+
+	if v, err := UpgradeVersion("v1.22.10", "v3.4.13", []string{"amd64"}); err != nil || v != "v3.5.9" {
+		t.Errorf("UpgradeVersion() = %s, %v, want v3.5.9", v, err)
+	}
+	if v, err := UpgradeVersion("v1.22.10", "v3.4.13", []string{"amd64", "arm64"}); err != nil || v != "v3.5.7" {
+		t.Errorf("UpgradeVersion() = %s, %v, want v3.5.7 which is in the catalog for all the archs", v, err)
+	}
+}
+
+func TestCheckCatalog(t *testing.T) {
+	if err := CheckCatalog("v3.5.6", []string{"amd64", "arm64"}); err != nil {
+		t.Errorf("CheckCatalog() error = %v, want v3.5.6 in the default catalog", err)
+	}
+	if err := CheckCatalog("v3.5.99", []string{"amd64"}); err == nil || !strings.Contains(err.Error(), "v3.5.99") {
+		t.Errorf("CheckCatalog() error = %v, want v3.5.99 missing in the catalog", err)
+	}
+}
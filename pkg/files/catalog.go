@@ -0,0 +1,243 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package files
+
+import (
+	"bytes"
+	_ "embed"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+	versionutil "k8s.io/apimachinery/pkg/util/version"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/core/util"
+)
+
+const (
+	// CatalogEnv is the environment variable of the catalog override, which can be a file path or a http(s) URL.
+	CatalogEnv = "KKCATALOG"
+)
+
+var (
+	//go:embed catalog/catalog.yaml
+	defaultCatalog []byte
+	//go:embed catalog/schema.json
+	catalogSchema []byte
+
+	catalogMu sync.Mutex
+	catalog   *Catalog
+)
+
+// Catalog describes the download URLs and the checksums of the binaries which can be installed by KubeKey.
+type Catalog struct {
+	APIVersion string                      `json:"apiVersion"`
+	Kind       string                      `json:"kind"`
+	Components map[string]CatalogComponent `json:"components"`
+}
+
+// CatalogComponent is a downloadable binary. Checksums are keyed by arch and then by version.
+type CatalogComponent struct {
+	URL       string                       `json:"url,omitempty"`
+	Checksums map[string]map[string]string `json:"checksums,omitempty"`
+}
+
+// GetCatalog returns the catalog in use. The embedded catalog is loaded on first use,
+// merged with the override specified by the KKCATALOG environment variable.
+func GetCatalog() *Catalog {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	if catalog == nil {
+		c, err := LoadCatalog(os.Getenv(CatalogEnv))
+		if err != nil {
+			logger.Log.Fatalf("load catalog failed: %v", err)
+		}
+		catalog = c
+	}
+	return catalog
+}
+
+// SetCatalog loads the embedded catalog merged with the override source, and makes it the catalog in use.
+func SetCatalog(source string) error {
+	c, err := LoadCatalog(source)
+	if err != nil {
+		return err
+	}
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	catalog = c
+	return nil
+}
+
+// LoadCatalog parses the embedded catalog and merges the override source on top of it.
+// The source can be empty, a file path or a http(s) URL.
+func LoadCatalog(source string) (*Catalog, error) {
+	c, err := ParseCatalog(defaultCatalog)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse the embedded catalog failed")
+	}
+	if source == "" {
+		return c, nil
+	}
+
+	content, err := readCatalog(source)
+	if err != nil {
+		return nil, err
+	}
+	override, err := ParseCatalog(content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse catalog %s failed", source)
+	}
+	c.Merge(override)
+	return c, nil
+}
+
+// ParseCatalog validates the yaml or json content against the catalog schema and decodes it.
+func ParseCatalog(content []byte) (*Catalog, error) {
+	contentToJson, err := k8syaml.ToJSON(content)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to convert catalog to json")
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(catalogSchema), gojsonschema.NewBytesLoader(contentToJson))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to validate catalog")
+	}
+	if !result.Valid() {
+		msgs := make([]string, 0, len(result.Errors()))
+		for _, e := range result.Errors() {
+			msgs = append(msgs, e.String())
+		}
+		return nil, errors.Errorf("invalid catalog: %s", strings.Join(msgs, "; "))
+	}
+
+	c := &Catalog{}
+	if err := yaml.Unmarshal(contentToJson, c); err != nil {
+		return nil, errors.Wrap(err, "Failed to json unmarshal")
+	}
+	if c.Components == nil {
+		c.Components = make(map[string]CatalogComponent)
+	}
+	return c, nil
+}
+
+func readCatalog(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		content, err := ioutil.ReadFile(source)
+		if err != nil {
+			return nil, errors.Wrapf(errors.WithStack(err), "read catalog %s failed", source)
+		}
+		return content, nil
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "fetch catalog %s failed", source)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetch catalog %s failed: %s", source, resp.Status)
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "read catalog %s failed", source)
+	}
+	return content, nil
+}
+
+// Merge adds the components, URLs and checksums of the override into the catalog.
+// A value of the override replaces the existing one.
+func (c *Catalog) Merge(override *Catalog) {
+	for name, o := range override.Components {
+		component := c.Components[name]
+		if o.URL != "" {
+			component.URL = o.URL
+		}
+		if component.Checksums == nil {
+			component.Checksums = make(map[string]map[string]string)
+		}
+		for arch, versions := range o.Checksums {
+			if component.Checksums[arch] == nil {
+				component.Checksums[arch] = make(map[string]string)
+			}
+			for version, sum := range versions {
+				component.Checksums[arch][version] = sum
+			}
+		}
+		c.Components[name] = component
+	}
+}
+
+// Sha256 returns the checksum of the component, or an empty string if the version is not in the catalog.
+func (c *Catalog) Sha256(name, arch, version string) string {
+	return c.Components[name].Checksums[arch][version]
+}
+
+// URL renders the download URL template of the component.
+func (c *Catalog) URL(name, arch, version string) (string, error) {
+	component, ok := c.Components[name]
+	if !ok || component.URL == "" {
+		return "", errors.Errorf("no download URL found for %s in the catalog", name)
+	}
+
+	tmpl, err := template.New(name).Parse(component.URL)
+	if err != nil {
+		return "", errors.Wrapf(err, "parse the URL template of %s failed", name)
+	}
+	var buf bytes.Buffer
+	data := struct {
+		Version   string
+		Arch      string
+		ArchAlias string
+	}{
+		Version:   version,
+		Arch:      arch,
+		ArchAlias: util.ArchAlias(arch),
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", errors.Wrapf(err, "render the URL template of %s failed", name)
+	}
+	return buf.String(), nil
+}
+
+// Versions returns the versions of the component for the arch, sorted from the oldest to the newest.
+func (c *Catalog) Versions(name, arch string) []string {
+	versions := make([]string, 0, len(c.Components[name].Checksums[arch]))
+	for v := range c.Components[name].Checksums[arch] {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		a, errA := versionutil.ParseGeneric(versions[i])
+		b, errB := versionutil.ParseGeneric(versions[j])
+		if errA != nil || errB != nil {
+			return versions[i] < versions[j]
+		}
+		return a.LessThan(b)
+	})
+	return versions
+}
@@ -0,0 +1,85 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package files
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSha256 = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+func TestLoadCatalog(t *testing.T) {
+	override := filepath.Join(t.TempDir(), "catalog.yaml")
+	content := `apiVersion: kubekey.kubesphere.io/v1alpha1
+kind: BinaryCatalog
+components:
+  kubeadm:
+    url: 'https://mirror.example.com/{{ .Version }}/{{ .ArchAlias }}/kubeadm'
+    checksums:
+      amd64:
+        "v1.99.0": ` + testSha256 + `
+`
+	if err := ioutil.WriteFile(override, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadCatalog(override)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Sha256(kubeadm, "amd64", "v1.99.0"); got != testSha256 {
+		t.Errorf("Sha256() = %s, want %s", got, testSha256)
+	}
+	if got := c.Sha256(kubeadm, "amd64", "v1.21.5"); got == "" {
+		t.Errorf("Sha256() of an embedded version is empty")
+	}
+	versions := c.Versions(kubeadm, "amd64")
+	if versions[0] != "v1.15.12" || versions[len(versions)-1] != "v1.99.0" {
+		t.Errorf("Versions() = %v, want sorted from v1.15.12 to v1.99.0", versions)
+	}
+	url, err := c.URL(kubeadm, "amd64", "v1.99.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://mirror.example.com/v1.99.0/x86_64/kubeadm" {
+		t.Errorf("URL() = %s", url)
+	}
+	url, err = c.URL(k3s, "arm64", "v1.21.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://github.com/k3s-io/k3s/releases/download/v1.21.4+k3s1/k3s-arm64" {
+		t.Errorf("URL() = %s", url)
+	}
+}
+
+func TestParseCatalogInvalid(t *testing.T) {
+	_, err := ParseCatalog([]byte(`apiVersion: kubekey.kubesphere.io/v1alpha1
+kind: BinaryCatalog
+components:
+  kubeadm:
+    checksums:
+      amd64:
+        "v1.99.0": not-a-sha256
+`))
+	if err == nil || !strings.Contains(err.Error(), "invalid catalog") {
+		t.Errorf("ParseCatalog() error = %v, want invalid catalog", err)
+	}
+}
@@ -0,0 +1,353 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package files
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vbauerster/mpb/v7"
+	"github.com/vbauerster/mpb/v7/decor"
+
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/core/util"
+)
+
+const (
+	DefaultDownloadRetry = 3
+
+	partSuffix = ".part"
+)
+
+// Downloader downloads the content of a URL to a local path.
+type Downloader interface {
+	Download(url, path string) error
+}
+
+// DownloadOptions holds the options of the download backend.
+type DownloadOptions struct {
+	// Command is a user defined command to download files, e.g. "curl -L -o %s %s".
+	// The first param is the output path and the second param is the URL.
+	// The native HTTP client is used if it is empty.
+	Command string
+	// Proxy is the URL of a proxy server. The HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables are used if it is empty.
+	Proxy string
+	// CAFile is the path of a PEM encoded CA bundle trusted besides the system CAs.
+	CAFile             string
+	InsecureSkipVerify bool
+	Retry              int
+	// Mirrors are the URL rewrite rules in the format of "prefix=replacement".
+	Mirrors []string
+}
+
+// Mirror rewrites the URLs which match the Pattern with the Replacement.
+// The Replacement can be a http(s) URL, a file:// URL or a local directory.
+type Mirror struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// NewPrefixMirror returns a mirror replacing the URL prefix, e.g. https://github.com -> https://mirror.example.com/github.com
+func NewPrefixMirror(prefix, replacement string) Mirror {
+	return Mirror{Pattern: regexp.MustCompile("^" + regexp.QuoteMeta(prefix)), Replacement: replacement}
+}
+
+// ZoneMirrors are the built-in mirrors selected by the KKZONE environment variable.
+var ZoneMirrors = map[string][]Mirror{
+	"cn": {
+		NewPrefixMirror("https://github.com/coreos/etcd/releases/download/", "https://kubernetes-release.pek3b.qingstor.com/etcd/release/download/"),
+		NewPrefixMirror("https://storage.googleapis.com/kubernetes-release/release/", "https://kubernetes-release.pek3b.qingstor.com/release/"),
+		NewPrefixMirror("https://github.com/containernetworking/plugins/releases/download/", "https://containernetworking.pek3b.qingstor.com/plugins/releases/download/"),
+		{
+			Pattern:     regexp.MustCompile(`^https://get\.helm\.sh/helm-(v[^-]+)-linux-([^.]+)\.tar\.gz$`),
+			Replacement: "https://kubernetes-helm.pek3b.qingstor.com/linux-$2/$1/helm",
+		},
+		NewPrefixMirror("https://download.docker.com/linux/static/stable/", "https://mirrors.aliyun.com/docker-ce/linux/static/stable/"),
+		NewPrefixMirror("https://github.com/kubernetes-sigs/cri-tools/releases/download/", "https://kubernetes-release.pek3b.qingstor.com/cri-tools/releases/download/"),
+		{
+			Pattern:     regexp.MustCompile(`^https://github\.com/k3s-io/k3s/releases/download/([^/]+)/k3s$`),
+			Replacement: "https://kubernetes-release.pek3b.qingstor.com/k3s/releases/download/$1/linux/amd64/k3s",
+		},
+		{
+			Pattern:     regexp.MustCompile(`^https://github\.com/k3s-io/k3s/releases/download/([^/]+)/k3s-([^/]+)$`),
+			Replacement: "https://kubernetes-release.pek3b.qingstor.com/k3s/releases/download/$1/linux/$2/k3s",
+		},
+		{
+			Pattern:     regexp.MustCompile(`^https://github\.com/kubesphere/kubekey/releases/download/[^/]+/registry-([^-]+)-linux-(.+)$`),
+			Replacement: "https://kubernetes-release.pek3b.qingstor.com/registry/$1/registry-$1-linux-$2",
+		},
+		NewPrefixMirror("https://github.com/goharbor/harbor/releases/download/", "https://kubernetes-release.pek3b.qingstor.com/harbor/releases/download/"),
+		NewPrefixMirror("https://github.com/docker/compose/releases/download/", "https://kubernetes-release.pek3b.qingstor.com/docker/compose/releases/download/"),
+		NewPrefixMirror("https://github.com/containerd/containerd/releases/download/", "https://kubernetes-release.pek3b.qingstor.com/containerd/containerd/releases/download/"),
+		NewPrefixMirror("https://github.com/opencontainers/runc/releases/download/", "https://kubernetes-release.pek3b.qingstor.com/opencontainers/runc/releases/download/"),
+	},
+}
+
+// ParseMirrors parses the "prefix=replacement" rules. The mirrors of the KKZONE are appended,
+// so the user defined ones take precedence.
+func ParseMirrors(rules []string) ([]Mirror, error) {
+	mirrors := make([]Mirror, 0, len(rules))
+	for _, rule := range rules {
+		arr := strings.SplitN(rule, "=", 2)
+		if len(arr) != 2 || arr[0] == "" || arr[1] == "" {
+			return nil, errors.Errorf("invalid download mirror %s, the format should be prefix=replacement", rule)
+		}
+		mirrors = append(mirrors, NewPrefixMirror(arr[0], arr[1]))
+	}
+	return append(mirrors, ZoneMirrors[os.Getenv("KKZONE")]...), nil
+}
+
+// ResolveURL returns the URL rewritten by the first matched mirror.
+func ResolveURL(rawURL string, mirrors []Mirror) string {
+	for _, m := range mirrors {
+		if m.Pattern.MatchString(rawURL) {
+			return m.Pattern.ReplaceAllString(rawURL, m.Replacement)
+		}
+	}
+	return rawURL
+}
+
+// NewDownloader returns the download backend for the options.
+func NewDownloader(o DownloadOptions) (Downloader, error) {
+	mirrors, err := ParseMirrors(o.Mirrors)
+	if err != nil {
+		return nil, err
+	}
+
+	retry := o.Retry
+	if retry <= 0 {
+		retry = DefaultDownloadRetry
+	}
+
+	if o.Command != "" {
+		return &mirrorDownloader{mirrors: mirrors, next: &CommandDownloader{Command: o.Command}}, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if o.Proxy != "" {
+		proxy, err := url.Parse(o.Proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid proxy %s", o.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: o.InsecureSkipVerify}
+	if o.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		ca, err := ioutil.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read CA file %s failed", o.CAFile)
+		}
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.Errorf("no valid certificate found in CA file %s", o.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return &mirrorDownloader{
+		mirrors: mirrors,
+		next: &HTTPDownloader{
+			Client: &http.Client{Transport: transport},
+			Retry:  retry,
+		},
+	}, nil
+}
+
+// mirrorDownloader rewrites the URL by the mirrors before downloading.
+type mirrorDownloader struct {
+	mirrors []Mirror
+	next    Downloader
+}
+
+func (m *mirrorDownloader) Download(rawURL, path string) error {
+	resolved := ResolveURL(rawURL, m.mirrors)
+	if resolved != rawURL {
+		logger.Log.Debugf("download %s from mirror %s", rawURL, resolved)
+	}
+	return m.next.Download(resolved, path)
+}
+
+// CommandDownloader downloads files by a user defined command.
+type CommandDownloader struct {
+	Command string
+}
+
+func (c *CommandDownloader) Download(rawURL, path string) error {
+	cmd := exec.Command("/bin/sh", "-c", fmt.Sprintf(c.Command, path, rawURL))
+	// Get the output in real time and print it to the terminal
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "download %s by command %s failed", rawURL, cmd.String())
+	}
+	return nil
+}
+
+// HTTPDownloader downloads files by the native HTTP client. An interrupted download is resumed by
+// an HTTP Range request. The file:// URLs and the local paths are copied from the local filesystem.
+type HTTPDownloader struct {
+	Client *http.Client
+	Retry  int
+}
+
+func (h *HTTPDownloader) Download(rawURL, path string) error {
+	if src, ok := localSource(rawURL); ok {
+		return copyLocal(src, path)
+	}
+
+	if err := util.MkFileFullPathDir(path); err != nil {
+		return err
+	}
+
+	var err error
+	for i := 0; i < h.Retry; i++ {
+		if i > 0 {
+			logger.Log.Warningf("download %s failed: %v, retrying (%d/%d)", rawURL, err, i, h.Retry-1)
+			time.Sleep(time.Duration(i) * 2 * time.Second)
+		}
+		if err = h.download(rawURL, path); err == nil {
+			return nil
+		}
+	}
+	if os.Getenv("KKZONE") != "cn" {
+		logger.Log.Warningln("Having a problem with accessing https://storage.googleapis.com? You can try again after setting environment 'export KKZONE=cn' or a --download-mirror")
+	}
+	return errors.Wrapf(err, "download %s failed", rawURL)
+}
+
+func (h *HTTPDownloader) download(rawURL, path string) error {
+	part := path + partSuffix
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "kubekey")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flag := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// the server doesn't support range requests, so download it from the beginning
+		offset = 0
+		flag |= os.O_TRUNC
+	case http.StatusPartialContent:
+		flag |= os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the part file is broken or larger than the remote file
+		_ = os.Remove(part)
+		return errors.Errorf("the range of %s is not satisfiable", rawURL)
+	default:
+		return errors.Errorf("unexpected status %s", resp.Status)
+	}
+
+	f, err := os.OpenFile(part, flag, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+
+	p := mpb.New(mpb.WithWidth(40))
+	bar := p.AddBar(total,
+		mpb.PrependDecorators(decor.Name(filepath.Base(path), decor.WCSyncSpaceR)),
+		mpb.AppendDecorators(decor.CountersKibiByte("% .2f / % .2f"), decor.Percentage(decor.WCSyncSpace)),
+	)
+	bar.SetCurrent(offset)
+	reader := bar.ProxyReader(resp.Body)
+	_, err = io.Copy(f, reader)
+	_ = reader.Close()
+	if err != nil {
+		bar.Abort(false)
+		p.Wait()
+		return err
+	}
+	bar.SetTotal(-1, true)
+	p.Wait()
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(part, path)
+}
+
+// localSource returns the local path if the URL is a file:// URL or a local path.
+func localSource(rawURL string) (string, bool) {
+	if strings.HasPrefix(rawURL, "file://") {
+		return strings.TrimPrefix(rawURL, "file://"), true
+	}
+	if strings.HasPrefix(rawURL, "/") || strings.HasPrefix(rawURL, ".") {
+		return rawURL, true
+	}
+	return "", false
+}
+
+func copyLocal(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.Wrapf(err, "open local source %s failed", src)
+	}
+	defer in.Close()
+
+	if err := util.MkFileFullPathDir(dst); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return errors.Wrapf(err, "copy %s to %s failed", src, dst)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package files
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResolveURL(t *testing.T) {
+	mirrors, err := ParseMirrors([]string{"https://github.com=https://mirror.example.com/github.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mirrors = append(mirrors, ZoneMirrors["cn"]...)
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{
+			url:  "https://github.com/coreos/etcd/releases/download/v3.4.13/etcd-v3.4.13-linux-amd64.tar.gz",
+			want: "https://mirror.example.com/github.com/coreos/etcd/releases/download/v3.4.13/etcd-v3.4.13-linux-amd64.tar.gz",
+		},
+		{
+			url:  "https://storage.googleapis.com/kubernetes-release/release/v1.21.5/bin/linux/amd64/kubeadm",
+			want: "https://kubernetes-release.pek3b.qingstor.com/release/v1.21.5/bin/linux/amd64/kubeadm",
+		},
+		{
+			url:  "https://get.helm.sh/helm-v3.6.3-linux-arm64.tar.gz",
+			want: "https://kubernetes-helm.pek3b.qingstor.com/linux-arm64/v3.6.3/helm",
+		},
+		{
+			url:  "https://example.com/foo",
+			want: "https://example.com/foo",
+		},
+	}
+	for _, tt := range tests {
+		if got := ResolveURL(tt.url, mirrors); got != tt.want {
+			t.Errorf("ResolveURL(%s) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+
+	if _, err := ParseMirrors([]string{"https://github.com"}); err == nil {
+		t.Errorf("ParseMirrors() should fail with an invalid rule")
+	}
+}
+
+func TestHTTPDownloaderResume(t *testing.T) {
+	content := bytes.Repeat([]byte("kubekey"), 1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file", time.Now(), bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	dst := filepath.Join(t.TempDir(), "file")
+	// a half downloaded file
+	if err := ioutil.WriteFile(dst+partSuffix, content[:100], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &HTTPDownloader{Client: server.Client(), Retry: 1}
+	if err := d.Download(server.URL, dst); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("Download() got %d bytes, want %d bytes", len(got), len(content))
+	}
+	if _, err := os.Stat(dst + partSuffix); !os.IsNotExist(err) {
+		t.Errorf("the part file should be removed after the download")
+	}
+}
+
+func TestHTTPDownloaderLocalSource(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := ioutil.WriteFile(src, []byte("kubekey"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &HTTPDownloader{Client: http.DefaultClient, Retry: 1}
+	dst := filepath.Join(dir, "dst")
+	if err := d.Download("file://"+src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ioutil.ReadFile(dst); string(got) != "kubekey" {
+		t.Errorf("Download() = %s, want kubekey", got)
+	}
+}
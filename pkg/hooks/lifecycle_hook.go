@@ -0,0 +1,199 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hooks
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/core/module"
+	"github.com/kubesphere/kubekey/pkg/core/task"
+)
+
+// HookDir is the directory on the nodes where the hooks are copied to.
+var HookDir = filepath.Join(common.KubeScriptDir, "hooks")
+
+// LifecyclePreHook runs the pre hooks of the module configured in the hooks of the cluster.
+type LifecyclePreHook struct {
+	module.PreHook
+}
+
+func (l *LifecyclePreHook) Try() error {
+	m := l.Module.(*module.BaseModule)
+	kubeRuntime := m.Runtime.(*common.KubeRuntime)
+	return runHooks(m, kubeRuntime.Cluster.Hooks[m.Name].Pre, kubekeyapiv1alpha2.PreHook)
+}
+
+// LifecyclePostHook runs the post hooks of the module configured in the hooks of the cluster,
+// they are not run if the module failed.
+type LifecyclePostHook struct {
+	module.PostHook
+}
+
+func (l *LifecyclePostHook) Try() error {
+	if l.Result.IsFailed() {
+		return nil
+	}
+	m := l.Module.(*module.BaseModule)
+	kubeRuntime := m.Runtime.(*common.KubeRuntime)
+	return runHooks(m, kubeRuntime.Cluster.Hooks[m.Name].Post, kubekeyapiv1alpha2.PostHook)
+}
+
+func runHooks(m *module.BaseModule, hooks []kubekeyapiv1alpha2.Hook, stage string) error {
+	for i, hook := range hooks {
+		name := hook.DisplayName(stage, i)
+		for _, local := range []string{hook.Script, hook.Binary} {
+			if local == "" {
+				continue
+			}
+			if _, err := os.Stat(local); err != nil {
+				return errors.Wrapf(err, "the %s hook %s of the module %s is not found", stage, name, m.Name)
+			}
+		}
+
+		hosts := hookHosts(m.Runtime, hook.Roles)
+		if len(hosts) == 0 {
+			continue
+		}
+
+		t := &task.RemoteTask{
+			Name:  "ExecHook",
+			Desc:  fmt.Sprintf("Exec the %s hook %s", stage, name),
+			Hosts: hosts,
+			Action: &ExecHook{
+				Hook:   hook,
+				Module: m.Name,
+				Stage:  stage,
+				Index:  i,
+			},
+			Parallel: true,
+			Retry:    1,
+		}
+		t.Init(m.Runtime.(connector.Runtime), m.ModuleCache, m.PipelineCache)
+
+		logger.Log.Infof("[%s] %s", m.Name, t.GetDesc())
+		res := t.Execute()
+		for _, ac := range res.ActionResults {
+			logger.Log.Infof("%s: [%s]", ac.Status.String(), ac.Host.GetName())
+		}
+		if res.IsFailed() {
+			if hook.Fatal {
+				return errors.Wrapf(res.CombineErr(), "the %s hook %s of the module %s failed", stage, name, m.Name)
+			}
+			logger.Log.Warningf("the %s hook %s of the module %s failed, ignore it: %v", stage, name, m.Name, res.CombineErr())
+		}
+	}
+	return nil
+}
+
+// hookHosts returns the hosts of any of the roles, or all the hosts if the roles are empty.
+func hookHosts(runtime connector.ModuleRuntime, roles []string) []connector.Host {
+	if len(roles) == 0 {
+		return runtime.GetAllHosts()
+	}
+
+	var hosts []connector.Host
+	for _, host := range runtime.GetAllHosts() {
+		for _, role := range roles {
+			if host.IsRole(role) {
+				hosts = append(hosts, host)
+				break
+			}
+		}
+	}
+	return hosts
+}
+
+type ExecHook struct {
+	common.KubeAction
+	Hook   kubekeyapiv1alpha2.Hook
+	Module string
+	Stage  string
+	Index  int
+}
+
+func (e *ExecHook) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("mkdir -p %s", HookDir), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "create the hook dir failed")
+	}
+
+	var cmd string
+	switch {
+	case e.Hook.Command != "":
+		path := filepath.Join(HookDir, fmt.Sprintf("%s-%s-%d.sh", e.Module, e.Stage, e.Index))
+		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("echo %s | base64 -d > %s",
+			base64.StdEncoding.EncodeToString([]byte(e.Hook.Command)), path), false); err != nil {
+			return errors.Wrap(errors.WithStack(err), "write the hook command failed")
+		}
+		cmd = hookCommand(e.Module, e.Stage, host, "bash "+path)
+	case e.Hook.Script != "":
+		path := filepath.Join(HookDir, fmt.Sprintf("%s-%s-%d-%s", e.Module, e.Stage, e.Index, filepath.Base(e.Hook.Script)))
+		if err := runtime.GetRunner().SudoScp(e.Hook.Script, path); err != nil {
+			return errors.Wrap(errors.WithStack(err), "copy the hook script failed")
+		}
+		cmd = hookCommand(e.Module, e.Stage, host, "bash "+path, e.Hook.Args...)
+	default:
+		path := filepath.Join(HookDir, fmt.Sprintf("%s-%s-%d-%s", e.Module, e.Stage, e.Index, filepath.Base(e.Hook.Binary)))
+		if err := runtime.GetRunner().SudoScp(e.Hook.Binary, path); err != nil {
+			return errors.Wrap(errors.WithStack(err), "copy the hook binary failed")
+		}
+		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod +x %s", path), false); err != nil {
+			return errors.Wrap(errors.WithStack(err), "chmod the hook binary failed")
+		}
+		cmd = hookCommand(e.Module, e.Stage, host, path, e.Hook.Args...)
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(cmd, true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "exec the hook failed")
+	}
+	return nil
+}
+
+// hookCommand returns the command which runs the hook with the module, the stage and the node in the environment variables.
+// The values of the variables and the args are quoted, so that they are passed to the hook as they are.
+func hookCommand(moduleName, stage string, host connector.Host, command string, args ...string) string {
+	words := []string{
+		"KUBEKEY_MODULE=" + shellQuote(moduleName),
+		"KUBEKEY_HOOK_STAGE=" + shellQuote(stage),
+		"KUBEKEY_NODE_NAME=" + shellQuote(host.GetName()),
+		"KUBEKEY_NODE_ADDRESS=" + shellQuote(host.GetInternalAddress()),
+		command,
+	}
+	for _, arg := range args {
+		words = append(words, shellQuote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// doubleQuoteEscaper escapes the characters which are special in double quotes.
+var doubleQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+
+// shellQuote quotes the word in single quotes for bash. SudoCmd runs the command in double quotes,
+// so the characters which are special in double quotes are escaped as well.
+func shellQuote(s string) string {
+	return doubleQuoteEscaper.Replace("'" + strings.ReplaceAll(s, "'", `'\''`) + "'")
+}
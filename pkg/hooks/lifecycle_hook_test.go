@@ -0,0 +1,93 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package hooks
+
+import (
+	"fmt"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+)
+
+func newHost(name, address string, roles ...string) *connector.BaseHost {
+	host := connector.NewHost()
+	host.SetName(name)
+	host.SetInternalAddress(address)
+	for _, role := range roles {
+		host.SetRole(role)
+	}
+	return host
+}
+
+func TestHookHosts(t *testing.T) {
+	runtime := &connector.BaseRuntime{}
+	runtime.SetAllHosts([]connector.Host{
+		newHost("node1", "192.168.0.1", "etcd", "master"),
+		newHost("node2", "192.168.0.2", "worker"),
+		newHost("node3", "192.168.0.3", "worker", "gpu"),
+	})
+
+	tests := []struct {
+		roles []string
+		want  []string
+	}{
+		{nil, []string{"node1", "node2", "node3"}},
+		{[]string{"worker"}, []string{"node2", "node3"}},
+		{[]string{"gpu", "master"}, []string{"node1", "node3"}},
+		{[]string{"registry"}, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, host := range hookHosts(runtime, tt.roles) {
+			got = append(got, host.GetName())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("hookHosts(%v) = %v, want %v", tt.roles, got, tt.want)
+		}
+	}
+}
+
+func TestHookCommand(t *testing.T) {
+	host := newHost("node2", "192.168.0.2", "worker")
+	got := hookCommand("JoinNodesModule", "post", host, "/usr/local/bin/kube-scripts/hooks/register", "--cmdb", "https://cmdb.example.com")
+	want := "KUBEKEY_MODULE='JoinNodesModule' KUBEKEY_HOOK_STAGE='post' KUBEKEY_NODE_NAME='node2' KUBEKEY_NODE_ADDRESS='192.168.0.2' " +
+		"/usr/local/bin/kube-scripts/hooks/register '--cmdb' 'https://cmdb.example.com'"
+	if got != want {
+		t.Errorf("hookCommand() = %s, want %s", got, want)
+	}
+}
+
+func TestHookCommandQuoting(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not found")
+	}
+	args := []string{"a b", "it's", `say "hi"`, "$HOME", "`id`", `back\slash`, "*", ""}
+	host := newHost("node2", "192.168.0.2", "worker")
+	cmd := hookCommand("JoinNodesModule", "post", host, `printf '%s\n'`, args...)
+	// run the command in double quotes as SudoCmd does, without sudo
+	out, err := exec.Command("/bin/sh", "-c", fmt.Sprintf("bash -c \"%s\"", cmd)).Output()
+	if err != nil {
+		t.Fatalf("run %s failed: %v", cmd, err)
+	}
+	want := strings.Join(args, "\n") + "\n"
+	if string(out) != want {
+		t.Errorf("hook output = %q, want %q", out, want)
+	}
+}
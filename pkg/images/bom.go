@@ -0,0 +1,220 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package images
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	versionutil "k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+)
+
+const (
+	// KubernetesComponent is the component whose version is the Kubernetes version of the cluster.
+	KubernetesComponent = "kubernetes"
+	// EtcdComponent is the component whose version defaults to the etcd version of KubeKey.
+	EtcdComponent = "etcd"
+
+	// clusterBOMCacheKey is the runtime cache key of the BOM of the cluster, which is looked up for every image of every task.
+	clusterBOMCacheKey = "clusterImageBOM"
+)
+
+var (
+	//go:embed bom/bom.yaml
+	defaultBOM []byte
+
+	bomOnce sync.Once
+	bom     *BOM
+)
+
+// BOM is the image bill-of-materials. It describes the images which may be deployed by KubeKey,
+// and how to choose their tags by the Kubernetes version and the component versions.
+type BOM struct {
+	APIVersion string              `json:"apiVersion"`
+	Kind       string              `json:"kind"`
+	Components map[string]string   `json:"components"`
+	Images     map[string]BOMImage `json:"images"`
+}
+
+// BOMImage describes an image. The tag is the version of the Component if it is set, otherwise it is chosen from Tags.
+type BOMImage struct {
+	Namespace string   `json:"namespace"`
+	Repo      string   `json:"repo"`
+	Component string   `json:"component,omitempty"`
+	Tags      []BOMTag `json:"tags,omitempty"`
+}
+
+// BOMTag is a tag candidate of an image, which matches the clusters at least of the Kubernetes version
+// and using one of the container managers. An empty condition matches all the clusters.
+type BOMTag struct {
+	Tag               string   `json:"tag"`
+	Kubernetes        string   `json:"kubernetes,omitempty"`
+	ContainerManagers []string `json:"containerManagers,omitempty"`
+}
+
+// DefaultBOM returns the image bill-of-materials embedded in KubeKey.
+func DefaultBOM() *BOM {
+	bomOnce.Do(func() {
+		b, err := ParseBOM(defaultBOM)
+		if err != nil {
+			logger.Log.Fatalf("parse the embedded image BOM failed: %v", err)
+		}
+		bom = b
+	})
+	return bom
+}
+
+// ClusterBOM returns the BOM overridden by the image BOM of the cluster config.
+// It is computed once per runtime and kept in the runtime cache of the KubeConf.
+func ClusterBOM(kubeConf *common.KubeConf) *BOM {
+	if kubeConf.Cache == nil {
+		return DefaultBOM().Override(kubeConf.Cluster.ImageBOM)
+	}
+	if b, ok := kubeConf.Cache.Get(clusterBOMCacheKey); ok {
+		return b.(*BOM)
+	}
+	b, _ := kubeConf.Cache.GetOrSet(clusterBOMCacheKey, DefaultBOM().Override(kubeConf.Cluster.ImageBOM))
+	return b.(*BOM)
+}
+
+// ParseBOM decodes the yaml content of an image bill-of-materials.
+func ParseBOM(content []byte) (*BOM, error) {
+	b := &BOM{}
+	if err := yaml.Unmarshal(content, b); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal image BOM")
+	}
+	if _, ok := b.Components[EtcdComponent]; !ok {
+		if b.Components == nil {
+			b.Components = make(map[string]string)
+		}
+		b.Components[EtcdComponent] = kubekeyv1alpha2.DefaultEtcdVersion
+	}
+	for name, image := range b.Images {
+		if image.Component == "" && len(image.Tags) == 0 {
+			return nil, errors.Errorf("image %s has neither a component nor tags", name)
+		}
+		if image.Component != "" && image.Component != KubernetesComponent {
+			if _, ok := b.Components[image.Component]; !ok {
+				return nil, errors.Errorf("the component %s of image %s is not defined", image.Component, name)
+			}
+		}
+		for _, t := range image.Tags {
+			if t.Kubernetes == "" {
+				continue
+			}
+			if _, err := versionutil.ParseSemantic(t.Kubernetes); err != nil {
+				return nil, errors.Wrapf(err, "invalid kubernetes version of image %s", name)
+			}
+		}
+	}
+	return b, nil
+}
+
+// Override returns a copy of the BOM with the component versions and the image tags of the cluster config.
+func (b *BOM) Override(o kubekeyv1alpha2.ImageBOM) *BOM {
+	out := &BOM{
+		APIVersion: b.APIVersion,
+		Kind:       b.Kind,
+		Components: make(map[string]string, len(b.Components)),
+		Images:     make(map[string]BOMImage, len(b.Images)),
+	}
+	for k, v := range b.Components {
+		out.Components[k] = v
+	}
+	for k, v := range o.Components {
+		out.Components[k] = v
+	}
+	for k, v := range b.Images {
+		out.Images[k] = v
+	}
+	for k, tag := range o.Images {
+		image, ok := out.Images[k]
+		if !ok {
+			continue
+		}
+		image.Component = ""
+		image.Tags = []BOMTag{{Tag: tag}}
+		out.Images[k] = image
+	}
+	return out
+}
+
+// Names returns the names of all the images in the BOM in order.
+func (b *BOM) Names() []string {
+	names := make([]string, 0, len(b.Images))
+	for name := range b.Images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tag returns the tag of the image for a cluster of the Kubernetes version and the container manager.
+func (b *BOM) Tag(name, kubeVersion, containerManager string) string {
+	image, ok := b.Images[name]
+	if !ok {
+		return ""
+	}
+	switch image.Component {
+	case "":
+	case KubernetesComponent:
+		return kubeVersion
+	default:
+		return b.Components[image.Component]
+	}
+
+	version, err := versionutil.ParseSemantic(kubeVersion)
+	if err != nil {
+		// fallback to the default tag
+		return image.Tags[0].Tag
+	}
+
+	var tag string
+	for _, t := range image.Tags {
+		if t.Kubernetes != "" && version.LessThan(versionutil.MustParseSemantic(t.Kubernetes)) {
+			continue
+		}
+		if len(t.ContainerManagers) != 0 && !contains(t.ContainerManagers, containerManager) {
+			continue
+		}
+		tag = t.Tag
+	}
+	return tag
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageName returns the full name of the image in docker.io, e.g. docker.io/kubesphere/pause:3.7
+func (b *BOM) ImageName(name, kubeVersion, containerManager string) string {
+	image := b.Images[name]
+	return fmt.Sprintf("docker.io/%s/%s:%s", image.Namespace, image.Repo, b.Tag(name, kubeVersion, containerManager))
+}
@@ -0,0 +1,76 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package images
+
+import (
+	"testing"
+
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/cache"
+)
+
+func TestBOMTag(t *testing.T) {
+	tests := []struct {
+		name             string
+		image            string
+		kubeVersion      string
+		containerManager string
+		override         kubekeyv1alpha2.ImageBOM
+		want             string
+	}{
+		{name: "pause of v1.20 with docker", image: "pause", kubeVersion: "v1.20.4", containerManager: "docker", want: "3.2"},
+		{name: "pause of v1.20 with containerd", image: "pause", kubeVersion: "v1.20.4", containerManager: "containerd", want: "3.4.1"},
+		{name: "pause of v1.22", image: "pause", kubeVersion: "v1.22.10", containerManager: "containerd", want: "3.5"},
+		{name: "pause of v1.24", image: "pause", kubeVersion: "v1.24.3", want: "3.7"},
+		{name: "coredns of v1.20 with containerd", image: "coredns", kubeVersion: "v1.20.4", containerManager: "containerd", want: "1.8.0"},
+		{name: "coredns of v1.23", image: "coredns", kubeVersion: "v1.23.9", want: "1.8.6"},
+		{name: "kubernetes component", image: "kube-apiserver", kubeVersion: "v1.23.9", want: "v1.23.9"},
+		{name: "etcd component", image: "etcd", kubeVersion: "v1.23.9", want: kubekeyv1alpha2.DefaultEtcdVersion},
+		{name: "default component", image: "calico-node", kubeVersion: "v1.23.9", want: kubekeyv1alpha2.DefaultCalicoVersion},
+		{name: "override component", image: "calico-node", kubeVersion: "v1.23.9",
+			override: kubekeyv1alpha2.ImageBOM{Components: map[string]string{"calico": "v3.24.0"}}, want: "v3.24.0"},
+		{name: "override image", image: "pause", kubeVersion: "v1.23.9",
+			override: kubekeyv1alpha2.ImageBOM{Images: map[string]string{"pause": "3.8"}}, want: "3.8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bom := DefaultBOM().Override(tt.override)
+			if got := bom.Tag(tt.image, tt.kubeVersion, tt.containerManager); got != tt.want {
+				t.Errorf("Tag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterBOM(t *testing.T) {
+	cluster := &kubekeyv1alpha2.ClusterSpec{ImageBOM: kubekeyv1alpha2.ImageBOM{Images: map[string]string{"pause": "3.8"}}}
+	kubeConf := &common.KubeConf{Cluster: cluster, Cache: cache.NewCache()}
+	bom := ClusterBOM(kubeConf)
+	if got := bom.Tag("pause", "v1.23.9", ""); got != "3.8" {
+		t.Errorf("Tag() = %v, want 3.8", got)
+	}
+	if ClusterBOM(&common.KubeConf{Cluster: cluster, Cache: kubeConf.Cache}) != bom {
+		t.Errorf("the BOM of the runtime is computed again")
+	}
+	if ClusterBOM(&common.KubeConf{Cluster: cluster, Cache: cache.NewCache()}) == bom {
+		t.Errorf("the BOM of another runtime is shared")
+	}
+	if got := ClusterBOM(&common.KubeConf{Cluster: cluster}).Tag("pause", "v1.23.9", ""); got != "3.8" {
+		t.Errorf("Tag() without a runtime cache = %v, want 3.8", got)
+	}
+}
@@ -71,6 +71,9 @@ type Index struct {
 }
 
 type Manifest struct {
+	MediaType   string `json:"mediaType"`
+	Digest      string `json:"digest"`
+	Size        int64  `json:"size"`
 	Annotations annotations
 }
 
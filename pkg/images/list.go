@@ -0,0 +1,65 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package images
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/kubesphere/kubekey/pkg/common"
+)
+
+// ListImages returns the images which the cluster config needs.
+func ListImages(args common.Argument) ([]Image, error) {
+	var loaderType string
+	if args.FilePath != "" {
+		loaderType = common.File
+	} else {
+		loaderType = common.AllInOne
+	}
+
+	runtime, err := common.NewKubeRuntime(loaderType, args)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeConf := &common.KubeConf{
+		ClusterName: runtime.ClusterName,
+		Cluster:     runtime.Cluster,
+		Arg:         runtime.Arg,
+		Cache:       runtime.Cache,
+	}
+	return ClusterImages(runtime, kubeConf), nil
+}
+
+// PrintImages prints the image names, or a table with the groups of the images if wide is true.
+func PrintImages(out io.Writer, images []Image, wide bool) {
+	if !wide {
+		for _, image := range images {
+			_, _ = fmt.Fprintln(out, image.ImageName())
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "IMAGE\tTAG\tGROUP")
+	for _, image := range images {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", image.ImageRepo(), image.Tag, image.Group)
+	}
+	_ = w.Flush()
+}
@@ -38,6 +38,7 @@ func (p *PullModule) Init() {
 		Name:     "PullImages",
 		Desc:     "Start to pull images on all nodes",
 		Hosts:    p.Runtime.GetAllHosts(),
+		Prepare:  new(common.OnlyNodePool),
 		Action:   new(PullImage),
 		Parallel: true,
 	}
@@ -32,7 +32,6 @@ import (
 	"github.com/kubesphere/kubekey/pkg/core/connector"
 	"github.com/kubesphere/kubekey/pkg/core/logger"
 	"github.com/pkg/errors"
-	versionutil "k8s.io/apimachinery/pkg/util/version"
 )
 
 type PullImage struct {
@@ -41,26 +40,9 @@ type PullImage struct {
 
 func (p *PullImage) Execute(runtime connector.Runtime) error {
 	if !p.KubeConf.Arg.SkipPullImages {
+		// The same images as kk images list, each host pulls the ones of its groups.
 		i := Images{}
-		i.Images = []Image{
-			GetImage(runtime, p.KubeConf, "etcd"),
-			GetImage(runtime, p.KubeConf, "pause"),
-			GetImage(runtime, p.KubeConf, "kube-apiserver"),
-			GetImage(runtime, p.KubeConf, "kube-controller-manager"),
-			GetImage(runtime, p.KubeConf, "kube-scheduler"),
-			GetImage(runtime, p.KubeConf, "kube-proxy"),
-			GetImage(runtime, p.KubeConf, "coredns"),
-			GetImage(runtime, p.KubeConf, "k8s-dns-node-cache"),
-			GetImage(runtime, p.KubeConf, "calico-kube-controllers"),
-			GetImage(runtime, p.KubeConf, "calico-cni"),
-			GetImage(runtime, p.KubeConf, "calico-node"),
-			GetImage(runtime, p.KubeConf, "calico-flexvol"),
-			GetImage(runtime, p.KubeConf, "cilium"),
-			GetImage(runtime, p.KubeConf, "cilium-operator-generic"),
-			GetImage(runtime, p.KubeConf, "flannel"),
-			GetImage(runtime, p.KubeConf, "kubeovn"),
-			GetImage(runtime, p.KubeConf, "haproxy"),
-		}
+		i.Images = ClusterImages(runtime, p.KubeConf)
 
 		if err := i.PullImages(runtime, p.KubeConf); err != nil {
 			return err
@@ -70,73 +52,84 @@ func (p *PullImage) Execute(runtime connector.Runtime) error {
 }
 
 // GetImage defines the list of all images and gets image object by name.
+// The namespaces, repos and tags of the images come from the image BOM.
 func GetImage(runtime connector.ModuleRuntime, kubeConf *common.KubeConf, name string) Image {
-	var image Image
-	pauseTag, corednsTag := "3.2", "1.6.9"
-
-	if versionutil.MustParseSemantic(kubeConf.Cluster.Kubernetes.Version).LessThan(versionutil.MustParseSemantic("v1.21.0")) {
-		pauseTag = "3.2"
-		corednsTag = "1.6.9"
-	}
-	if versionutil.MustParseSemantic(kubeConf.Cluster.Kubernetes.Version).AtLeast(versionutil.MustParseSemantic("v1.21.0")) ||
-		(kubeConf.Cluster.Kubernetes.ContainerManager != "" && kubeConf.Cluster.Kubernetes.ContainerManager != "docker") {
-		pauseTag = "3.4.1"
-		corednsTag = "1.8.0"
-	}
-	if versionutil.MustParseSemantic(kubeConf.Cluster.Kubernetes.Version).AtLeast(versionutil.MustParseSemantic("v1.22.0")) {
-		pauseTag = "3.5"
-		corednsTag = "1.8.0"
-	}
-	if versionutil.MustParseSemantic(kubeConf.Cluster.Kubernetes.Version).AtLeast(versionutil.MustParseSemantic("v1.23.0")) {
-		pauseTag = "3.6"
-		corednsTag = "1.8.6"
-	}
-	if versionutil.MustParseSemantic(kubeConf.Cluster.Kubernetes.Version).AtLeast(versionutil.MustParseSemantic("v1.24.0")) {
-		pauseTag = "3.7"
-		corednsTag = "1.8.6"
+	bom := ClusterBOM(kubeConf)
+	newImage := func(name, group string, enable bool) Image {
+		b := bom.Images[name]
+		return Image{
+			RepoAddr:  kubeConf.Cluster.Registry.PrivateRegistry,
+			Namespace: b.Namespace,
+			Repo:      b.Repo,
+			Tag:       bom.Tag(name, kubeConf.Cluster.Kubernetes.Version, kubeConf.Cluster.Kubernetes.ContainerManager),
+			Group:     group,
+			Enable:    enable,
+		}
 	}
 
-	logger.Log.Debugf("pauseTag: %s, corednsTag: %s", pauseTag, corednsTag)
+	// haproxy runs on the workers as the internal load balancer, or on the masters behind the VIP of keepalived.
+	haproxyGroup := kubekeyv1alpha2.Worker
+	if kubeConf.Cluster.ControlPlaneEndpoint.IsKeepalivedEnabled() {
+		haproxyGroup = kubekeyv1alpha2.Master
+	}
 
 	ImageList := map[string]Image{
-		"pause":                   {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: kubekeyv1alpha2.DefaultKubeImageNamespace, Repo: "pause", Tag: pauseTag, Group: kubekeyv1alpha2.K8s, Enable: true},
-		"etcd":                    {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: kubekeyv1alpha2.DefaultKubeImageNamespace, Repo: "etcd", Tag: kubekeyv1alpha2.DefaultEtcdVersion, Group: kubekeyv1alpha2.Master, Enable: strings.EqualFold(kubeConf.Cluster.Etcd.Type, kubekeyv1alpha2.Kubeadm)},
-		"kube-apiserver":          {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: kubekeyv1alpha2.DefaultKubeImageNamespace, Repo: "kube-apiserver", Tag: kubeConf.Cluster.Kubernetes.Version, Group: kubekeyv1alpha2.Master, Enable: true},
-		"kube-controller-manager": {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: kubekeyv1alpha2.DefaultKubeImageNamespace, Repo: "kube-controller-manager", Tag: kubeConf.Cluster.Kubernetes.Version, Group: kubekeyv1alpha2.Master, Enable: true},
-		"kube-scheduler":          {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: kubekeyv1alpha2.DefaultKubeImageNamespace, Repo: "kube-scheduler", Tag: kubeConf.Cluster.Kubernetes.Version, Group: kubekeyv1alpha2.Master, Enable: true},
-		"kube-proxy":              {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: kubekeyv1alpha2.DefaultKubeImageNamespace, Repo: "kube-proxy", Tag: kubeConf.Cluster.Kubernetes.Version, Group: kubekeyv1alpha2.K8s, Enable: !kubeConf.Cluster.Kubernetes.DisableKubeProxy},
+		"pause":                   newImage("pause", kubekeyv1alpha2.K8s, true),
+		"etcd":                    newImage("etcd", kubekeyv1alpha2.Master, strings.EqualFold(kubeConf.Cluster.Etcd.Type, kubekeyv1alpha2.Kubeadm)),
+		"kube-apiserver":          newImage("kube-apiserver", kubekeyv1alpha2.Master, true),
+		"kube-controller-manager": newImage("kube-controller-manager", kubekeyv1alpha2.Master, true),
+		"kube-scheduler":          newImage("kube-scheduler", kubekeyv1alpha2.Master, true),
+		"kube-proxy":              newImage("kube-proxy", kubekeyv1alpha2.K8s, !kubeConf.Cluster.Kubernetes.DisableKubeProxy),
 
 		// network
-		"coredns":                 {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: "coredns", Repo: "coredns", Tag: corednsTag, Group: kubekeyv1alpha2.K8s, Enable: true},
-		"k8s-dns-node-cache":      {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: kubekeyv1alpha2.DefaultKubeImageNamespace, Repo: "k8s-dns-node-cache", Tag: "1.15.12", Group: kubekeyv1alpha2.K8s, Enable: kubeConf.Cluster.Kubernetes.EnableNodelocaldns()},
-		"calico-kube-controllers": {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: "calico", Repo: "kube-controllers", Tag: kubekeyv1alpha2.DefaultCalicoVersion, Group: kubekeyv1alpha2.K8s, Enable: strings.EqualFold(kubeConf.Cluster.Network.Plugin, "calico")},
-		"calico-cni":              {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: "calico", Repo: "cni", Tag: kubekeyv1alpha2.DefaultCalicoVersion, Group: kubekeyv1alpha2.K8s, Enable: strings.EqualFold(kubeConf.Cluster.Network.Plugin, "calico")},
-		"calico-node":             {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: "calico", Repo: "node", Tag: kubekeyv1alpha2.DefaultCalicoVersion, Group: kubekeyv1alpha2.K8s, Enable: strings.EqualFold(kubeConf.Cluster.Network.Plugin, "calico")},
-		"calico-flexvol":          {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: "calico", Repo: "pod2daemon-flexvol", Tag: kubekeyv1alpha2.DefaultCalicoVersion, Group: kubekeyv1alpha2.K8s, Enable: strings.EqualFold(kubeConf.Cluster.Network.Plugin, "calico")},
-		"calico-typha":            {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: "calico", Repo: "typha", Tag: kubekeyv1alpha2.DefaultCalicoVersion, Group: kubekeyv1alpha2.K8s, Enable: strings.EqualFold(kubeConf.Cluster.Network.Plugin, "calico") && len(runtime.GetHostsByRole(common.K8s)) > 50},
-		"flannel":                 {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: kubekeyv1alpha2.DefaultKubeImageNamespace, Repo: "flannel", Tag: kubekeyv1alpha2.DefaultFlannelVersion, Group: kubekeyv1alpha2.K8s, Enable: strings.EqualFold(kubeConf.Cluster.Network.Plugin, "flannel")},
-		"cilium":                  {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: "cilium", Repo: "cilium", Tag: kubekeyv1alpha2.DefaultCiliumVersion, Group: kubekeyv1alpha2.K8s, Enable: strings.EqualFold(kubeConf.Cluster.Network.Plugin, "cilium")},
-		"cilium-operator-generic": {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: "cilium", Repo: "operator-generic", Tag: kubekeyv1alpha2.DefaultCiliumVersion, Group: kubekeyv1alpha2.K8s, Enable: strings.EqualFold(kubeConf.Cluster.Network.Plugin, "cilium")},
-		"kubeovn":                 {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: "kubeovn", Repo: "kube-ovn", Tag: kubekeyv1alpha2.DefaultKubeovnVersion, Group: kubekeyv1alpha2.K8s, Enable: strings.EqualFold(kubeConf.Cluster.Network.Plugin, "kubeovn")},
-		"multus":                  {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: kubekeyv1alpha2.DefaultKubeImageNamespace, Repo: "multus-cni", Tag: kubekeyv1alpha2.DefalutMultusVersion, Group: kubekeyv1alpha2.K8s, Enable: strings.Contains(kubeConf.Cluster.Network.Plugin, "multus")},
+		"coredns":                 newImage("coredns", kubekeyv1alpha2.K8s, true),
+		"k8s-dns-node-cache":      newImage("k8s-dns-node-cache", kubekeyv1alpha2.K8s, kubeConf.Cluster.Kubernetes.EnableNodelocaldns()),
+		"calico-kube-controllers": newImage("calico-kube-controllers", kubekeyv1alpha2.K8s, strings.EqualFold(kubeConf.Cluster.Network.Plugin, "calico")),
+		"calico-cni":              newImage("calico-cni", kubekeyv1alpha2.K8s, strings.EqualFold(kubeConf.Cluster.Network.Plugin, "calico")),
+		"calico-node":             newImage("calico-node", kubekeyv1alpha2.K8s, strings.EqualFold(kubeConf.Cluster.Network.Plugin, "calico")),
+		"calico-flexvol":          newImage("calico-flexvol", kubekeyv1alpha2.K8s, strings.EqualFold(kubeConf.Cluster.Network.Plugin, "calico")),
+		"calico-typha":            newImage("calico-typha", kubekeyv1alpha2.K8s, strings.EqualFold(kubeConf.Cluster.Network.Plugin, "calico") && len(runtime.GetHostsByRole(common.K8s)) > 50),
+		"flannel":                 newImage("flannel", kubekeyv1alpha2.K8s, strings.EqualFold(kubeConf.Cluster.Network.Plugin, "flannel")),
+		"cilium":                  newImage("cilium", kubekeyv1alpha2.K8s, strings.EqualFold(kubeConf.Cluster.Network.Plugin, "cilium")),
+		"cilium-operator-generic": newImage("cilium-operator-generic", kubekeyv1alpha2.K8s, strings.EqualFold(kubeConf.Cluster.Network.Plugin, "cilium")),
+		"hubble-relay":            newImage("hubble-relay", kubekeyv1alpha2.K8s, strings.EqualFold(kubeConf.Cluster.Network.Plugin, "cilium") && kubeConf.Cluster.Network.Cilium.Hubble.Relay),
+		"hubble-ui":               newImage("hubble-ui", kubekeyv1alpha2.K8s, strings.EqualFold(kubeConf.Cluster.Network.Plugin, "cilium") && kubeConf.Cluster.Network.Cilium.Hubble.UI),
+		"hubble-ui-backend":       newImage("hubble-ui-backend", kubekeyv1alpha2.K8s, strings.EqualFold(kubeConf.Cluster.Network.Plugin, "cilium") && kubeConf.Cluster.Network.Cilium.Hubble.UI),
+		"kubeovn":                 newImage("kubeovn", kubekeyv1alpha2.K8s, strings.EqualFold(kubeConf.Cluster.Network.Plugin, "kubeovn")),
+		"multus":                  newImage("multus", kubekeyv1alpha2.K8s, strings.Contains(kubeConf.Cluster.Network.Plugin, "multus")),
 		// storage
-		"provisioner-localpv": {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: "openebs", Repo: "provisioner-localpv", Tag: "3.3.0", Group: kubekeyv1alpha2.Worker, Enable: false},
-		"linux-utils":         {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: "openebs", Repo: "linux-utils", Tag: "3.3.0", Group: kubekeyv1alpha2.Worker, Enable: false},
+		"provisioner-localpv": newImage("provisioner-localpv", kubekeyv1alpha2.Worker, false),
+		"linux-utils":         newImage("linux-utils", kubekeyv1alpha2.Worker, false),
 		// load balancer
-		"haproxy": {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: "library", Repo: "haproxy", Tag: "2.3", Group: kubekeyv1alpha2.Worker, Enable: kubeConf.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
+		"haproxy":    newImage("haproxy", haproxyGroup, kubeConf.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled() || kubeConf.Cluster.ControlPlaneEndpoint.IsKeepalivedEnabled()),
+		"kube-vip":   newImage("kube-vip", kubekeyv1alpha2.Master, kubeConf.Cluster.ControlPlaneEndpoint.IsKubeVipEnabled()),
+		"keepalived": newImage("keepalived", kubekeyv1alpha2.Master, kubeConf.Cluster.ControlPlaneEndpoint.IsKeepalivedEnabled()),
 		// kata-deploy
-		"kata-deploy": {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: kubekeyv1alpha2.DefaultKubeImageNamespace, Repo: "kata-deploy", Tag: "stable", Group: kubekeyv1alpha2.Worker, Enable: kubeConf.Cluster.Kubernetes.EnableKataDeploy()},
+		"kata-deploy": newImage("kata-deploy", kubekeyv1alpha2.Worker, kubeConf.Cluster.Kubernetes.EnableKataDeploy()),
 		// node-feature-discovery
-		"node-feature-discovery": {RepoAddr: kubeConf.Cluster.Registry.PrivateRegistry, Namespace: kubekeyv1alpha2.DefaultKubeImageNamespace, Repo: "node-feature-discovery", Tag: "v0.10.0", Group: kubekeyv1alpha2.K8s, Enable: kubeConf.Cluster.Kubernetes.EnableNodeFeatureDiscovery()},
+		"node-feature-discovery": newImage("node-feature-discovery", kubekeyv1alpha2.K8s, kubeConf.Cluster.Kubernetes.EnableNodeFeatureDiscovery()),
+		// the probe of kk check cluster
+		"busybox": newImage("busybox", kubekeyv1alpha2.K8s, kubeConf.Arg.CheckCluster),
 	}
 
-	image = ImageList[name]
+	image := ImageList[name]
 	if kubeConf.Cluster.Registry.NamespaceOverride != "" {
 		image.NamespaceOverride = kubeConf.Cluster.Registry.NamespaceOverride
 	}
 	return image
 }
 
+// ClusterImages returns the images of the BOM which are enabled by the cluster config.
+func ClusterImages(runtime connector.ModuleRuntime, kubeConf *common.KubeConf) []Image {
+	images := make([]Image, 0)
+	for _, name := range ClusterBOM(kubeConf).Names() {
+		if image := GetImage(runtime, kubeConf, name); image.Enable {
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
 type SaveImages struct {
 	common.ArtifactAction
 }
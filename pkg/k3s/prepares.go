@@ -37,7 +37,8 @@ func (n *NodeInCluster) PreCheck(runtime connector.Runtime) (bool, error) {
 		}
 		_, ipOk := cluster.NodesInfo[host.GetInternalAddress()]
 		if n.Not {
-			return !(versionOk || ipOk), nil
+			// the nodes out of the selected node pool are not installed or joined
+			return !(versionOk || ipOk) && common.IsInNodePool(host, n.KubeConf.Arg.NodePool), nil
 		}
 		return versionOk || ipOk, nil
 	}
@@ -193,7 +193,12 @@ func (g *GenerateK3sService) Execute(runtime connector.Runtime) error {
 		"terminated-pod-gc-threshold": "5",
 	}, g.KubeConf.Cluster.Kubernetes.ControllerManagerArgs)
 	kubeSchedulerArgs, _ := util.GetArgs(map[string]string{}, g.KubeConf.Cluster.Kubernetes.SchedulerArgs)
-	kubeletArgs, _ := util.GetArgs(defaultKubeletArs, g.KubeConf.Cluster.Kubernetes.KubeletArgs)
+	_, clusterKubeletArgs := util.GetArgs(defaultKubeletArs, g.KubeConf.Cluster.Kubernetes.KubeletArgs)
+	var nodeKubeletArgs []string
+	for _, arg := range host.(*kubekeyapiv1alpha2.KubeHost).KubeletArgs {
+		nodeKubeletArgs = append(nodeKubeletArgs, strings.TrimLeft(arg, "-"))
+	}
+	kubeletArgs, _ := util.GetArgs(clusterKubeletArgs, nodeKubeletArgs)
 	kubeProxyArgs, _ := util.GetArgs(defaultKubeProxyArgs, g.KubeConf.Cluster.Kubernetes.KubeProxyArgs)
 
 	templateAction := action.Template{
@@ -0,0 +1,77 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+)
+
+// WaitForAPIServer waits for the local kube-apiserver to be healthy after it is restarted.
+func WaitForAPIServer(runtime connector.Runtime) error {
+	return waitForHealthy(runtime, "kube-apiserver", fmt.Sprintf(
+		"/usr/local/bin/kubectl --kubeconfig %s --server https://127.0.0.1:6443 get --raw=/healthz",
+		filepath.Join(common.KubeConfigDir, "admin.conf")))
+}
+
+// WaitForControlPlane waits for the local kube-apiserver, kube-controller-manager and kube-scheduler to be healthy
+// after they are restarted.
+func WaitForControlPlane(runtime connector.Runtime) error {
+	if err := WaitForAPIServer(runtime); err != nil {
+		return err
+	}
+	// The healthz of kube-controller-manager and kube-scheduler is served without authentication,
+	// with a self-signed cert by default.
+	for component, port := range map[string]int{"kube-controller-manager": 10257, "kube-scheduler": 10259} {
+		if err := waitForHealthy(runtime, component, fmt.Sprintf(
+			"curl -sk --connect-timeout 5 https://127.0.0.1:%d/healthz | grep -qx ok", port)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func waitForHealthy(runtime connector.Runtime, component, cmd string) error {
+	for i := 0; i < 30; i++ {
+		if _, err := runtime.GetRunner().SudoCmd(cmd, false); err == nil {
+			return nil
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return errors.Errorf("%s on %s is not healthy after restart", component, runtime.RemoteHost().GetName())
+}
+
+// RestartControlPlaneCmds returns the commands to recreate the static pods of the control-plane components with the
+// container runtime of the cluster, so that they reload their certs and configuration.
+func RestartControlPlaneCmds(containerManager string, components ...string) []string {
+	cmds := make([]string, 0, len(components))
+	for _, component := range components {
+		if containerManager == common.Docker || containerManager == "" {
+			cmds = append(cmds, fmt.Sprintf("docker ps -af name=k8s_%s* -q | xargs --no-run-if-empty docker rm -f", component))
+		} else {
+			// Removing the pod sandbox makes the kubelet recreate the static pod, it works for containerd, cri-o and isula.
+			cmds = append(cmds, fmt.Sprintf("crictl pods --namespace kube-system --name ^%s- -q | xargs --no-run-if-empty crictl rmp -f", component))
+		}
+	}
+	return cmds
+}
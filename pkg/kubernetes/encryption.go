@@ -0,0 +1,455 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/core/util"
+)
+
+const (
+	// EncryptionConfigDir is mounted into kube-apiserver by kubeadm.
+	EncryptionConfigDir  = "/etc/kubernetes/encryption"
+	EncryptionConfigFile = "/etc/kubernetes/encryption/config.yaml"
+
+	// EncryptionConfig is the pipeline cache key of the EncryptionConfiguration of the cluster.
+	EncryptionConfig = "encryptionConfig"
+	// EncryptionKey is the pipeline cache key of the key being rotated to.
+	EncryptionKey = "encryptionKey"
+
+	EncryptionAESCBC    = "aescbc"
+	EncryptionAESGCM    = "aesgcm"
+	EncryptionSecretbox = "secretbox"
+	EncryptionKMS       = "kms"
+)
+
+// EncryptionRotationStep is a step of the encryption key rotation.
+type EncryptionRotationStep int
+
+const (
+	// AddEncryptionKey adds the new key after the key in use, so that every kube-apiserver can decrypt with it
+	// before any of them encrypts with it.
+	AddEncryptionKey EncryptionRotationStep = iota
+	// PromoteEncryptionKey makes the new key the one to encrypt with.
+	PromoteEncryptionKey
+	// PruneEncryptionKeys drops the old keys after all the resources are rewritten with the new key.
+	PruneEncryptionKeys
+)
+
+func (s EncryptionRotationStep) String() string {
+	switch s {
+	case AddEncryptionKey:
+		return "add"
+	case PromoteEncryptionKey:
+		return "promote"
+	case PruneEncryptionKeys:
+		return "prune"
+	}
+	return "unknown"
+}
+
+// encryptionConfiguration is the EncryptionConfiguration of kube-apiserver.
+type encryptionConfiguration struct {
+	APIVersion string                  `json:"apiVersion"`
+	Kind       string                  `json:"kind"`
+	Resources  []resourceConfiguration `json:"resources"`
+}
+
+type resourceConfiguration struct {
+	Resources []string                `json:"resources"`
+	Providers []providerConfiguration `json:"providers"`
+}
+
+type providerConfiguration struct {
+	AESCBC    *keysConfiguration `json:"aescbc,omitempty"`
+	AESGCM    *keysConfiguration `json:"aesgcm,omitempty"`
+	Secretbox *keysConfiguration `json:"secretbox,omitempty"`
+	KMS       *kmsConfiguration  `json:"kms,omitempty"`
+	Identity  *struct{}          `json:"identity,omitempty"`
+}
+
+type keysConfiguration struct {
+	Keys []encryptionKey `json:"keys"`
+}
+
+type encryptionKey struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+}
+
+type kmsConfiguration struct {
+	Name      string `json:"name"`
+	Endpoint  string `json:"endpoint"`
+	CacheSize int32  `json:"cachesize,omitempty"`
+	Timeout   string `json:"timeout,omitempty"`
+}
+
+// keys returns the keys of the provider, or nil if the provider has no keys.
+func (p *providerConfiguration) keys() *keysConfiguration {
+	switch {
+	case p.AESCBC != nil:
+		return p.AESCBC
+	case p.AESGCM != nil:
+		return p.AESGCM
+	case p.Secretbox != nil:
+		return p.Secretbox
+	}
+	return nil
+}
+
+// is returns true if the provider is of the type.
+func (p *providerConfiguration) is(provider string) bool {
+	switch provider {
+	case EncryptionAESCBC:
+		return p.AESCBC != nil
+	case EncryptionAESGCM:
+		return p.AESGCM != nil
+	case EncryptionSecretbox:
+		return p.Secretbox != nil
+	case EncryptionKMS:
+		return p.KMS != nil
+	}
+	return false
+}
+
+// newKeysProvider returns a provider of the type with the key.
+func newKeysProvider(provider string, key encryptionKey) (providerConfiguration, error) {
+	keys := &keysConfiguration{Keys: []encryptionKey{key}}
+	switch provider {
+	case EncryptionAESCBC:
+		return providerConfiguration{AESCBC: keys}, nil
+	case EncryptionAESGCM:
+		return providerConfiguration{AESGCM: keys}, nil
+	case EncryptionSecretbox:
+		return providerConfiguration{Secretbox: keys}, nil
+	}
+	return providerConfiguration{}, errors.Errorf("unsupported encryption provider %s", provider)
+}
+
+// encryptionProvider returns the provider of the config, which defaults to aescbc.
+func encryptionProvider(cfg *kubekeyv1alpha2.EncryptionAtRest) string {
+	if cfg.Provider == "" {
+		return EncryptionAESCBC
+	}
+	return cfg.Provider
+}
+
+// newEncryptionKey returns a random 32-byte key, which is valid for aescbc, aesgcm and secretbox.
+func newEncryptionKey() (encryptionKey, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return encryptionKey{}, errors.Wrap(err, "generate encryption key failed")
+	}
+	return encryptionKey{
+		Name:   fmt.Sprintf("key-%s", time.Now().Format("20060102150405")),
+		Secret: base64.StdEncoding.EncodeToString(secret),
+	}, nil
+}
+
+// newEncryptionConfiguration returns the EncryptionConfiguration of the config, which encrypts with the key.
+// The identity provider is kept last to read the resources written before the encryption is enabled.
+func newEncryptionConfiguration(cfg *kubekeyv1alpha2.EncryptionAtRest, key encryptionKey) (*encryptionConfiguration, error) {
+	var provider providerConfiguration
+	switch encryptionProvider(cfg) {
+	case EncryptionKMS:
+		if cfg.KMS.Name == "" || !strings.HasPrefix(cfg.KMS.Endpoint, "unix://") {
+			return nil, errors.New("the name and the unix socket endpoint of the KMS plugin are required by the kms provider")
+		}
+		provider = providerConfiguration{KMS: &kmsConfiguration{
+			Name:      cfg.KMS.Name,
+			Endpoint:  cfg.KMS.Endpoint,
+			CacheSize: cfg.KMS.CacheSize,
+			Timeout:   cfg.KMS.Timeout,
+		}}
+	default:
+		p, err := newKeysProvider(encryptionProvider(cfg), key)
+		if err != nil {
+			return nil, err
+		}
+		provider = p
+	}
+
+	resources := cfg.Resources
+	if len(resources) == 0 {
+		resources = []string{"secrets"}
+	}
+	return &encryptionConfiguration{
+		APIVersion: "apiserver.config.k8s.io/v1",
+		Kind:       "EncryptionConfiguration",
+		Resources: []resourceConfiguration{{
+			Resources: resources,
+			Providers: []providerConfiguration{provider, {Identity: &struct{}{}}},
+		}},
+	}, nil
+}
+
+// rotateEncryptionConfiguration applies the step of rotating to the key of the provider to the EncryptionConfiguration.
+// The new key is added after the first provider, and then moved to the first, so the provider can be changed by the rotation.
+func rotateEncryptionConfiguration(config *encryptionConfiguration, step EncryptionRotationStep, provider string, key encryptionKey) error {
+	for i := range config.Resources {
+		r := &config.Resources[i]
+		if len(r.Providers) == 0 {
+			return errors.Errorf("no encryption provider is configured for %s", strings.Join(r.Resources, ","))
+		}
+
+		switch step {
+		case AddEncryptionKey:
+			if indexOfKey(r.Providers, key.Name) >= 0 {
+				continue
+			}
+			if r.Providers[0].is(provider) {
+				keys := r.Providers[0].keys()
+				keys.Keys = append(keys.Keys[:1], append([]encryptionKey{key}, keys.Keys[1:]...)...)
+				continue
+			}
+			p, err := newKeysProvider(provider, key)
+			if err != nil {
+				return err
+			}
+			r.Providers = append(r.Providers[:1], append([]providerConfiguration{p}, r.Providers[1:]...)...)
+		case PromoteEncryptionKey:
+			index := indexOfKey(r.Providers, key.Name)
+			if index < 0 {
+				return errors.Errorf("encryption key %s is not found", key.Name)
+			}
+			p := r.Providers[index]
+			r.Providers = append([]providerConfiguration{p}, append(r.Providers[:index:index], r.Providers[index+1:]...)...)
+			keys := p.keys()
+			for j, k := range keys.Keys {
+				if k.Name == key.Name {
+					keys.Keys = append([]encryptionKey{k}, append(keys.Keys[:j:j], keys.Keys[j+1:]...)...)
+					break
+				}
+			}
+		case PruneEncryptionKeys:
+			var providers []providerConfiguration
+			for _, p := range r.Providers {
+				keys := p.keys()
+				switch {
+				case keys == nil:
+					providers = append(providers, p)
+				case keys.Keys[0].Name == key.Name:
+					keys.Keys = keys.Keys[:1]
+					providers = append(providers, p)
+				}
+			}
+			r.Providers = providers
+		}
+	}
+	return nil
+}
+
+// indexOfKey returns the index of the provider which has the key, or -1 if it is not found.
+func indexOfKey(providers []providerConfiguration, name string) int {
+	for i := range providers {
+		if keys := providers[i].keys(); keys != nil {
+			for _, k := range keys.Keys {
+				if k.Name == name {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// kmsSocketDir returns the dir of the unix socket of the KMS plugin, which is mounted into kube-apiserver,
+// or "" if the provider is not kms.
+func kmsSocketDir(cfg *kubekeyv1alpha2.EncryptionAtRest) string {
+	if encryptionProvider(cfg) != EncryptionKMS {
+		return ""
+	}
+	return filepath.Dir(strings.TrimPrefix(cfg.KMS.Endpoint, "unix://"))
+}
+
+func localEncryptionConfig(runtime connector.Runtime) string {
+	return filepath.Join(runtime.GetWorkDir(), "encryption", "config.yaml")
+}
+
+func writeEncryptionConfig(runtime connector.Runtime, config *encryptionConfiguration) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "marshal EncryptionConfiguration failed")
+	}
+	if err := util.WriteFile(localEncryptionConfig(runtime), data); err != nil {
+		return errors.Wrap(errors.WithStack(err), "write EncryptionConfiguration failed")
+	}
+	return nil
+}
+
+type FetchEncryptionConfig struct {
+	common.KubeAction
+	// Required fails the action if the EncryptionConfiguration is not found.
+	Required bool
+}
+
+// Execute caches the EncryptionConfiguration of the cluster, so that the keys in use are kept.
+func (f *FetchEncryptionConfig) Execute(runtime connector.Runtime) error {
+	if _, ok := f.PipelineCache.Get(EncryptionConfig); ok {
+		return nil
+	}
+
+	host := runtime.RemoteHost()
+	exist, err := runtime.GetRunner().FileExist(EncryptionConfigFile)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		if f.Required {
+			return errors.Errorf("%s is not found on %s, the encryption at rest is not enabled", EncryptionConfigFile, host.GetName())
+		}
+		logger.Log.Messagef(host.GetName(), "%s is not found, the encryption at rest is not enabled on it", EncryptionConfigFile)
+		return nil
+	}
+
+	localFile := localEncryptionConfig(runtime)
+	if err := os.MkdirAll(filepath.Dir(localFile), 0700); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "create %s failed", filepath.Dir(localFile))
+	}
+	if err := runtime.GetRunner().Fetch(localFile, EncryptionConfigFile); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "fetch %s failed", EncryptionConfigFile)
+	}
+	data, err := os.ReadFile(localFile)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "read %s failed", localFile)
+	}
+	config := new(encryptionConfiguration)
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "parse %s failed", EncryptionConfigFile)
+	}
+	f.PipelineCache.Set(EncryptionConfig, config)
+	return nil
+}
+
+type GenerateEncryptionConfig struct {
+	common.KubeAction
+}
+
+// Execute writes the EncryptionConfiguration of the cluster, or a new one with a random key if the cluster has none.
+func (g *GenerateEncryptionConfig) Execute(runtime connector.Runtime) error {
+	if v, ok := g.PipelineCache.Get(EncryptionConfig); ok {
+		return writeEncryptionConfig(runtime, v.(*encryptionConfiguration))
+	}
+
+	key, err := newEncryptionKey()
+	if err != nil {
+		return err
+	}
+	config, err := newEncryptionConfiguration(&g.KubeConf.Cluster.Kubernetes.EncryptionAtRest, key)
+	if err != nil {
+		return err
+	}
+	g.PipelineCache.Set(EncryptionConfig, config)
+	return writeEncryptionConfig(runtime, config)
+}
+
+type SyncEncryptionConfig struct {
+	common.KubeAction
+}
+
+func (s *SyncEncryptionConfig) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("mkdir -p %s && chmod 700 %s", EncryptionConfigDir, EncryptionConfigDir), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "create %s failed", EncryptionConfigDir)
+	}
+	if err := runtime.GetRunner().SudoScp(localEncryptionConfig(runtime), EncryptionConfigFile); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "sync %s failed", EncryptionConfigFile)
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod 600 %s", EncryptionConfigFile), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "change the mode of %s failed", EncryptionConfigFile)
+	}
+	return nil
+}
+
+type RotateEncryptionConfig struct {
+	common.KubeAction
+	Step EncryptionRotationStep
+}
+
+// Execute applies the step of the rotation to the EncryptionConfiguration of the cluster. The new key is generated
+// by the first step, and cached for the others.
+func (r *RotateEncryptionConfig) Execute(runtime connector.Runtime) error {
+	v, ok := r.PipelineCache.Get(EncryptionConfig)
+	if !ok {
+		return errors.New("get the EncryptionConfiguration by pipeline cache failed")
+	}
+	config := v.(*encryptionConfiguration)
+
+	var key encryptionKey
+	if v, ok := r.PipelineCache.Get(EncryptionKey); ok {
+		key = v.(encryptionKey)
+	} else {
+		k, err := newEncryptionKey()
+		if err != nil {
+			return err
+		}
+		key = k
+		r.PipelineCache.Set(EncryptionKey, key)
+	}
+
+	if err := rotateEncryptionConfiguration(config, r.Step, encryptionProvider(&r.KubeConf.Cluster.Kubernetes.EncryptionAtRest), key); err != nil {
+		return err
+	}
+	return writeEncryptionConfig(runtime, config)
+}
+
+type RestartAPIServer struct {
+	common.KubeAction
+}
+
+// Execute restarts kube-apiserver to reload the EncryptionConfiguration, and waits for it to be healthy.
+func (r *RestartAPIServer) Execute(runtime connector.Runtime) error {
+	cmds := RestartControlPlaneCmds(r.KubeConf.Cluster.Kubernetes.ContainerManager, "kube-apiserver")
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(cmds, " && "), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restart kube-apiserver failed")
+	}
+	return WaitForAPIServer(runtime)
+}
+
+type RewriteEncryptedResources struct {
+	common.KubeAction
+}
+
+// Execute rewrites all the encrypted resources, so that they are encrypted with the key in use.
+func (r *RewriteEncryptedResources) Execute(runtime connector.Runtime) error {
+	v, ok := r.PipelineCache.Get(EncryptionConfig)
+	if !ok {
+		return errors.New("get the EncryptionConfiguration by pipeline cache failed")
+	}
+	for _, res := range v.(*encryptionConfiguration).Resources {
+		for _, resource := range res.Resources {
+			cmd := fmt.Sprintf("/usr/local/bin/kubectl get %s --all-namespaces -o json | /usr/local/bin/kubectl replace -f -", resource)
+			if _, err := runtime.GetRunner().SudoCmd(cmd, true); err != nil {
+				return errors.Wrapf(errors.WithStack(err), "rewrite %s failed", resource)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+)
+
+// keyNames returns the names of the keys of the providers, "identity" and "kms" for the providers without keys.
+func keyNames(config *encryptionConfiguration) []string {
+	var names []string
+	for _, p := range config.Resources[0].Providers {
+		switch {
+		case p.Identity != nil:
+			names = append(names, "identity")
+		case p.KMS != nil:
+			names = append(names, "kms")
+		default:
+			for _, k := range p.keys().Keys {
+				names = append(names, k.Name)
+			}
+		}
+	}
+	return names
+}
+
+func TestNewEncryptionConfiguration(t *testing.T) {
+	key := encryptionKey{Name: "key-1", Secret: "c2VjcmV0"}
+
+	config, err := newEncryptionConfiguration(&kubekeyv1alpha2.EncryptionAtRest{}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := config.Resources[0].Resources; !reflect.DeepEqual(got, []string{"secrets"}) {
+		t.Errorf("resources = %v, want [secrets]", got)
+	}
+	if config.Resources[0].Providers[0].AESCBC == nil {
+		t.Errorf("the first provider is not aescbc")
+	}
+	if got := keyNames(config); !reflect.DeepEqual(got, []string{"key-1", "identity"}) {
+		t.Errorf("keys = %v, want [key-1 identity]", got)
+	}
+
+	config, err = newEncryptionConfiguration(&kubekeyv1alpha2.EncryptionAtRest{
+		Provider: EncryptionKMS,
+		KMS:      kubekeyv1alpha2.KMSPlugin{Name: "vault", Endpoint: "unix:///var/run/kms/socket.sock"},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := keyNames(config); !reflect.DeepEqual(got, []string{"kms", "identity"}) {
+		t.Errorf("keys = %v, want [kms identity]", got)
+	}
+
+	if _, err := newEncryptionConfiguration(&kubekeyv1alpha2.EncryptionAtRest{Provider: EncryptionKMS}, key); err == nil {
+		t.Errorf("expected an error for the kms provider without the plugin")
+	}
+	if _, err := newEncryptionConfiguration(&kubekeyv1alpha2.EncryptionAtRest{Provider: "aes"}, key); err == nil {
+		t.Errorf("expected an error for an unsupported provider")
+	}
+}
+
+func TestRotateEncryptionConfiguration(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		want     [][]string
+	}{
+		{
+			name:     "same provider",
+			provider: EncryptionAESCBC,
+			want: [][]string{
+				{"key-1", "key-2", "identity"},
+				{"key-2", "key-1", "identity"},
+				{"key-2", "identity"},
+			},
+		},
+		{
+			name:     "change provider",
+			provider: EncryptionSecretbox,
+			want: [][]string{
+				{"key-1", "key-2", "identity"},
+				{"key-2", "key-1", "identity"},
+				{"key-2", "identity"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := newEncryptionConfiguration(&kubekeyv1alpha2.EncryptionAtRest{}, encryptionKey{Name: "key-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			newKey := encryptionKey{Name: "key-2"}
+			for i, step := range []EncryptionRotationStep{AddEncryptionKey, PromoteEncryptionKey, PruneEncryptionKeys} {
+				if err := rotateEncryptionConfiguration(config, step, tt.provider, newKey); err != nil {
+					t.Fatalf("%s: %v", step, err)
+				}
+				if got := keyNames(config); !reflect.DeepEqual(got, tt.want[i]) {
+					t.Errorf("%s: keys = %v, want %v", step, got, tt.want[i])
+				}
+			}
+			if !config.Resources[0].Providers[0].is(tt.provider) {
+				t.Errorf("the first provider is not %s", tt.provider)
+			}
+		})
+	}
+}
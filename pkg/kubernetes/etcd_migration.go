@@ -0,0 +1,282 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/etcd"
+	"github.com/kubesphere/kubekey/pkg/images"
+)
+
+const (
+	stackedEtcdCADir     = "/etc/kubernetes/pki/etcd"
+	stackedEtcdManifest  = "/etc/kubernetes/manifests/etcd.yaml"
+	apiServerManifest    = "/etc/kubernetes/manifests/kube-apiserver.yaml"
+	stackedEtcdConfig    = "/etc/kubernetes/kubeadm-etcd.yaml"
+	stackedEtcdServerURL = "https://127.0.0.1:2379"
+)
+
+// apiServerEtcdArgs returns the etcd flags of kube-apiserver on the host for the etcd of the type.
+func apiServerEtcdArgs(runtime connector.Runtime, etcdType string) map[string]string {
+	host := runtime.RemoteHost()
+	if etcdType == kubekeyv1alpha2.Kubeadm {
+		return map[string]string{
+			"etcd-servers":  stackedEtcdServerURL,
+			"etcd-cafile":   filepath.Join(stackedEtcdCADir, "ca.crt"),
+			"etcd-certfile": "/etc/kubernetes/pki/apiserver-etcd-client.crt",
+			"etcd-keyfile":  "/etc/kubernetes/pki/apiserver-etcd-client.key",
+		}
+	}
+	return map[string]string{
+		"etcd-servers":  strings.Join(etcd.KubeKeyEndpoints(runtime), ","),
+		"etcd-cafile":   filepath.Join(common.ETCDCertDir, "ca.pem"),
+		"etcd-certfile": filepath.Join(common.ETCDCertDir, fmt.Sprintf("node-%s.pem", host.GetName())),
+		"etcd-keyfile":  filepath.Join(common.ETCDCertDir, fmt.Sprintf("node-%s-key.pem", host.GetName())),
+	}
+}
+
+// kubeadmEtcdConfig returns the etcd of the kubeadm ClusterConfiguration for the etcd of the type.
+func kubeadmEtcdConfig(kubeConf *common.KubeConf, runtime connector.Runtime) map[string]interface{} {
+	if kubeConf.Cluster.Etcd.Type == kubekeyv1alpha2.Kubeadm {
+		etcdImage := images.GetImage(runtime, kubeConf, "etcd")
+		return map[string]interface{}{
+			"local": map[string]interface{}{
+				"imageRepository": strings.TrimSuffix(etcdImage.ImageRepo(), "/etcd"),
+				"imageTag":        etcdImage.Tag,
+				"dataDir":         "/var/lib/etcd",
+			},
+		}
+	}
+	// The same certs as the kubeadm config generated by KubeKey, which are synchronized to all the control-plane nodes.
+	firstMaster := runtime.GetHostsByRole(common.Master)[0]
+	return map[string]interface{}{
+		"external": map[string]interface{}{
+			"endpoints": etcd.KubeKeyEndpoints(runtime),
+			"caFile":    filepath.Join(common.ETCDCertDir, "ca.pem"),
+			"certFile":  filepath.Join(common.ETCDCertDir, fmt.Sprintf("node-%s.pem", firstMaster.GetName())),
+			"keyFile":   filepath.Join(common.ETCDCertDir, fmt.Sprintf("node-%s-key.pem", firstMaster.GetName())),
+		},
+	}
+}
+
+type FetchStackedEtcdCA struct {
+	common.KubeAction
+}
+
+// Execute fetches the etcd CA of kubeadm as the CA of the etcd installed by KubeKey, so that the members trust each other.
+func (f *FetchStackedEtcdCA) Execute(runtime connector.Runtime) error {
+	pkiPath := filepath.Join(runtime.GetWorkDir(), "pki", "etcd")
+	if err := os.RemoveAll(pkiPath); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "remove %s failed", pkiPath)
+	}
+	if err := os.MkdirAll(pkiPath, 0755); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "create %s failed", pkiPath)
+	}
+
+	for src, dst := range map[string]string{"ca.crt": "ca.pem", "ca.key": "ca-key.pem"} {
+		if err := runtime.GetRunner().Fetch(filepath.Join(pkiPath, dst), filepath.Join(stackedEtcdCADir, src)); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "fetch %s failed", filepath.Join(stackedEtcdCADir, src))
+		}
+	}
+	return nil
+}
+
+type FetchKubeKeyEtcdCA struct {
+	common.KubeAction
+}
+
+// Execute fetches the CA of the etcd installed by KubeKey, which signs the certs of the stacked etcd of kubeadm.
+func (f *FetchKubeKeyEtcdCA) Execute(runtime connector.Runtime) error {
+	keyFile := filepath.Join(common.ETCDCertDir, "ca-key.pem")
+	exist, err := runtime.GetRunner().FileExist(keyFile)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.Errorf("%s is not found, the key of the etcd CA is required to sign the certs of the stacked etcd", keyFile)
+	}
+
+	pkiPath := filepath.Join(runtime.GetWorkDir(), "pki", "etcd")
+	if err := os.MkdirAll(pkiPath, 0755); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "create %s failed", pkiPath)
+	}
+	for _, name := range []string{"ca.pem", "ca-key.pem"} {
+		if err := runtime.GetRunner().Fetch(filepath.Join(pkiPath, name), filepath.Join(common.ETCDCertDir, name)); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "fetch %s failed", filepath.Join(common.ETCDCertDir, name))
+		}
+	}
+	return nil
+}
+
+type SyncStackedEtcdCA struct {
+	common.KubeAction
+}
+
+func (s *SyncStackedEtcdCA) Execute(runtime connector.Runtime) error {
+	pkiPath := filepath.Join(runtime.GetWorkDir(), "pki", "etcd")
+	for src, dst := range map[string]string{"ca.pem": "ca.crt", "ca-key.pem": "ca.key"} {
+		if err := runtime.GetRunner().SudoScp(filepath.Join(pkiPath, src), filepath.Join(stackedEtcdCADir, dst)); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "sync %s failed", dst)
+		}
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chmod 600 %s", filepath.Join(stackedEtcdCADir, "ca.key")), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "chmod etcd CA key failed")
+	}
+	return nil
+}
+
+type UpdateKubeadmEtcd struct {
+	common.KubeAction
+}
+
+// Execute updates the etcd in the ClusterConfiguration of kubeadm-config to the etcd type of the config.
+func (u *UpdateKubeadmEtcd) Execute(runtime connector.Runtime) error {
+	etcdConfig := kubeadmEtcdConfig(u.KubeConf, runtime)
+	return patchKubeadmConfig(runtime, "ClusterConfiguration", func(clusterConfiguration map[string]interface{}) bool {
+		clusterConfiguration["etcd"] = etcdConfig
+		return true
+	})
+}
+
+type GenerateStackedEtcd struct {
+	common.KubeAction
+}
+
+// Execute signs the certs of the stacked etcd and creates its static pod, which joins the existing etcd cluster.
+func (g *GenerateStackedEtcd) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	cluster, ok := g.PipelineCache.GetMustString(etcd.MigrationInitialCluster)
+	if !ok {
+		return errors.New("get the initial etcd cluster by pipeline cache failed")
+	}
+	if cluster == "" {
+		return nil
+	}
+
+	if out, _ := runtime.GetRunner().SudoCmd("ls -A /var/lib/etcd 2>/dev/null || true", false); strings.TrimSpace(out) != "" {
+		return errors.Errorf("/var/lib/etcd on %s is not empty", host.GetName())
+	}
+
+	clusterConfiguration, err := runtime.GetRunner().SudoCmd(
+		"/usr/local/bin/kubectl -n kube-system get cm kubeadm-config -o jsonpath='{.data.ClusterConfiguration}'", false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "get the ClusterConfiguration of kubeadm-config failed")
+	}
+	doc := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(clusterConfiguration), &doc); err != nil {
+		return errors.Wrap(errors.WithStack(err), "unmarshal the ClusterConfiguration of kubeadm-config failed")
+	}
+	initConfiguration, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": doc["apiVersion"],
+		"kind":       "InitConfiguration",
+		"localAPIEndpoint": map[string]interface{}{
+			"advertiseAddress": host.GetInternalAddress(),
+			"bindPort":         kubekeyv1alpha2.DefaultApiserverPort,
+		},
+		"nodeRegistration": map[string]interface{}{
+			"name": host.GetName(),
+		},
+	})
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "marshal InitConfiguration failed")
+	}
+	config := string(initConfiguration) + "---\n" + clusterConfiguration
+
+	phases := []string{"certs etcd-server", "certs etcd-peer", "certs etcd-healthcheck-client", "certs apiserver-etcd-client", "etcd local"}
+	cmds := []string{fmt.Sprintf("echo %s | base64 -d > %s", base64.StdEncoding.EncodeToString([]byte(config)), stackedEtcdConfig)}
+	for _, phase := range phases {
+		cmds = append(cmds, fmt.Sprintf("/usr/local/bin/kubeadm init phase %s --config %s", phase, stackedEtcdConfig))
+	}
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(cmds, " && "), true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "generate the stacked etcd failed")
+	}
+
+	// kubeadm creates the manifest of a new etcd cluster, which is changed to join the existing one.
+	joinCmd := fmt.Sprintf("sed -i 's#^\\( *\\)- --initial-cluster=.*#\\1- --initial-cluster=%s\\n\\1- --initial-cluster-state=existing#' %s",
+		cluster, stackedEtcdManifest)
+	if _, err := runtime.GetRunner().SudoCmd(joinCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "update the initial cluster of the stacked etcd failed")
+	}
+	return nil
+}
+
+type SwitchAPIServerEtcd struct {
+	common.KubeAction
+}
+
+// Execute points kube-apiserver to the etcd of the type of the config, and waits for it to be restarted by kubelet.
+func (s *SwitchAPIServerEtcd) Execute(runtime connector.Runtime) error {
+	args := apiServerEtcdArgs(runtime, s.KubeConf.Cluster.Etcd.Type)
+	var exprs []string
+	for _, name := range []string{"etcd-servers", "etcd-cafile", "etcd-certfile", "etcd-keyfile"} {
+		exprs = append(exprs, fmt.Sprintf("-e 's#--%[1]s=.*#--%[1]s=%[2]s#'", name, args[name]))
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("sed -i %s %s", strings.Join(exprs, " "), apiServerManifest), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "update the etcd of kube-apiserver failed")
+	}
+
+	restarted := false
+	checkCmd := fmt.Sprintf("pgrep -f 'kube-apiserver.*--etcd-servers=%s'", args["etcd-servers"])
+	for i := 0; i < 30; i++ {
+		if _, err := runtime.GetRunner().SudoCmd(checkCmd, false); err == nil {
+			restarted = true
+			break
+		}
+		time.Sleep(5 * time.Second)
+	}
+	if !restarted {
+		return errors.Errorf("kube-apiserver on %s is not restarted with etcd %s", runtime.RemoteHost().GetName(), args["etcd-servers"])
+	}
+	return WaitForAPIServer(runtime)
+}
+
+type RemoveStackedEtcd struct {
+	common.KubeAction
+}
+
+// Execute stops the stacked etcd which has been removed from the cluster, and keeps its data and manifest for recovery.
+func (r *RemoveStackedEtcd) Execute(runtime connector.Runtime) error {
+	exist, err := runtime.GetRunner().FileExist(stackedEtcdManifest)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		logger.Log.Messagef(runtime.RemoteHost().GetName(), "%s is not found, skip removing the stacked etcd", stackedEtcdManifest)
+		return nil
+	}
+
+	suffix := time.Now().Format("20060102150405")
+	cmd := fmt.Sprintf("mv -f %[1]s /etc/kubernetes/etcd.yaml.migrated-%[2]s && mv -f /var/lib/etcd /var/lib/etcd.migrated-%[2]s",
+		stackedEtcdManifest, suffix)
+	if _, err := runtime.GetRunner().SudoCmd(cmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "remove the stacked etcd failed")
+	}
+	return nil
+}
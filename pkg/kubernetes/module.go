@@ -22,11 +22,14 @@ import (
 
 	"github.com/pkg/errors"
 
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/pkg/binaries"
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/action"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
 	"github.com/kubesphere/kubekey/pkg/core/prepare"
 	"github.com/kubesphere/kubekey/pkg/core/task"
+	"github.com/kubesphere/kubekey/pkg/etcd"
 	"github.com/kubesphere/kubekey/pkg/images"
 	"github.com/kubesphere/kubekey/pkg/kubernetes/templates"
 )
@@ -126,6 +129,147 @@ func (i *InstallKubeBinariesModule) Init() {
 	}
 }
 
+type SyncPKIModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (s *SyncPKIModule) IsSkip() bool {
+	return s.Skip
+}
+
+func (s *SyncPKIModule) Init() {
+	s.Name = "SyncPKIModule"
+	s.Desc = "Sync the kubernetes CA and certs supplied"
+
+	generate := &task.LocalTask{
+		Name:   "GenerateKubernetesPKI",
+		Desc:   "Validate the kubernetes CA and certs supplied",
+		Action: new(GenerateKubernetesPKI),
+	}
+
+	sync := &task.RemoteTask{
+		Name:     "SyncKubernetesPKI",
+		Desc:     "Synchronize the kubernetes CA and certs to control-plane nodes",
+		Hosts:    s.Runtime.GetHostsByRole(common.Master),
+		Prepare:  &NodeInCluster{Not: true},
+		Action:   new(SyncKubernetesPKI),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	s.Tasks = []task.Interface{
+		generate,
+		sync,
+	}
+}
+
+type EncryptionConfigModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (e *EncryptionConfigModule) IsSkip() bool {
+	return e.Skip
+}
+
+func (e *EncryptionConfigModule) Init() {
+	e.Name = "EncryptionConfigModule"
+	e.Desc = "Sync the EncryptionConfiguration of kube-apiserver"
+
+	fetch := &task.RemoteTask{
+		Name:     "FetchEncryptionConfig",
+		Desc:     "Fetch the EncryptionConfiguration of the cluster",
+		Hosts:    e.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(NodeInCluster),
+		Action:   new(FetchEncryptionConfig),
+		Parallel: false,
+	}
+
+	generate := &task.LocalTask{
+		Name:   "GenerateEncryptionConfig",
+		Desc:   "Generate the EncryptionConfiguration",
+		Action: new(GenerateEncryptionConfig),
+	}
+
+	sync := &task.RemoteTask{
+		Name:     "SyncEncryptionConfig",
+		Desc:     "Synchronize the EncryptionConfiguration to control-plane nodes",
+		Hosts:    e.Runtime.GetHostsByRole(common.Master),
+		Prepare:  &NodeInCluster{Not: true},
+		Action:   new(SyncEncryptionConfig),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	e.Tasks = []task.Interface{
+		fetch,
+		generate,
+		sync,
+	}
+}
+
+type RotateEncryptionKeyModule struct {
+	common.KubeModule
+}
+
+func (r *RotateEncryptionKeyModule) Init() {
+	r.Name = "RotateEncryptionKeyModule"
+	r.Desc = "Rotate the encryption key of kube-apiserver"
+
+	masters := r.Runtime.GetHostsByRole(common.Master)
+
+	fetch := &task.RemoteTask{
+		Name:   "FetchEncryptionConfig",
+		Desc:   "Fetch the EncryptionConfiguration of the cluster",
+		Hosts:  masters[:1],
+		Action: &FetchEncryptionConfig{Required: true},
+	}
+
+	r.Tasks = []task.Interface{
+		fetch,
+	}
+
+	// Every step is applied to all the kube-apiservers before the next one, so that none of them encrypts with a key
+	// another one can not decrypt with.
+	for _, step := range []EncryptionRotationStep{AddEncryptionKey, PromoteEncryptionKey, PruneEncryptionKeys} {
+		rotate := &task.LocalTask{
+			Name:   "RotateEncryptionConfig",
+			Desc:   fmt.Sprintf("Rotate the EncryptionConfiguration: %s the new key", step),
+			Action: &RotateEncryptionConfig{Step: step},
+		}
+
+		sync := &task.RemoteTask{
+			Name:     "SyncEncryptionConfig",
+			Desc:     "Synchronize the EncryptionConfiguration to control-plane nodes",
+			Hosts:    masters,
+			Action:   new(SyncEncryptionConfig),
+			Parallel: true,
+			Retry:    2,
+		}
+
+		restart := &task.RemoteTask{
+			Name:     "RestartAPIServer",
+			Desc:     "Restart kube-apiserver one by one",
+			Hosts:    masters,
+			Action:   new(RestartAPIServer),
+			Parallel: false,
+		}
+
+		r.Tasks = append(r.Tasks, rotate, sync, restart)
+
+		if step == PromoteEncryptionKey {
+			rewrite := &task.RemoteTask{
+				Name:   "RewriteEncryptedResources",
+				Desc:   "Rewrite the encrypted resources with the new key",
+				Hosts:  masters[:1],
+				Action: new(RewriteEncryptedResources),
+			}
+			r.Tasks = append(r.Tasks, rewrite)
+		}
+	}
+}
+
 type InitKubernetesModule struct {
 	common.KubeModule
 }
@@ -351,6 +495,16 @@ func (r *ResetClusterModule) Init() {
 	}
 }
 
+// remainingMaster returns the first control-plane node which is not going to be deleted.
+func remainingMaster(runtime connector.ModuleRuntime, nodeName string) []connector.Host {
+	for _, host := range runtime.GetHostsByRole(common.Master) {
+		if host.GetName() != nodeName {
+			return []connector.Host{host}
+		}
+	}
+	return nil
+}
+
 type CompareConfigAndClusterInfoModule struct {
 	common.KubeModule
 }
@@ -360,11 +514,10 @@ func (c *CompareConfigAndClusterInfoModule) Init() {
 	c.Desc = "Compare config and cluster nodes info"
 
 	check := &task.RemoteTask{
-		Name:    "FindNode",
-		Desc:    "Find information about nodes that are expected to be deleted",
-		Hosts:   c.Runtime.GetHostsByRole(common.Master),
-		Prepare: new(common.OnlyFirstMaster),
-		Action:  new(FindNode),
+		Name:   "FindNode",
+		Desc:   "Find information about nodes that are expected to be deleted",
+		Hosts:  remainingMaster(c.Runtime, c.KubeConf.Arg.NodeName),
+		Action: new(FindNode),
 	}
 
 	c.Tasks = []task.Interface{
@@ -374,34 +527,163 @@ func (c *CompareConfigAndClusterInfoModule) Init() {
 
 type DeleteKubeNodeModule struct {
 	common.KubeModule
+	Skip         bool
+	ControlPlane bool
+	// SkipDrain is used when the node can not be connected, the pods on it are deleted along with the node.
+	SkipDrain bool
+}
+
+func (d *DeleteKubeNodeModule) IsSkip() bool {
+	return d.Skip
 }
 
 func (d *DeleteKubeNodeModule) Init() {
 	d.Name = "DeleteKubeNodeModule"
 	d.Desc = "Delete kubernetes node"
 
+	master := remainingMaster(d.Runtime, d.KubeConf.Arg.NodeName)
+
 	drain := &task.RemoteTask{
-		Name:    "DrainNode",
-		Desc:    "Node safely evict all pods",
-		Hosts:   d.Runtime.GetHostsByRole(common.Master),
-		Prepare: new(common.OnlyFirstMaster),
-		Action:  new(DrainNode),
-		Retry:   2,
+		Name:   "DrainNode",
+		Desc:   "Node safely evict all pods",
+		Hosts:  master,
+		Action: new(DrainNode),
+		Retry:  2,
 	}
 
 	deleteNode := &task.RemoteTask{
-		Name:    "DeleteNode",
-		Desc:    "Delete the node using kubectl",
-		Hosts:   d.Runtime.GetHostsByRole(common.Master),
-		Prepare: new(common.OnlyFirstMaster),
-		Action:  new(KubectlDeleteNode),
-		Retry:   5,
+		Name:   "DeleteNode",
+		Desc:   "Delete the node using kubectl",
+		Hosts:  master,
+		Action: new(KubectlDeleteNode),
+		Retry:  5,
 	}
 
 	d.Tasks = []task.Interface{
 		drain,
 		deleteNode,
 	}
+	if d.SkipDrain {
+		d.Tasks = []task.Interface{
+			deleteNode,
+		}
+	}
+
+	if d.ControlPlane {
+		updateClusterStatus := &task.RemoteTask{
+			Name:   "UpdateKubeadmClusterStatus",
+			Desc:   "Remove the control-plane node from kubeadm-config",
+			Hosts:  master,
+			Action: new(UpdateKubeadmClusterStatus),
+			Retry:  3,
+		}
+		d.Tasks = append(d.Tasks, updateClusterStatus)
+	}
+
+	reset := &task.RemoteTask{
+		Name:    "KubeadmReset",
+		Desc:    "Reset the deleted node",
+		Hosts:   d.Runtime.GetHostsByRole(common.K8s),
+		Prepare: new(DstNode),
+		Action:  new(KubeadmReset),
+	}
+
+	d.Tasks = append(d.Tasks, reset)
+}
+
+type UpdateAPIServerCertSANsModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (u *UpdateAPIServerCertSANsModule) IsSkip() bool {
+	return u.Skip
+}
+
+func (u *UpdateAPIServerCertSANsModule) Init() {
+	u.Name = "UpdateAPIServerCertSANsModule"
+	u.Desc = "Update the cert SANs of kube-apiserver"
+
+	updateKubeadmConfig := &task.RemoteTask{
+		Name:    "UpdateKubeadmCertSANs",
+		Desc:    "Update the cert SANs in kubeadm-config",
+		Hosts:   u.Runtime.GetHostsByRole(common.Master),
+		Prepare: new(common.OnlyFirstMaster),
+		Action:  new(UpdateKubeadmCertSANs),
+		Retry:   3,
+	}
+
+	regenerateCert := &task.RemoteTask{
+		Name:     "RegenerateAPIServerCert",
+		Desc:     "Regenerate the kube-apiserver certificate",
+		Hosts:    u.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(APIServerCertSANsChanged),
+		Action:   new(RegenerateAPIServerCert),
+		Parallel: false,
+	}
+
+	u.Tasks = []task.Interface{
+		updateKubeadmConfig,
+		regenerateCert,
+	}
+}
+
+// UpdateControlPlaneEndpointModule updates the control plane endpoint in kubeadm-config, cluster-info and the kubeconfig
+// files of the control-plane nodes, after the port of the endpoint is changed.
+type UpdateControlPlaneEndpointModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (u *UpdateControlPlaneEndpointModule) IsSkip() bool {
+	return u.Skip
+}
+
+func (u *UpdateControlPlaneEndpointModule) Init() {
+	u.Name = "UpdateControlPlaneEndpointModule"
+	u.Desc = "Update the control plane endpoint"
+
+	updateKubeadmConfig := &task.RemoteTask{
+		Name:    "UpdateKubeadmControlPlaneEndpoint",
+		Desc:    "Update the control plane endpoint in kubeadm-config",
+		Hosts:   u.Runtime.GetHostsByRole(common.Master),
+		Prepare: new(common.OnlyFirstMaster),
+		Action:  new(UpdateKubeadmControlPlaneEndpoint),
+		Retry:   3,
+	}
+
+	updateClusterInfo := &task.RemoteTask{
+		Name:    "UpdateClusterInfoServer",
+		Desc:    "Update the server in cluster-info",
+		Hosts:   u.Runtime.GetHostsByRole(common.Master),
+		Prepare: new(common.OnlyFirstMaster),
+		Action:  new(UpdateClusterInfoServer),
+		Retry:   3,
+	}
+
+	updateKubeconfig := &task.RemoteTask{
+		Name:     "UpdateKubeconfigServer",
+		Desc:     "Update the server of the kubeconfig files and restart kube-controller-manager and kube-scheduler one by one",
+		Hosts:    u.Runtime.GetHostsByRole(common.Master),
+		Action:   new(UpdateKubeconfigServer),
+		Parallel: false,
+		Retry:    2,
+	}
+
+	copyKubeConfig := &task.RemoteTask{
+		Name:     "CopyKubeConfig",
+		Desc:     "Copy admin.conf to ~/.kube/config",
+		Hosts:    u.Runtime.GetHostsByRole(common.Master),
+		Action:   new(CopyKubeConfigForControlPlane),
+		Parallel: true,
+	}
+
+	u.Tasks = []task.Interface{
+		updateKubeadmConfig,
+		updateClusterInfo,
+		updateKubeconfig,
+		copyKubeConfig,
+	}
 }
 
 type SetUpgradePlanModule struct {
@@ -587,12 +869,286 @@ func (c *ConfigureKubernetesModule) Init() {
 		Name:     "ConfigureKubernetes",
 		Desc:     "Configure kubernetes",
 		Hosts:    c.Runtime.GetHostsByRole(common.K8s),
+		Prepare:  new(common.OnlyNodePool),
 		Action:   new(ConfigureKubernetes),
 		Retry:    3,
 		Parallel: true,
 	}
 
+	removeStaleLabels := &task.RemoteTask{
+		Name:     "RemoveStaleNodeLabels",
+		Desc:     "Remove the labels no longer configured from the nodes",
+		Hosts:    c.Runtime.GetHostsByRole(common.K8s),
+		Prepare:  new(common.OnlyNodePool),
+		Action:   new(RemoveStaleNodeLabels),
+		Retry:    3,
+		Parallel: true,
+	}
+
+	configureTaints := &task.RemoteTask{
+		Name:     "ConfigureNodeTaints",
+		Desc:     "Configure the taints of the nodes",
+		Hosts:    c.Runtime.GetHostsByRole(common.K8s),
+		Prepare:  new(common.OnlyNodePool),
+		Action:   new(ConfigureNodeTaints),
+		Retry:    3,
+		Parallel: true,
+	}
+
 	c.Tasks = []task.Interface{
 		configure,
+		removeStaleLabels,
+		configureTaints,
+	}
+
+	if c.KubeConf.Cluster.Kubernetes.Type != common.K3s {
+		configureKubelet := &task.RemoteTask{
+			Name:     "ConfigureKubelet",
+			Desc:     "Override the kubelet configuration of the nodes one by one",
+			Hosts:    c.Runtime.GetHostsByRole(common.K8s),
+			Prepare:  new(common.OnlyNodePool),
+			Action:   new(ConfigureKubelet),
+			Retry:    3,
+			Parallel: false,
+		}
+		c.Tasks = append(c.Tasks, configureKubelet)
+	}
+}
+
+type PrepareEtcdMigrationModule struct {
+	common.KubeModule
+}
+
+func (p *PrepareEtcdMigrationModule) Init() {
+	p.Name = "PrepareEtcdMigrationModule"
+	p.Desc = "Prepare to migrate etcd"
+
+	if p.KubeConf.Cluster.Etcd.Type == kubekeyv1alpha2.KubeKey {
+		firstMaster := p.Runtime.GetHostsByRole(common.Master)[:1]
+
+		check := &task.RemoteTask{
+			Name:   "CheckEtcdMigration",
+			Desc:   "Check whether etcd is stacked on the control-plane nodes",
+			Hosts:  firstMaster,
+			Action: &etcd.CheckMigration{ToKubeKey: true},
+		}
+
+		fetchCA := &task.RemoteTask{
+			Name:   "FetchStackedEtcdCA",
+			Desc:   "Fetch the etcd CA of kubeadm",
+			Hosts:  firstMaster,
+			Action: new(FetchStackedEtcdCA),
+		}
+
+		p.Tasks = []task.Interface{
+			check,
+			fetchCA,
+		}
+		return
+	}
+
+	firstNode := p.Runtime.GetHostsByRole(common.ETCD)[:1]
+
+	check := &task.RemoteTask{
+		Name:   "CheckEtcdMigration",
+		Desc:   "Check whether etcd is installed by KubeKey",
+		Hosts:  firstNode,
+		Action: new(etcd.CheckMigration),
+	}
+
+	fetchCA := &task.RemoteTask{
+		Name:   "FetchKubeKeyEtcdCA",
+		Desc:   "Fetch the etcd CA of KubeKey",
+		Hosts:  firstNode,
+		Action: new(FetchKubeKeyEtcdCA),
+	}
+
+	p.Tasks = []task.Interface{
+		check,
+		fetchCA,
+	}
+}
+
+type MigrateEtcdModule struct {
+	common.KubeModule
+}
+
+func (m *MigrateEtcdModule) Init() {
+	m.Name = "MigrateEtcdModule"
+
+	switch m.KubeConf.Cluster.Etcd.Type {
+	case kubekeyv1alpha2.KubeKey:
+		m.Desc = "Migrate etcd from the control-plane nodes to the etcd nodes"
+		m.Tasks = MigrateEtcdModuleToKubeKey(m)
+	case kubekeyv1alpha2.Kubeadm:
+		m.Desc = "Migrate etcd from the etcd nodes to the control-plane nodes"
+		m.Tasks = MigrateEtcdModuleToKubeadm(m)
+	}
+}
+
+func MigrateEtcdModuleToKubeKey(m *MigrateEtcdModule) []task.Interface {
+	stacked := etcd.StackedEndpoints(m.Runtime)
+	kubekey := etcd.KubeKeyEndpoints(m.Runtime)
+	firstNode := m.Runtime.GetHostsByRole(common.ETCD)[:1]
+
+	snapshot := &task.RemoteTask{
+		Name:   "SnapshotETCD",
+		Desc:   "Save an etcd snapshot before migrating",
+		Hosts:  firstNode,
+		Action: &etcd.SnapshotETCD{Endpoint: stacked[0]},
+	}
+
+	joinMember := &task.RemoteTask{
+		Name:     "JoinEtcdMember",
+		Desc:     "Join the etcd nodes to the stacked etcd cluster one by one",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Action:   &etcd.JoinMigrationMember{Endpoints: stacked},
+		Parallel: false,
+	}
+
+	switchAPIServer := &task.RemoteTask{
+		Name:     "SwitchAPIServerEtcd",
+		Desc:     "Switch kube-apiserver to the etcd nodes one by one",
+		Hosts:    m.Runtime.GetHostsByRole(common.Master),
+		Action:   new(SwitchAPIServerEtcd),
+		Parallel: false,
+	}
+
+	moveLeader := &task.RemoteTask{
+		Name:   "MoveEtcdLeader",
+		Desc:   "Move the etcd leader to the etcd nodes",
+		Hosts:  firstNode,
+		Action: &etcd.MoveLeader{From: stacked, To: kubekey},
+	}
+
+	removeMembers := &task.RemoteTask{
+		Name:   "RemoveStackedEtcdMembers",
+		Desc:   "Remove the stacked etcd members one by one",
+		Hosts:  firstNode,
+		Action: &etcd.RemoveMigratedMembers{From: stacked, To: kubekey},
+	}
+
+	removeStacked := &task.RemoteTask{
+		Name:     "RemoveStackedEtcd",
+		Desc:     "Stop the stacked etcd on the control-plane nodes",
+		Hosts:    m.Runtime.GetHostsByRole(common.Master),
+		Action:   new(RemoveStackedEtcd),
+		Parallel: true,
+	}
+
+	updateKubeadmConfig := &task.RemoteTask{
+		Name:   "UpdateKubeadmEtcd",
+		Desc:   "Update the etcd of kubeadm-config to the etcd nodes",
+		Hosts:  m.Runtime.GetHostsByRole(common.Master)[:1],
+		Action: new(UpdateKubeadmEtcd),
+	}
+
+	return []task.Interface{
+		snapshot,
+		joinMember,
+		switchAPIServer,
+		moveLeader,
+		removeMembers,
+		removeStacked,
+		updateKubeadmConfig,
+	}
+}
+
+func MigrateEtcdModuleToKubeadm(m *MigrateEtcdModule) []task.Interface {
+	stacked := etcd.StackedEndpoints(m.Runtime)
+	kubekey := etcd.KubeKeyEndpoints(m.Runtime)
+	firstNode := m.Runtime.GetHostsByRole(common.ETCD)[:1]
+	masters := m.Runtime.GetHostsByRole(common.Master)
+
+	syncCA := &task.RemoteTask{
+		Name:     "SyncStackedEtcdCA",
+		Desc:     "Synchronize the etcd CA of KubeKey to the control-plane nodes",
+		Hosts:    masters,
+		Action:   new(SyncStackedEtcdCA),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	snapshot := &task.RemoteTask{
+		Name:   "SnapshotETCD",
+		Desc:   "Save an etcd snapshot before migrating",
+		Hosts:  firstNode,
+		Action: new(etcd.SnapshotETCD),
+	}
+
+	// kubeadm generates the stacked etcd from the etcd of kubeadm-config.
+	updateKubeadmConfig := &task.RemoteTask{
+		Name:   "UpdateKubeadmEtcd",
+		Desc:   "Update the etcd of kubeadm-config to the stacked etcd",
+		Hosts:  masters[:1],
+		Action: new(UpdateKubeadmEtcd),
+	}
+
+	tasks := []task.Interface{
+		syncCA,
+		snapshot,
+		updateKubeadmConfig,
+	}
+
+	// The stacked etcd members are added one by one, each of which must be started before the next one is added.
+	for i, master := range masters {
+		addMember := &task.RemoteTask{
+			Name:  "AddStackedEtcdMember",
+			Desc:  fmt.Sprintf("Add the stacked etcd member of %s", master.GetName()),
+			Hosts: firstNode,
+			Action: &etcd.AddMigrationMember{
+				Endpoints: kubekey,
+				Name:      master.GetName(),
+				PeerURL:   fmt.Sprintf("https://%s:2380", master.GetInternalAddress()),
+			},
+		}
+
+		generate := &task.RemoteTask{
+			Name:   "GenerateStackedEtcd",
+			Desc:   fmt.Sprintf("Generate the stacked etcd on %s", master.GetName()),
+			Hosts:  []connector.Host{master},
+			Action: new(GenerateStackedEtcd),
+		}
+
+		waitHealthy := &task.RemoteTask{
+			Name:   "WaitStackedEtcdHealthy",
+			Desc:   fmt.Sprintf("Wait for the stacked etcd member of %s to be healthy", master.GetName()),
+			Hosts:  firstNode,
+			Action: &etcd.WaitMemberHealthy{Endpoint: stacked[i]},
+		}
+
+		tasks = append(tasks, addMember, generate, waitHealthy)
+	}
+
+	switchAPIServer := &task.RemoteTask{
+		Name:     "SwitchAPIServerEtcd",
+		Desc:     "Switch kube-apiserver to the stacked etcd one by one",
+		Hosts:    masters,
+		Action:   new(SwitchAPIServerEtcd),
+		Parallel: false,
+	}
+
+	moveLeader := &task.RemoteTask{
+		Name:   "MoveEtcdLeader",
+		Desc:   "Move the etcd leader to the control-plane nodes",
+		Hosts:  firstNode,
+		Action: &etcd.MoveLeader{From: kubekey, To: stacked},
 	}
+
+	removeMembers := &task.RemoteTask{
+		Name:   "RemoveKubeKeyEtcdMembers",
+		Desc:   "Remove the etcd members of the etcd nodes one by one",
+		Hosts:  firstNode,
+		Action: &etcd.RemoveMigratedMembers{From: kubekey, To: stacked},
+	}
+
+	stopMember := &task.RemoteTask{
+		Name:     "StopKubeKeyEtcd",
+		Desc:     "Stop etcd on the etcd nodes",
+		Hosts:    m.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(etcd.StopMigratedMember),
+		Parallel: true,
+	}
+
+	return append(tasks, switchAPIServer, moveLeader, removeMembers, stopMember)
 }
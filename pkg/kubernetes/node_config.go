@@ -0,0 +1,290 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	versionutil "k8s.io/apimachinery/pkg/util/version"
+
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+)
+
+const (
+	// LabelsAnnotation records the labels applied by KubeKey, so that the ones removed from the configuration are removed from the node.
+	LabelsAnnotation = "kubekey.kubesphere.io/labels"
+	// TaintsAnnotation records the taints applied by KubeKey, so that the ones removed from the configuration are removed from the node.
+	TaintsAnnotation = "kubekey.kubesphere.io/taints"
+
+	kubeletConfigFile = "/var/lib/kubelet/config.yaml"
+	// kubeletOverridesFile keeps the kubelet configuration of the node which overrides the one of the cluster,
+	// the kubelet configuration is restored when the overrides are removed from the configuration.
+	kubeletOverridesFile = "/var/lib/kubelet/kubekey-overrides.yaml"
+)
+
+// controlPlaneTaints returns the taints which kubeadm adds to the control-plane nodes of the version.
+func controlPlaneTaints(version string) []kubekeyv1alpha2.Taint {
+	master := kubekeyv1alpha2.Taint{Key: "node-role.kubernetes.io/master", Effect: kubekeyv1alpha2.TaintEffectNoSchedule}
+	controlPlane := kubekeyv1alpha2.Taint{Key: "node-role.kubernetes.io/control-plane", Effect: kubekeyv1alpha2.TaintEffectNoSchedule}
+
+	v := versionutil.MustParseSemantic(version)
+	switch {
+	case v.LessThan(versionutil.MustParseSemantic("v1.24.0")):
+		return []kubekeyv1alpha2.Taint{master}
+	case v.LessThan(versionutil.MustParseSemantic("v1.25.0")):
+		return []kubekeyv1alpha2.Taint{master, controlPlane}
+	default:
+		return []kubekeyv1alpha2.Taint{controlPlane}
+	}
+}
+
+// nodeRegistrationTaints returns the taints registered by kubeadm with the node. kubeadm does not add the
+// control-plane taints once the taints are set, so they are added unless the node is a worker as well.
+func nodeRegistrationTaints(host *kubekeyv1alpha2.KubeHost, version string) []kubekeyv1alpha2.Taint {
+	if len(host.Taints) == 0 {
+		return nil
+	}
+	var taints []kubekeyv1alpha2.Taint
+	if host.IsRole(common.Master) && !host.IsRole(common.Worker) {
+		taints = append(taints, controlPlaneTaints(version)...)
+	}
+	return append(taints, host.Taints...)
+}
+
+// kubeletExtraArgs returns the extra flags of the kubelet of the node without the leading dashes.
+// The cgroup driver is detected from the container runtime, so it can not be overridden.
+func kubeletExtraArgs(host *kubekeyv1alpha2.KubeHost) map[string]string {
+	args := make(map[string]string, len(host.KubeletArgs))
+	for _, arg := range host.KubeletArgs {
+		kv := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)
+		if len(kv) < 2 || kv[0] == "cgroup-driver" {
+			continue
+		}
+		args[kv[0]] = kv[1]
+	}
+	return args
+}
+
+// staleLabels returns the keys of the labels recorded in the annotation which are no longer configured.
+func staleLabels(annotation string, labels map[string]string) []string {
+	var stale []string
+	for _, key := range strings.Split(annotation, ",") {
+		if _, ok := labels[key]; key != "" && !ok {
+			stale = append(stale, key)
+		}
+	}
+	return stale
+}
+
+// labelsAnnotation returns the keys of the labels in the form recorded in the annotation.
+func labelsAnnotation(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// staleTaints returns the taints, in the form of key:effect, recorded in the annotation which are no longer configured.
+func staleTaints(annotation string, taints []kubekeyv1alpha2.Taint) []string {
+	configured := make(map[string]bool, len(taints))
+	for _, t := range taints {
+		configured[fmt.Sprintf("%s:%s", t.Key, t.Effect)] = true
+	}
+	var stale []string
+	for _, t := range strings.Split(annotation, ",") {
+		if t != "" && !configured[t] {
+			stale = append(stale, t)
+		}
+	}
+	return stale
+}
+
+// taintsAnnotation returns the taints in the form recorded in the annotation.
+func taintsAnnotation(taints []kubekeyv1alpha2.Taint) string {
+	keys := make([]string, 0, len(taints))
+	for _, t := range taints {
+		keys = append(keys, fmt.Sprintf("%s:%s", t.Key, t.Effect))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// kubeletConfigMapName returns the name of the ConfigMap in which kubeadm stores the kubelet configuration of the cluster.
+func kubeletConfigMapName(version string) string {
+	v := versionutil.MustParseSemantic(version)
+	if v.LessThan(versionutil.MustParseSemantic("v1.24.0")) {
+		return fmt.Sprintf("kubelet-config-%d.%d", v.Major(), v.Minor())
+	}
+	return "kubelet-config"
+}
+
+// mergeKubeletConfiguration replaces the top-level fields of the kubelet configuration with the overrides.
+func mergeKubeletConfiguration(config string, overrides map[string]interface{}) (string, error) {
+	merged := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(config), &merged); err != nil {
+		return "", errors.Wrap(err, "failed to parse kubelet configuration")
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	out, err := yaml.Marshal(merged)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal kubelet configuration")
+	}
+	return string(out), nil
+}
+
+type ConfigureNodeTaints struct {
+	common.KubeAction
+}
+
+func (c *ConfigureNodeTaints) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost().(*kubekeyv1alpha2.KubeHost)
+
+	annotation, err := nodeAnnotation(runtime, host.GetName(), TaintsAnnotation)
+	if err != nil {
+		return err
+	}
+	if annotation == "" && len(host.Taints) == 0 {
+		return nil
+	}
+	var cmds []string
+	for _, t := range staleTaints(annotation, host.Taints) {
+		// the taint may have been removed by hand
+		cmds = append(cmds, fmt.Sprintf("(/usr/local/bin/kubectl taint nodes %s %s- || true)", host.GetName(), t))
+	}
+	for _, t := range host.Taints {
+		cmds = append(cmds, fmt.Sprintf("/usr/local/bin/kubectl taint nodes %s %s --overwrite", host.GetName(), t.String()))
+	}
+	cmds = append(cmds, annotateNodeCmd(host.GetName(), TaintsAnnotation, taintsAnnotation(host.Taints)))
+
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(cmds, " && "), true); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "configure the taints of node %s failed", host.GetName())
+	}
+	return nil
+}
+
+type RemoveStaleNodeLabels struct {
+	common.KubeAction
+}
+
+func (r *RemoveStaleNodeLabels) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost().(*kubekeyv1alpha2.KubeHost)
+
+	annotation, err := nodeAnnotation(runtime, host.GetName(), LabelsAnnotation)
+	if err != nil {
+		return err
+	}
+	if annotation == "" && len(host.Labels) == 0 {
+		return nil
+	}
+	var cmds []string
+	for _, key := range staleLabels(annotation, host.Labels) {
+		cmds = append(cmds, fmt.Sprintf("/usr/local/bin/kubectl label node %s %s-", host.GetName(), key))
+	}
+	cmds = append(cmds, annotateNodeCmd(host.GetName(), LabelsAnnotation, labelsAnnotation(host.Labels)))
+
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(cmds, " && "), true); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "remove the stale labels of node %s failed", host.GetName())
+	}
+	return nil
+}
+
+func nodeAnnotation(runtime connector.Runtime, node, key string) (string, error) {
+	out, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl get node %s -o jsonpath='{.metadata.annotations.%s}'",
+		node, strings.ReplaceAll(key, ".", "\\.")), false)
+	if err != nil {
+		return "", errors.Wrapf(errors.WithStack(err), "get the annotation %s of node %s failed", key, node)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// annotateNodeCmd returns the command which sets the annotation of the node, or removes it if the value is empty.
+func annotateNodeCmd(node, key, value string) string {
+	if value == "" {
+		return fmt.Sprintf("/usr/local/bin/kubectl annotate node %s %s-", node, key)
+	}
+	return fmt.Sprintf("/usr/local/bin/kubectl annotate node %s --overwrite %s=%s", node, key, value)
+}
+
+type ConfigureKubelet struct {
+	common.KubeAction
+}
+
+func (c *ConfigureKubelet) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost().(*kubekeyv1alpha2.KubeHost)
+
+	if len(host.KubeletConfiguration) == 0 {
+		exist, err := runtime.GetRunner().FileExist(kubeletOverridesFile)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return nil
+		}
+	}
+
+	clusterConfig, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl -n kube-system get cm %s -o jsonpath='{.data.kubelet}'",
+		kubeletConfigMapName(c.KubeConf.Cluster.Kubernetes.Version)), false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "get the kubelet configuration of the cluster failed")
+	}
+	config, err := mergeKubeletConfiguration(clusterConfig, host.KubeletConfiguration)
+	if err != nil {
+		return err
+	}
+	currentConfig, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("cat %s", kubeletConfigFile), false)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "read %s failed", kubeletConfigFile)
+	}
+	current, err := mergeKubeletConfiguration(currentConfig, nil)
+	if err != nil {
+		return err
+	}
+
+	var cmds []string
+	if len(host.KubeletConfiguration) == 0 {
+		cmds = append(cmds, fmt.Sprintf("rm -f %s", kubeletOverridesFile))
+	} else {
+		overrides, err := yaml.Marshal(host.KubeletConfiguration)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal kubelet configuration")
+		}
+		cmds = append(cmds, fmt.Sprintf("echo %s | base64 -d > %s", base64.StdEncoding.EncodeToString(overrides), kubeletOverridesFile))
+	}
+	if config != current {
+		cmds = append(cmds,
+			fmt.Sprintf("echo %s | base64 -d > %s", base64.StdEncoding.EncodeToString([]byte(config)), kubeletConfigFile),
+			"systemctl restart kubelet")
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(cmds, " && "), true); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "configure the kubelet of node %s failed", host.GetName())
+	}
+	return nil
+}
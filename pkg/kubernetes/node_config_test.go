@@ -0,0 +1,145 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+)
+
+func TestKubeletExtraArgs(t *testing.T) {
+	host := &kubekeyv1alpha2.KubeHost{
+		KubeletArgs: []string{"--node-status-update-frequency=4s", "max-pods=64", "cgroup-driver=cgroupfs", "invalid"},
+	}
+	want := map[string]string{"node-status-update-frequency": "4s", "max-pods": "64"}
+	if got := kubeletExtraArgs(host); !reflect.DeepEqual(got, want) {
+		t.Errorf("kubeletExtraArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestNodeRegistrationTaints(t *testing.T) {
+	gpu := kubekeyv1alpha2.Taint{Key: "nvidia.com/gpu", Effect: kubekeyv1alpha2.TaintEffectNoSchedule}
+	newHost := func(taints []kubekeyv1alpha2.Taint, roles ...string) *kubekeyv1alpha2.KubeHost {
+		host := &kubekeyv1alpha2.KubeHost{BaseHost: connector.NewHost(), Taints: taints}
+		for _, role := range roles {
+			host.SetRole(role)
+		}
+		return host
+	}
+
+	tests := []struct {
+		name    string
+		host    *kubekeyv1alpha2.KubeHost
+		version string
+		want    []string
+	}{
+		{"no taints", newHost(nil, "master"), "v1.23.10", nil},
+		{"worker", newHost([]kubekeyv1alpha2.Taint{gpu}, "worker"), "v1.23.10", []string{"nvidia.com/gpu:NoSchedule"}},
+		{"master", newHost([]kubekeyv1alpha2.Taint{gpu}, "master"), "v1.23.10",
+			[]string{"node-role.kubernetes.io/master:NoSchedule", "nvidia.com/gpu:NoSchedule"}},
+		{"master of v1.24", newHost([]kubekeyv1alpha2.Taint{gpu}, "master"), "v1.24.3",
+			[]string{"node-role.kubernetes.io/master:NoSchedule", "node-role.kubernetes.io/control-plane:NoSchedule", "nvidia.com/gpu:NoSchedule"}},
+		{"master of v1.25", newHost([]kubekeyv1alpha2.Taint{gpu}, "master"), "v1.25.3",
+			[]string{"node-role.kubernetes.io/control-plane:NoSchedule", "nvidia.com/gpu:NoSchedule"}},
+		{"master and worker", newHost([]kubekeyv1alpha2.Taint{gpu}, "master", "worker"), "v1.23.10", []string{"nvidia.com/gpu:NoSchedule"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, taint := range nodeRegistrationTaints(tt.host, tt.version) {
+			got = append(got, taint.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: taints = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStaleLabelsAndTaints(t *testing.T) {
+	labels := map[string]string{"pool": "gpu", "zone": "a"}
+	if got := staleLabels("pool,ingress", labels); !reflect.DeepEqual(got, []string{"ingress"}) {
+		t.Errorf("stale labels = %v, want [ingress]", got)
+	}
+	if got := staleLabels("", labels); got != nil {
+		t.Errorf("stale labels = %v, want none", got)
+	}
+	if got := labelsAnnotation(labels); got != "pool,zone" {
+		t.Errorf("labels annotation = %q, want pool,zone", got)
+	}
+
+	taints := []kubekeyv1alpha2.Taint{
+		{Key: "nvidia.com/gpu", Value: "a100", Effect: kubekeyv1alpha2.TaintEffectNoSchedule},
+		{Key: "dedicated", Effect: kubekeyv1alpha2.TaintEffectNoExecute},
+	}
+	got := staleTaints("nvidia.com/gpu:NoSchedule,nvidia.com/gpu:NoExecute,ingress:NoSchedule", taints)
+	if want := []string{"nvidia.com/gpu:NoExecute", "ingress:NoSchedule"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stale taints = %v, want %v", got, want)
+	}
+	if got := taintsAnnotation(taints); got != "dedicated:NoExecute,nvidia.com/gpu:NoSchedule" {
+		t.Errorf("taints annotation = %q", got)
+	}
+}
+
+func TestKubeletConfigMapName(t *testing.T) {
+	if got := kubeletConfigMapName("v1.23.10"); got != "kubelet-config-1.23" {
+		t.Errorf("name = %s, want kubelet-config-1.23", got)
+	}
+	if got := kubeletConfigMapName("v1.24.3"); got != "kubelet-config" {
+		t.Errorf("name = %s, want kubelet-config", got)
+	}
+}
+
+func TestMergeKubeletConfiguration(t *testing.T) {
+	config := `apiVersion: kubelet.config.k8s.io/v1beta1
+kind: KubeletConfiguration
+maxPods: 110
+kubeReserved:
+  cpu: 200m
+  memory: 250Mi
+staticPodPath: /etc/kubernetes/manifests
+`
+	overrides := map[string]interface{}{
+		"maxPods":      64,
+		"kubeReserved": map[interface{}]interface{}{"cpu": "1"},
+	}
+	merged, err := mergeKubeletConfiguration(config, overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(merged), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["maxPods"] != 64 || got["staticPodPath"] != "/etc/kubernetes/manifests" {
+		t.Errorf("merged = %v", got)
+	}
+	if want := map[interface{}]interface{}{"cpu": "1"}; !reflect.DeepEqual(got["kubeReserved"], want) {
+		t.Errorf("kubeReserved = %v, want %v", got["kubeReserved"], want)
+	}
+
+	unchanged, err := mergeKubeletConfiguration(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := mergeKubeletConfiguration(unchanged, nil); again != unchanged {
+		t.Errorf("the normalized configuration is not stable")
+	}
+}
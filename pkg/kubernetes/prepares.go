@@ -17,9 +17,14 @@
 package kubernetes
 
 import (
+	"crypto/x509"
+
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
 	"github.com/pkg/errors"
+	certutil "k8s.io/client-go/util/cert"
+	netutils "k8s.io/utils/net"
 )
 
 type NoClusterInfo struct {
@@ -53,7 +58,8 @@ func (n *NodeInCluster) PreCheck(runtime connector.Runtime) (bool, error) {
 		}
 		_, ipOk := cluster.NodesInfo[host.GetInternalAddress()]
 		if n.Not {
-			return !(versionOk || ipOk), nil
+			// the nodes out of the selected node pool are not installed or joined
+			return !(versionOk || ipOk) && common.IsInNodePool(host, n.KubeConf.Arg.NodePool), nil
 		}
 		return versionOk || ipOk, nil
 	} else {
@@ -130,3 +136,61 @@ func (n *NotEqualDesiredVersion) PreCheck(runtime connector.Runtime) (bool, erro
 	}
 	return true, nil
 }
+
+// DstNode only runs on the node which is expected to be deleted.
+type DstNode struct {
+	common.KubePrepare
+}
+
+func (d *DstNode) PreCheck(runtime connector.Runtime) (bool, error) {
+	return runtime.RemoteHost().GetName() == d.KubeConf.Arg.NodeName, nil
+}
+
+// APIServerCertSANsChanged skips the control-plane node whose apiserver cert already has all the cert SANs of the cluster.
+type APIServerCertSANsChanged struct {
+	common.KubePrepare
+}
+
+func (a *APIServerCertSANsChanged) PreCheck(runtime connector.Runtime) (bool, error) {
+	out, err := runtime.GetRunner().SudoCmd("cat /etc/kubernetes/pki/apiserver.crt", false)
+	if err != nil {
+		return false, errors.Wrap(errors.WithStack(err), "read the apiserver cert failed")
+	}
+	certs, err := certutil.ParseCertsPEM([]byte(out))
+	if err != nil {
+		return false, errors.Wrap(err, "parse the apiserver cert failed")
+	}
+	missing := missingCertSANs(certs[0], apiServerCertSANs(a.KubeConf))
+	if len(missing) == 0 {
+		return false, nil
+	}
+	logger.Log.Infof("%s: the apiserver cert misses the SANs %v", runtime.RemoteHost().GetName(), missing)
+	return true, nil
+}
+
+// missingCertSANs returns the SANs which are not in the cert.
+func missingCertSANs(cert *x509.Certificate, sans []string) []string {
+	var missing []string
+	for _, san := range sans {
+		found := false
+		if ip := netutils.ParseIPSloppy(san); ip != nil {
+			for _, certIP := range cert.IPAddresses {
+				if certIP.Equal(ip) {
+					found = true
+					break
+				}
+			}
+		} else {
+			for _, name := range cert.DNSNames {
+				if name == san {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			missing = append(missing, san)
+		}
+	}
+	return missing
+}
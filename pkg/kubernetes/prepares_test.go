@@ -0,0 +1,48 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"crypto/x509"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestMissingCertSANs(t *testing.T) {
+	cert := &x509.Certificate{
+		DNSNames:    []string{"kubernetes", "lb.kubesphere.local"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("192.168.0.2")},
+	}
+	tests := []struct {
+		name string
+		sans []string
+		want []string
+	}{
+		{name: "unchanged", sans: []string{"kubernetes", "lb.kubesphere.local", "127.0.0.1", "192.168.0.2"}},
+		{name: "subset", sans: []string{"lb.kubesphere.local", "192.168.0.2"}},
+		{name: "new VIP", sans: []string{"lb.kubesphere.local", "192.168.0.100"}, want: []string{"192.168.0.100"}},
+		{name: "new domain", sans: []string{"lb.example.com", "192.168.0.2"}, want: []string{"lb.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingCertSANs(cert, tt.sans); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingCertSANs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package kubernetes
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/base64"
 	"fmt"
 	"os"
@@ -35,6 +36,9 @@ import (
 	versionutil "k8s.io/apimachinery/pkg/util/version"
 	kube "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
+	certutil "k8s.io/client-go/util/cert"
+	netutils "k8s.io/utils/net"
+	"sigs.k8s.io/yaml"
 
 	kubekeyv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/pkg/common"
@@ -51,6 +55,7 @@ import (
 	"github.com/kubesphere/kubekey/pkg/plugins/dns"
 	dnsTemplates "github.com/kubesphere/kubekey/pkg/plugins/dns/templates"
 	"github.com/kubesphere/kubekey/pkg/utils"
+	"github.com/kubesphere/kubekey/pkg/utils/certs"
 )
 
 type GetClusterStatus struct {
@@ -252,6 +257,15 @@ func (g *GenerateKubeadmConfig) Execute(runtime connector.Runtime) error {
 		_, ControllerManagerArgs := util.GetArgs(v1beta2.ControllermanagerArgs, g.KubeConf.Cluster.Kubernetes.ControllerManagerArgs)
 		_, SchedulerArgs := util.GetArgs(v1beta2.SchedulerArgs, g.KubeConf.Cluster.Kubernetes.SchedulerArgs)
 
+		var encryptionConfigDir, kmsDir string
+		if g.KubeConf.Cluster.Kubernetes.EnableEncryptionAtRest() {
+			encryptionConfigDir = EncryptionConfigDir
+			kmsDir = kmsSocketDir(&g.KubeConf.Cluster.Kubernetes.EncryptionAtRest)
+			if _, ok := ApiServerArgs["encryption-provider-config"]; !ok {
+				ApiServerArgs["encryption-provider-config"] = EncryptionConfigFile
+			}
+		}
+
 		checkCgroupDriver, err := v1beta2.GetKubeletCgroupDriver(runtime, g.KubeConf)
 		if err != nil {
 			return err
@@ -292,6 +306,8 @@ func (g *GenerateKubeadmConfig) Execute(runtime connector.Runtime) error {
 				"PodSubnet":              g.KubeConf.Cluster.Network.KubePodsCIDR,
 				"ServiceSubnet":          g.KubeConf.Cluster.Network.KubeServiceCIDR,
 				"CertSANs":               g.KubeConf.Cluster.GenerateCertSANs(),
+				"EncryptionConfigDir":    encryptionConfigDir,
+				"KMSSocketDir":           kmsDir,
 				"ExternalEtcd":           externalEtcd,
 				"NodeCidrMaskSize":       g.KubeConf.Cluster.Kubernetes.NodeCidrMaskSize,
 				"CriSock":                g.KubeConf.Cluster.Kubernetes.ContainerRuntimeEndpoint,
@@ -301,6 +317,8 @@ func (g *GenerateKubeadmConfig) Execute(runtime connector.Runtime) error {
 				"KubeletConfiguration":   v1beta2.GetKubeletConfiguration(runtime, g.KubeConf, g.KubeConf.Cluster.Kubernetes.ContainerRuntimeEndpoint),
 				"KubeProxyConfiguration": v1beta2.GetKubeProxyConfiguration(g.KubeConf),
 				"IsControlPlane":         host.IsRole(common.Master),
+				"KubeletExtraArgs":       kubeletExtraArgs(host.(*kubekeyv1alpha2.KubeHost)),
+				"Taints":                 nodeRegistrationTaints(host.(*kubekeyv1alpha2.KubeHost), g.KubeConf.Cluster.Kubernetes.Version),
 				"CgroupDriver":           checkCgroupDriver,
 				"BootstrapToken":         bootstrapToken,
 				"CertificateKey":         certificateKey,
@@ -489,41 +507,31 @@ type FindNode struct {
 }
 
 func (f *FindNode) Execute(runtime connector.Runtime) error {
-	var resArr []string
-	res, err := runtime.GetRunner().Cmd(
-		"sudo -E /usr/local/bin/kubectl get nodes | grep -v NAME | grep -v 'master\\|control-plane' | awk '{print $1}'",
-		true)
-	if err != nil {
-		return errors.Wrap(errors.WithStack(err), "kubectl get nodes failed")
-	}
-
-	if !strings.Contains(res, "\r\n") {
-		resArr = append(resArr, res)
-	} else {
-		resArr = strings.Split(res, "\r\n")
-	}
-
-	workerName := make(map[string]struct{})
-	for j := 0; j < len(runtime.GetHostsByRole(common.Worker)); j++ {
-		workerName[runtime.GetHostsByRole(common.Worker)[j].GetName()] = struct{}{}
-	}
-
-	var node string
-	for i := 0; i < len(resArr); i++ {
-		if _, ok := workerName[resArr[i]]; ok && resArr[i] == f.KubeConf.Arg.NodeName {
-			node = resArr[i]
-			break
+	nodeName := f.KubeConf.Arg.NodeName
+	// The node may have been removed from the runtime if it can not be connected, so it is looked up in the config.
+	var found bool
+	for _, host := range f.KubeConf.Cluster.Hosts {
+		if host.Name == nodeName {
+			found = true
 		}
 	}
 
-	if node == "" {
+	if !found {
 		return errors.New("" +
 			"1. check the node name in the config-sample.yaml\n" +
-			"2. check the node name in the Kubernetes cluster\n" +
-			"3. only support to delete a worker\n")
+			"2. check the node name in the Kubernetes cluster\n")
+	}
+
+	for _, host := range runtime.GetHostsByRole(common.K8s) {
+		if host.GetName() != nodeName {
+			continue
+		}
+		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("/usr/local/bin/kubectl get node %s", nodeName), false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "the node %s is not found in the Kubernetes cluster", nodeName)
+		}
 	}
 
-	f.PipelineCache.Set("dstNode", node)
+	f.PipelineCache.Set("dstNode", nodeName)
 	return nil
 }
 
@@ -554,13 +562,288 @@ func (k *KubectlDeleteNode) Execute(runtime connector.Runtime) error {
 		return errors.New("get dstNode failed by pipeline cache")
 	}
 	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
-		"/usr/local/bin/kubectl delete node %s", nodeName),
+		"/usr/local/bin/kubectl delete node %s --ignore-not-found", nodeName),
 		true); err != nil {
 		return errors.Wrap(err, "delete the node failed")
 	}
 	return nil
 }
 
+// patchKubeadmConfig applies the patch to the document of the key in the kubeadm-config ConfigMap.
+// The ConfigMap is not updated if the key does not exist or the patch returns false.
+func patchKubeadmConfig(runtime connector.Runtime, key string, patch func(doc map[string]interface{}) bool) error {
+	return patchConfigMap(runtime, "kube-system", "kubeadm-config", key, patch)
+}
+
+// PatchClusterInfo applies the patch to each cluster of the kubeconfig in the kube-public/cluster-info ConfigMap,
+// which is used by kubeadm join to discover the cluster. The signatures of the kubeconfig are updated by the
+// bootstrap signer of kube-controller-manager.
+func PatchClusterInfo(runtime connector.Runtime, patch func(cluster map[string]interface{}) bool) error {
+	return patchConfigMap(runtime, "kube-public", "cluster-info", "kubeconfig", func(kubeconfig map[string]interface{}) bool {
+		patched := false
+		clusters, _ := kubeconfig["clusters"].([]interface{})
+		for _, c := range clusters {
+			namedCluster, _ := c.(map[string]interface{})
+			if cluster, ok := namedCluster["cluster"].(map[string]interface{}); ok && patch(cluster) {
+				patched = true
+			}
+		}
+		return patched
+	})
+}
+
+// patchConfigMap applies the patch to the yaml document of the key in the ConfigMap.
+// The ConfigMap is not updated if the key does not exist or the patch returns false.
+func patchConfigMap(runtime connector.Runtime, namespace, name, key string, patch func(doc map[string]interface{}) bool) error {
+	out, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("/usr/local/bin/kubectl -n %s get cm %s -o yaml", namespace, name), false)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "get %s failed", name)
+	}
+	cm := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(out), &cm); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "unmarshal %s failed", name)
+	}
+
+	data, _ := cm["data"].(map[string]interface{})
+	docStr, ok := data[key].(string)
+	if !ok {
+		return nil
+	}
+	doc := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(docStr), &doc); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "unmarshal %s of %s failed", key, name)
+	}
+	if !patch(doc) {
+		return nil
+	}
+
+	docBytes, err := yaml.Marshal(doc)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "marshal %s of %s failed", key, name)
+	}
+	data[key] = string(docBytes)
+	cmBytes, err := yaml.Marshal(cm)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "marshal %s failed", name)
+	}
+
+	cmPath := filepath.Join(common.TmpDir, name+".yaml")
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("echo %s | base64 -d > %s && /usr/local/bin/kubectl replace -f %s",
+		base64.StdEncoding.EncodeToString(cmBytes), cmPath, cmPath), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "update %s failed", name)
+	}
+	return nil
+}
+
+type UpdateKubeadmClusterStatus struct {
+	common.KubeAction
+}
+
+// Execute removes the api endpoint of the deleted control-plane node from the ClusterStatus of kubeadm-config.
+// The ClusterStatus is only kept in kubeadm-config before Kubernetes v1.22.
+func (u *UpdateKubeadmClusterStatus) Execute(runtime connector.Runtime) error {
+	nodeName, ok := u.PipelineCache.GetMustString("dstNode")
+	if !ok {
+		return errors.New("get dstNode failed by pipeline cache")
+	}
+
+	return patchKubeadmConfig(runtime, "ClusterStatus", func(clusterStatus map[string]interface{}) bool {
+		endpoints, _ := clusterStatus["apiEndpoints"].(map[string]interface{})
+		if _, ok := endpoints[nodeName]; !ok {
+			return false
+		}
+		delete(endpoints, nodeName)
+		return true
+	})
+}
+
+// apiServerCertSANs returns the distinct and non-empty cert SANs of the cluster.
+func apiServerCertSANs(kubeConf *common.KubeConf) []string {
+	var sans []string
+	seen := make(map[string]struct{})
+	for _, san := range kubeConf.Cluster.GenerateCertSANs() {
+		if _, ok := seen[san]; ok || san == "" {
+			continue
+		}
+		seen[san] = struct{}{}
+		sans = append(sans, san)
+	}
+	return sans
+}
+
+type UpdateKubeadmCertSANs struct {
+	common.KubeAction
+}
+
+// Execute updates the apiServer.certSANs in the ClusterConfiguration of kubeadm-config, which is used by kubeadm join.
+func (u *UpdateKubeadmCertSANs) Execute(runtime connector.Runtime) error {
+	certSANs := apiServerCertSANs(u.KubeConf)
+	return patchKubeadmConfig(runtime, "ClusterConfiguration", func(clusterConfiguration map[string]interface{}) bool {
+		apiServer, ok := clusterConfiguration["apiServer"].(map[string]interface{})
+		if !ok {
+			apiServer = make(map[string]interface{})
+			clusterConfiguration["apiServer"] = apiServer
+		}
+		apiServer["certSANs"] = certSANs
+		return true
+	})
+}
+
+type RegenerateAPIServerCert struct {
+	common.KubeAction
+}
+
+// Execute reissues the apiserver serving certificate with the cert SANs of the config.
+// The previous certificate is restored if kubeadm fails. kube-apiserver reloads the serving certificate by itself.
+func (r *RegenerateAPIServerCert) Execute(runtime connector.Runtime) error {
+	if r.KubeConf.Cluster.Kubernetes.PKI.Cert("apiserver") != nil {
+		return errors.New("the apiserver cert is supplied, reissue it with the new cert SANs and sync it to the control-plane nodes instead")
+	}
+	host := runtime.RemoteHost()
+	kubeadmCmd := fmt.Sprintf("/usr/local/bin/kubeadm init phase certs apiserver "+
+		"--apiserver-advertise-address %s --control-plane-endpoint %s:%d --service-cidr %s --service-dns-domain %s "+
+		"--kubernetes-version %s --apiserver-cert-extra-sans %s",
+		host.GetInternalAddress(),
+		r.KubeConf.Cluster.ControlPlaneEndpoint.Domain, r.KubeConf.Cluster.ControlPlaneEndpoint.Port,
+		r.KubeConf.Cluster.Network.KubeServiceCIDR,
+		r.KubeConf.Cluster.Kubernetes.DNSDomain,
+		r.KubeConf.Cluster.Kubernetes.Version,
+		strings.Join(apiServerCertSANs(r.KubeConf), ","))
+
+	cmd := fmt.Sprintf("cd /etc/kubernetes/pki && "+
+		"cp -f apiserver.crt apiserver.crt.bak && cp -f apiserver.key apiserver.key.bak && "+
+		"rm -f apiserver.crt apiserver.key && "+
+		"(%s || (mv -f apiserver.crt.bak apiserver.crt && mv -f apiserver.key.bak apiserver.key && exit 1))", kubeadmCmd)
+	if _, err := runtime.GetRunner().SudoCmd(cmd, true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "regenerate apiserver certificate failed")
+	}
+	return nil
+}
+
+type UpdateKubeadmControlPlaneEndpoint struct {
+	common.KubeAction
+}
+
+// Execute updates the controlPlaneEndpoint in the ClusterConfiguration of kubeadm-config, which is used by kubeadm join.
+func (u *UpdateKubeadmControlPlaneEndpoint) Execute(runtime connector.Runtime) error {
+	endpoint := fmt.Sprintf("%s:%d", u.KubeConf.Cluster.ControlPlaneEndpoint.Domain, u.KubeConf.Cluster.ControlPlaneEndpoint.Port)
+	return patchKubeadmConfig(runtime, "ClusterConfiguration", func(clusterConfiguration map[string]interface{}) bool {
+		if clusterConfiguration["controlPlaneEndpoint"] == endpoint {
+			return false
+		}
+		clusterConfiguration["controlPlaneEndpoint"] = endpoint
+		return true
+	})
+}
+
+type UpdateKubeconfigServer struct {
+	common.KubeAction
+}
+
+// Execute updates the port of the control plane endpoint in the kubeconfig files written by kubeadm, and restarts
+// kube-controller-manager and kube-scheduler to reconnect with the new port.
+func (u *UpdateKubeconfigServer) Execute(runtime connector.Runtime) error {
+	endpoint := u.KubeConf.Cluster.ControlPlaneEndpoint
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"sed -i 's#server: https://%s:[0-9]*#server: https://%s:%d#g' "+
+			"/etc/kubernetes/admin.conf /etc/kubernetes/controller-manager.conf /etc/kubernetes/scheduler.conf",
+		endpoint.Domain, endpoint.Domain, endpoint.Port), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "update the server of the kubeconfig files failed")
+	}
+
+	cmds := RestartControlPlaneCmds(u.KubeConf.Cluster.Kubernetes.ContainerManager, "kube-controller-manager", "kube-scheduler")
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(cmds, " && "), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restart kube-controller-manager and kube-scheduler failed")
+	}
+	return nil
+}
+
+type UpdateClusterInfoServer struct {
+	common.KubeAction
+}
+
+// Execute updates the server of the kubeconfig in the cluster-info ConfigMap, which is used by kubeadm join.
+func (u *UpdateClusterInfoServer) Execute(runtime connector.Runtime) error {
+	endpoint := u.KubeConf.Cluster.ControlPlaneEndpoint
+	server := fmt.Sprintf("https://%s:%d", endpoint.Domain, endpoint.Port)
+	return PatchClusterInfo(runtime, func(cluster map[string]interface{}) bool {
+		if cluster["server"] == server {
+			return false
+		}
+		cluster["server"] = server
+		return true
+	})
+}
+
+type GenerateKubernetesPKI struct {
+	common.KubeAction
+}
+
+// Execute validates the CA and the apiserver cert supplied by users, and writes them to the work dir.
+// kubeadm signs the other certs with the supplied CA, so the key of the CA is required.
+func (g *GenerateKubernetesPKI) Execute(runtime connector.Runtime) error {
+	pki := &g.KubeConf.Cluster.Kubernetes.PKI
+	if pki.CAKeyFile == "" {
+		return errors.New("the key of the kubernetes CA is required, since kubeadm signs the other certs with it")
+	}
+
+	pkiPath := filepath.Join(runtime.GetWorkDir(), "pki", "kubernetes")
+	if err := os.RemoveAll(pkiPath); err != nil {
+		return errors.Wrapf(err, "failed to clean the dir %s", pkiPath)
+	}
+
+	var altNames certutil.AltNames
+	for _, san := range apiServerCertSANs(g.KubeConf) {
+		if ip := netutils.ParseIPSloppy(san); ip != nil {
+			altNames.IPs = append(altNames.IPs, ip)
+		} else {
+			altNames.DNSNames = append(altNames.DNSNames, san)
+		}
+	}
+	ca := &certs.KubekeyCert{Name: "ca", BaseName: "ca"}
+	apiServer := &certs.KubekeyCert{
+		Name:     "apiserver",
+		BaseName: "apiserver",
+		CAName:   "ca",
+		Config: certs.CertConfig{
+			Config: certutil.Config{
+				Usages:   []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+				AltNames: altNames,
+			},
+		},
+	}
+	// The apiserver cert which is not supplied is signed by kubeadm.
+	if _, err := certs.WriteExternalPKI(pki, ca, []*certs.KubekeyCert{apiServer}, pkiPath); err != nil {
+		return errors.Wrap(err, "failed to use the kubernetes PKI supplied")
+	}
+	return nil
+}
+
+type SyncKubernetesPKI struct {
+	common.KubeAction
+}
+
+// Execute copies the CA and the apiserver cert supplied by users to the pki dir of kubeadm.
+func (s *SyncKubernetesPKI) Execute(runtime connector.Runtime) error {
+	pkiPath := filepath.Join(runtime.GetWorkDir(), "pki", "kubernetes")
+	for _, name := range []string{"ca", "apiserver"} {
+		certPath, keyPath := certs.PathsForCertAndKey(pkiPath, name)
+		if !util.IsExist(certPath) {
+			continue
+		}
+		if err := runtime.GetRunner().SudoScp(certPath, fmt.Sprintf("/etc/kubernetes/pki/%s.crt", name)); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "sync the %s cert failed", name)
+		}
+		if err := runtime.GetRunner().SudoScp(keyPath, fmt.Sprintf("/etc/kubernetes/pki/%s.key", name)); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "sync the %s key failed", name)
+		}
+	}
+	if _, err := runtime.GetRunner().SudoCmd("chmod 600 /etc/kubernetes/pki/*.key", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "change the mode of keys failed")
+	}
+	return nil
+}
+
 type SetUpgradePlan struct {
 	common.KubeAction
 	Step UpgradeStep
@@ -634,7 +917,7 @@ func calculateNextStr(currentVersion, desiredVersion string) string {
 		return desiredVersion
 	} else {
 		nextVersionPatchList := make([]int, 0)
-		for supportVersionStr := range files.FileSha256["kubeadm"]["amd64"] {
+		for _, supportVersionStr := range files.GetCatalog().Versions("kubeadm", "amd64") {
 			supportVersion := versionutil.MustParseSemantic(supportVersionStr)
 			if supportVersion.Minor() == nextVersionMinor {
 				nextVersionPatchList = append(nextVersionPatchList, int(supportVersion.Patch()))
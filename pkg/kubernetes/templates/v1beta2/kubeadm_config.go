@@ -87,6 +87,18 @@ apiServer:
     {{- range .CertSANs }}
     - {{ . }}
     {{- end }}
+{{- if .EncryptionConfigDir }}
+  extraVolumes:
+  - name: encryption-config
+    hostPath: {{ .EncryptionConfigDir }}
+    mountPath: {{ .EncryptionConfigDir }}
+    readOnly: true
+{{- if .KMSSocketDir }}
+  - name: kms-socket
+    hostPath: {{ .KMSSocketDir }}
+    mountPath: {{ .KMSSocketDir }}
+{{- end }}
+{{- end }}
 controllerManager:
   extraArgs:
     node-cidr-mask-size: "{{ .NodeCidrMaskSize }}"
@@ -112,6 +124,19 @@ nodeRegistration:
 {{- end }}
   kubeletExtraArgs:
     cgroup-driver: {{ .CgroupDriver }}
+{{- range $k, $v := .KubeletExtraArgs }}
+    {{ $k }}: "{{ $v }}"
+{{- end }}
+{{- if .Taints }}
+  taints:
+{{- range .Taints }}
+  - key: {{ .Key }}
+{{- if .Value }}
+    value: "{{ .Value }}"
+{{- end }}
+    effect: {{ .Effect }}
+{{- end }}
+{{- end }}
 ---
 apiVersion: kubeproxy.config.k8s.io/v1alpha1
 kind: KubeProxyConfiguration
@@ -144,6 +169,19 @@ nodeRegistration:
 {{- end }}
   kubeletExtraArgs:
     cgroup-driver: {{ .CgroupDriver }}
+{{- range $k, $v := .KubeletExtraArgs }}
+    {{ $k }}: "{{ $v }}"
+{{- end }}
+{{- if .Taints }}
+  taints:
+{{- range .Taints }}
+  - key: {{ .Key }}
+{{- if .Value }}
+    value: "{{ .Value }}"
+{{- end }}
+    effect: {{ .Effect }}
+{{- end }}
+{{- end }}
 
 {{- end }}
     `)))
@@ -16,6 +16,54 @@
 
 package loadbalancer
 
+import (
+	"fmt"
+	"strings"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/common"
+)
+
 const (
 	LocalServer = "server: https://127.0.0.1"
+
+	// VIPInterface is the host cache key of the network interface which the VIP is bound to.
+	VIPInterface = "vipInterface"
+
+	keepalivedPriority = 100
 )
+
+// apiServer returns server, or the local haproxy if it is empty.
+func apiServer(server string, kubeConf *common.KubeConf) string {
+	if server != "" {
+		return server
+	}
+	return fmt.Sprintf("https://127.0.0.1:%d", kubeConf.Cluster.ControlPlaneEndpoint.Port)
+}
+
+// VIPServer returns the address of the apiserver through the VIP.
+func VIPServer(kubeConf *common.KubeConf) string {
+	return fmt.Sprintf("https://%s:%d", kubeConf.Cluster.ControlPlaneEndpoint.Domain, kubeConf.Cluster.ControlPlaneEndpoint.Port)
+}
+
+// vipInterface returns the network interface of the VIP set by the config, kube-vip announces the VIP
+// on the loopback interface in the bgp mode.
+func vipInterface(endpoint kubekeyapiv1alpha2.ControlPlaneEndpoint) string {
+	if endpoint.IsKeepalivedEnabled() {
+		return endpoint.Keepalived.Interface
+	}
+	if endpoint.KubeVip.Interface == "" && endpoint.KubeVip.Mode == kubekeyapiv1alpha2.KubeVipBGPMode {
+		return "lo"
+	}
+	return endpoint.KubeVip.Interface
+}
+
+// kubeVipBGPPeers formats the peers as the bgp_peers of kube-vip, which is a comma separated list of
+// address:as:password:multihop.
+func kubeVipBGPPeers(peers []kubekeyapiv1alpha2.BGPPeer) string {
+	list := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		list = append(list, fmt.Sprintf("%s:%d:%s:%t", peer.Address, peer.AS, peer.Password, peer.Multihop))
+	}
+	return strings.Join(list, ",")
+}
@@ -0,0 +1,91 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"testing"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+)
+
+func TestKubeVipBGPPeers(t *testing.T) {
+	tests := []struct {
+		name  string
+		peers []kubekeyapiv1alpha2.BGPPeer
+		want  string
+	}{
+		{name: "no peers", want: ""},
+		{
+			name:  "single peer",
+			peers: []kubekeyapiv1alpha2.BGPPeer{{Address: "192.168.0.1", AS: 65000}},
+			want:  "192.168.0.1:65000::false",
+		},
+		{
+			name: "multiple peers",
+			peers: []kubekeyapiv1alpha2.BGPPeer{
+				{Address: "192.168.0.1", AS: 65000, Password: "secret"},
+				{Address: "192.168.1.1", AS: 65001, Multihop: true},
+			},
+			want: "192.168.0.1:65000:secret:false,192.168.1.1:65001::true",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kubeVipBGPPeers(tt.peers); got != tt.want {
+				t.Errorf("kubeVipBGPPeers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVIPInterface(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint kubekeyapiv1alpha2.ControlPlaneEndpoint
+		want     string
+	}{
+		{
+			name:     "kube-vip arp",
+			endpoint: kubekeyapiv1alpha2.ControlPlaneEndpoint{InternalLoadbalancer: kubekeyapiv1alpha2.KubeVip, KubeVip: kubekeyapiv1alpha2.KubeVipCfg{Mode: "arp"}},
+			want:     "",
+		},
+		{
+			name:     "kube-vip bgp",
+			endpoint: kubekeyapiv1alpha2.ControlPlaneEndpoint{InternalLoadbalancer: kubekeyapiv1alpha2.KubeVip, KubeVip: kubekeyapiv1alpha2.KubeVipCfg{Mode: "bgp"}},
+			want:     "lo",
+		},
+		{
+			name: "kube-vip bgp with interface",
+			endpoint: kubekeyapiv1alpha2.ControlPlaneEndpoint{InternalLoadbalancer: kubekeyapiv1alpha2.KubeVip,
+				KubeVip: kubekeyapiv1alpha2.KubeVipCfg{Mode: "bgp", Interface: "eth1"}},
+			want: "eth1",
+		},
+		{
+			name: "keepalived",
+			endpoint: kubekeyapiv1alpha2.ControlPlaneEndpoint{InternalLoadbalancer: kubekeyapiv1alpha2.KeepalivedHaproxy,
+				Keepalived: kubekeyapiv1alpha2.KeepalivedCfg{Interface: "eth0"}},
+			want: "eth0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vipInterface(tt.endpoint); got != tt.want {
+				t.Errorf("vipInterface() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
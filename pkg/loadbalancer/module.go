@@ -17,6 +17,7 @@
 package loadbalancer
 
 import (
+	"fmt"
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/action"
@@ -26,6 +27,7 @@ import (
 	"github.com/kubesphere/kubekey/pkg/core/util"
 	"github.com/kubesphere/kubekey/pkg/loadbalancer/templates"
 	"path/filepath"
+	"time"
 )
 
 type HaproxyModule struct {
@@ -51,6 +53,7 @@ func (h *HaproxyModule) Init() {
 			Dst:      filepath.Join(common.HaproxyDir, templates.HaproxyConfig.Name()),
 			Data: util.Data{
 				"MasterNodes":                          templates.MasterNodeStr(h.Runtime, h.KubeConf),
+				"LoadbalancerApiserverAddress":         "127.0.0.1",
 				"LoadbalancerApiserverPort":            kubekeyapiv1alpha2.DefaultApiserverPort,
 				"LoadbalancerApiserverHealthcheckPort": 8081,
 				"KubernetesType":                       h.KubeConf.Cluster.Kubernetes.Type,
@@ -159,6 +162,7 @@ func (k *K3sHaproxyModule) Init() {
 			Dst:      filepath.Join(common.HaproxyDir, templates.HaproxyConfig.Name()),
 			Data: util.Data{
 				"MasterNodes":                          templates.MasterNodeStr(k.Runtime, k.KubeConf),
+				"LoadbalancerApiserverAddress":         "127.0.0.1",
 				"LoadbalancerApiserverPort":            k.KubeConf.Cluster.ControlPlaneEndpoint.Port,
 				"LoadbalancerApiserverHealthcheckPort": 8081,
 				"KubernetesType":                       k.KubeConf.Cluster.Kubernetes.Type,
@@ -222,3 +226,206 @@ func (k *K3sHaproxyModule) Init() {
 		updateHostsFile,
 	}
 }
+
+// VIPModule runs kube-vip, or keepalived and haproxy, as static pods on the masters to float the address
+// of the control plane endpoint among them. It deploys the first master before kubeadm init, and the
+// other masters after they joined the cluster.
+type VIPModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (v *VIPModule) IsSkip() bool {
+	return v.Skip
+}
+
+func (v *VIPModule) Init() {
+	v.Name = "VIPModule"
+	v.Desc = "Deploy the VIP of the control plane endpoint"
+
+	endpoint := v.KubeConf.Cluster.ControlPlaneEndpoint
+
+	detectInterface := &task.RemoteTask{
+		Name:     "DetectVIPInterface",
+		Desc:     "Detect the network interface of the VIP",
+		Hosts:    v.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(deployVIPPrepare),
+		Action:   new(DetectVIPInterface),
+		Parallel: true,
+	}
+
+	v.Tasks = []task.Interface{detectInterface}
+
+	if endpoint.IsKeepalivedEnabled() {
+		haproxyCfg := &task.RemoteTask{
+			Name:    "GenerateHaproxyConfig",
+			Desc:    "Generate haproxy.cfg",
+			Hosts:   v.Runtime.GetHostsByRole(common.Master),
+			Prepare: new(deployVIPPrepare),
+			Action: &action.Template{
+				Template: templates.HaproxyConfig,
+				Dst:      filepath.Join(common.HaproxyDir, templates.HaproxyConfig.Name()),
+				Data: util.Data{
+					"MasterNodes":                          templates.MasterNodeStr(v.Runtime, v.KubeConf),
+					"LoadbalancerApiserverAddress":         "*",
+					"LoadbalancerApiserverPort":            endpoint.Port,
+					"LoadbalancerApiserverHealthcheckPort": 8081,
+					"KubernetesType":                       v.KubeConf.Cluster.Kubernetes.Type,
+				},
+			},
+			Parallel: true,
+		}
+
+		getMd5Sum := &task.RemoteTask{
+			Name:     "GetChecksumFromConfig",
+			Desc:     "Calculate the MD5 value according to haproxy.cfg",
+			Hosts:    v.Runtime.GetHostsByRole(common.Master),
+			Prepare:  new(deployVIPPrepare),
+			Action:   new(GetChecksum),
+			Parallel: true,
+		}
+
+		haproxyManifest := &task.RemoteTask{
+			Name:     "GenerateHaproxyManifest",
+			Desc:     "Generate haproxy manifest",
+			Hosts:    v.Runtime.GetHostsByRole(common.Master),
+			Prepare:  new(deployVIPPrepare),
+			Action:   new(GenerateHaproxyManifest),
+			Parallel: true,
+		}
+
+		keepalivedCfg := &task.RemoteTask{
+			Name:     "GenerateKeepalivedConfig",
+			Desc:     "Generate keepalived.conf",
+			Hosts:    v.Runtime.GetHostsByRole(common.Master),
+			Prepare:  new(deployVIPPrepare),
+			Action:   new(GenerateKeepalivedConfig),
+			Parallel: true,
+		}
+
+		keepalivedManifest := &task.RemoteTask{
+			Name:     "GenerateKeepalivedManifest",
+			Desc:     "Generate keepalived manifest",
+			Hosts:    v.Runtime.GetHostsByRole(common.Master),
+			Prepare:  new(deployVIPPrepare),
+			Action:   new(GenerateKeepalivedManifest),
+			Parallel: true,
+		}
+
+		v.Tasks = append(v.Tasks, haproxyCfg, getMd5Sum, haproxyManifest, keepalivedCfg, keepalivedManifest)
+	} else {
+		kubeVipManifest := &task.RemoteTask{
+			Name:     "GenerateKubeVipManifest",
+			Desc:     "Generate kube-vip manifest",
+			Hosts:    v.Runtime.GetHostsByRole(common.Master),
+			Prepare:  new(deployVIPPrepare),
+			Action:   new(GenerateKubeVipManifest),
+			Parallel: true,
+		}
+
+		v.Tasks = append(v.Tasks, kubeVipManifest)
+	}
+}
+
+// UpdateServerModule points the kubelet, the kube-proxy and the /etc/hosts of the nodes to the address of
+// the control plane endpoint, which is the VIP or the external load balancer. All nodes must have joined the cluster.
+type UpdateServerModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (u *UpdateServerModule) IsSkip() bool {
+	return u.Skip
+}
+
+func (u *UpdateServerModule) Init() {
+	u.Name = "UpdateServerModule"
+	u.Desc = "Connect the nodes to the control plane endpoint"
+
+	checkEndpoint := &task.RemoteTask{
+		Name:     "CheckControlPlaneEndpoint",
+		Desc:     "Check the apiserver through the address of the control plane endpoint",
+		Hosts:    []connector.Host{u.Runtime.GetHostsByRole(common.Master)[0]},
+		Action:   new(CheckControlPlaneEndpoint),
+		Parallel: true,
+		Retry:    20,
+		Delay:    5 * time.Second,
+	}
+
+	updateHostsFile := &task.RemoteTask{
+		Name:     "UpdateHostsFile",
+		Desc:     "Update /etc/hosts",
+		Hosts:    u.Runtime.GetHostsByRole(common.K8s),
+		Action:   &UpdateHosts{Address: u.KubeConf.Cluster.ControlPlaneEndpoint.Address},
+		Parallel: true,
+		Retry:    3,
+	}
+
+	updateKubeletConfig := &task.RemoteTask{
+		Name:  "UpdateKubeletConfig",
+		Desc:  "Update kubelet config",
+		Hosts: u.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			new(common.OnlyKubernetes),
+			&updateKubeletPrepare{Server: VIPServer(u.KubeConf)},
+		},
+		Action:   &UpdateKubelet{Server: VIPServer(u.KubeConf)},
+		Parallel: false,
+		Retry:    3,
+	}
+
+	updateKubeProxyConfig := &task.RemoteTask{
+		Name:  "UpdateKubeProxyConfig",
+		Desc:  "Update kube-proxy configmap",
+		Hosts: []connector.Host{u.Runtime.GetHostsByRole(common.Master)[0]},
+		Prepare: &prepare.PrepareCollection{
+			new(common.EnableKubeProxy),
+			new(common.OnlyKubernetes),
+			new(common.OnlyFirstMaster),
+			&updateKubeProxyPrapre{Server: VIPServer(u.KubeConf)},
+		},
+		Action:   &UpdateKubeProxy{Server: VIPServer(u.KubeConf)},
+		Parallel: true,
+		Retry:    3,
+	}
+
+	u.Tasks = []task.Interface{
+		checkEndpoint,
+		updateHostsFile,
+		updateKubeletConfig,
+		updateKubeProxyConfig,
+	}
+}
+
+// RemoveModule removes the internal load balancer in Mode from the nodes one by one.
+type RemoveModule struct {
+	common.KubeModule
+	Skip bool
+	Mode string
+}
+
+func (r *RemoveModule) IsSkip() bool {
+	return r.Skip || r.Mode == ""
+}
+
+func (r *RemoveModule) Init() {
+	r.Name = "RemoveInternalLoadbalancerModule"
+	r.Desc = "Remove the internal load balancer"
+
+	remove := &task.RemoteTask{
+		Name:     "RemoveLoadBalancer",
+		Desc:     fmt.Sprintf("Remove %s", r.Mode),
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   &RemoveLoadBalancer{Mode: r.Mode},
+		Parallel: false,
+	}
+	// haproxy of the internal load balancer runs on the workers.
+	if r.Mode == kubekeyapiv1alpha2.Haproxy {
+		remove.Hosts = r.Runtime.GetHostsByRole(common.Worker)
+		remove.Prepare = new(common.OnlyWorker)
+	}
+
+	r.Tasks = []task.Interface{
+		remove,
+	}
+}
@@ -49,8 +49,10 @@ func (u *updateK3sPrepare) PreCheck(runtime connector.Runtime) (bool, error) {
 	return true, nil
 }
 
+// updateKubeletPrepare skips the kubelet which has connected to Server, or the local haproxy if it is empty.
 type updateKubeletPrepare struct {
 	common.KubePrepare
+	Server string
 }
 
 func (u *updateKubeletPrepare) PreCheck(runtime connector.Runtime) (bool, error) {
@@ -63,7 +65,7 @@ func (u *updateKubeletPrepare) PreCheck(runtime connector.Runtime) (bool, error)
 		if out, err := runtime.GetRunner().SudoCmd("sed -n '/server:.*/p' /etc/kubernetes/kubelet.conf", true); err != nil {
 			return false, err
 		} else {
-			if strings.Contains(strings.TrimSpace(out), LocalServer) {
+			if strings.Contains(strings.TrimSpace(out), expectedServer(u.Server)) {
 				logger.Log.Debugf("do not restart kubelet, /etc/kubernetes/kubelet.conf content is %s", out)
 				return false, nil
 			}
@@ -76,6 +78,7 @@ func (u *updateKubeletPrepare) PreCheck(runtime connector.Runtime) (bool, error)
 
 type updateKubeProxyPrapre struct {
 	common.KubePrepare
+	Server string
 }
 
 func (u *updateKubeProxyPrapre) PreCheck(runtime connector.Runtime) (bool, error) {
@@ -84,10 +87,30 @@ func (u *updateKubeProxyPrapre) PreCheck(runtime connector.Runtime) (bool, error
 			"| sed -n '/server:.*/p'", false); err != nil {
 		return false, err
 	} else {
-		if strings.Contains(strings.TrimSpace(out), LocalServer) {
+		if strings.Contains(strings.TrimSpace(out), expectedServer(u.Server)) {
 			logger.Log.Debugf("do not restart kube-proxy, configmap kube-proxy content is %s", out)
 			return false, nil
 		}
 	}
 	return true, nil
 }
+
+func expectedServer(server string) string {
+	if server == "" {
+		return LocalServer
+	}
+	return "server: " + server
+}
+
+// deployVIPPrepare skips the masters which have not joined the cluster except the first one,
+// because kubeadm refuses to join a node whose manifests directory is not empty.
+type deployVIPPrepare struct {
+	common.KubePrepare
+}
+
+func (d *deployVIPPrepare) PreCheck(runtime connector.Runtime) (bool, error) {
+	if runtime.RemoteHost().GetName() == runtime.GetHostsByRole(common.Master)[0].GetName() {
+		return true, nil
+	}
+	return runtime.GetRunner().FileExist("/etc/kubernetes/kubelet.conf")
+}
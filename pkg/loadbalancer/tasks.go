@@ -18,6 +18,7 @@ package loadbalancer
 
 import (
 	"fmt"
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/action"
 	"github.com/kubesphere/kubekey/pkg/core/connector"
@@ -26,7 +27,7 @@ import (
 	"github.com/kubesphere/kubekey/pkg/loadbalancer/templates"
 	"github.com/pkg/errors"
 	"path/filepath"
-	"strconv"
+	"strings"
 )
 
 type GetChecksum struct {
@@ -86,14 +87,16 @@ func (u *UpdateK3s) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+// UpdateKubelet points the kubelet to Server, which defaults to the local haproxy.
 type UpdateKubelet struct {
 	common.KubeAction
+	Server string
 }
 
 func (u *UpdateKubelet) Execute(runtime connector.Runtime) error {
 	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
-		"sed -i 's#server:.*#server: https://127.0.0.1:%s#g' /etc/kubernetes/kubelet.conf",
-		strconv.Itoa(u.KubeConf.Cluster.ControlPlaneEndpoint.Port)), false); err != nil {
+		"sed -i 's#server:.*#server: %s#g' /etc/kubernetes/kubelet.conf",
+		apiServer(u.Server, u.KubeConf)), false); err != nil {
 		return err
 	}
 	if _, err := runtime.GetRunner().SudoCmd("systemctl daemon-reload && systemctl restart kubelet", false); err != nil {
@@ -102,17 +105,19 @@ func (u *UpdateKubelet) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+// UpdateKubeProxy points the kube-proxy to Server, which defaults to the local haproxy.
 type UpdateKubeProxy struct {
 	common.KubeAction
+	Server string
 }
 
 func (u *UpdateKubeProxy) Execute(runtime connector.Runtime) error {
 	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
 		"set -o pipefail "+
 			"&& /usr/local/bin/kubectl --kubeconfig /etc/kubernetes/admin.conf get configmap kube-proxy -n kube-system -o yaml "+
-			"| sed 's#server:.*#server: https://127.0.0.1:%s#g' "+
+			"| sed 's#server:.*#server: %s#g' "+
 			"| /usr/local/bin/kubectl --kubeconfig /etc/kubernetes/admin.conf replace -f -",
-		strconv.Itoa(u.KubeConf.Cluster.ControlPlaneEndpoint.Port)), false); err != nil {
+		apiServer(u.Server, u.KubeConf)), false); err != nil {
 		return err
 	}
 	if _, err := runtime.GetRunner().SudoCmd("/usr/local/bin/kubectl "+
@@ -126,13 +131,19 @@ func (u *UpdateKubeProxy) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+// UpdateHosts resolves the domain of the control plane endpoint to Address, which defaults to 127.0.0.1.
 type UpdateHosts struct {
 	common.KubeAction
+	Address string
 }
 
 func (u *UpdateHosts) Execute(runtime connector.Runtime) error {
-	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("sed -i 's#.* %s#127.0.0.1 %s#g' /etc/hosts",
-		u.KubeConf.Cluster.ControlPlaneEndpoint.Domain, u.KubeConf.Cluster.ControlPlaneEndpoint.Domain), false); err != nil {
+	address := u.Address
+	if address == "" {
+		address = "127.0.0.1"
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("sed -i 's#.* %s#%s %s#g' /etc/hosts",
+		u.KubeConf.Cluster.ControlPlaneEndpoint.Domain, address, u.KubeConf.Cluster.ControlPlaneEndpoint.Domain), false); err != nil {
 		return err
 	}
 	return nil
@@ -165,3 +176,178 @@ func (g *GenerateK3sHaproxyManifest) Execute(runtime connector.Runtime) error {
 	}
 	return nil
 }
+
+// DetectVIPInterface caches the network interface which the VIP is bound to on the master.
+type DetectVIPInterface struct {
+	common.KubeAction
+}
+
+func (d *DetectVIPInterface) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	iface := vipInterface(d.KubeConf.Cluster.ControlPlaneEndpoint)
+	if iface == "" {
+		out, err := runtime.GetRunner().Cmd(fmt.Sprintf(
+			"ip -o -4 addr show | grep ' inet %s/' | cut -d' ' -f2", host.GetInternalAddress()), false)
+		if err != nil {
+			return errors.Wrapf(errors.WithStack(err), "find the network interface of %s failed", host.GetInternalAddress())
+		}
+		iface = strings.TrimSpace(out)
+		if iface == "" {
+			return errors.Errorf("no network interface has the address %s, please set the interface of the VIP", host.GetInternalAddress())
+		}
+	}
+	// type: string
+	host.GetCache().Set(VIPInterface, iface)
+	return nil
+}
+
+type GenerateKubeVipManifest struct {
+	common.KubeAction
+}
+
+func (g *GenerateKubeVipManifest) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	iface, ok := host.GetCache().GetMustString(VIPInterface)
+	if !ok {
+		return errors.New("get the network interface of the VIP by host label failed")
+	}
+
+	endpoint := g.KubeConf.Cluster.ControlPlaneEndpoint
+	templateAction := action.Template{
+		Template: templates.KubeVipManifest,
+		Dst:      filepath.Join(common.KubeManifestDir, templates.KubeVipManifest.Name()),
+		Data: util.Data{
+			"KubeVipImage": images.GetImage(runtime, g.KubeConf, "kube-vip").ImageName(),
+			"Address":      endpoint.Address,
+			"Port":         kubekeyapiv1alpha2.DefaultApiserverPort,
+			"Interface":    iface,
+			"Mode":         endpoint.KubeVip.Mode,
+			"RouterID":     host.GetInternalAddress(),
+			"AS":           endpoint.KubeVip.BGP.AS,
+			"Peers":        kubeVipBGPPeers(endpoint.KubeVip.BGP.Peers),
+		},
+	}
+
+	templateAction.Init(nil, nil)
+	if err := templateAction.Execute(runtime); err != nil {
+		return err
+	}
+	return nil
+}
+
+type GenerateKeepalivedConfig struct {
+	common.KubeAction
+}
+
+func (g *GenerateKeepalivedConfig) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	iface, ok := host.GetCache().GetMustString(VIPInterface)
+	if !ok {
+		return errors.New("get the network interface of the VIP by host label failed")
+	}
+
+	// The earlier master in the config has the higher priority to hold the VIP.
+	priority := keepalivedPriority
+	peers := make([]string, 0)
+	for i, master := range runtime.GetHostsByRole(common.Master) {
+		if master.GetName() == host.GetName() {
+			priority = keepalivedPriority - i
+			continue
+		}
+		peers = append(peers, master.GetInternalAddress())
+	}
+
+	templateAction := action.Template{
+		Template: templates.KeepalivedConfig,
+		Dst:      filepath.Join(common.KeepalivedDir, templates.KeepalivedConfig.Name()),
+		Data: util.Data{
+			"RouterID":        host.GetName(),
+			"HealthCheckPort": 8081,
+			"Interface":       iface,
+			"VirtualRouterID": g.KubeConf.Cluster.ControlPlaneEndpoint.Keepalived.VirtualRouterID,
+			"Priority":        priority,
+			"InternalAddress": host.GetInternalAddress(),
+			"Peers":           peers,
+			"Address":         g.KubeConf.Cluster.ControlPlaneEndpoint.Address,
+		},
+	}
+
+	templateAction.Init(nil, nil)
+	if err := templateAction.Execute(runtime); err != nil {
+		return err
+	}
+	return nil
+}
+
+type GenerateKeepalivedManifest struct {
+	common.KubeAction
+}
+
+func (g *GenerateKeepalivedManifest) Execute(runtime connector.Runtime) error {
+	// Calculation config md5 as the checksum.
+	// It will make keepalived reload when config changes.
+	md5Str, err := runtime.GetRunner().FileMd5(filepath.Join(common.KeepalivedDir, templates.KeepalivedConfig.Name()))
+	if err != nil {
+		return err
+	}
+
+	templateAction := action.Template{
+		Template: templates.KeepalivedManifest,
+		Dst:      filepath.Join(common.KubeManifestDir, templates.KeepalivedManifest.Name()),
+		Data: util.Data{
+			"KeepalivedImage": images.GetImage(runtime, g.KubeConf, "keepalived").ImageName(),
+			"Checksum":        md5Str,
+		},
+	}
+
+	templateAction.Init(nil, nil)
+	if err := templateAction.Execute(runtime); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CheckControlPlaneEndpoint checks the apiserver is reachable through the address of the control plane endpoint.
+type CheckControlPlaneEndpoint struct {
+	common.KubeAction
+}
+
+func (c *CheckControlPlaneEndpoint) Execute(runtime connector.Runtime) error {
+	endpoint := c.KubeConf.Cluster.ControlPlaneEndpoint
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl --kubeconfig /etc/kubernetes/admin.conf --server https://%s:%d get --raw=/healthz",
+		endpoint.Address, endpoint.Port), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "the apiserver is unreachable through %s:%d", endpoint.Address, endpoint.Port)
+	}
+	return nil
+}
+
+// RemoveLoadBalancer removes the static pods and the configs of the internal load balancer in Mode.
+type RemoveLoadBalancer struct {
+	common.KubeAction
+	Mode string
+}
+
+func (r *RemoveLoadBalancer) Execute(runtime connector.Runtime) error {
+	var files []string
+	switch r.Mode {
+	case kubekeyapiv1alpha2.Haproxy:
+		files = []string{filepath.Join(common.KubeManifestDir, templates.HaproxyManifest.Name()), common.HaproxyDir}
+	case kubekeyapiv1alpha2.KubeVip:
+		files = []string{filepath.Join(common.KubeManifestDir, templates.KubeVipManifest.Name())}
+	case kubekeyapiv1alpha2.KeepalivedHaproxy:
+		files = []string{
+			filepath.Join(common.KubeManifestDir, templates.KeepalivedManifest.Name()),
+			filepath.Join(common.KubeManifestDir, templates.HaproxyManifest.Name()),
+			common.KeepalivedDir,
+			common.HaproxyDir,
+		}
+	default:
+		return errors.Errorf("unknown internal load balancer %s", r.Mode)
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -rf %s", strings.Join(files, " ")), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "remove %s failed", r.Mode)
+	}
+	return nil
+}
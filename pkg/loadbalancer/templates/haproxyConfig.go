@@ -54,7 +54,7 @@ frontend healthz
   monitor-uri /healthz
 
 frontend kube_api_frontend
-  bind 127.0.0.1:{{ .LoadbalancerApiserverPort }}
+  bind {{ .LoadbalancerApiserverAddress }}:{{ .LoadbalancerApiserverPort }}
   mode tcp
   option tcplog
   default_backend kube_api_backend
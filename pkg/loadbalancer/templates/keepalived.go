@@ -0,0 +1,97 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"text/template"
+
+	"github.com/lithammer/dedent"
+)
+
+var KeepalivedConfig = template.Must(template.New("keepalived.conf").Parse(
+	dedent.Dedent(`
+global_defs {
+  router_id {{ .RouterID }}
+  script_user root
+  enable_script_security
+}
+
+vrrp_script check_haproxy {
+  script "/usr/bin/wget -q -O /dev/null http://127.0.0.1:{{ .HealthCheckPort }}/healthz"
+  interval 3
+  fall 3
+  rise 2
+  weight -50
+}
+
+vrrp_instance kube_api_vip {
+  state BACKUP
+  interface {{ .Interface }}
+  virtual_router_id {{ .VirtualRouterID }}
+  priority {{ .Priority }}
+  advert_int 1
+  unicast_src_ip {{ .InternalAddress }}
+  unicast_peer {
+{{- range .Peers }}
+    {{ . }}
+{{- end }}
+  }
+  virtual_ipaddress {
+    {{ .Address }}
+  }
+  track_script {
+    check_haproxy
+  }
+}
+`)))
+
+var KeepalivedManifest = template.Must(template.New("keepalived.yaml").Parse(
+	dedent.Dedent(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: keepalived
+  namespace: kube-system
+  labels:
+    k8s-app: kube-keepalived
+  annotations:
+    cfg-checksum: "{{ .Checksum }}"
+spec:
+  hostNetwork: true
+  priorityClassName: system-node-critical
+  containers:
+  - name: keepalived
+    image: {{ .KeepalivedImage }}
+    imagePullPolicy: IfNotPresent
+    args:
+    - --copy-service
+    securityContext:
+      capabilities:
+        add:
+        - NET_ADMIN
+        - NET_BROADCAST
+        - NET_RAW
+    volumeMounts:
+    - mountPath: /container/service/keepalived/assets/keepalived.conf
+      name: keepalived-conf
+      readOnly: true
+  volumes:
+  - name: keepalived-conf
+    hostPath:
+      path: /etc/kubekey/keepalived/keepalived.conf
+      type: File
+`)))
@@ -0,0 +1,97 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"text/template"
+
+	"github.com/lithammer/dedent"
+)
+
+var KubeVipManifest = template.Must(template.New("kube-vip.yaml").Parse(
+	dedent.Dedent(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: kube-vip
+  namespace: kube-system
+  labels:
+    k8s-app: kube-vip
+spec:
+  hostNetwork: true
+  hostAliases:
+  - hostnames:
+    - kubernetes
+    ip: 127.0.0.1
+  priorityClassName: system-node-critical
+  containers:
+  - name: kube-vip
+    image: {{ .KubeVipImage }}
+    imagePullPolicy: IfNotPresent
+    args:
+    - manager
+    env:
+    - name: address
+      value: "{{ .Address }}"
+    - name: port
+      value: "{{ .Port }}"
+    - name: vip_interface
+      value: "{{ .Interface }}"
+    - name: vip_cidr
+      value: "32"
+    - name: cp_enable
+      value: "true"
+    - name: cp_namespace
+      value: kube-system
+{{- if eq .Mode "bgp" }}
+    - name: vip_leaderelection
+      value: "false"
+    - name: bgp_enable
+      value: "true"
+    - name: bgp_routerid
+      value: "{{ .RouterID }}"
+    - name: bgp_as
+      value: "{{ .AS }}"
+    - name: bgp_peers
+      value: "{{ .Peers }}"
+{{- else }}
+    - name: vip_arp
+      value: "true"
+    - name: vip_leaderelection
+      value: "true"
+    - name: vip_leaseduration
+      value: "5"
+    - name: vip_renewdeadline
+      value: "3"
+    - name: vip_retryperiod
+      value: "1"
+{{- end }}
+    securityContext:
+      capabilities:
+        add:
+        - NET_ADMIN
+        - NET_RAW
+    volumeMounts:
+    - mountPath: /etc/kubernetes/admin.conf
+      name: kubeconfig
+      readOnly: true
+  volumes:
+  - name: kubeconfig
+    hostPath:
+      path: /etc/kubernetes/admin.conf
+      type: File
+`)))
@@ -17,7 +17,8 @@
 package pipelines
 
 import (
-	"fmt"
+	"github.com/pkg/errors"
+
 	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	kubekeycontroller "github.com/kubesphere/kubekey/controllers/kubekey"
 	"github.com/kubesphere/kubekey/pkg/artifact"
@@ -32,47 +33,25 @@ import (
 	"github.com/kubesphere/kubekey/pkg/core/module"
 	"github.com/kubesphere/kubekey/pkg/core/pipeline"
 	"github.com/kubesphere/kubekey/pkg/etcd"
+	"github.com/kubesphere/kubekey/pkg/files"
 	"github.com/kubesphere/kubekey/pkg/filesystem"
 	"github.com/kubesphere/kubekey/pkg/hooks"
 	"github.com/kubesphere/kubekey/pkg/images"
 	"github.com/kubesphere/kubekey/pkg/k3s"
 	"github.com/kubesphere/kubekey/pkg/kubernetes"
 	"github.com/kubesphere/kubekey/pkg/loadbalancer"
+	"github.com/kubesphere/kubekey/pkg/plugins/network"
 )
 
 func NewAddNodesPipeline(runtime *common.KubeRuntime) error {
-	noArtifact := runtime.Arg.Artifact == ""
-
-	m := []module.Module{
-		&precheck.GreetingsModule{},
-		&precheck.NodePreCheckModule{},
-		&confirm.InstallConfirmModule{Skip: runtime.Arg.SkipConfirmCheck},
-		&artifact.UnArchiveModule{Skip: noArtifact},
-		&os.RepositoryModule{Skip: noArtifact || !runtime.Arg.InstallPackages},
-		&binaries.NodeBinariesModule{},
-		&os.ConfigureOSModule{},
-		&registry.RegistryCertsModule{Skip: len(runtime.GetHostsByRole(common.Registry)) == 0},
-		&kubernetes.StatusModule{},
-		&container.InstallContainerModule{},
-		&images.PullModule{Skip: runtime.Arg.SkipPullImages},
-		&etcd.PreCheckModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
-		&etcd.CertsModule{},
-		&etcd.InstallETCDBinaryModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
-		&etcd.ConfigureModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
-		&etcd.BackupModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
-		&kubernetes.InstallKubeBinariesModule{},
-		&kubernetes.JoinNodesModule{},
-		&loadbalancer.HaproxyModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
-		&kubernetes.ConfigureKubernetesModule{},
-		&filesystem.ChownModule{},
-		&certs.AutoRenewCertsModule{Skip: !runtime.Cluster.Kubernetes.EnableAutoRenewCerts()},
-	}
+	m := addNodesModules(runtime, false)
 
 	p := pipeline.Pipeline{
 		Name:            "AddNodesPipeline",
 		Modules:         m,
 		Runtime:         runtime,
-		ModulePostHooks: []module.PostHookInterface{&hooks.UpdateCRStatusHook{}},
+		ModulePreHooks:  []module.PreHookInterface{&hooks.LifecyclePreHook{}},
+		ModulePostHooks: []module.PostHookInterface{&hooks.UpdateCRStatusHook{}, &hooks.LifecyclePostHook{}},
 	}
 	if err := p.Start(); err != nil {
 		if runtime.Arg.InCluster {
@@ -98,11 +77,50 @@ func NewAddNodesPipeline(runtime *common.KubeRuntime) error {
 	return nil
 }
 
+// addNodesModules returns the modules which install the new nodes of the config and join them into the cluster.
+// addNodesModules returns the modules which add the nodes. If replaceNode is true, the certs of etcd are reissued
+// for the hosts of the cluster, since a node has been replaced.
+func addNodesModules(runtime *common.KubeRuntime, replaceNode bool) []module.Module {
+	noArtifact := runtime.Arg.Artifact == ""
+
+	return []module.Module{
+		&precheck.GreetingsModule{},
+		&precheck.NodePreCheckModule{},
+		&network.PreCheckModule{},
+		&confirm.InstallConfirmModule{Skip: runtime.Arg.SkipConfirmCheck},
+		&artifact.UnArchiveModule{Skip: noArtifact},
+		&os.RepositoryModule{Skip: noArtifact || !runtime.Arg.InstallPackages},
+		&binaries.NodeBinariesModule{},
+		&os.ConfigureOSModule{},
+		&registry.RegistryCertsModule{Skip: len(runtime.GetHostsByRole(common.Registry)) == 0},
+		&kubernetes.StatusModule{},
+		&container.InstallContainerModule{},
+		&images.PullModule{Skip: runtime.Arg.SkipPullImages},
+		&etcd.PreCheckModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
+		&etcd.CertsModule{ReissueChangedSANs: replaceNode},
+		&etcd.InstallETCDBinaryModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
+		&etcd.ConfigureModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
+		&etcd.BackupModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
+		&kubernetes.InstallKubeBinariesModule{},
+		&kubernetes.SyncPKIModule{Skip: !runtime.Cluster.Kubernetes.PKI.Enabled()},
+		&kubernetes.EncryptionConfigModule{Skip: !runtime.Cluster.Kubernetes.EnableEncryptionAtRest()},
+		&kubernetes.JoinNodesModule{},
+		&loadbalancer.HaproxyModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
+		&loadbalancer.VIPModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsVIPEnabled()},
+		&loadbalancer.UpdateServerModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsVIPEnabled()},
+		&kubernetes.ConfigureKubernetesModule{},
+		&network.ConfigureCalicoModule{Skip: runtime.Cluster.Network.Plugin != common.Calico || !runtime.Cluster.Network.Calico.IsCustomized()},
+		&filesystem.ChownModule{},
+		&certs.AutoRenewCertsModule{Skip: !runtime.Cluster.Kubernetes.EnableAutoRenewCerts()},
+	}
+}
+
 func NewK3sAddNodesPipeline(runtime *common.KubeRuntime) error {
 	noArtifact := runtime.Arg.Artifact == ""
 
 	m := []module.Module{
 		&precheck.GreetingsModule{},
+		&network.PreCheckModule{},
 		&artifact.UnArchiveModule{Skip: noArtifact},
 		&os.RepositoryModule{Skip: noArtifact || !runtime.Arg.InstallPackages},
 		&binaries.K3sNodeBinariesModule{},
@@ -117,6 +135,7 @@ func NewK3sAddNodesPipeline(runtime *common.KubeRuntime) error {
 		&k3s.JoinNodesModule{},
 		&loadbalancer.K3sHaproxyModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
 		&kubernetes.ConfigureKubernetesModule{},
+		&network.ConfigureCalicoModule{Skip: runtime.Cluster.Network.Plugin != common.Calico || !runtime.Cluster.Network.Calico.IsCustomized()},
 		&filesystem.ChownModule{},
 		&certs.AutoRenewCertsModule{Skip: !runtime.Cluster.Kubernetes.EnableAutoRenewCerts()},
 	}
@@ -125,7 +144,8 @@ func NewK3sAddNodesPipeline(runtime *common.KubeRuntime) error {
 		Name:            "AddNodesPipeline",
 		Modules:         m,
 		Runtime:         runtime,
-		ModulePostHooks: []module.PostHookInterface{&hooks.UpdateCRStatusHook{}},
+		ModulePreHooks:  []module.PreHookInterface{&hooks.LifecyclePreHook{}},
+		ModulePostHooks: []module.PostHookInterface{&hooks.UpdateCRStatusHook{}, &hooks.LifecyclePostHook{}},
 	}
 	if err := p.Start(); err != nil {
 		if runtime.Arg.InCluster {
@@ -151,13 +171,12 @@ func NewK3sAddNodesPipeline(runtime *common.KubeRuntime) error {
 	return nil
 }
 
-func AddNodes(args common.Argument, downloadCmd string) error {
-	args.DownloadCommand = func(path, url string) string {
-		// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
-		// some poor network environment. Or users even can choose another cli, it might be wget.
-		// perhaps we should have a build-in download function instead of totally rely on the external one
-		return fmt.Sprintf(downloadCmd, path, url)
+func AddNodes(args common.Argument, downloadOpts files.DownloadOptions) error {
+	downloader, err := files.NewDownloader(downloadOpts)
+	if err != nil {
+		return err
 	}
+	args.Downloader = downloader
 
 	var loaderType string
 	if args.FilePath != "" {
@@ -170,6 +189,11 @@ func AddNodes(args common.Argument, downloadCmd string) error {
 	if err != nil {
 		return err
 	}
+	if args.NodePool != "" {
+		if err := checkNodePool(runtime, args.NodePool); err != nil {
+			return err
+		}
+	}
 	if args.InCluster {
 		c, err := kubekeycontroller.NewKubekeyClient()
 		if err != nil {
@@ -203,3 +227,12 @@ func AddNodes(args common.Argument, downloadCmd string) error {
 	}
 	return nil
 }
+
+// checkNodePool checks that the node pool to add exists. The other hosts are kept in the runtime, e.g. to be written
+// to /etc/hosts, while only the nodes of the pool are installed and joined.
+func checkNodePool(runtime *common.KubeRuntime, pool string) error {
+	if len(runtime.GetHostsByRole(pool)) == 0 {
+		return errors.Errorf("the node pool %s is not found in the roleGroups", pool)
+	}
+	return nil
+}
@@ -17,8 +17,6 @@
 package pipelines
 
 import (
-	"fmt"
-
 	"github.com/pkg/errors"
 
 	"github.com/kubesphere/kubekey/pkg/artifact"
@@ -27,6 +25,7 @@ import (
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/module"
 	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/files"
 	"github.com/kubesphere/kubekey/pkg/filesystem"
 	"github.com/kubesphere/kubekey/pkg/images"
 )
@@ -37,6 +36,7 @@ func NewArtifactExportPipeline(runtime *common.ArtifactRuntime) error {
 		&images.CopyImagesToLocalModule{},
 		&binaries.ArtifactBinariesModule{},
 		&artifact.RepositoryModule{},
+		&artifact.DeltaModule{Skip: runtime.Arg.Base == ""},
 		&artifact.ArchiveModule{},
 		&filesystem.ChownOutputModule{},
 		&filesystem.ChownWorkDirModule{},
@@ -61,6 +61,7 @@ func NewK3sArtifactExportPipeline(runtime *common.ArtifactRuntime) error {
 		&images.CopyImagesToLocalModule{},
 		&binaries.K3sArtifactBinariesModule{},
 		&artifact.RepositoryModule{},
+		&artifact.DeltaModule{Skip: runtime.Arg.Base == ""},
 		&artifact.ArchiveModule{},
 		&filesystem.ChownOutputModule{},
 		&filesystem.ChownWorkDirModule{},
@@ -79,13 +80,12 @@ func NewK3sArtifactExportPipeline(runtime *common.ArtifactRuntime) error {
 	return nil
 }
 
-func ArtifactExport(args common.ArtifactArgument, downloadCmd string) error {
-	args.DownloadCommand = func(path, url string) string {
-		// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
-		// some poor network environment. Or users even can choose another cli, it might be wget.
-		// perhaps we should have a build-in download function instead of totally rely on the external one
-		return fmt.Sprintf(downloadCmd, path, url)
+func ArtifactExport(args common.ArtifactArgument, downloadOpts files.DownloadOptions) error {
+	downloader, err := files.NewDownloader(downloadOpts)
+	if err != nil {
+		return err
 	}
+	args.Downloader = downloader
 
 	runtime, err := common.NewArtifactRuntime(args)
 	if err != nil {
@@ -0,0 +1,106 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/kubesphere/kubekey/pkg/certs"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/core/module"
+	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+)
+
+func CollectCertsPipeline(runtime *common.KubeRuntime, clusterCerts *certs.ClusterCerts) error {
+	m := []module.Module{
+		&certs.CheckCertsModule{},
+		&certs.CollectClusterCertsModule{ClusterCerts: clusterCerts},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "CollectCertsPipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// collectCerts lists the cluster certs with a new runtime, so that the hosts removed from the cluster by a failed
+// collection are checked again next time.
+func collectCerts(loaderType string, args common.Argument) (*certs.ClusterCerts, error) {
+	runtime, err := common.NewKubeRuntime(loaderType, args)
+	if err != nil {
+		return nil, err
+	}
+	clusterCerts := &certs.ClusterCerts{}
+	if err := CollectCertsPipeline(runtime, clusterCerts); err != nil {
+		// The connections are only closed by the pipeline which succeeds.
+		for _, host := range runtime.GetAllHosts() {
+			runtime.GetConnector().Close(host)
+		}
+		return nil, err
+	}
+	return clusterCerts, nil
+}
+
+// CertsExporter collects the cluster certs every interval, and serves their expiration as Prometheus metrics
+// on the /metrics path of the address.
+func CertsExporter(args common.Argument, address string, interval time.Duration) error {
+	var loaderType string
+	if args.FilePath != "" {
+		loaderType = common.File
+	} else {
+		loaderType = common.AllInOne
+	}
+
+	exporter := certs.NewExporter()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exporter)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- http.ListenAndServe(address, mux)
+	}()
+	logger.Log.Infof("Serving the metrics of cluster certs on %s/metrics", address)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		clusterCerts, err := collectCerts(loaderType, args)
+		if err != nil {
+			logger.Log.Errorf("Failed to collect cluster certs: %v", err)
+		}
+		exporter.Update(clusterCerts, err)
+
+		select {
+		case err := <-serveErr:
+			return errors.Wrapf(err, "failed to serve metrics on %s", address)
+		case <-ticker.C:
+		}
+	}
+}
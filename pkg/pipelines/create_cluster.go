@@ -27,6 +27,7 @@ import (
 	"github.com/kubesphere/kubekey/pkg/bootstrap/confirm"
 	"github.com/kubesphere/kubekey/pkg/bootstrap/precheck"
 	"github.com/kubesphere/kubekey/pkg/certs"
+	"github.com/kubesphere/kubekey/pkg/check"
 	"github.com/kubesphere/kubekey/pkg/container"
 	"github.com/kubesphere/kubekey/pkg/images"
 	"github.com/kubesphere/kubekey/pkg/kubernetes"
@@ -41,6 +42,7 @@ import (
 	"github.com/kubesphere/kubekey/pkg/core/module"
 	"github.com/kubesphere/kubekey/pkg/core/pipeline"
 	"github.com/kubesphere/kubekey/pkg/etcd"
+	"github.com/kubesphere/kubekey/pkg/files"
 	"github.com/kubesphere/kubekey/pkg/filesystem"
 	"github.com/kubesphere/kubekey/pkg/hooks"
 	"github.com/kubesphere/kubekey/pkg/k3s"
@@ -63,6 +65,7 @@ func NewCreateClusterPipeline(runtime *common.KubeRuntime) error {
 	m := []module.Module{
 		&precheck.GreetingsModule{},
 		&precheck.NodePreCheckModule{},
+		&network.PreCheckModule{},
 		&confirm.InstallConfirmModule{Skip: runtime.Arg.SkipConfirmCheck},
 		&artifact.UnArchiveModule{Skip: noArtifact},
 		&os.RepositoryModule{Skip: noArtifact || !runtime.Arg.InstallPackages},
@@ -78,13 +81,19 @@ func NewCreateClusterPipeline(runtime *common.KubeRuntime) error {
 		&etcd.ConfigureModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
 		&etcd.BackupModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
 		&kubernetes.InstallKubeBinariesModule{},
+		&kubernetes.SyncPKIModule{Skip: !runtime.Cluster.Kubernetes.PKI.Enabled()},
+		&kubernetes.EncryptionConfigModule{Skip: !runtime.Cluster.Kubernetes.EnableEncryptionAtRest()},
+		&loadbalancer.VIPModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsVIPEnabled()},
 		&kubernetes.InitKubernetesModule{},
 		&dns.ClusterDNSModule{},
 		&kubernetes.StatusModule{},
 		&kubernetes.JoinNodesModule{},
 		&loadbalancer.HaproxyModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
+		&loadbalancer.VIPModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsVIPEnabled()},
+		&loadbalancer.UpdateServerModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsVIPEnabled()},
 		&network.DeployNetworkPluginModule{},
 		&kubernetes.ConfigureKubernetesModule{},
+		&network.ConfigureCalicoModule{Skip: runtime.Cluster.Network.Plugin != common.Calico || !runtime.Cluster.Network.Calico.IsCustomized()},
 		&filesystem.ChownModule{},
 		&certs.AutoRenewCertsModule{Skip: !runtime.Cluster.Kubernetes.EnableAutoRenewCerts()},
 		&kubernetes.SaveKubeConfigModule{},
@@ -93,13 +102,15 @@ func NewCreateClusterPipeline(runtime *common.KubeRuntime) error {
 		&storage.DeployLocalVolumeModule{Skip: skipLocalStorage},
 		&kubesphere.DeployModule{Skip: !runtime.Cluster.KubeSphere.Enabled},
 		&kubesphere.CheckResultModule{Skip: !runtime.Cluster.KubeSphere.Enabled},
+		&check.ClusterModule{Skip: !runtime.Arg.CheckCluster},
 	}
 
 	p := pipeline.Pipeline{
 		Name:            "CreateClusterPipeline",
 		Modules:         m,
 		Runtime:         runtime,
-		ModulePostHooks: []module.PostHookInterface{&hooks.UpdateCRStatusHook{}},
+		ModulePreHooks:  []module.PreHookInterface{&hooks.LifecyclePreHook{}},
+		ModulePostHooks: []module.PostHookInterface{&hooks.UpdateCRStatusHook{}, &hooks.LifecyclePostHook{}},
 	}
 	if err := p.Start(); err != nil {
 		return err
@@ -158,6 +169,7 @@ func NewK3sCreateClusterPipeline(runtime *common.KubeRuntime) error {
 
 	m := []module.Module{
 		&precheck.GreetingsModule{},
+		&network.PreCheckModule{},
 		&artifact.UnArchiveModule{Skip: noArtifact},
 		&os.RepositoryModule{Skip: noArtifact || !runtime.Arg.InstallPackages},
 		&binaries.K3sNodeBinariesModule{},
@@ -176,6 +188,7 @@ func NewK3sCreateClusterPipeline(runtime *common.KubeRuntime) error {
 		&loadbalancer.K3sHaproxyModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
 		&network.DeployNetworkPluginModule{},
 		&kubernetes.ConfigureKubernetesModule{},
+		&network.ConfigureCalicoModule{Skip: runtime.Cluster.Network.Plugin != common.Calico || !runtime.Cluster.Network.Calico.IsCustomized()},
 		&filesystem.ChownModule{},
 		&certs.AutoRenewCertsModule{Skip: !runtime.Cluster.Kubernetes.EnableAutoRenewCerts()},
 		&k3s.SaveKubeConfigModule{},
@@ -189,7 +202,8 @@ func NewK3sCreateClusterPipeline(runtime *common.KubeRuntime) error {
 		Name:            "K3sCreateClusterPipeline",
 		Modules:         m,
 		Runtime:         runtime,
-		ModulePostHooks: []module.PostHookInterface{&hooks.UpdateCRStatusHook{}},
+		ModulePreHooks:  []module.PreHookInterface{&hooks.LifecyclePreHook{}},
+		ModulePostHooks: []module.PostHookInterface{&hooks.UpdateCRStatusHook{}, &hooks.LifecyclePostHook{}},
 	}
 	if err := p.Start(); err != nil {
 		return err
@@ -236,13 +250,12 @@ Please check the result using the command:
 	return nil
 }
 
-func CreateCluster(args common.Argument, downloadCmd string) error {
-	args.DownloadCommand = func(path, url string) string {
-		// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
-		// some poor network environment. Or users even can choose another cli, it might be wget.
-		// perhaps we should have a build-in download function instead of totally rely on the external one
-		return fmt.Sprintf(downloadCmd, path, url)
+func CreateCluster(args common.Argument, downloadOpts files.DownloadOptions) error {
+	downloader, err := files.NewDownloader(downloadOpts)
+	if err != nil {
+		return err
 	}
+	args.Downloader = downloader
 
 	var loaderType string
 	if args.FilePath != "" {
@@ -24,6 +24,7 @@ import (
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/module"
 	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/hooks"
 	"github.com/kubesphere/kubekey/pkg/k3s"
 	"github.com/kubesphere/kubekey/pkg/kubernetes"
 )
@@ -38,9 +39,11 @@ func NewDeleteClusterPipeline(runtime *common.KubeRuntime) error {
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "DeleteClusterPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:            "DeleteClusterPipeline",
+		Modules:         m,
+		Runtime:         runtime,
+		ModulePreHooks:  []module.PreHookInterface{&hooks.LifecyclePreHook{}},
+		ModulePostHooks: []module.PostHookInterface{&hooks.LifecyclePostHook{}},
 	}
 	if err := p.Start(); err != nil {
 		return err
@@ -58,9 +61,11 @@ func NewK3sDeleteClusterPipeline(runtime *common.KubeRuntime) error {
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "K3sDeleteClusterPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:            "K3sDeleteClusterPipeline",
+		Modules:         m,
+		Runtime:         runtime,
+		ModulePreHooks:  []module.PreHookInterface{&hooks.LifecyclePreHook{}},
+		ModulePostHooks: []module.PostHookInterface{&hooks.LifecyclePostHook{}},
 	}
 	if err := p.Start(); err != nil {
 		return err
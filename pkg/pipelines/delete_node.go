@@ -17,26 +17,81 @@
 package pipelines
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/pkg/bootstrap/confirm"
 	"github.com/kubesphere/kubekey/pkg/bootstrap/os"
 	"github.com/kubesphere/kubekey/pkg/bootstrap/precheck"
 	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
 	"github.com/kubesphere/kubekey/pkg/core/module"
 	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/etcd"
+	"github.com/kubesphere/kubekey/pkg/hooks"
 	"github.com/kubesphere/kubekey/pkg/kubernetes"
+	"github.com/kubesphere/kubekey/pkg/loadbalancer"
 )
 
 func DeleteNodePipeline(runtime *common.KubeRuntime) error {
+	var node connector.Host
+	for _, host := range runtime.GetAllHosts() {
+		if host.GetName() == runtime.Arg.NodeName {
+			node = host
+		}
+	}
+	if node == nil {
+		return errors.Errorf("the node %s is not found in the config", runtime.Arg.NodeName)
+	}
+
+	isMaster := node.IsRole(common.Master)
+	isETCD := node.IsRole(common.ETCD) && runtime.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey
+	if isMaster && len(runtime.GetHostsByRole(common.Master)) == 1 {
+		return errors.Errorf("the node %s is the only control-plane node, it can not be deleted", node.GetName())
+	}
+	if isETCD && len(runtime.GetHostsByRole(common.ETCD)) == 1 {
+		return errors.Errorf("the node %s is the only etcd member, it can not be deleted", node.GetName())
+	}
+
 	m := []module.Module{
 		&precheck.GreetingsModule{},
-		&confirm.DeleteNodeConfirmModule{},
+		&confirm.DeleteNodeConfirmModule{Skip: runtime.Arg.NodePool != ""},
 		&kubernetes.CompareConfigAndClusterInfoModule{},
-		&kubernetes.DeleteKubeNodeModule{},
+		&etcd.QuorumCheckModule{Skip: !isETCD},
+		&kubernetes.DeleteKubeNodeModule{Skip: !node.IsRole(common.K8s), ControlPlane: isMaster},
+		&etcd.RemoveMemberModule{Skip: !isETCD},
 		&os.ClearNodeOSModule{},
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "DeleteNodePipeline",
+		Name:            "DeleteNodePipeline",
+		Modules:         m,
+		Runtime:         runtime,
+		ModulePreHooks:  []module.PreHookInterface{&hooks.LifecyclePreHook{}},
+		ModulePostHooks: []module.PostHookInterface{&hooks.LifecyclePostHook{}},
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+
+	if !isMaster && !isETCD {
+		return nil
+	}
+
+	// The remaining nodes are reconfigured without the deleted node.
+	runtime.DeleteHost(node)
+	m = []module.Module{
+		&etcd.PreCheckModule{Skip: !isETCD},
+		&etcd.RefreshConfigModule{Skip: !isETCD},
+		&loadbalancer.HaproxyModule{Skip: !isMaster || !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
+		&loadbalancer.VIPModule{Skip: !isMaster || !runtime.Cluster.ControlPlaneEndpoint.IsKeepalivedEnabled()},
+	}
+
+	p = pipeline.Pipeline{
+		Name:    "RefreshClusterPipeline",
 		Modules: m,
 		Runtime: runtime,
 	}
@@ -59,8 +114,47 @@ func DeleteNode(args common.Argument) error {
 		return err
 	}
 
+	if args.NodePool != "" {
+		return deleteNodePool(runtime, loaderType, args)
+	}
+
 	if err := DeleteNodePipeline(runtime); err != nil {
 		return err
 	}
 	return nil
 }
+
+// deleteNodePool deletes the nodes of the node pool one by one after the confirmation.
+func deleteNodePool(runtime *common.KubeRuntime, loaderType string, args common.Argument) error {
+	var nodes []string
+	for _, host := range runtime.GetHostsByRole(args.NodePool) {
+		nodes = append(nodes, host.GetName())
+	}
+	if len(nodes) == 0 {
+		return errors.Errorf("the node pool %s is not found in the roleGroups", args.NodePool)
+	}
+
+	p := pipeline.Pipeline{
+		Name: "DeleteNodePoolConfirmPipeline",
+		Modules: []module.Module{
+			&precheck.GreetingsModule{},
+			&confirm.DeleteNodeConfirmModule{Content: fmt.Sprintf("node pool %s (%s)", args.NodePool, strings.Join(nodes, ", "))},
+		},
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		args.NodeName = node
+		runtime, err := common.NewKubeRuntime(loaderType, args)
+		if err != nil {
+			return err
+		}
+		if err := DeleteNodePipeline(runtime); err != nil {
+			return errors.Wrapf(err, "delete the node %s of the node pool %s failed", node, args.NodePool)
+		}
+	}
+	return nil
+}
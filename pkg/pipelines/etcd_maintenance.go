@@ -0,0 +1,90 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/confirm"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/module"
+	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/etcd"
+)
+
+// etcdMaintenanceConfirmMessage returns the message to confirm before the operation, or "" if it does not change etcd.
+func etcdMaintenanceConfirmMessage(opts etcd.MaintenanceOptions) string {
+	switch {
+	case opts.Operation == etcd.MaintenanceDefrag:
+		return "The etcd members will be defragmented one by one, the followers first and the leader last. " +
+			"A member can not serve requests while it is being defragmented."
+	case opts.Operation == etcd.MaintenanceCompact:
+		return "The history of the etcd keys before the revision will be removed, and can not be restored."
+	case opts.Operation == etcd.MaintenanceAlarm && opts.Disarm:
+		return "All the etcd alarms will be disarmed. Make sure the cause of the alarms, e.g. the DB size exceeding the quota, is resolved."
+	}
+	return ""
+}
+
+func EtcdMaintenancePipeline(runtime *common.KubeRuntime, opts etcd.MaintenanceOptions) error {
+	message := etcdMaintenanceConfirmMessage(opts)
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&confirm.MaintenanceConfirmModule{Skip: message == "" || runtime.Arg.SkipConfirmCheck, Message: message},
+		&etcd.MaintenanceModule{Options: opts},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "EtcdMaintenancePipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func EtcdMaintenance(args common.Argument, opts etcd.MaintenanceOptions) error {
+	runtime, err := common.NewKubeRuntime(common.File, args)
+	if err != nil {
+		return err
+	}
+
+	switch runtime.Cluster.Etcd.Type {
+	case kubekeyapiv1alpha2.Kubeadm:
+		return errors.New("the etcd deployed by kubeadm is not supported, set etcd.type to kubekey or external")
+	case kubekeyapiv1alpha2.External:
+		if len(runtime.Cluster.Etcd.External.Endpoints) == 0 {
+			return errors.New("etcd.external.endpoints is required to operate the external etcd")
+		}
+		if len(runtime.GetHostsByRole(common.Master)) == 0 {
+			return errors.New("a control-plane node is required to operate the external etcd")
+		}
+	default:
+		if len(runtime.GetHostsByRole(common.ETCD)) == 0 {
+			return errors.New("no etcd node is found in roleGroups.etcd")
+		}
+	}
+
+	if err := EtcdMaintenancePipeline(runtime, opts); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/artifact"
+	"github.com/kubesphere/kubekey/pkg/binaries"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/confirm"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/module"
+	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/etcd"
+	"github.com/kubesphere/kubekey/pkg/files"
+	"github.com/kubesphere/kubekey/pkg/kubernetes"
+)
+
+func EtcdMigrationPipeline(runtime *common.KubeRuntime) error {
+	toKubeKey := runtime.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey
+	noArtifact := runtime.Arg.Artifact == ""
+
+	message := "etcd will be migrated from the etcd nodes to the control-plane nodes as static pods of kubeadm. " +
+		"The etcd on the etcd nodes is stopped, and its data is kept in /var/lib/etcd.migrated-<time>."
+	if toKubeKey {
+		message = "etcd will be migrated from the control-plane nodes to the etcd nodes, and installed by KubeKey. " +
+			"The stacked etcd is stopped, and its data is kept in /var/lib/etcd.migrated-<time>."
+	}
+	message += fmt.Sprintf(" A snapshot is saved to %s first. kube-apiserver is restarted on each control-plane node.", etcd.SnapshotDir)
+
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&confirm.MaintenanceConfirmModule{Skip: runtime.Arg.SkipConfirmCheck, Message: message},
+		&kubernetes.PrepareEtcdMigrationModule{},
+		&artifact.UnArchiveModule{Skip: noArtifact || !toKubeKey},
+		&binaries.EtcdBinariesModule{Skip: !toKubeKey, Version: runtime.Cluster.Etcd.Version},
+		&etcd.PreCheckModule{},
+		&etcd.CertsModule{Skip: !toKubeKey},
+		&etcd.InstallETCDBinaryModule{Skip: !toKubeKey},
+		&kubernetes.MigrateEtcdModule{},
+		&etcd.BackupModule{Skip: !toKubeKey},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "EtcdMigrationPipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func EtcdMigration(args common.Argument, downloadOpts files.DownloadOptions) error {
+	downloader, err := files.NewDownloader(downloadOpts)
+	if err != nil {
+		return err
+	}
+	args.Downloader = downloader
+
+	runtime, err := common.NewKubeRuntime(common.File, args)
+	if err != nil {
+		return err
+	}
+
+	switch runtime.Cluster.Etcd.Type {
+	case kubekeyapiv1alpha2.KubeKey:
+		pki := runtime.Cluster.Etcd.PKI
+		if pki.Enabled() || len(pki.Certs) > 0 {
+			return errors.New("etcd.pki is not supported by the migration, the etcd certs are signed by the etcd CA of kubeadm")
+		}
+	case kubekeyapiv1alpha2.Kubeadm:
+	default:
+		return errors.Errorf("etcd can only be migrated to the kubekey or kubeadm type, the etcd type is %s", runtime.Cluster.Etcd.Type)
+	}
+
+	// The stacked etcd and the etcd installed by KubeKey use the same ports and data dir.
+	etcdHosts := runtime.GetHostsByRole(common.ETCD)
+	if len(etcdHosts) == 0 {
+		return errors.New("the etcd nodes are required to migrate etcd")
+	}
+	for _, host := range etcdHosts {
+		if host.IsRole(common.Master) {
+			return errors.Errorf("etcd node %s is a control-plane node, the etcd nodes must be separate from the control-plane nodes", host.GetName())
+		}
+	}
+
+	if err := EtcdMigrationPipeline(runtime); err != nil {
+		return err
+	}
+	return nil
+}
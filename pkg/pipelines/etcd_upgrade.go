@@ -0,0 +1,115 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/artifact"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/confirm"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/module"
+	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/etcd"
+	"github.com/kubesphere/kubekey/pkg/files"
+)
+
+// etcdArchs returns the distinct archs of the etcd nodes.
+func etcdArchs(runtime *common.KubeRuntime) []string {
+	archMap := make(map[string]bool)
+	var archs []string
+	for _, host := range runtime.GetHostsByRole(common.ETCD) {
+		if !archMap[host.GetArch()] {
+			archMap[host.GetArch()] = true
+			archs = append(archs, host.GetArch())
+		}
+	}
+	return archs
+}
+
+// upgradeETCDVersion returns the version etcd is upgraded to in the cluster upgrade, or "" if the target Kubernetes
+// version does not require a newer etcd than the configured version. The members which already run the required
+// version are left as they are by the upgrade module.
+func upgradeETCDVersion(runtime *common.KubeRuntime) (string, error) {
+	archs := etcdArchs(runtime)
+	if runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey || len(archs) == 0 {
+		return "", nil
+	}
+	return etcd.UpgradeVersion(runtime.Cluster.Kubernetes.Version, runtime.Cluster.Etcd.Version, archs)
+}
+
+func EtcdUpgradePipeline(runtime *common.KubeRuntime, version string) error {
+	noArtifact := runtime.Arg.Artifact == ""
+
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&confirm.MaintenanceConfirmModule{
+			Skip: runtime.Arg.SkipConfirmCheck,
+			Message: fmt.Sprintf("The etcd members will be upgraded to %s one by one after a snapshot is saved to %s. "+
+				"etcd can not be downgraded once all the members are upgraded.", version, etcd.SnapshotDir),
+		},
+		&artifact.UnArchiveModule{Skip: noArtifact},
+		&etcd.UpgradeModule{Version: version},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "EtcdUpgradePipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func EtcdUpgrade(args common.Argument, downloadOpts files.DownloadOptions, version string) error {
+	downloader, err := files.NewDownloader(downloadOpts)
+	if err != nil {
+		return err
+	}
+	args.Downloader = downloader
+
+	runtime, err := common.NewKubeRuntime(common.File, args)
+	if err != nil {
+		return err
+	}
+
+	if runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey {
+		return errors.Errorf("only the etcd installed by kubekey can be upgraded, the etcd type is %s", runtime.Cluster.Etcd.Type)
+	}
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if err := etcd.CheckCatalog(version, etcdArchs(runtime)); err != nil {
+		return err
+	}
+
+	if err := EtcdUpgradePipeline(runtime, version); err != nil {
+		return err
+	}
+	if runtime.Cluster.Etcd.Version != version {
+		fmt.Printf("etcd is running %s, set etcd.version to %s in %s so that new etcd nodes install the same version.\n",
+			version, version, args.FilePath)
+	}
+	return nil
+}
@@ -17,7 +17,6 @@
 package pipelines
 
 import (
-	"fmt"
 	"github.com/kubesphere/kubekey/pkg/artifact"
 	"github.com/kubesphere/kubekey/pkg/binaries"
 	"github.com/kubesphere/kubekey/pkg/bootstrap/os"
@@ -26,6 +25,7 @@ import (
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/module"
 	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/files"
 	"github.com/kubesphere/kubekey/pkg/filesystem"
 )
 
@@ -53,13 +53,12 @@ func NewInitRegistryPipeline(runtime *common.KubeRuntime) error {
 	return nil
 }
 
-func InitRegistry(args common.Argument, downloadCmd string) error {
-	args.DownloadCommand = func(path, url string) string {
-		// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
-		// some poor network environment. Or users even can choose another cli, it might be wget.
-		// perhaps we should have a build-in download function instead of totally rely on the external one
-		return fmt.Sprintf(downloadCmd, path, url)
+func InitRegistry(args common.Argument, downloadOpts files.DownloadOptions) error {
+	downloader, err := files.NewDownloader(downloadOpts)
+	if err != nil {
+		return err
 	}
+	args.Downloader = downloader
 
 	var loaderType string
 	if args.FilePath != "" {
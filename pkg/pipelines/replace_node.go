@@ -0,0 +1,182 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	kubekeycontroller "github.com/kubesphere/kubekey/controllers/kubekey"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/confirm"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/config"
+	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/core/module"
+	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/etcd"
+	"github.com/kubesphere/kubekey/pkg/files"
+	"github.com/kubesphere/kubekey/pkg/hooks"
+	"github.com/kubesphere/kubekey/pkg/kubernetes"
+)
+
+// RemoveReplacedNodePipeline removes the old node from the cluster without connecting to it,
+// so that a node which is down can be replaced.
+func RemoveReplacedNodePipeline(runtime *common.KubeRuntime, oldNode connector.Host) error {
+	isMaster := oldNode.IsRole(common.Master)
+	isETCD := oldNode.IsRole(common.ETCD) && runtime.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey
+
+	runtime.DeleteHost(oldNode)
+
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&confirm.DeleteNodeConfirmModule{},
+		&kubernetes.CompareConfigAndClusterInfoModule{},
+		&etcd.QuorumCheckModule{Skip: !isETCD},
+		&kubernetes.DeleteKubeNodeModule{Skip: !oldNode.IsRole(common.K8s), ControlPlane: isMaster, SkipDrain: true},
+		&etcd.RemoveMemberModule{Skip: !isETCD},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "RemoveReplacedNodePipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func NewReplaceNodePipeline(runtime *common.KubeRuntime, isMaster bool) error {
+	m := addNodesModules(runtime, true)
+	m = append(m, &kubernetes.UpdateAPIServerCertSANsModule{Skip: !isMaster})
+
+	p := pipeline.Pipeline{
+		Name:            "ReplaceNodePipeline",
+		Modules:         m,
+		Runtime:         runtime,
+		ModulePreHooks:  []module.PreHookInterface{&hooks.LifecyclePreHook{}},
+		ModulePostHooks: []module.PostHookInterface{&hooks.UpdateCRStatusHook{}, &hooks.LifecyclePostHook{}},
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ReplaceNode(args common.Argument, newNode string, downloadOpts files.DownloadOptions) error {
+	downloader, err := files.NewDownloader(downloadOpts)
+	if err != nil {
+		return err
+	}
+	args.Downloader = downloader
+
+	runtime, err := common.NewKubeRuntime(common.File, args)
+	if err != nil {
+		return err
+	}
+	if runtime.Cluster.Kubernetes.Type == common.K3s {
+		return errors.New("replacing a node of k3s is not supported")
+	}
+
+	var oldNode connector.Host
+	for _, host := range runtime.GetAllHosts() {
+		switch host.GetName() {
+		case args.NodeName:
+			oldNode = host
+		case newNode:
+			return errors.Errorf("the node %s already has roles in the roleGroups", newNode)
+		}
+	}
+	if oldNode == nil {
+		return errors.Errorf("the node %s is not found in the roleGroups", args.NodeName)
+	}
+	var newNodeFound bool
+	for _, host := range runtime.Cluster.Hosts {
+		if host.Name == newNode {
+			newNodeFound = true
+		}
+	}
+	if !newNodeFound {
+		return errors.Errorf("the node %s is not found in the hosts", newNode)
+	}
+
+	// The config file is updated after the old node is removed, so it is checked in advance.
+	var listed bool
+	for _, names := range runtime.Cluster.RoleGroups {
+		for _, name := range names {
+			if name == args.NodeName {
+				listed = true
+			}
+		}
+	}
+	if !listed {
+		return errors.Errorf("the node %s must be listed by name in the roleGroups", args.NodeName)
+	}
+
+	// Keep the control-plane and the etcd quorum during the replacement.
+	isMaster := oldNode.IsRole(common.Master)
+	if isMaster && len(runtime.GetHostsByRole(common.Master)) == 1 {
+		return errors.Errorf("the node %s is the only control-plane node, it can not be replaced", oldNode.GetName())
+	}
+	if oldNode.IsRole(common.ETCD) && runtime.Cluster.Etcd.Type == kubekeyapiv1alpha2.KubeKey &&
+		len(runtime.GetHostsByRole(common.ETCD)) == 1 {
+		return errors.Errorf("the node %s is the only etcd member, it can not be replaced", oldNode.GetName())
+	}
+
+	if args.InCluster {
+		c, err := kubekeycontroller.NewKubekeyClient()
+		if err != nil {
+			return err
+		}
+		runtime.ClientSet = c
+	}
+
+	if err := RemoveReplacedNodePipeline(runtime, oldNode); err != nil {
+		return err
+	}
+
+	if err := config.ReplaceNode(args.FilePath, args.NodeName, newNode); err != nil {
+		return err
+	}
+	logger.Log.Infof("The node %s is replaced by %s in %s", args.NodeName, newNode, args.FilePath)
+
+	newRuntime, err := common.NewKubeRuntime(common.File, args)
+	if err != nil {
+		return err
+	}
+	newRuntime.ClientSet = runtime.ClientSet
+
+	if args.InCluster {
+		if err := kubekeycontroller.ReplaceClusterNode(newRuntime, args.NodeName, newNode); err != nil {
+			return err
+		}
+	}
+
+	if err := NewReplaceNodePipeline(newRuntime, isMaster); err != nil {
+		return err
+	}
+
+	if args.InCluster {
+		if err := kubekeycontroller.UpdateStatus(newRuntime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
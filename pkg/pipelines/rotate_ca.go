@@ -0,0 +1,144 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/confirm"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/pkg/certs"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/core/module"
+	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/core/util"
+	"github.com/kubesphere/kubekey/pkg/etcd"
+)
+
+// remainingCARotationPhases returns the phases to run after the last finished one.
+func remainingCARotationPhases(finished string) ([]string, error) {
+	if finished == "" {
+		return certs.CARotationPhases, nil
+	}
+	for i, phase := range certs.CARotationPhases {
+		if phase == finished {
+			return certs.CARotationPhases[i+1:], nil
+		}
+	}
+	return nil, errors.Errorf("unknown CA rotation phase %q", finished)
+}
+
+func caRotationPhaseModules(runtime *common.KubeRuntime, phase string) []module.Module {
+	switch phase {
+	case certs.CARotationPrepare:
+		return []module.Module{
+			&certs.PrepareCARotationModule{},
+			&certs.MarkCARotationPhaseModule{Phase: phase},
+		}
+	case certs.CARotationResign:
+		return []module.Module{
+			&certs.DistributeCABundleModule{Phase: phase},
+			&etcd.PreCheckModule{Skip: runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey},
+			&etcd.RenewCertsModule{},
+			&certs.RenewCertsModule{},
+			&certs.ResignKubeletCertsModule{},
+			&certs.VerifyRenewedCertsModule{},
+			&certs.MarkCARotationPhaseModule{Phase: phase},
+		}
+	default:
+		return []module.Module{
+			&certs.DistributeCABundleModule{Phase: phase},
+			&certs.RestartForCARotationModule{Phase: phase},
+			&certs.VerifyRenewedCertsModule{},
+			&certs.MarkCARotationPhaseModule{Phase: phase},
+		}
+	}
+}
+
+func RotateCAPipeline(runtime *common.KubeRuntime) error {
+	stateP := pipeline.Pipeline{
+		Name: "CARotationStatePipeline",
+		Modules: []module.Module{
+			&precheck.GreetingsModule{},
+			&certs.CARotationStateModule{},
+		},
+		Runtime: runtime,
+	}
+	if err := stateP.Start(); err != nil {
+		return err
+	}
+
+	var finished string
+	localDir := certs.CARotationLocalDir(runtime)
+	if data, err := ioutil.ReadFile(filepath.Join(localDir, certs.CARotationPhaseFile)); err == nil {
+		finished = strings.TrimSpace(string(data))
+	} else if !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to read the CA rotation phase")
+	}
+	phases, err := remainingCARotationPhases(finished)
+	if err != nil {
+		return err
+	}
+	if finished != "" {
+		if runtime.Arg.RotateSAKey && !util.IsExist(filepath.Join(localDir, "new", "sa.pem")) {
+			return errors.New("the service-account key rotation can only be enabled before the CA rotation starts")
+		}
+		logger.Log.Infof("Resuming the CA rotation after the finished %s phase", finished)
+	}
+
+	m := []module.Module{
+		&confirm.RotateCAConfirmModule{Skip: runtime.Arg.SkipConfirmCheck, Phases: phases},
+	}
+	for _, phase := range phases {
+		m = append(m, caRotationPhaseModules(runtime, phase)...)
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "RotateCAPipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func RotateCA(args common.Argument) error {
+	runtime, err := common.NewKubeRuntime(common.File, args)
+	if err != nil {
+		return err
+	}
+	if runtime.Cluster.Kubernetes.Type == common.K3s {
+		return errors.New("rotating the CA of k3s is not supported")
+	}
+	if runtime.Cluster.Kubernetes.PKI.Enabled() || runtime.Cluster.Etcd.PKI.Enabled() {
+		return errors.New("the CA is supplied in the config, it should be rotated by its issuer")
+	}
+
+	if err := RotateCAPipeline(runtime); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/kubesphere/kubekey/pkg/bootstrap/confirm"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/module"
+	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/kubernetes"
+)
+
+func RotateEncryptionKeyPipeline(runtime *common.KubeRuntime) error {
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&confirm.MaintenanceConfirmModule{
+			Skip: runtime.Arg.SkipConfirmCheck,
+			Message: "A new encryption key will be added, and all the encrypted resources will be rewritten with it before the old keys are dropped. " +
+				"kube-apiserver is restarted on each control-plane node three times.",
+		},
+		&kubernetes.RotateEncryptionKeyModule{},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "RotateEncryptionKeyPipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func RotateEncryptionKey(args common.Argument) error {
+	runtime, err := common.NewKubeRuntime(common.File, args)
+	if err != nil {
+		return err
+	}
+	if runtime.Cluster.Kubernetes.Type == common.K3s {
+		return errors.New("rotating the encryption key of k3s is not supported")
+	}
+	if !runtime.Cluster.Kubernetes.EnableEncryptionAtRest() {
+		return errors.New("kubernetes.encryptionAtRest is not enabled in the config")
+	}
+	if runtime.Cluster.Kubernetes.EncryptionAtRest.Provider == kubernetes.EncryptionKMS {
+		return errors.New("the key of the kms provider is managed by the KMS, it should be rotated by the KMS")
+	}
+
+	if err := RotateEncryptionKeyPipeline(runtime); err != nil {
+		return err
+	}
+	return nil
+}
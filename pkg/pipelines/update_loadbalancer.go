@@ -0,0 +1,168 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/confirm"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/config"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/core/module"
+	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/kubernetes"
+	"github.com/kubesphere/kubekey/pkg/loadbalancer"
+)
+
+// ExternalLoadBalancer is the mode of kk update loadbalancer which uses the load balancer outside the cluster.
+const ExternalLoadBalancer = "external"
+
+// UpdateLoadBalancerPipeline switches the control plane endpoint from the internal load balancer in oldMode
+// to the one in runtime.Cluster. The new load balancer is installed and the nodes are connected to it before
+// the old one is removed, unless they hold the same VIP.
+func UpdateLoadBalancerPipeline(runtime *common.KubeRuntime, oldEndpoint kubekeyapiv1alpha2.ControlPlaneEndpoint) error {
+	endpoint := runtime.Cluster.ControlPlaneEndpoint
+	removeFirst := oldEndpoint.IsVIPEnabled() && endpoint.IsVIPEnabled() && oldEndpoint.Address == endpoint.Address
+	oldMode := oldEndpoint.InternalLoadbalancer
+	if oldMode == endpoint.InternalLoadbalancer {
+		oldMode = ""
+	}
+
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&confirm.MaintenanceConfirmModule{
+			Skip: runtime.Arg.SkipConfirmCheck,
+			Message: fmt.Sprintf("The control plane endpoint will be switched from %s to %s. "+
+				"The apiserver certs are reissued if the cert SANs change, and the kubelet is restarted on each node.",
+				loadBalancerString(oldEndpoint), loadBalancerString(endpoint)),
+		},
+		&kubernetes.UpdateAPIServerCertSANsModule{},
+		&loadbalancer.RemoveModule{Skip: !removeFirst, Mode: oldMode},
+		&loadbalancer.HaproxyModule{Skip: !endpoint.IsInternalLBEnabled()},
+		&loadbalancer.VIPModule{Skip: !endpoint.IsVIPEnabled()},
+		&loadbalancer.UpdateServerModule{Skip: endpoint.IsInternalLBEnabled()},
+		&kubernetes.UpdateControlPlaneEndpointModule{},
+		&loadbalancer.RemoveModule{Skip: removeFirst, Mode: oldMode},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "UpdateLoadBalancerPipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func UpdateLoadBalancer(args common.Argument, mode, address string, port int) error {
+	runtime, err := common.NewKubeRuntime(common.File, args)
+	if err != nil {
+		return err
+	}
+	if runtime.Cluster.Kubernetes.Type == common.K3s {
+		return errors.New("updating the load balancer of k3s is not supported")
+	}
+
+	oldEndpoint := runtime.Cluster.ControlPlaneEndpoint
+	endpoint, err := newControlPlaneEndpoint(oldEndpoint, mode, address, port)
+	if err != nil {
+		return err
+	}
+	if endpoint.IsInternalLBEnabled() {
+		// The address of the internal load balancer is the first master, as the default one of the config.
+		endpoint.Address = runtime.GetHostsByRole(common.Master)[0].GetInternalAddress()
+	}
+	if endpoint.InternalLoadbalancer == oldEndpoint.InternalLoadbalancer &&
+		endpoint.Address == oldEndpoint.Address && endpoint.Port == oldEndpoint.Port {
+		return errors.Errorf("the control plane endpoint is already %s", loadBalancerString(endpoint))
+	}
+	runtime.Cluster.ControlPlaneEndpoint = endpoint
+
+	if err := UpdateLoadBalancerPipeline(runtime, oldEndpoint); err != nil {
+		return err
+	}
+
+	configAddress := endpoint.Address
+	if endpoint.IsInternalLBEnabled() {
+		configAddress = ""
+	}
+	if err := config.UpdateControlPlaneEndpoint(args.FilePath, endpoint.InternalLoadbalancer, configAddress, endpoint.Port); err != nil {
+		return err
+	}
+	logger.Log.Infof("The controlPlaneEndpoint is updated in %s", args.FilePath)
+	return nil
+}
+
+// newControlPlaneEndpoint returns the control plane endpoint of the load balancer in mode, the port defaults to
+// the one the load balancer listens on.
+func newControlPlaneEndpoint(old kubekeyapiv1alpha2.ControlPlaneEndpoint, mode, address string, port int) (kubekeyapiv1alpha2.ControlPlaneEndpoint, error) {
+	endpoint := old
+	endpoint.InternalLoadbalancer = mode
+	endpoint.Address = address
+	endpoint.Port = port
+
+	switch mode {
+	case kubekeyapiv1alpha2.Haproxy:
+		if address != "" {
+			return endpoint, errors.New("the address of haproxy is the local host, it can not be set")
+		}
+	case kubekeyapiv1alpha2.KubeVip, kubekeyapiv1alpha2.KeepalivedHaproxy, ExternalLoadBalancer:
+		if address == "" {
+			return endpoint, errors.Errorf("the address is required by %s", mode)
+		}
+	default:
+		return endpoint, errors.Errorf("unknown load balancer mode %s, it must be haproxy, kube-vip, keepalived+haproxy or external", mode)
+	}
+	if mode == ExternalLoadBalancer {
+		endpoint.InternalLoadbalancer = ""
+	}
+
+	if endpoint.Port == 0 {
+		endpoint.Port = kubekeyapiv1alpha2.DefaultLBPort
+		if endpoint.IsKeepalivedEnabled() {
+			endpoint.Port = kubekeyapiv1alpha2.DefaultKeepalivedLBPort
+		}
+	}
+	// haproxy of the internal load balancer and kube-vip do not change the port of the apiserver,
+	// while haproxy of keepalived+haproxy runs beside the apiserver.
+	if (endpoint.IsInternalLBEnabled() || endpoint.IsKubeVipEnabled()) && endpoint.Port != kubekeyapiv1alpha2.DefaultApiserverPort {
+		return endpoint, errors.Errorf("the port of %s must be %d", mode, kubekeyapiv1alpha2.DefaultApiserverPort)
+	}
+	if endpoint.IsKeepalivedEnabled() && endpoint.Port == kubekeyapiv1alpha2.DefaultApiserverPort {
+		return endpoint, errors.Errorf("the port of %s can not be %d which is used by the apiserver", mode, kubekeyapiv1alpha2.DefaultApiserverPort)
+	}
+	if endpoint.IsKubeVipEnabled() && endpoint.KubeVip.Mode == kubekeyapiv1alpha2.KubeVipBGPMode &&
+		(endpoint.KubeVip.BGP.AS == 0 || len(endpoint.KubeVip.BGP.Peers) == 0) {
+		return endpoint, errors.New("the AS and the peers of BGP must be set in the kubevip of the controlPlaneEndpoint")
+	}
+	return endpoint, nil
+}
+
+func loadBalancerString(endpoint kubekeyapiv1alpha2.ControlPlaneEndpoint) string {
+	mode := endpoint.InternalLoadbalancer
+	if mode == "" {
+		mode = ExternalLoadBalancer
+	}
+	return fmt.Sprintf("%s (%s:%d)", mode, endpoint.Address, endpoint.Port)
+}
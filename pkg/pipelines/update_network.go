@@ -0,0 +1,130 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	kubekeycontroller "github.com/kubesphere/kubekey/controllers/kubekey"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/confirm"
+	"github.com/kubesphere/kubekey/pkg/bootstrap/precheck"
+	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/config"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+	"github.com/kubesphere/kubekey/pkg/core/module"
+	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/plugins/network"
+)
+
+// UpdateNetworkPipeline migrates the cluster from flannel to the network plugin in runtime.Cluster. The new plugin is
+// deployed beside flannel and the nodes are moved to it one at a time before flannel is removed.
+func UpdateNetworkPipeline(runtime *common.KubeRuntime) error {
+	cfg := runtime.Cluster.Network
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&network.PreCheckModule{},
+		&network.MigrationPreCheckModule{},
+		&confirm.MaintenanceConfirmModule{
+			Skip: runtime.Arg.SkipConfirmCheck,
+			Message: fmt.Sprintf("The network plugin will be migrated from %s to %s. The nodes are drained one at a time, "+
+				"the pods on the migrated nodes can not reach the pods on the other nodes until all the nodes are migrated.",
+				common.Flannel, cfg.Plugin),
+		},
+		&network.DeployNetworkPluginModule{Migrating: true},
+		&network.MigrateModule{},
+		&network.DeployNetworkPluginModule{},
+		&network.RemoveFlannelModule{},
+		&network.ConfigureCalicoModule{Skip: cfg.Plugin != common.Calico || !cfg.Calico.IsCustomized()},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "UpdateNetworkPipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func UpdateNetwork(args common.Argument, plugin string) error {
+	runtime, err := common.NewKubeRuntime(common.File, args)
+	if err != nil {
+		return err
+	}
+	if runtime.Cluster.Kubernetes.Type == common.K3s {
+		return errors.New("updating the network plugin of k3s is not supported")
+	}
+
+	cfg := &runtime.Cluster.Network
+	ipam, err := migrationIPAM(cfg.Plugin, plugin)
+	if err != nil {
+		return err
+	}
+	// The new plugin allocates the pod addresses from the podCIDR of the nodes as flannel does,
+	// so that the pods keep their addresses on the nodes not migrated yet.
+	cfg.Plugin = plugin
+	if plugin == common.Calico {
+		cfg.Calico.IPAM = ipam
+	} else {
+		cfg.Cilium.IPAM = ipam
+	}
+
+	if args.InCluster {
+		c, err := kubekeycontroller.NewKubekeyClient()
+		if err != nil {
+			return err
+		}
+		runtime.ClientSet = c
+	}
+
+	if err := UpdateNetworkPipeline(runtime); err != nil {
+		return err
+	}
+
+	if err := config.UpdateNetworkPlugin(args.FilePath, plugin, ipam); err != nil {
+		return err
+	}
+	logger.Log.Infof("The network plugin is updated to %s in %s", plugin, args.FilePath)
+
+	if args.InCluster {
+		if err := kubekeycontroller.UpdateClusterNetworkPlugin(runtime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrationIPAM checks that the network plugin can be migrated from oldPlugin to plugin, and returns the ipam of
+// the new plugin which allocates the pod addresses from the podCIDR of the nodes.
+func migrationIPAM(oldPlugin, plugin string) (string, error) {
+	if oldPlugin != common.Flannel {
+		return "", errors.Errorf("only the network plugin flannel can be migrated, but the plugin is %s", oldPlugin)
+	}
+	switch plugin {
+	case common.Calico:
+		return kubekeyapiv1alpha2.CalicoHostLocalIPAM, nil
+	case common.Cilium:
+		return kubekeyapiv1alpha2.CiliumKubernetesIPAM, nil
+	default:
+		return "", errors.Errorf("unsupported network plugin %s, it must be calico or cilium", plugin)
+	}
+}
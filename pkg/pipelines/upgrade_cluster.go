@@ -17,8 +17,6 @@
 package pipelines
 
 import (
-	"fmt"
-
 	"github.com/pkg/errors"
 
 	"github.com/kubesphere/kubekey/pkg/artifact"
@@ -28,7 +26,10 @@ import (
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/module"
 	"github.com/kubesphere/kubekey/pkg/core/pipeline"
+	"github.com/kubesphere/kubekey/pkg/etcd"
+	"github.com/kubesphere/kubekey/pkg/files"
 	"github.com/kubesphere/kubekey/pkg/filesystem"
+	"github.com/kubesphere/kubekey/pkg/hooks"
 	"github.com/kubesphere/kubekey/pkg/kubernetes"
 	"github.com/kubesphere/kubekey/pkg/kubesphere"
 	"github.com/kubesphere/kubekey/pkg/loadbalancer"
@@ -36,6 +37,10 @@ import (
 
 func NewUpgradeClusterPipeline(runtime *common.KubeRuntime) error {
 	noArtifact := runtime.Arg.Artifact == ""
+	etcdVersion, err := upgradeETCDVersion(runtime)
+	if err != nil {
+		return err
+	}
 
 	m := []module.Module{
 		&precheck.GreetingsModule{},
@@ -43,23 +48,28 @@ func NewUpgradeClusterPipeline(runtime *common.KubeRuntime) error {
 		&precheck.ClusterPreCheckModule{},
 		&confirm.UpgradeConfirmModule{Skip: runtime.Arg.SkipConfirmCheck},
 		&artifact.UnArchiveModule{Skip: noArtifact},
+		&etcd.UpgradeModule{Skip: etcdVersion == "", Version: etcdVersion, IgnoreNewer: true},
 		&kubernetes.SetUpgradePlanModule{Step: kubernetes.ToV121},
 		&kubernetes.ProgressiveUpgradeModule{Step: kubernetes.ToV121},
 		&loadbalancer.HaproxyModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
+		&loadbalancer.VIPModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsVIPEnabled()},
 		&kubesphere.CleanClusterConfigurationModule{Skip: !runtime.Cluster.KubeSphere.Enabled},
 		&kubesphere.ConvertModule{Skip: !runtime.Cluster.KubeSphere.Enabled},
 		&kubesphere.DeployModule{Skip: !runtime.Cluster.KubeSphere.Enabled},
 		&kubesphere.CheckResultModule{Skip: !runtime.Cluster.KubeSphere.Enabled},
 		&kubernetes.SetUpgradePlanModule{Step: kubernetes.ToV122},
 		&kubernetes.ProgressiveUpgradeModule{Step: kubernetes.ToV122},
+		&kubernetes.ConfigureKubernetesModule{},
 		&filesystem.ChownModule{},
 		&certs.AutoRenewCertsModule{Skip: !runtime.Cluster.Kubernetes.EnableAutoRenewCerts()},
 	}
 
 	p := pipeline.Pipeline{
-		Name:    "UpgradeClusterPipeline",
-		Modules: m,
-		Runtime: runtime,
+		Name:            "UpgradeClusterPipeline",
+		Modules:         m,
+		Runtime:         runtime,
+		ModulePreHooks:  []module.PreHookInterface{&hooks.LifecyclePreHook{}},
+		ModulePostHooks: []module.PostHookInterface{&hooks.LifecyclePostHook{}},
 	}
 	if err := p.Start(); err != nil {
 		return err
@@ -67,13 +77,12 @@ func NewUpgradeClusterPipeline(runtime *common.KubeRuntime) error {
 	return nil
 }
 
-func UpgradeCluster(args common.Argument, downloadCmd string) error {
-	args.DownloadCommand = func(path, url string) string {
-		// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
-		// some poor network environment. Or users even can choose another cli, it might be wget.
-		// perhaps we should have a build-in download function instead of totally rely on the external one
-		return fmt.Sprintf(downloadCmd, path, url)
+func UpgradeCluster(args common.Argument, downloadOpts files.DownloadOptions) error {
+	downloader, err := files.NewDownloader(downloadOpts)
+	if err != nil {
+		return err
 	}
+	args.Downloader = downloader
 
 	var loaderType string
 	if args.FilePath != "" {
@@ -18,7 +18,9 @@ package network
 
 import (
 	"path/filepath"
+	"time"
 
+	"github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/action"
 	"github.com/kubesphere/kubekey/pkg/core/logger"
@@ -32,6 +34,16 @@ import (
 
 type DeployNetworkPluginModule struct {
 	common.KubeModule
+	// Migrating deploys calico or cilium only on the nodes labelled by the migration, so that it runs
+	// beside flannel until all the nodes are migrated.
+	Migrating bool
+}
+
+func (d *DeployNetworkPluginModule) migrationNodeLabel() string {
+	if d.Migrating {
+		return MigratedNodeLabel
+	}
+	return ""
 }
 
 func (d *DeployNetworkPluginModule) Init() {
@@ -47,6 +59,11 @@ func (d *DeployNetworkPluginModule) Init() {
 		d.Tasks = deployCilium(d)
 	case common.Kubeovn:
 		d.Tasks = deployKubeOVN(d)
+	case v1alpha2.CustomCNI:
+		d.Tasks = deployCustomCNI(d)
+	case v1alpha2.NoneCNI:
+		logger.Log.Info("The network plugin is none, the nodes are not ready until a CNI is installed.")
+		return
 	default:
 		return
 	}
@@ -55,6 +72,210 @@ func (d *DeployNetworkPluginModule) Init() {
 	}
 }
 
+type PreCheckModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (p *PreCheckModule) IsSkip() bool {
+	return p.Skip
+}
+
+func (p *PreCheckModule) Init() {
+	p.Name = "NetworkPreCheckModule"
+	p.Desc = "Check the network plugin configuration"
+
+	switch p.KubeConf.Cluster.Network.Plugin {
+	case common.Calico:
+		p.Tasks = []task.Interface{
+			&task.LocalTask{
+				Name:   "CheckCalicoConfig",
+				Desc:   "Check the calico bgp configuration and ip pools",
+				Action: new(CheckCalicoConfig),
+			},
+		}
+	case common.Cilium:
+		p.Tasks = []task.Interface{
+			&task.LocalTask{
+				Name:   "CheckCiliumConfig",
+				Desc:   "Check the cilium configuration against the cluster",
+				Action: new(CheckCiliumConfig),
+			},
+		}
+	case v1alpha2.CustomCNI:
+		p.Tasks = []task.Interface{
+			&task.LocalTask{
+				Name:   "CheckCustomCNIConfig",
+				Desc:   "Check the sources of the custom network plugin",
+				Action: new(CheckCustomCNIConfig),
+			},
+		}
+	}
+}
+
+// ConfigureCalicoModule applies the BGP configuration and the IP pools of calico, it is run again when nodes are added
+// so that the peers and the route reflectors of the new nodes take effect.
+type ConfigureCalicoModule struct {
+	common.KubeModule
+	Skip bool
+}
+
+func (c *ConfigureCalicoModule) IsSkip() bool {
+	return c.Skip
+}
+
+func (c *ConfigureCalicoModule) Init() {
+	c.Name = "ConfigureCalicoModule"
+	c.Desc = "Configure calico bgp and ip pools"
+
+	cfg := &c.KubeConf.Cluster.Network.Calico
+	routeReflectors := calicoRouteReflectors(cfg, c.KubeConf.Cluster.Hosts)
+	nodeToNodeMesh := len(routeReflectors) == 0
+	if cfg.BGP.NodeToNodeMesh != nil {
+		nodeToNodeMesh = *cfg.BGP.NodeToNodeMesh
+	}
+
+	generate := &task.RemoteTask{
+		Name:    "GenerateCalicoResources",
+		Desc:    "Generate calico bgp configuration and ip pools",
+		Hosts:   c.Runtime.GetHostsByRole(common.Master),
+		Prepare: new(common.OnlyFirstMaster),
+		Action: &action.Template{
+			Template: templates.CalicoResources,
+			Dst:      filepath.Join(common.KubeConfigDir, templates.CalicoResources.Name()),
+			Data: util.Data{
+				"NodeToNodeMesh":      nodeToNodeMesh,
+				"AS":                  cfg.BGP.AS,
+				"RouteReflectors":     len(routeReflectors) != 0,
+				"RouteReflectorLabel": calicoRouteReflectorLabel,
+				"Peers":               calicoBGPPeers(cfg),
+				"IPPools":             calicoIPPools(cfg),
+			},
+		},
+		Parallel: true,
+	}
+
+	label := &task.RemoteTask{
+		Name:     "LabelCalicoRouteReflectors",
+		Desc:     "Label calico route reflectors",
+		Hosts:    c.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(LabelCalicoRouteReflectors),
+		Parallel: true,
+		Retry:    3,
+	}
+
+	apply := &task.RemoteTask{
+		Name:     "ApplyCalicoResources",
+		Desc:     "Apply calico bgp configuration and ip pools",
+		Hosts:    c.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(ApplyCalicoResources),
+		Parallel: true,
+		Retry:    5,
+	}
+
+	c.Tasks = []task.Interface{
+		generate,
+		label,
+		apply,
+	}
+}
+
+// MigrationPreCheckModule checks that the pods of flannel and the new network plugin are allocated from the same CIDR,
+// so that the pods keep the podCIDR of their nodes during the migration.
+type MigrationPreCheckModule struct {
+	common.KubeModule
+}
+
+func (m *MigrationPreCheckModule) Init() {
+	m.Name = "NetworkMigrationPreCheckModule"
+	m.Desc = "Check the network migration from flannel"
+
+	check := &task.RemoteTask{
+		Name:     "CheckFlannelNetwork",
+		Desc:     "Check the network of flannel and the podCIDR of the nodes",
+		Hosts:    m.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(CheckFlannelNetwork),
+		Parallel: true,
+	}
+
+	m.Tasks = []task.Interface{
+		check,
+	}
+}
+
+// MigrateModule moves the nodes from flannel to the network plugin of the cluster one at a time. It must run after
+// the new plugin is deployed by DeployNetworkPluginModule with Migrating.
+type MigrateModule struct {
+	common.KubeModule
+}
+
+func (m *MigrateModule) Init() {
+	m.Name = "NetworkMigrateModule"
+	m.Desc = "Migrate the nodes from flannel"
+
+	pin := &task.RemoteTask{
+		Name:     "PinFlannel",
+		Desc:     "Keep flannel away from the migrated nodes",
+		Hosts:    m.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(PinFlannel),
+		Parallel: true,
+		Retry:    3,
+	}
+
+	migrate := &task.RemoteTask{
+		Name:     "MigrateNode",
+		Desc:     "Migrate the node to the new network plugin",
+		Hosts:    m.Runtime.GetHostsByRole(common.K8s),
+		Action:   new(MigrateNode),
+		Parallel: false,
+	}
+
+	m.Tasks = []task.Interface{
+		pin,
+		migrate,
+	}
+}
+
+// RemoveFlannelModule removes the resources of flannel and the migration label of the nodes, the new network plugin
+// must have been deployed to all the nodes.
+type RemoveFlannelModule struct {
+	common.KubeModule
+}
+
+func (r *RemoveFlannelModule) Init() {
+	r.Name = "RemoveFlannelModule"
+	r.Desc = "Remove flannel"
+
+	remove := &task.RemoteTask{
+		Name:     "DeleteFlannel",
+		Desc:     "Delete the daemonset and the configuration of flannel",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(DeleteFlannel),
+		Parallel: true,
+		Retry:    3,
+	}
+
+	unlabel := &task.RemoteTask{
+		Name:     "UnlabelMigratedNodes",
+		Desc:     "Remove the migration label of the nodes",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(UnlabelMigratedNodes),
+		Parallel: true,
+		Retry:    3,
+	}
+
+	r.Tasks = []task.Interface{
+		remove,
+		unlabel,
+	}
+}
+
 func deployMultus(d *DeployNetworkPluginModule) []task.Interface {
 	generateMultus := &task.RemoteTask{
 		Name:  "GenerateMultus",
@@ -111,6 +332,9 @@ func deployCalico(d *DeployNetworkPluginModule) []task.Interface {
 				"NodeCidrMaskSize":       d.KubeConf.Cluster.Kubernetes.NodeCidrMaskSize,
 				"IPIPMode":               d.KubeConf.Cluster.Network.Calico.IPIPMode,
 				"VXLANMode":              d.KubeConf.Cluster.Network.Calico.VXLANMode,
+				"IPAutodetectionMethod":  d.KubeConf.Cluster.Network.Calico.IPAutodetectionMethod,
+				"IPAM":                   d.KubeConf.Cluster.Network.Calico.IPAM,
+				"MigrationNodeLabel":     d.migrationNodeLabel(),
 			},
 		},
 		Parallel: true,
@@ -139,7 +363,10 @@ func deployCalico(d *DeployNetworkPluginModule) []task.Interface {
 				"NodeCidrMaskSize":        d.KubeConf.Cluster.Kubernetes.NodeCidrMaskSize,
 				"IPIPMode":                d.KubeConf.Cluster.Network.Calico.IPIPMode,
 				"VXLANMode":               d.KubeConf.Cluster.Network.Calico.VXLANMode,
+				"IPAutodetectionMethod":   d.KubeConf.Cluster.Network.Calico.IPAutodetectionMethod,
 				"ConatinerManagerIsIsula": d.KubeConf.Cluster.Kubernetes.ContainerManager == "isula",
+				"IPAM":                    d.KubeConf.Cluster.Network.Calico.IPAM,
+				"MigrationNodeLabel":      d.migrationNodeLabel(),
 			},
 		},
 		Parallel: true,
@@ -220,6 +447,53 @@ func deployCilium(d *DeployNetworkPluginModule) []task.Interface {
 		Retry:    2,
 	}
 
+	cilium := d.KubeConf.Cluster.Network.Cilium
+	generateCiliumValues := &task.RemoteTask{
+		Name:    "GenerateCiliumValues",
+		Desc:    "Generate cilium chart values",
+		Hosts:   d.Runtime.GetHostsByRole(common.Master),
+		Prepare: new(common.OnlyFirstMaster),
+		Action: &action.Template{
+			Template: templates.CiliumValues,
+			Dst:      filepath.Join(common.KubeConfigDir, templates.CiliumValues.Name()),
+			Data: util.Data{
+				"CiliumImage":                 images.GetImage(d.Runtime, d.KubeConf, "cilium").ImageName(),
+				"CiliumOperatorImage":         images.GetImage(d.Runtime, d.KubeConf, "cilium-operator-generic").ImageName(),
+				"IPAM":                        cilium.IPAM,
+				"KubePodsCIDR":                d.KubeConf.Cluster.Network.KubePodsCIDR,
+				"NodeCidrMaskSize":            d.KubeConf.Cluster.Kubernetes.NodeCidrMaskSize,
+				"KubeProxyReplacement":        cilium.KubeProxyReplacement,
+				"ControlPlaneEndpointAddress": d.KubeConf.Cluster.ControlPlaneEndpoint.Address,
+				"ControlPlaneEndpointPort":    d.KubeConf.Cluster.ControlPlaneEndpoint.Port,
+				"Tunnel":                      cilium.Tunnel,
+				"NativeRoutingCIDR":           cilium.NativeRoutingCIDR,
+				"AutoDirectNodeRoutes":        cilium.AutoDirectNodeRoutes,
+				"BandwidthManager":            cilium.BandwidthManager,
+				"EncryptionEnabled":           cilium.Encryption.Enabled,
+				"EncryptionType":              cilium.Encryption.Type,
+				"IPsecSecretName":             ciliumIPsecSecret,
+				"HubbleEnabled":               cilium.EnableHubble(),
+				"HubbleRelay":                 cilium.Hubble.Relay,
+				"HubbleRelayImage":            images.GetImage(d.Runtime, d.KubeConf, "hubble-relay").ImageName(),
+				"HubbleUI":                    cilium.Hubble.UI,
+				"HubbleUIImage":               images.GetImage(d.Runtime, d.KubeConf, "hubble-ui").ImageName(),
+				"HubbleUIBackendImage":        images.GetImage(d.Runtime, d.KubeConf, "hubble-ui-backend").ImageName(),
+				"MigrationNodeLabel":          d.migrationNodeLabel(),
+			},
+		},
+		Parallel: true,
+	}
+
+	createIPsecSecret := &task.RemoteTask{
+		Name:     "CreateCiliumIPsecSecret",
+		Desc:     "Create cilium ipsec keys",
+		Hosts:    d.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(CreateCiliumIPsecSecret),
+		Parallel: true,
+		Retry:    5,
+	}
+
 	deploy := &task.RemoteTask{
 		Name:     "DeployCilium",
 		Desc:     "Deploy cilium",
@@ -230,10 +504,40 @@ func deployCilium(d *DeployNetworkPluginModule) []task.Interface {
 		Retry:    5,
 	}
 
-	return []task.Interface{
+	tasks := []task.Interface{
 		releaseCiliumChart,
 		syncCiliumChart,
-		deploy,
+		generateCiliumValues,
+	}
+	if cilium.Encryption.Enabled && cilium.Encryption.Type == ciliumIPsec {
+		tasks = append(tasks, createIPsecSecret)
+	}
+	return append(tasks, deploy)
+}
+
+func deployCustomCNI(d *DeployNetworkPluginModule) []task.Interface {
+	install := &task.LocalTask{
+		Name:   "InstallCustomCNI",
+		Desc:   "Install the custom network plugin",
+		Action: new(InstallCustomCNI),
+		Retry:  3,
+	}
+
+	// the plugins of kubekey are ready soon after they are applied, but a custom one may be not.
+	waitNodesReady := &task.RemoteTask{
+		Name:     "WaitNodesReady",
+		Desc:     "Wait for all nodes to be ready",
+		Hosts:    d.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(CheckNodesReady),
+		Parallel: true,
+		Retry:    60,
+		Delay:    10 * time.Second,
+	}
+
+	return []task.Interface{
+		install,
+		waitNodesReady,
 	}
 }
 
@@ -17,25 +17,52 @@
 package network
 
 import (
+	"crypto/rand"
 	"embed"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+	"github.com/kubesphere/kubekey/pkg/addons"
 	"github.com/kubesphere/kubekey/pkg/common"
 	"github.com/kubesphere/kubekey/pkg/core/action"
 	"github.com/kubesphere/kubekey/pkg/core/connector"
+	"github.com/kubesphere/kubekey/pkg/core/task"
 	"github.com/kubesphere/kubekey/pkg/core/util"
 	"github.com/kubesphere/kubekey/pkg/images"
 	"github.com/kubesphere/kubekey/pkg/plugins/network/templates"
 	"github.com/pkg/errors"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+
+	versionutil "k8s.io/apimachinery/pkg/util/version"
 )
 
 //go:embed cilium-1.11.6.tgz
 
 var f embed.FS
 
+const (
+	ciliumMinKubeVersion = "v1.16.0"
+	ciliumIPsec          = "ipsec"
+	ciliumIPsecSecret    = "cilium-ipsec-keys"
+
+	calicoDefaultIPPool                     = "default-ipv4-ippool"
+	calicoRouteReflectorLabel               = "route-reflector"
+	calicoRouteReflectorClusterIDAnnotation = "projectcalico.org/RouteReflectorClusterID"
+
+	flannelDaemonSet = "kube-flannel-ds"
+	flannelConfigMap = "kube-flannel-cfg"
+	flannelSelector  = "app=flannel"
+)
+
+// MigratedNodeLabel marks the nodes migrated from flannel, the new network plugin only runs on them during the migration.
+const MigratedNodeLabel = "kubekey.kubesphere.io/network-migrated"
+
 type ReleaseCiliumChart struct {
 	common.KubeAction
 }
@@ -75,26 +102,719 @@ func (s *SyncCiliumChart) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+type CheckCiliumConfig struct {
+	common.KubeAction
+}
+
+func (c *CheckCiliumConfig) Execute(_ connector.Runtime) error {
+	return validateCiliumCfg(c.KubeConf.Cluster.Kubernetes.Version, c.KubeConf.Cluster.Kubernetes.DisableKubeProxy,
+		&c.KubeConf.Cluster.Network.Cilium)
+}
+
+func validateCiliumCfg(kubeVersion string, disableKubeProxy bool, cfg *v1alpha2.CiliumCfg) error {
+	v, err := versionutil.ParseGeneric(kubeVersion)
+	if err != nil {
+		return errors.Wrapf(err, "parse kubernetes version %s failed", kubeVersion)
+	}
+	if !v.AtLeast(versionutil.MustParseGeneric(ciliumMinKubeVersion)) {
+		return errors.Errorf("cilium %s requires Kubernetes %s or later, but the target version is %s",
+			v1alpha2.DefaultCiliumVersion, ciliumMinKubeVersion, kubeVersion)
+	}
+
+	switch cfg.KubeProxyReplacement {
+	case v1alpha2.CiliumKubeProxyReplacementDisabled, "probe", "partial", v1alpha2.CiliumKubeProxyReplacementStrict:
+	default:
+		return errors.Errorf("unsupported cilium kubeProxyReplacement %s, expected one of disabled, probe, partial and strict", cfg.KubeProxyReplacement)
+	}
+	if disableKubeProxy && cfg.KubeProxyReplacement != v1alpha2.CiliumKubeProxyReplacementStrict {
+		return errors.Errorf("cilium kubeProxyReplacement must be strict when kube-proxy is disabled, got %s", cfg.KubeProxyReplacement)
+	}
+	if !disableKubeProxy && cfg.KubeProxyReplacement == v1alpha2.CiliumKubeProxyReplacementStrict {
+		return errors.New("cilium kubeProxyReplacement strict requires kubernetes.disableKubeProxy to be true")
+	}
+
+	switch cfg.Tunnel {
+	case "vxlan", "geneve":
+		if cfg.AutoDirectNodeRoutes {
+			return errors.Errorf("cilium autoDirectNodeRoutes requires the tunnel to be disabled, got %s", cfg.Tunnel)
+		}
+	case v1alpha2.CiliumTunnelDisabled:
+		if cfg.NativeRoutingCIDR == "" {
+			return errors.New("cilium nativeRoutingCIDR must be set when the tunnel is disabled")
+		}
+	default:
+		return errors.Errorf("unsupported cilium tunnel %s, expected one of vxlan, geneve and disabled", cfg.Tunnel)
+	}
+
+	switch cfg.IPAM {
+	case v1alpha2.DefaultCiliumIPAM, v1alpha2.CiliumKubernetesIPAM:
+	default:
+		return errors.Errorf("unsupported cilium ipam %s, expected one of cluster-pool and kubernetes", cfg.IPAM)
+	}
+
+	if cfg.Encryption.Enabled {
+		switch cfg.Encryption.Type {
+		case v1alpha2.DefaultCiliumEncryptionType, ciliumIPsec:
+		default:
+			return errors.Errorf("unsupported cilium encryption type %s, expected one of wireguard and ipsec", cfg.Encryption.Type)
+		}
+	}
+
+	if cfg.Hubble.Relay && !cfg.EnableHubble() {
+		return errors.New("cilium hubble relay requires hubble to be enabled")
+	}
+	if cfg.Hubble.UI && !cfg.Hubble.Relay {
+		return errors.New("cilium hubble ui requires hubble relay to be enabled")
+	}
+	return nil
+}
+
+type CreateCiliumIPsecSecret struct {
+	common.KubeAction
+}
+
+func (c *CreateCiliumIPsecSecret) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(
+		fmt.Sprintf("/usr/local/bin/kubectl -n kube-system get secret %s", ciliumIPsecSecret), false); err == nil {
+		return nil
+	}
+
+	key := make([]byte, 20)
+	if _, err := rand.Read(key); err != nil {
+		return errors.Wrap(err, "generate cilium ipsec key failed")
+	}
+	cmd := fmt.Sprintf("/usr/local/bin/kubectl -n kube-system create secret generic %s --from-literal=keys='3 rfc4106(gcm(aes)) %s 128'",
+		ciliumIPsecSecret, hex.EncodeToString(key))
+	if _, err := runtime.GetRunner().SudoCmd(cmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "create cilium ipsec secret failed")
+	}
+	return nil
+}
+
 type DeployCilium struct {
 	common.KubeAction
 }
 
 func (d *DeployCilium) Execute(runtime connector.Runtime) error {
-	ciliumImage := images.GetImage(runtime, d.KubeConf, "cilium").ImageName()
-	ciliumOperatorImage := images.GetImage(runtime, d.KubeConf, "cilium-operator-generic").ImageName()
+	cmd := fmt.Sprintf("/usr/local/bin/helm upgrade --install cilium /etc/kubernetes/cilium.tgz --namespace kube-system -f %s",
+		filepath.Join(common.KubeConfigDir, templates.CiliumValues.Name()))
+	if _, err := runtime.GetRunner().SudoCmd(cmd, true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "deploy cilium failed")
+	}
+	return nil
+}
+
+type CheckCalicoConfig struct {
+	common.KubeAction
+}
+
+func (c *CheckCalicoConfig) Execute(_ connector.Runtime) error {
+	return validateCalicoCfg(&c.KubeConf.Cluster.Network, c.KubeConf.Cluster.Hosts)
+}
+
+func validateCalicoCfg(network *v1alpha2.NetworkConfig, hosts []v1alpha2.HostCfg) error {
+	cfg := &network.Calico
+	if !validIPAutodetectionMethod(cfg.IPAutodetectionMethod) {
+		return errors.Errorf("unsupported calico ipAutodetectionMethod %s", cfg.IPAutodetectionMethod)
+	}
+	switch cfg.IPAM {
+	case v1alpha2.DefaultCalicoIPAM, v1alpha2.CalicoHostLocalIPAM:
+	default:
+		return errors.Errorf("unsupported calico ipam %s, expected one of calico-ipam and host-local", cfg.IPAM)
+	}
+
+	hostNames := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		hostNames[host.Name] = true
+	}
+	peerNames := make(map[string]bool, len(cfg.BGP.Peers))
+	for i, peer := range calicoBGPPeers(cfg) {
+		origin := cfg.BGP.Peers[i]
+		if net.ParseIP(origin.Address) == nil {
+			return errors.Errorf("invalid address %s of the calico bgp peer", origin.Address)
+		}
+		if origin.AS == 0 {
+			return errors.Errorf("the AS of the calico bgp peer %s must be set", origin.Address)
+		}
+		if origin.Node != "" && origin.NodeSelector != "" {
+			return errors.Errorf("the calico bgp peer %s cannot set both the node and the nodeSelector", origin.Address)
+		}
+		if origin.Node != "" && !hostNames[origin.Node] {
+			return errors.Errorf("the node %s of the calico bgp peer %s is not in the hosts", origin.Node, origin.Address)
+		}
+		if peerNames[peer.Name] {
+			return errors.Errorf("the calico bgp peer %s of the node %s is duplicated", origin.Address, origin.Node)
+		}
+		peerNames[peer.Name] = true
+	}
+	if len(cfg.BGP.RouteReflectors.NodeLabels) != 0 {
+		if len(calicoRouteReflectors(cfg, hosts)) == 0 {
+			return errors.New("no host matches the node labels of the calico route reflectors")
+		}
+		if net.ParseIP(cfg.BGP.RouteReflectors.ClusterID).To4() == nil {
+			return errors.Errorf("the clusterID %s of the calico route reflectors must be an IPv4 address", cfg.BGP.RouteReflectors.ClusterID)
+		}
+	}
+
+	_, podsNet, err := net.ParseCIDR(network.KubePodsCIDR)
+	if err != nil {
+		return errors.Wrapf(err, "invalid kubePodsCIDR %s", network.KubePodsCIDR)
+	}
+	pools := map[string]*net.IPNet{calicoDefaultIPPool: podsNet}
+	for _, pool := range cfg.IPPools {
+		if pool.Name == "" {
+			return errors.Errorf("the name of the calico ip pool %s must be set", pool.CIDR)
+		}
+		if _, ok := pools[pool.Name]; ok {
+			return errors.Errorf("the calico ip pool %s is duplicated or reserved", pool.Name)
+		}
+		_, poolNet, err := net.ParseCIDR(pool.CIDR)
+		if err != nil {
+			return errors.Wrapf(err, "invalid cidr %s of the calico ip pool %s", pool.CIDR, pool.Name)
+		}
+		ones, bits := poolNet.Mask.Size()
+		if pool.BlockSize < ones || pool.BlockSize > bits || (bits == 32 && pool.BlockSize < 20) || (bits == 128 && pool.BlockSize < 116) {
+			return errors.Errorf("invalid blockSize %d of the calico ip pool %s", pool.BlockSize, pool.Name)
+		}
+		for _, mode := range []string{pool.IPIPMode, pool.VXLANMode} {
+			if mode != "Always" && mode != "CrossSubnet" && mode != "Never" {
+				return errors.Errorf("unsupported mode %s of the calico ip pool %s, expected one of Always, CrossSubnet and Never", mode, pool.Name)
+			}
+		}
+		if pool.IPIPMode != "Never" && pool.VXLANMode != "Never" {
+			return errors.Errorf("the calico ip pool %s cannot enable both ipip and vxlan", pool.Name)
+		}
+		for name, other := range pools {
+			if other.Contains(poolNet.IP) || poolNet.Contains(other.IP) {
+				return errors.Errorf("the calico ip pool %s overlaps with %s", pool.Name, name)
+			}
+		}
+		pools[pool.Name] = poolNet
+	}
+	return nil
+}
+
+func validIPAutodetectionMethod(method string) bool {
+	if method == "first-found" || method == "kubernetes-internal-ip" {
+		return true
+	}
+	for _, prefix := range []string{"can-reach=", "interface=", "skip-interface=", "cidr="} {
+		if strings.HasPrefix(method, prefix) && len(method) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type calicoBGPPeer struct {
+	Name         string
+	Address      string
+	AS           uint32
+	Node         string
+	NodeSelector string
+}
+
+// calicoBGPPeers names the peers by their addresses and nodes, so that they are updated in place when applied again.
+func calicoBGPPeers(cfg *v1alpha2.CalicoCfg) []calicoBGPPeer {
+	peers := make([]calicoBGPPeer, 0, len(cfg.BGP.Peers))
+	for _, peer := range cfg.BGP.Peers {
+		name := "peer-" + strings.NewReplacer(".", "-", ":", "-").Replace(peer.Address)
+		if peer.Node != "" {
+			name = fmt.Sprintf("%s-%s", peer.Node, name)
+		}
+		peers = append(peers, calicoBGPPeer{
+			Name:         strings.ToLower(name),
+			Address:      peer.Address,
+			AS:           peer.AS,
+			Node:         peer.Node,
+			NodeSelector: peer.NodeSelector,
+		})
+	}
+	return peers
+}
+
+// calicoRouteReflectors returns the names of the hosts with all the node labels of the route reflectors.
+func calicoRouteReflectors(cfg *v1alpha2.CalicoCfg, hosts []v1alpha2.HostCfg) []string {
+	labels := cfg.BGP.RouteReflectors.NodeLabels
+	if len(labels) == 0 {
+		return nil
+	}
+	var names []string
+	for _, host := range hosts {
+		matched := true
+		for k, v := range labels {
+			if value, ok := host.Labels[k]; !ok || value != v {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			names = append(names, host.Name)
+		}
+	}
+	return names
+}
 
-	cmd := fmt.Sprintf("/usr/local/bin/helm upgrade --install cilium /etc/kubernetes/cilium.tgz --namespace kube-system "+
-		"--set operator.image.override=%s "+
-		"--set operator.replicas=1 "+
-		"--set image.override=%s "+
-		"--set ipam.operator.clusterPoolIPv4PodCIDR=%s", ciliumOperatorImage, ciliumImage, d.KubeConf.Cluster.Network.KubePodsCIDR)
+type calicoIPPool struct {
+	Name         string
+	CIDR         string
+	BlockSize    int
+	IPIPMode     string
+	VXLANMode    string
+	NATOutgoing  bool
+	NodeSelector string
+}
+
+func calicoIPPools(cfg *v1alpha2.CalicoCfg) []calicoIPPool {
+	pools := make([]calicoIPPool, 0, len(cfg.IPPools))
+	for i := range cfg.IPPools {
+		pool := &cfg.IPPools[i]
+		pools = append(pools, calicoIPPool{
+			Name:         pool.Name,
+			CIDR:         pool.CIDR,
+			BlockSize:    pool.BlockSize,
+			IPIPMode:     pool.IPIPMode,
+			VXLANMode:    pool.VXLANMode,
+			NATOutgoing:  pool.EnableNATOutgoing(),
+			NodeSelector: pool.NodeSelector,
+		})
+	}
+	return pools
+}
 
-	if d.KubeConf.Cluster.Kubernetes.DisableKubeProxy {
-		cmd = fmt.Sprintf("%s --set kubeProxyReplacement=strict --set k8sServiceHost=%s --set k8sServicePort=%d", cmd, d.KubeConf.Cluster.ControlPlaneEndpoint.Address, d.KubeConf.Cluster.ControlPlaneEndpoint.Port)
+type LabelCalicoRouteReflectors struct {
+	common.KubeAction
+}
+
+func (l *LabelCalicoRouteReflectors) Execute(runtime connector.Runtime) error {
+	cfg := &l.KubeConf.Cluster.Network.Calico
+	for _, node := range calicoRouteReflectors(cfg, l.KubeConf.Cluster.Hosts) {
+		if _, err := runtime.GetRunner().SudoCmd(
+			fmt.Sprintf("/usr/local/bin/kubectl label --overwrite node %s %s=true", node, calicoRouteReflectorLabel), true); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "label the calico route reflector %s failed", node)
+		}
+		if _, err := runtime.GetRunner().SudoCmd(
+			fmt.Sprintf("/usr/local/bin/kubectl annotate --overwrite node %s %s=%s", node,
+				calicoRouteReflectorClusterIDAnnotation, cfg.BGP.RouteReflectors.ClusterID), true); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "annotate the calico route reflector %s failed", node)
+		}
 	}
+	return nil
+}
+
+type ApplyCalicoResources struct {
+	common.KubeAction
+}
 
+func (a *ApplyCalicoResources) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(
+		fmt.Sprintf("/usr/local/bin/kubectl apply -f %s", filepath.Join(common.KubeConfigDir, templates.CalicoResources.Name())), true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "apply calico bgp configuration and ip pools failed")
+	}
+	return nil
+}
+
+type CheckCustomCNIConfig struct {
+	common.KubeAction
+}
+
+func (c *CheckCustomCNIConfig) Execute(_ connector.Runtime) error {
+	sources := c.KubeConf.Cluster.Network.Custom.Sources
+	if sources.Chart.Name == "" && len(sources.Yaml.Path) == 0 {
+		return errors.New("the chart or the yaml of the custom network plugin must be set in network.custom.sources")
+	}
+	return nil
+}
+
+type InstallCustomCNI struct {
+	common.KubeAction
+}
+
+func (i *InstallCustomCNI) Execute(runtime connector.Runtime) error {
+	custom := i.KubeConf.Cluster.Network.Custom
+	kubeConfig := filepath.Join(runtime.GetWorkDir(), fmt.Sprintf("config-%s", runtime.GetObjName()))
+	if err := addons.InstallAddons(i.KubeConf, &custom, kubeConfig); err != nil {
+		return errors.Wrap(err, "install the custom network plugin failed")
+	}
+	return nil
+}
+
+type CheckNodesReady struct {
+	common.KubeAction
+}
+
+func (c *CheckNodesReady) Execute(runtime connector.Runtime) error {
+	out, err := runtime.GetRunner().SudoCmd("/usr/local/bin/kubectl get nodes --no-headers", false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "get nodes failed")
+	}
+	if nodes := notReadyNodes(out); len(nodes) != 0 {
+		return errors.Errorf("the nodes %s are not ready", strings.Join(nodes, ", "))
+	}
+	return nil
+}
+
+// notReadyNodes parses the output of kubectl get nodes, the status of a ready node is Ready or Ready,SchedulingDisabled.
+func notReadyNodes(out string) []string {
+	var nodes []string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if strings.Split(fields[1], ",")[0] != "Ready" {
+			nodes = append(nodes, fields[0])
+		}
+	}
+	return nodes
+}
+
+type CheckFlannelNetwork struct {
+	common.KubeAction
+}
+
+func (c *CheckFlannelNetwork) Execute(runtime connector.Runtime) error {
+	netConf, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl -n kube-system get cm %s -o jsonpath='{.data.net-conf\\.json}'", flannelConfigMap), false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "get the network of flannel failed")
+	}
+	nodes, err := runtime.GetRunner().SudoCmd(
+		"/usr/local/bin/kubectl get nodes --no-headers -o custom-columns=NAME:.metadata.name,PODCIDR:.spec.podCIDR", false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "get the podCIDR of the nodes failed")
+	}
+	return validateMigrationCIDR(netConf, nodes, c.KubeConf.Cluster.Network.KubePodsCIDR)
+}
+
+// validateMigrationCIDR checks that the network of flannel is kubePodsCIDR and that every node has a podCIDR in it,
+// the new network plugin allocates the pod addresses from the podCIDR of the node as flannel does.
+func validateMigrationCIDR(netConf, nodes, kubePodsCIDR string) error {
+	_, podsNet, err := net.ParseCIDR(kubePodsCIDR)
+	if err != nil {
+		return errors.Wrapf(err, "invalid kubePodsCIDR %s", kubePodsCIDR)
+	}
+
+	conf := struct {
+		Network string `json:"Network"`
+	}{}
+	if err := json.Unmarshal([]byte(netConf), &conf); err != nil {
+		return errors.Wrap(errors.WithStack(err), "parse the net-conf.json of flannel failed")
+	}
+	_, flannelNet, err := net.ParseCIDR(conf.Network)
+	if err != nil {
+		return errors.Wrapf(err, "invalid network %s of flannel", conf.Network)
+	}
+	if flannelNet.String() != podsNet.String() {
+		return errors.Errorf("the network %s of flannel is different from the kubePodsCIDR %s", conf.Network, kubePodsCIDR)
+	}
+
+	for _, line := range strings.Split(nodes, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		ip, _, err := net.ParseCIDR(fields[1])
+		if err != nil {
+			return errors.Errorf("the node %s has no podCIDR, the node CIDRs must be allocated by kube-controller-manager", fields[0])
+		}
+		if !podsNet.Contains(ip) {
+			return errors.Errorf("the podCIDR %s of the node %s is out of the kubePodsCIDR %s", fields[1], fields[0], kubePodsCIDR)
+		}
+	}
+	return nil
+}
+
+type PinFlannel struct {
+	common.KubeAction
+}
+
+// Execute keeps flannel on the nodes without MigratedNodeLabel, the pod of flannel is removed from a node once it is labelled.
+func (p *PinFlannel) Execute(runtime connector.Runtime) error {
+	patch := fmt.Sprintf(`{"spec":{"template":{"spec":{"affinity":{"nodeAffinity":{"requiredDuringSchedulingIgnoredDuringExecution":`+
+		`{"nodeSelectorTerms":[{"matchExpressions":[{"key":"kubernetes.io/os","operator":"In","values":["linux"]},`+
+		`{"key":"%s","operator":"DoesNotExist"}]}]}}}}}}}`, MigratedNodeLabel)
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("/usr/local/bin/kubectl -n kube-system patch ds %s --type merge -p '%s'",
+		flannelDaemonSet, strings.ReplaceAll(patch, `"`, `\"`)), true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "patch the node affinity of flannel failed")
+	}
+	return nil
+}
+
+type MigrateNode struct {
+	common.KubeAction
+}
+
+// Execute moves the node to the new network plugin. The node is drained and labelled, then the configuration and the
+// interfaces of flannel are removed from it and the pods on it are restarted to get the addresses of the new plugin.
+func (m *MigrateNode) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	node := host.GetName()
+	master := []connector.Host{runtime.GetHostsByRole(common.Master)[0]}
+	agentSelector := networkAgentSelector(m.KubeConf.Cluster.Network.Plugin)
+
+	tasks := []task.Interface{
+		&task.RemoteTask{
+			Name:   "DrainNode",
+			Desc:   "Cordon and drain the node",
+			Hosts:  master,
+			Action: &DrainNode{Node: node},
+			Retry:  2,
+		},
+		&task.RemoteTask{
+			Name:   "LabelMigratedNode",
+			Desc:   "Label the node as migrated",
+			Hosts:  master,
+			Action: &LabelMigratedNode{Node: node},
+			Retry:  3,
+		},
+		&task.RemoteTask{
+			Name:   "WaitFlannelRemoved",
+			Desc:   "Wait for flannel to be removed from the node",
+			Hosts:  master,
+			Action: &CheckPodsRemoved{Node: node, Selector: flannelSelector},
+			Retry:  30,
+			Delay:  5 * time.Second,
+		},
+		&task.RemoteTask{
+			Name:   "RemoveFlannelInterfaces",
+			Desc:   "Remove the cni configuration and the interfaces of flannel",
+			Hosts:  []connector.Host{host},
+			Action: new(RemoveFlannelInterfaces),
+			Retry:  2,
+		},
+		&task.RemoteTask{
+			Name:   "RestartNetworkAgent",
+			Desc:   "Restart the agent of the new network plugin on the node",
+			Hosts:  master,
+			Action: &RestartNetworkAgent{Node: node, Selector: agentSelector},
+			Retry:  3,
+		},
+		&task.RemoteTask{
+			Name:   "WaitNetworkAgentReady",
+			Desc:   "Wait for the agent of the new network plugin to be ready",
+			Hosts:  master,
+			Action: &CheckPodsReady{Node: node, Selector: agentSelector},
+			Retry:  30,
+			Delay:  10 * time.Second,
+		},
+		&task.RemoteTask{
+			Name:   "RestartPods",
+			Desc:   "Restart the pods on the node with the new network plugin",
+			Hosts:  master,
+			Action: &RestartPods{Node: node},
+			Retry:  3,
+		},
+		&task.RemoteTask{
+			Name:   "UncordonNode",
+			Desc:   "Uncordon the node",
+			Hosts:  master,
+			Action: &UncordonNode{Node: node},
+			Retry:  3,
+		},
+	}
+
+	for i := range tasks {
+		t := tasks[i]
+		t.Init(runtime, m.ModuleCache, m.PipelineCache)
+		if res := t.Execute(); res.IsFailed() {
+			return errors.Wrapf(res.CombineErr(), "migrate the node %s failed", node)
+		}
+	}
+	return nil
+}
+
+// networkAgentSelector returns the label selector of the daemonset pods of the network plugin.
+func networkAgentSelector(plugin string) string {
+	if plugin == common.Cilium {
+		return "k8s-app=cilium"
+	}
+	return "k8s-app=calico-node"
+}
+
+type DrainNode struct {
+	common.KubeAction
+	Node string
+}
+
+func (d *DrainNode) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl drain %s --delete-emptydir-data --ignore-daemonsets --timeout=2m --force", d.Node), true); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "drain the node %s failed", d.Node)
+	}
+	return nil
+}
+
+type LabelMigratedNode struct {
+	common.KubeAction
+	Node string
+}
+
+func (l *LabelMigratedNode) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl label node %s %s=true --overwrite", l.Node, MigratedNodeLabel), true); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "label the node %s failed", l.Node)
+	}
+	return nil
+}
+
+type CheckPodsRemoved struct {
+	common.KubeAction
+	Node     string
+	Selector string
+}
+
+func (c *CheckPodsRemoved) Execute(runtime connector.Runtime) error {
+	out, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl -n kube-system get pods -l %s --field-selector spec.nodeName=%s --no-headers", c.Selector, c.Node), false)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "get the pods %s on the node %s failed", c.Selector, c.Node)
+	}
+	if strings.TrimSpace(out) != "" {
+		return errors.Errorf("the pods %s are still running on the node %s", c.Selector, c.Node)
+	}
+	return nil
+}
+
+type RemoveFlannelInterfaces struct {
+	common.KubeAction
+}
+
+func (r *RemoveFlannelInterfaces) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(
+		"rm -f /etc/cni/net.d/10-flannel.conflist /run/flannel/subnet.env && rm -rf /var/lib/cni/networks/cbr0 && "+
+			"(ip link delete flannel.1 2>/dev/null; ip link delete cni0 2>/dev/null; true)", true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "remove the interfaces of flannel failed")
+	}
+	return nil
+}
+
+type RestartNetworkAgent struct {
+	common.KubeAction
+	Node     string
+	Selector string
+}
+
+// Execute restarts the agent which may be failing while the interfaces of flannel exist.
+func (r *RestartNetworkAgent) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl -n kube-system delete pods -l %s --field-selector spec.nodeName=%s", r.Selector, r.Node), true); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "restart the pods %s on the node %s failed", r.Selector, r.Node)
+	}
+	return nil
+}
+
+type CheckPodsReady struct {
+	common.KubeAction
+	Node     string
+	Selector string
+}
+
+func (c *CheckPodsReady) Execute(runtime connector.Runtime) error {
+	out, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl -n kube-system get pods -l %s --field-selector spec.nodeName=%s --no-headers", c.Selector, c.Node), false)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "get the pods %s on the node %s failed", c.Selector, c.Node)
+	}
+	if !podsReady(out) {
+		return errors.Errorf("the pods %s on the node %s are not ready", c.Selector, c.Node)
+	}
+	return nil
+}
+
+// podsReady parses the output of kubectl get pods, there must be pods and all their containers must be ready.
+func podsReady(out string) bool {
+	var found bool
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		ready := strings.Split(fields[1], "/")
+		if len(ready) != 2 || ready[0] != ready[1] || fields[2] != "Running" {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+type RestartPods struct {
+	common.KubeAction
+	Node string
+}
+
+// Execute deletes the pods on the pod network of the node, the pods of the daemonsets are not evicted by the drain.
+func (r *RestartPods) Execute(runtime connector.Runtime) error {
+	out, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl get pods -A --field-selector spec.nodeName=%s --no-headers "+
+			"-o custom-columns=NAMESPACE:.metadata.namespace,NAME:.metadata.name,HOSTNETWORK:.spec.hostNetwork", r.Node), false)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "get the pods on the node %s failed", r.Node)
+	}
+	for _, pod := range podNetworkPods(out) {
+		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+			"/usr/local/bin/kubectl -n %s delete pod %s --ignore-not-found", pod[0], pod[1]), true); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "restart the pod %s/%s failed", pod[0], pod[1])
+		}
+	}
+	return nil
+}
+
+// podNetworkPods parses the namespaces, the names and the hostNetwork of the pods, and returns the namespaces and
+// the names of the pods not using the host network.
+func podNetworkPods(out string) [][2]string {
+	var pods [][2]string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[2] == "true" {
+			continue
+		}
+		pods = append(pods, [2]string{fields[0], fields[1]})
+	}
+	return pods
+}
+
+type UncordonNode struct {
+	common.KubeAction
+	Node string
+}
+
+func (u *UncordonNode) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("/usr/local/bin/kubectl uncordon %s", u.Node), true); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "uncordon the node %s failed", u.Node)
+	}
+	return nil
+}
+
+type DeleteFlannel struct {
+	common.KubeAction
+}
+
+func (d *DeleteFlannel) Execute(runtime connector.Runtime) error {
+	cmd := strings.Join([]string{
+		fmt.Sprintf("/usr/local/bin/kubectl -n kube-system delete ds %s --ignore-not-found", flannelDaemonSet),
+		fmt.Sprintf("/usr/local/bin/kubectl -n kube-system delete cm %s --ignore-not-found", flannelConfigMap),
+		"/usr/local/bin/kubectl -n kube-system delete sa flannel --ignore-not-found",
+		"/usr/local/bin/kubectl delete clusterrolebinding flannel --ignore-not-found",
+		"/usr/local/bin/kubectl delete clusterrole flannel --ignore-not-found",
+	}, " && ")
 	if _, err := runtime.GetRunner().SudoCmd(cmd, true); err != nil {
-		return errors.Wrap(errors.WithStack(err), "deploy cilium failed")
+		return errors.Wrap(errors.WithStack(err), "delete flannel failed")
+	}
+	// PodSecurityPolicy is removed since Kubernetes v1.25, the error is ignored.
+	_, _ = runtime.GetRunner().SudoCmd("/usr/local/bin/kubectl delete psp psp.flannel.unprivileged --ignore-not-found", false)
+	return nil
+}
+
+type UnlabelMigratedNodes struct {
+	common.KubeAction
+}
+
+func (u *UnlabelMigratedNodes) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl label nodes --all %s-", MigratedNodeLabel), true); err != nil {
+		return errors.Wrap(errors.WithStack(err), "remove the migration label of the nodes failed")
 	}
 	return nil
 }
@@ -0,0 +1,254 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package network
+
+import (
+	"testing"
+
+	"github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+)
+
+func TestValidateCiliumCfg(t *testing.T) {
+	disabled := false
+	defaultCfg := func() v1alpha2.CiliumCfg {
+		return v1alpha2.CiliumCfg{
+			KubeProxyReplacement: "disabled",
+			Tunnel:               "vxlan",
+			IPAM:                 "cluster-pool",
+			Encryption:           v1alpha2.CiliumEncryption{Type: "wireguard"},
+		}
+	}
+	tests := []struct {
+		name             string
+		kubeVersion      string
+		disableKubeProxy bool
+		modify           func(cfg *v1alpha2.CiliumCfg)
+		wantErr          bool
+	}{
+		{name: "default", kubeVersion: "v1.23.7"},
+		{name: "old kubernetes", kubeVersion: "v1.15.12", wantErr: true},
+		{name: "kube-proxy replacement", kubeVersion: "v1.23.7", disableKubeProxy: true,
+			modify: func(cfg *v1alpha2.CiliumCfg) { cfg.KubeProxyReplacement = "strict" }},
+		{name: "kube-proxy disabled without replacement", kubeVersion: "v1.23.7", disableKubeProxy: true, wantErr: true},
+		{name: "strict replacement beside kube-proxy", kubeVersion: "v1.23.7", wantErr: true,
+			modify: func(cfg *v1alpha2.CiliumCfg) { cfg.KubeProxyReplacement = "strict" }},
+		{name: "unknown kube-proxy replacement", kubeVersion: "v1.23.7", wantErr: true,
+			modify: func(cfg *v1alpha2.CiliumCfg) { cfg.KubeProxyReplacement = "full" }},
+		{name: "native routing", kubeVersion: "v1.23.7",
+			modify: func(cfg *v1alpha2.CiliumCfg) {
+				cfg.Tunnel = "disabled"
+				cfg.NativeRoutingCIDR = "10.233.64.0/18"
+				cfg.AutoDirectNodeRoutes = true
+			}},
+		{name: "native routing without cidr", kubeVersion: "v1.23.7", wantErr: true,
+			modify: func(cfg *v1alpha2.CiliumCfg) { cfg.Tunnel = "disabled" }},
+		{name: "direct node routes with tunnel", kubeVersion: "v1.23.7", wantErr: true,
+			modify: func(cfg *v1alpha2.CiliumCfg) { cfg.AutoDirectNodeRoutes = true }},
+		{name: "unknown ipam", kubeVersion: "v1.23.7", wantErr: true,
+			modify: func(cfg *v1alpha2.CiliumCfg) { cfg.IPAM = "crd" }},
+		{name: "ipsec", kubeVersion: "v1.23.7",
+			modify: func(cfg *v1alpha2.CiliumCfg) {
+				cfg.Encryption = v1alpha2.CiliumEncryption{Enabled: true, Type: "ipsec"}
+			}},
+		{name: "unknown encryption", kubeVersion: "v1.23.7", wantErr: true,
+			modify: func(cfg *v1alpha2.CiliumCfg) { cfg.Encryption = v1alpha2.CiliumEncryption{Enabled: true, Type: "tls"} }},
+		{name: "hubble ui", kubeVersion: "v1.23.7",
+			modify: func(cfg *v1alpha2.CiliumCfg) { cfg.Hubble = v1alpha2.CiliumHubble{Relay: true, UI: true} }},
+		{name: "hubble ui without relay", kubeVersion: "v1.23.7", wantErr: true,
+			modify: func(cfg *v1alpha2.CiliumCfg) { cfg.Hubble = v1alpha2.CiliumHubble{UI: true} }},
+		{name: "hubble relay without hubble", kubeVersion: "v1.23.7", wantErr: true,
+			modify: func(cfg *v1alpha2.CiliumCfg) { cfg.Hubble = v1alpha2.CiliumHubble{Enabled: &disabled, Relay: true} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultCfg()
+			if tt.modify != nil {
+				tt.modify(&cfg)
+			}
+			if err := validateCiliumCfg(tt.kubeVersion, tt.disableKubeProxy, &cfg); (err != nil) != tt.wantErr {
+				t.Errorf("validateCiliumCfg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCalicoCfg(t *testing.T) {
+	hosts := []v1alpha2.HostCfg{
+		{Name: "node1", Labels: map[string]string{"rack": "r1", "rr": "true"}},
+		{Name: "node2", Labels: map[string]string{"rack": "r1"}},
+	}
+	defaultCfg := func() v1alpha2.NetworkConfig {
+		return v1alpha2.NetworkConfig{
+			KubePodsCIDR: "10.233.64.0/18",
+			Calico: v1alpha2.CalicoCfg{
+				IPAutodetectionMethod: "can-reach=$(NODEIP)",
+				IPAM:                  "calico-ipam",
+				BGP:                   v1alpha2.CalicoBGP{RouteReflectors: v1alpha2.CalicoRouteReflectors{ClusterID: "244.0.0.1"}},
+			},
+		}
+	}
+	pool := func(name, cidr string) v1alpha2.CalicoIPPool {
+		return v1alpha2.CalicoIPPool{Name: name, CIDR: cidr, BlockSize: 24, IPIPMode: "Always", VXLANMode: "Never", NodeSelector: "all()"}
+	}
+	tests := []struct {
+		name    string
+		modify  func(cfg *v1alpha2.CalicoCfg)
+		wantErr bool
+	}{
+		{name: "default"},
+		{name: "interface autodetection",
+			modify: func(cfg *v1alpha2.CalicoCfg) { cfg.IPAutodetectionMethod = "interface=eth.*" }},
+		{name: "unknown autodetection", wantErr: true,
+			modify: func(cfg *v1alpha2.CalicoCfg) { cfg.IPAutodetectionMethod = "eth0" }},
+		{name: "host-local ipam",
+			modify: func(cfg *v1alpha2.CalicoCfg) { cfg.IPAM = "host-local" }},
+		{name: "unknown ipam", wantErr: true,
+			modify: func(cfg *v1alpha2.CalicoCfg) { cfg.IPAM = "dhcp" }},
+		{name: "peers",
+			modify: func(cfg *v1alpha2.CalicoCfg) {
+				cfg.BGP.Peers = []v1alpha2.CalicoBGPPeer{
+					{Address: "192.168.0.254", AS: 65001},
+					{Address: "192.168.1.254", AS: 65002, Node: "node1"},
+					{Address: "192.168.1.254", AS: 65002, Node: "node2"},
+				}
+			}},
+		{name: "peer without AS", wantErr: true,
+			modify: func(cfg *v1alpha2.CalicoCfg) { cfg.BGP.Peers = []v1alpha2.CalicoBGPPeer{{Address: "192.168.0.254"}} }},
+		{name: "peer of unknown node", wantErr: true,
+			modify: func(cfg *v1alpha2.CalicoCfg) {
+				cfg.BGP.Peers = []v1alpha2.CalicoBGPPeer{{Address: "192.168.0.254", AS: 65001, Node: "node3"}}
+			}},
+		{name: "duplicated peers", wantErr: true,
+			modify: func(cfg *v1alpha2.CalicoCfg) {
+				cfg.BGP.Peers = []v1alpha2.CalicoBGPPeer{{Address: "192.168.0.254", AS: 65001}, {Address: "192.168.0.254", AS: 65002}}
+			}},
+		{name: "route reflectors",
+			modify: func(cfg *v1alpha2.CalicoCfg) { cfg.BGP.RouteReflectors.NodeLabels = map[string]string{"rr": "true"} }},
+		{name: "no route reflector matched", wantErr: true,
+			modify: func(cfg *v1alpha2.CalicoCfg) { cfg.BGP.RouteReflectors.NodeLabels = map[string]string{"rr": "false"} }},
+		{name: "ip pools",
+			modify: func(cfg *v1alpha2.CalicoCfg) {
+				cfg.IPPools = []v1alpha2.CalicoIPPool{pool("rack1", "10.234.0.0/18"), pool("rack2", "10.234.64.0/18")}
+			}},
+		{name: "reserved ip pool", wantErr: true,
+			modify: func(cfg *v1alpha2.CalicoCfg) {
+				cfg.IPPools = []v1alpha2.CalicoIPPool{pool("default-ipv4-ippool", "10.234.0.0/18")}
+			}},
+		{name: "ip pool overlapping kubePodsCIDR", wantErr: true,
+			modify: func(cfg *v1alpha2.CalicoCfg) { cfg.IPPools = []v1alpha2.CalicoIPPool{pool("rack1", "10.233.96.0/20")} }},
+		{name: "overlapping ip pools", wantErr: true,
+			modify: func(cfg *v1alpha2.CalicoCfg) {
+				cfg.IPPools = []v1alpha2.CalicoIPPool{pool("rack1", "10.234.0.0/16"), pool("rack2", "10.234.64.0/18")}
+			}},
+		{name: "block size larger than the pool", wantErr: true,
+			modify: func(cfg *v1alpha2.CalicoCfg) { cfg.IPPools = []v1alpha2.CalicoIPPool{pool("rack1", "10.234.0.0/26")} }},
+		{name: "ipip and vxlan", wantErr: true,
+			modify: func(cfg *v1alpha2.CalicoCfg) {
+				p := pool("rack1", "10.234.0.0/18")
+				p.VXLANMode = "CrossSubnet"
+				cfg.IPPools = []v1alpha2.CalicoIPPool{p}
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := defaultCfg()
+			if tt.modify != nil {
+				tt.modify(&network.Calico)
+			}
+			if err := validateCalicoCfg(&network, hosts); (err != nil) != tt.wantErr {
+				t.Errorf("validateCalicoCfg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCalicoBGPPeers(t *testing.T) {
+	cfg := &v1alpha2.CalicoCfg{BGP: v1alpha2.CalicoBGP{Peers: []v1alpha2.CalicoBGPPeer{
+		{Address: "192.168.0.254", AS: 65001},
+		{Address: "fd00::1", AS: 65002, Node: "Node1"},
+	}}}
+	peers := calicoBGPPeers(cfg)
+	if peers[0].Name != "peer-192-168-0-254" || peers[1].Name != "node1-peer-fd00--1" {
+		t.Errorf("calicoBGPPeers() = %v", peers)
+	}
+}
+
+func TestNotReadyNodes(t *testing.T) {
+	out := "node1   Ready                      control-plane,master   5m    v1.23.7\r\n" +
+		"node2   Ready,SchedulingDisabled   worker                 4m    v1.23.7\r\n" +
+		"node3   NotReady                   worker                 4m    v1.23.7\r\n" +
+		"node4   NotReady,SchedulingDisabled   worker              4m    v1.23.7"
+	nodes := notReadyNodes(out)
+	if len(nodes) != 2 || nodes[0] != "node3" || nodes[1] != "node4" {
+		t.Errorf("notReadyNodes() = %v, want [node3 node4]", nodes)
+	}
+	if nodes := notReadyNodes(""); len(nodes) != 0 {
+		t.Errorf("notReadyNodes() = %v, want none", nodes)
+	}
+}
+
+func TestValidateMigrationCIDR(t *testing.T) {
+	netConf := `{"Network": "10.233.64.0/18", "Backend": {"Type": "vxlan"}}`
+	nodes := "node1   10.233.64.0/24\r\nnode2   10.233.65.0/24\r\n"
+	tests := []struct {
+		name         string
+		netConf      string
+		nodes        string
+		kubePodsCIDR string
+		wantErr      bool
+	}{
+		{name: "compatible", netConf: netConf, nodes: nodes, kubePodsCIDR: "10.233.64.0/18"},
+		{name: "different network", netConf: netConf, nodes: nodes, kubePodsCIDR: "10.244.0.0/16", wantErr: true},
+		{name: "invalid net-conf", netConf: "{", nodes: nodes, kubePodsCIDR: "10.233.64.0/18", wantErr: true},
+		{name: "node without podCIDR", netConf: netConf, nodes: nodes + "node3   <none>", kubePodsCIDR: "10.233.64.0/18", wantErr: true},
+		{name: "podCIDR out of kubePodsCIDR", netConf: netConf, nodes: nodes + "node3   10.244.0.0/24", kubePodsCIDR: "10.233.64.0/18", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateMigrationCIDR(tt.netConf, tt.nodes, tt.kubePodsCIDR); (err != nil) != tt.wantErr {
+				t.Errorf("validateMigrationCIDR() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPodsReady(t *testing.T) {
+	if !podsReady("calico-node-x7k2p   1/1   Running   0   2m\r\n") {
+		t.Errorf("podsReady() = false, want true")
+	}
+	if podsReady("calico-node-x7k2p   0/1   Running   0   2m\r\n") {
+		t.Errorf("podsReady() = true, want false when the container is not ready")
+	}
+	if podsReady("cilium-p2x4c   0/1   Init:0/2   0   2m\r\n") {
+		t.Errorf("podsReady() = true, want false when the pod is initializing")
+	}
+	if podsReady("") {
+		t.Errorf("podsReady() = true, want false without pods")
+	}
+}
+
+func TestPodNetworkPods(t *testing.T) {
+	out := "kube-system   kube-proxy-8xq2v            true\r\n" +
+		"kube-system   nodelocaldns-4hx9z          true\r\n" +
+		"default       nginx-6799fc88d8-6rmvl      <none>\r\n" +
+		"monitoring    node-exporter-p7nmc         false"
+	pods := podNetworkPods(out)
+	if len(pods) != 2 || pods[0] != [2]string{"default", "nginx-6799fc88d8-6rmvl"} ||
+		pods[1] != [2]string{"monitoring", "node-exporter-p7nmc"} {
+		t.Errorf("podNetworkPods() = %v", pods)
+	}
+}
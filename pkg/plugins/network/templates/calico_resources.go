@@ -0,0 +1,77 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"github.com/lithammer/dedent"
+	"text/template"
+)
+
+var CalicoResources = template.Must(template.New("calico-resources.yaml").Parse(
+	dedent.Dedent(`---
+apiVersion: crd.projectcalico.org/v1
+kind: BGPConfiguration
+metadata:
+  name: default
+spec:
+  logSeverityScreen: Info
+  nodeToNodeMeshEnabled: {{ .NodeToNodeMesh }}
+{{- if .AS }}
+  asNumber: {{ .AS }}
+{{- end }}
+{{- if .RouteReflectors }}
+---
+apiVersion: crd.projectcalico.org/v1
+kind: BGPPeer
+metadata:
+  name: peer-with-route-reflectors
+spec:
+  nodeSelector: all()
+  peerSelector: {{ .RouteReflectorLabel }} == 'true'
+{{- end }}
+{{- range .Peers }}
+---
+apiVersion: crd.projectcalico.org/v1
+kind: BGPPeer
+metadata:
+  name: {{ .Name }}
+spec:
+  peerIP: {{ .Address }}
+  asNumber: {{ .AS }}
+{{- if .Node }}
+  node: {{ .Node }}
+{{- end }}
+{{- if .NodeSelector }}
+  nodeSelector: {{ printf "%q" .NodeSelector }}
+{{- end }}
+{{- end }}
+{{- range .IPPools }}
+---
+apiVersion: crd.projectcalico.org/v1
+kind: IPPool
+metadata:
+  name: {{ .Name }}
+spec:
+  cidr: {{ .CIDR }}
+  blockSize: {{ .BlockSize }}
+  ipipMode: {{ .IPIPMode }}
+  vxlanMode: {{ .VXLANMode }}
+  natOutgoing: {{ .NATOutgoing }}
+  nodeSelector: {{ printf "%q" .NodeSelector }}
+{{- end }}
+
+    `)))
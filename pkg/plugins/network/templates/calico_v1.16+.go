@@ -57,7 +57,12 @@ data:
           "nodename": "__KUBERNETES_NODE_NAME__",
           "mtu": __CNI_MTU__,
           "ipam": {
+{{- if eq .IPAM "host-local" }}
+              "type": "host-local",
+              "subnet": "usePodCidr"
+{{- else }}
               "type": "calico-ipam"
+{{- end }}
           },
           "policy": {
               "type": "k8s"
@@ -4454,6 +4459,9 @@ spec:
     spec:
       nodeSelector:
         kubernetes.io/os: linux
+{{- if .MigrationNodeLabel }}
+        {{ .MigrationNodeLabel }}: "true"
+{{- end }}
       hostNetwork: true
       tolerations:
         # Make sure calico-node gets scheduled on all nodes.
@@ -4470,6 +4478,7 @@ spec:
       terminationGracePeriodSeconds: 0
       priorityClassName: system-node-critical
       initContainers:
+{{- if ne .IPAM "host-local" }}
         # This container performs upgrade from host-local IPAM to calico-ipam.
         # It can be deleted if this is a fresh installation, or if you have already
         # upgraded to use calico-ipam.
@@ -4498,6 +4507,7 @@ spec:
               name: cni-bin-dir
           securityContext:
             privileged: true
+{{- end }}
         # This container installs the CNI binaries
         # and CNI network config file on each node.
         - name: install-cni
@@ -4585,7 +4595,12 @@ spec:
                 fieldRef:
                   fieldPath: status.hostIP
             - name: IP_AUTODETECTION_METHOD
-              value: "can-reach=$(NODEIP)"
+              value: "{{ .IPAutodetectionMethod }}"
+{{- if eq .IPAM "host-local" }}
+            # Route the podCIDR of the node allocated by host-local IPAM.
+            - name: USE_POD_CIDR
+              value: "true"
+{{- end }}
             - name: IP
               value: "autodetect"
             # Enable IPIP
@@ -20,6 +20,7 @@ import (
 	"crypto/x509/pkix"
 	"fmt"
 	"github.com/kubesphere/kubekey/pkg/common"
+	"github.com/kubesphere/kubekey/pkg/core/logger"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -122,7 +123,9 @@ func CreateCACertAndKeyFiles(certSpec *KubekeyCert, pkiPath string, kubeConf *co
 	)
 }
 
-func GenerateCerts(cert *KubekeyCert, caCert *KubekeyCert, pkiPath string, kubeConf *common.KubeConf) error {
+// GenerateCerts creates the certificate signed by the CA, or uses the existing one on disk.
+// If reissueChangedSANs is true, an existing certificate whose SANs differ from the config is reissued.
+func GenerateCerts(cert *KubekeyCert, caCert *KubekeyCert, pkiPath string, kubeConf *common.KubeConf, reissueChangedSANs bool) error {
 	// TODO: if using external etcd, skips etcd certificates generation
 
 	if certData, intermediates, err := TryLoadCertChainFromDisk(pkiPath, cert.BaseName); err == nil {
@@ -139,6 +142,14 @@ func GenerateCerts(cert *KubekeyCert, caCert *KubekeyCert, pkiPath string, kubeC
 			return errors.Wrapf(err, "[certs] certificate %s not signed by CA certificate %s", cert.BaseName, caCert.BaseName)
 		}
 
+		// The SANs change when the hosts are changed, e.g. a node is replaced.
+		if reissueChangedSANs {
+			if err := validateCertificateWithConfig(certData, cert.BaseName, &cert.Config); err != nil {
+				logger.Log.Infof("[certs] Reissuing %s certificate: %v", cert.BaseName, err)
+				return ReissueCertAndKeyFilesWithCA(caCert, cert, pkiPath, kubeConf)
+			}
+		}
+
 		fmt.Printf("[certs] Using existing %s certificate and key on disk\n", cert.BaseName)
 		return nil
 	}
@@ -159,7 +170,31 @@ func CreateCertAndKeyFilesWithCA(caCertSpec *KubekeyCert, certSpec *KubekeyCert,
 		return errors.Wrapf(err, "couldn't load CA certificate %s", caCertSpec.Name)
 	}
 
-	return certSpec.CreateFromCA(kubeConf, pkiPath, caCert, caKey)
+	if err := certSpec.CreateFromCA(kubeConf, pkiPath, caCert, caKey); err != nil {
+		return err
+	}
+	return appendCAChain(pkiPath, caCertSpec.BaseName, certSpec)
+}
+
+// ReissueCertAndKeyFilesWithCA loads the given certificate authority from disk, then generates and overwrites the given certificate and key.
+func ReissueCertAndKeyFilesWithCA(caCertSpec *KubekeyCert, certSpec *KubekeyCert, pkiPath string, kubeConf *common.KubeConf) error {
+	caCert, caKey, err := LoadCertificateAuthority(pkiPath, caCertSpec.BaseName)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't load CA certificate %s", caCertSpec.Name)
+	}
+
+	cfg, err := certSpec.GetConfig(kubeConf)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't create %q certificate", certSpec.Name)
+	}
+	cert, key, err := NewCertAndKey(caCert, caKey, cfg)
+	if err != nil {
+		return err
+	}
+	if err := WriteCertAndKey(pkiPath, certSpec.BaseName, cert, key); err != nil {
+		return errors.Wrapf(err, "failure while saving %s certificate and key", certSpec.BaseName)
+	}
+	return appendCAChain(pkiPath, caCertSpec.BaseName, certSpec)
 }
 
 // LoadCertificateAuthority tries to load a CA in the given directory with the given name.
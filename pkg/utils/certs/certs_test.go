@@ -0,0 +1,69 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto/x509"
+	"net"
+	"testing"
+
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/kubesphere/kubekey/pkg/core/logger"
+)
+
+func TestGenerateCertsChangedSANs(t *testing.T) {
+	logger.Log = logger.NewLogger(t.TempDir(), false)
+	dir := t.TempDir()
+	ca := &KubekeyCert{Name: "ca", BaseName: "ca", Config: CertConfig{Config: certutil.Config{CommonName: "ca"}}}
+	if err := CreateCACertAndKeyFiles(ca, dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	newServerCert := func(ip net.IP) *KubekeyCert {
+		return &KubekeyCert{Name: "server", BaseName: "server", CAName: "ca", Config: CertConfig{Config: certutil.Config{
+			CommonName: "server",
+			Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			AltNames:   certutil.AltNames{IPs: []net.IP{ip}},
+		}}}
+	}
+	if err := GenerateCerts(newServerCert(net.IPv4(192, 168, 0, 2)), ca, dir, nil, false); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := newServerCert(net.IPv4(192, 168, 0, 3))
+	if err := GenerateCerts(changed, ca, dir, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if ip := serverIP(t, dir); !ip.Equal(net.IPv4(192, 168, 0, 2)) {
+		t.Fatalf("expected the existing cert to be kept, got the IP %v", ip)
+	}
+
+	if err := GenerateCerts(changed, ca, dir, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if ip := serverIP(t, dir); !ip.Equal(net.IPv4(192, 168, 0, 3)) {
+		t.Fatalf("expected the cert to be reissued with the new SANs, got the IP %v", ip)
+	}
+}
+
+func serverIP(t *testing.T, dir string) net.IP {
+	cert, err := TryLoadCertFromDisk(dir, "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert.IPAddresses[0]
+}
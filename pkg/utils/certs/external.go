@@ -0,0 +1,243 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+)
+
+// LoadExternalCA loads and validates the CA supplied by users. The CA file must contain the chain up to the root CA.
+// The returned key is nil if the key of the CA is not supplied.
+func LoadExternalCA(pki *kubekeyapiv1alpha2.PKI) ([]*x509.Certificate, crypto.Signer, error) {
+	chain, err := certutil.CertsFromFile(pki.CAFile)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "couldn't load the CA file %s", pki.CAFile)
+	}
+
+	ca := chain[0]
+	if !ca.IsCA || (ca.KeyUsage != 0 && ca.KeyUsage&x509.KeyUsageCertSign == 0) {
+		return nil, nil, errors.Errorf("the certificate in %s is not a CA", pki.CAFile)
+	}
+	if err := ValidateCertPeriod(ca, 0); err != nil {
+		return nil, nil, errors.Wrapf(err, "the CA in %s is invalid", pki.CAFile)
+	}
+	root := chain[len(chain)-1]
+	if err := root.CheckSignatureFrom(root); err != nil {
+		return nil, nil, errors.Errorf("the last certificate in %s is not a root CA, the chain up to the root CA is required", pki.CAFile)
+	}
+	if err := VerifyCertChain(ca, chain[1:], root); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to verify the chain of the CA in %s", pki.CAFile)
+	}
+
+	if pki.CAKeyFile == "" {
+		return chain, nil, nil
+	}
+	key, err := loadExternalKey(pki.CAKeyFile, ca)
+	if err != nil {
+		return nil, nil, err
+	}
+	return chain, key, nil
+}
+
+// LoadExternalCert loads a pre-issued cert supplied by users, and validates it with the CA chain
+// and the config of the cert which would be generated by KubeKey.
+func LoadExternalCert(cert *kubekeyapiv1alpha2.PKICert, caChain []*x509.Certificate, cfg *CertConfig) (*x509.Certificate, crypto.Signer, error) {
+	certs, err := certutil.CertsFromFile(cert.CertFile)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "couldn't load the certificate file %s", cert.CertFile)
+	}
+
+	leaf := certs[0]
+	if err := ValidateCertPeriod(leaf, 0); err != nil {
+		return nil, nil, errors.Wrapf(err, "the certificate %s is invalid", cert.Name)
+	}
+	intermediates := append(certs[1:], caChain[:len(caChain)-1]...)
+	if err := VerifyCertChain(leaf, intermediates, caChain[len(caChain)-1]); err != nil {
+		return nil, nil, errors.Wrapf(err, "the certificate %s is not issued by the CA", cert.Name)
+	}
+	if err := validateCertificateWithConfig(leaf, cert.Name, cfg); err != nil {
+		return nil, nil, err
+	}
+	if err := validateKeyUsages(leaf, cfg.Usages); err != nil {
+		return nil, nil, errors.Wrapf(err, "the certificate %s is invalid", cert.Name)
+	}
+
+	key, err := loadExternalKey(cert.KeyFile, leaf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return leaf, key, nil
+}
+
+// validateKeyUsages makes sure that a given certificate can be used for all the extended key usages.
+func validateKeyUsages(cert *x509.Certificate, usages []x509.ExtKeyUsage) error {
+	for _, usage := range usages {
+		found := false
+		for _, u := range cert.ExtKeyUsage {
+			if u == usage || u == x509.ExtKeyUsageAny {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("the extended key usage %d is required", usage)
+		}
+	}
+	return nil
+}
+
+func loadExternalKey(keyFile string, cert *x509.Certificate) (crypto.Signer, error) {
+	privKey, err := keyutil.PrivateKeyFromFile(keyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't load the private key file %s", keyFile)
+	}
+	key, ok := privKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("the private key file %s is not a signer", keyFile)
+	}
+
+	pub, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't marshal the public key of %s", keyFile)
+	}
+	certPub, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't marshal the public key of the certificate")
+	}
+	if !bytes.Equal(pub, certPub) {
+		return nil, errors.Errorf("the private key file %s does not match the certificate", keyFile)
+	}
+	return key, nil
+}
+
+// WriteExternalCA writes the CA and the key supplied by users to the given location.
+// The CA file only has the CA, which may be the signing CA of kube-controller-manager, and the CA is the trust
+// anchor of the components. The CA and its intermediate CAs up to the root CA are written to the chain file,
+// which is appended to the serving certs signed by the CA.
+// An existing CA which is different from the supplied one is never replaced, since the certs in use are signed by it.
+func WriteExternalCA(pkiPath, name string, chain []*x509.Certificate, key crypto.Signer) error {
+	if existing, err := TryLoadCertFromDisk(pkiPath, name); err == nil && !existing.Equal(chain[0]) {
+		return errors.Errorf("the supplied CA is different from the %s CA in use, rotate the CA instead", name)
+	}
+
+	if err := certutil.WriteCert(pathForCert(pkiPath, name), EncodeCertPEM(chain[0])); err != nil {
+		return errors.Wrapf(err, "unable to write the CA of %s", name)
+	}
+	chainPath := pathForCert(pkiPath, caChainName(name))
+	if len(chain) == 1 {
+		if err := os.RemoveAll(chainPath); err != nil {
+			return errors.Wrapf(err, "unable to remove the CA chain of %s", name)
+		}
+	} else {
+		// The root CA is not served.
+		var data []byte
+		for _, c := range chain[:len(chain)-1] {
+			data = append(data, EncodeCertPEM(c)...)
+		}
+		if err := certutil.WriteCert(chainPath, data); err != nil {
+			return errors.Wrapf(err, "unable to write the CA chain of %s", name)
+		}
+	}
+	if key != nil {
+		return WriteKey(pkiPath, name, key)
+	}
+	return nil
+}
+
+// caChainName returns the base name of the chain file of the CA.
+func caChainName(caName string) string {
+	return caName + "-chain"
+}
+
+// appendCAChain appends the chain of the CA supplied by users to the serving cert signed by it, so that the clients
+// which only trust the root CA can verify the cert. Nothing is done if the CA has no chain file.
+func appendCAChain(pkiPath, caName string, certSpec *KubekeyCert) error {
+	chain, err := ioutil.ReadFile(pathForCert(pkiPath, caChainName(caName)))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "unable to read the CA chain of %s", caName)
+	}
+	cert, intermediates, err := TryLoadCertChainFromDisk(pkiPath, certSpec.BaseName)
+	if err != nil {
+		return err
+	}
+	if !HasServerAuth(cert) || len(intermediates) != 0 {
+		return nil
+	}
+	if err := certutil.WriteCert(pathForCert(pkiPath, certSpec.BaseName), append(EncodeCertPEM(cert), chain...)); err != nil {
+		return errors.Wrapf(err, "unable to write the certificate %s", certSpec.BaseName)
+	}
+	return nil
+}
+
+// WriteExternalCert writes a pre-issued cert supplied by users, with its intermediate CAs, to the given location.
+func WriteExternalCert(pkiPath string, cert *kubekeyapiv1alpha2.PKICert) error {
+	certData, err := ioutil.ReadFile(cert.CertFile)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't read the certificate file %s", cert.CertFile)
+	}
+	keyData, err := ioutil.ReadFile(cert.KeyFile)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't read the private key file %s", cert.KeyFile)
+	}
+	if err := certutil.WriteCert(pathForCert(pkiPath, cert.Name), certData); err != nil {
+		return errors.Wrapf(err, "unable to write the certificate %s", cert.Name)
+	}
+	return keyutil.WriteKey(pathForKey(pkiPath, cert.Name), keyData)
+}
+
+// WriteExternalPKI writes the CA and the pre-issued certs supplied by users to the given location.
+// It returns the certs in certsList which are not supplied and still need to be signed by the CA.
+func WriteExternalPKI(pki *kubekeyapiv1alpha2.PKI, ca *KubekeyCert, certsList []*KubekeyCert, pkiPath string) ([]*KubekeyCert, error) {
+	chain, key, err := LoadExternalCA(pki)
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteExternalCA(pkiPath, ca.BaseName, chain, key); err != nil {
+		return nil, err
+	}
+
+	var unsigned []*KubekeyCert
+	for _, c := range certsList {
+		supplied := pki.Cert(c.BaseName)
+		if supplied == nil {
+			if key == nil {
+				return nil, errors.Errorf("the certificate %s is not supplied, and the key of the CA is required to sign it", c.BaseName)
+			}
+			unsigned = append(unsigned, c)
+			continue
+		}
+		if _, _, err := LoadExternalCert(supplied, chain, &c.Config); err != nil {
+			return nil, err
+		}
+		if err := WriteExternalCert(pkiPath, supplied); err != nil {
+			return nil, err
+		}
+	}
+	return unsigned, nil
+}
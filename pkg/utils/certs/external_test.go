@@ -0,0 +1,196 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto"
+	"crypto/x509"
+	"net"
+	"path/filepath"
+	"testing"
+
+	certutil "k8s.io/client-go/util/cert"
+
+	kubekeyapiv1alpha2 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha2"
+)
+
+type testPKI struct {
+	dir          string
+	root         *x509.Certificate
+	intermediate *x509.Certificate
+	key          crypto.Signer
+}
+
+// newTestPKI creates a root CA and an intermediate CA signed by it, and writes the key of the intermediate CA.
+func newTestPKI(t *testing.T) *testPKI {
+	dir := t.TempDir()
+	root, rootKey, err := NewCertificateAuthority(&CertConfig{Config: certutil.Config{CommonName: "root-ca"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := NewPrivateKey(x509.RSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intermediate, err := NewSignedCert(&CertConfig{Config: certutil.Config{CommonName: "intermediate-ca"}}, key, root, rootKey, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteKey(dir, "intermediate", key); err != nil {
+		t.Fatal(err)
+	}
+	return &testPKI{dir: dir, root: root, intermediate: intermediate, key: key}
+}
+
+func (p *testPKI) writeCerts(t *testing.T, name string, chain ...*x509.Certificate) string {
+	var data []byte
+	for _, c := range chain {
+		data = append(data, EncodeCertPEM(c)...)
+	}
+	path := filepath.Join(p.dir, name+".pem")
+	if err := certutil.WriteCert(path, data); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func (p *testPKI) issue(t *testing.T, name string, cfg *CertConfig) *kubekeyapiv1alpha2.PKICert {
+	key, err := NewPrivateKey(x509.RSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := NewSignedCert(cfg, key, p.intermediate, p.key, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteKey(p.dir, name, key); err != nil {
+		t.Fatal(err)
+	}
+	certFile := p.writeCerts(t, name, cert)
+	_, keyFile := PathsForCertAndKey(p.dir, name)
+	return &kubekeyapiv1alpha2.PKICert{Name: name, CertFile: certFile, KeyFile: keyFile}
+}
+
+func TestLoadExternalCA(t *testing.T) {
+	p := newTestPKI(t)
+	_, keyFile := PathsForCertAndKey(p.dir, "intermediate")
+
+	chain, key, err := LoadExternalCA(&kubekeyapiv1alpha2.PKI{CAFile: p.writeCerts(t, "chain", p.intermediate, p.root), CAKeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chain) != 2 || !chain[0].Equal(p.intermediate) || key == nil {
+		t.Fatalf("unexpected CA loaded: %d certs, key %v", len(chain), key)
+	}
+
+	if _, key, err := LoadExternalCA(&kubekeyapiv1alpha2.PKI{CAFile: p.writeCerts(t, "chain", p.intermediate, p.root)}); err != nil || key != nil {
+		t.Fatalf("expected the CA without a key, got key %v, error %v", key, err)
+	}
+	if _, _, err := LoadExternalCA(&kubekeyapiv1alpha2.PKI{CAFile: p.writeCerts(t, "incomplete", p.intermediate)}); err == nil {
+		t.Fatal("expected an error for the chain without the root CA")
+	}
+	_, leafKeyFile := PathsForCertAndKey(p.dir, "leaf")
+	p.issue(t, "leaf", &CertConfig{Config: certutil.Config{CommonName: "leaf"}})
+	if _, _, err := LoadExternalCA(&kubekeyapiv1alpha2.PKI{CAFile: p.writeCerts(t, "root", p.root), CAKeyFile: leafKeyFile}); err == nil {
+		t.Fatal("expected an error for the key which does not match the CA")
+	}
+}
+
+func TestLoadExternalCert(t *testing.T) {
+	p := newTestPKI(t)
+	chain := []*x509.Certificate{p.intermediate, p.root}
+	serverCfg := func() *CertConfig {
+		return &CertConfig{Config: certutil.Config{
+			CommonName: "server",
+			Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			AltNames: certutil.AltNames{
+				DNSNames: []string{"localhost"},
+				IPs:      []net.IP{net.IPv4(127, 0, 0, 1)},
+			},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		issued  *CertConfig
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			issued: serverCfg(),
+		},
+		{
+			name: "missing SAN",
+			issued: &CertConfig{Config: certutil.Config{
+				CommonName: "server",
+				Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+				AltNames:   certutil.AltNames{DNSNames: []string{"localhost"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "missing key usage",
+			issued: &CertConfig{Config: certutil.Config{
+				CommonName: "server",
+				Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+				AltNames:   serverCfg().AltNames,
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := p.issue(t, "server", tt.issued)
+			if _, _, err := LoadExternalCert(cert, chain, serverCfg()); (err != nil) != tt.wantErr {
+				t.Errorf("LoadExternalCert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	other := newTestPKI(t)
+	cert := other.issue(t, "server", serverCfg())
+	if _, _, err := LoadExternalCert(cert, chain, serverCfg()); err == nil {
+		t.Error("expected an error for the cert issued by another CA")
+	}
+}
+
+func TestWriteExternalCA(t *testing.T) {
+	p := newTestPKI(t)
+	dir := t.TempDir()
+	if err := WriteExternalCA(dir, "ca", []*x509.Certificate{p.intermediate, p.root}, p.key); err != nil {
+		t.Fatal(err)
+	}
+	if certs, err := certutil.CertsFromFile(pathForCert(dir, "ca")); err != nil || len(certs) != 1 || !certs[0].Equal(p.intermediate) {
+		t.Fatalf("expected only the intermediate CA in the CA file, got %d certs, error %v", len(certs), err)
+	}
+
+	ca := &KubekeyCert{Name: "ca", BaseName: "ca"}
+	server := &KubekeyCert{Name: "server", BaseName: "server", CAName: "ca", Config: CertConfig{Config: certutil.Config{
+		CommonName: "server",
+		Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}}}
+	if err := CreateCertAndKeyFilesWithCA(ca, server, dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, intermediates, err := TryLoadCertChainFromDisk(dir, "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intermediates) != 1 || !intermediates[0].Equal(p.intermediate) {
+		t.Errorf("expected the serving cert to be followed by the intermediate CA, got %d certs", len(intermediates))
+	}
+}
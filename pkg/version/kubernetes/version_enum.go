@@ -18,7 +18,10 @@ package kubernetes
 
 import (
 	"fmt"
+
 	versionutil "k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/kubesphere/kubekey/pkg/files"
 )
 
 type Version int
@@ -86,71 +89,7 @@ func VersionSupport(version string) bool {
 	return false
 }
 
-// SupportedK8sVersionList returns the supported list of Kubernetes
+// SupportedK8sVersionList returns the supported list of Kubernetes, which are the kubeadm versions in the binary catalog.
 func SupportedK8sVersionList() []string {
-	return []string{
-		"v1.15.12",
-		"v1.16.8",
-		"v1.16.10",
-		"v1.16.12",
-		"v1.16.13",
-		"v1.17.0",
-		"v1.17.4",
-		"v1.17.5",
-		"v1.17.6",
-		"v1.17.7",
-		"v1.17.8",
-		"v1.17.9",
-		"v1.18.3",
-		"v1.18.5",
-		"v1.18.6",
-		"v1.18.8",
-		"v1.19.0",
-		"v1.19.8",
-		"v1.19.9",
-		"v1.20.4",
-		"v1.20.6",
-		"v1.20.10",
-		"v1.21.0",
-		"v1.21.1",
-		"v1.21.2",
-		"v1.21.3",
-		"v1.21.4",
-		"v1.21.5",
-		"v1.21.6",
-		"v1.21.7",
-		"v1.21.8",
-		"v1.21.9",
-		"v1.21.10",
-		"v1.21.11",
-		"v1.21.12",
-		"v1.21.13",
-		"v1.22.0",
-		"v1.22.1",
-		"v1.22.2",
-		"v1.22.3",
-		"v1.22.4",
-		"v1.22.5",
-		"v1.22.6",
-		"v1.22.7",
-		"v1.22.8",
-		"v1.22.9",
-		"v1.22.10",
-		"v1.22.11",
-		"v1.22.12",
-		"v1.23.0",
-		"v1.23.1",
-		"v1.23.2",
-		"v1.23.3",
-		"v1.23.4",
-		"v1.23.5",
-		"v1.23.6",
-		"v1.23.7",
-		"v1.23.8",
-		"v1.23.9",
-		"v1.24.0",
-		"v1.24.1",
-		"v1.24.2",
-		"v1.24.3",
-	}
+	return files.GetCatalog().Versions("kubeadm", "amd64")
 }